@@ -2,21 +2,23 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"ElectronicQueue/internal/cluster"
 	"ElectronicQueue/internal/config"
 	"ElectronicQueue/internal/database"
 	"ElectronicQueue/internal/handlers"
 	"ElectronicQueue/internal/logger"
 	"ElectronicQueue/internal/middleware"
 	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/notification"
 	"ElectronicQueue/internal/pubsub"
 	"ElectronicQueue/internal/repository"
 	"ElectronicQueue/internal/services"
@@ -61,26 +63,78 @@ func main() {
 	}
 	log.WithField("dbname", cfg.DBName).Info("Database connected successfully")
 
-	repo := repository.NewRepository(db)
-	processService, err := services.NewBusinessProcessService(repo.BusinessProcess)
+	piiCipher, err := utils.NewPIICipher(cfg.PIIEncryptionKeys, cfg.PIIActiveKey, cfg.PIIIndexKey)
 	if err != nil {
-		log.WithError(err).Fatal("Failed to initialize Business Process Service")
+		log.WithError(err).Fatal("Failed to initialize patient data encryption")
 	}
+	models.SetPIICipher(piiCipher)
 
-	notificationChannel := make(chan string, 100)
+	repo := repository.NewRepository(db)
+
+	// Карточки, сохраненные до включения шифрования или зашифрованные прежним ключом, перешифровываются при запуске.
+	if count, err := repo.Patient.ReencryptPII(200); err != nil {
+		log.WithError(err).Error("Failed to re-encrypt patient personal data")
+	} else if count > 0 {
+		log.WithField("patients", count).Info("Patient personal data re-encrypted with the active key")
+	}
 	listenerCtx, cancelListener := context.WithCancel(context.Background())
 
-	psBroker := pubsub.NewBroker()
-	go psBroker.ListenAndPublish(notificationChannel)
+	pubsubBufferSize, err := strconv.Atoi(cfg.PubSubBufferSize)
+	if err != nil || pubsubBufferSize <= 0 {
+		log.WithField("value", cfg.PubSubBufferSize).Fatal("Invalid PUBSUB_BUFFER_SIZE value")
+	}
+	pubsubMaxDrops, err := strconv.Atoi(cfg.PubSubMaxDrops)
+	if err != nil || pubsubMaxDrops < 0 {
+		log.WithField("value", cfg.PubSubMaxDrops).Fatal("Invalid PUBSUB_MAX_DROPS value")
+	}
+	pubsubReplaySize, err := strconv.Atoi(cfg.PubSubReplaySize)
+	if err != nil || pubsubReplaySize < 0 {
+		log.WithField("value", cfg.PubSubReplaySize).Fatal("Invalid PUBSUB_REPLAY_SIZE value")
+	}
+	psBroker := pubsub.NewBroker(pubsubBufferSize, pubsubMaxDrops, pubsubReplaySize)
+	sseMaxPerEndpoint, err := strconv.Atoi(cfg.SSEMaxClientsPerEndpoint)
+	if err != nil || sseMaxPerEndpoint < 0 {
+		log.WithField("value", cfg.SSEMaxClientsPerEndpoint).Fatal("Invalid SSE_MAX_CLIENTS_PER_ENDPOINT value")
+	}
+	sseMaxPerIP, err := strconv.Atoi(cfg.SSEMaxClientsPerIP)
+	if err != nil || sseMaxPerIP < 0 {
+		log.WithField("value", cfg.SSEMaxClientsPerIP).Fatal("Invalid SSE_MAX_CLIENTS_PER_IP value")
+	}
+	sseConns, err := middleware.NewSSEConnections(cfg.SSEHeartbeatInterval, cfg.SSEWriteTimeout, sseMaxPerEndpoint, sseMaxPerIP)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid SSE connection settings")
+	}
 
 	pool, err := initPgxPool(listenerCtx, cfg)
 	if err != nil {
 		log.WithError(err).Fatal("Failed to initialize database listener with pgx")
 	}
 
-	go listenForNotifications(listenerCtx, pool, notificationChannel, log)
+	listener, err := pubsub.NewListener(pubsub.NewPgxSource(pool), psBroker,
+		[]string{pubsub.ChannelTicketUpdate, pubsub.ChannelScheduleUpdate}, cfg.ListenerReconnectMin, cfg.ListenerReconnectMax)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid database listener settings")
+	}
 
-	r := setupRouter(psBroker, db, cfg, processService)
+	// Несколько экземпляров сервера обмениваются событиями и сбросами кэшей через NOTIFY,
+	// а фоновые задания выполняет только экземпляр, удерживающий advisory-блокировку.
+	instanceID := cluster.NewInstanceID()
+	clusterBackend := cluster.NewPgxBackend(pool)
+	relay := cluster.NewRelay(instanceID, psBroker, clusterBackend, listener)
+	elector, err := cluster.NewElector(instanceID, clusterBackend, cluster.JobsLockKey, cfg.LeaderElectionInterval)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid leader election settings")
+	}
+	log.WithField("instance_id", instanceID).Info("Cluster instance initialized")
+
+	processService, err := services.NewBusinessProcessService(repo.BusinessProcess, relay)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize Business Process Service")
+	}
+
+	go listener.Run(listenerCtx)
+
+	r := setupRouter(listenerCtx, psBroker, relay, elector, listener, sseConns, db, cfg, processService)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -111,50 +165,25 @@ func initPgxPool(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error)
 	return pool, nil
 }
 
-// listenForNotifications слушает LISTEN/NOTIFY и отправляет в указанный канал
-func listenForNotifications(ctx context.Context, pool *pgxpool.Pool, notifications chan<- string, log *logger.AsyncLogger) {
-	conn, err := pool.Acquire(ctx)
-	if err != nil {
-		log.WithError(err).Error("Listener: Failed to acquire connection from pool")
-		return
-	}
-	defer conn.Release()
-
-	_, err = conn.Exec(ctx, "LISTEN ticket_update")
-	if err != nil {
-		log.WithError(err).Error("Listener: Failed to execute LISTEN command for ticket_update")
-		return
-	}
-	log.Info("Listener: Listening to 'ticket_update' channel")
-
-	_, err = conn.Exec(ctx, "LISTEN schedule_update")
-	if err != nil {
-		log.WithError(err).Error("Listener: Failed to execute LISTEN command for schedule_update")
-		return
-	}
-	log.Info("Listener: Listening to 'schedule_update' channel")
-
-	for {
-		notification, err := conn.Conn().WaitForNotification(ctx)
-		if err != nil {
-			if ctx.Err() != nil {
-				log.Info("Listener context cancelled, shutting down.")
-				return
-			}
-			log.WithError(err).Error("Listener: Error waiting for notification")
-			time.Sleep(5 * time.Second)
-			continue
+// parseTrustedProxies разбирает список доверенных прокси через запятую. Пустой список — nil:
+// заголовки X-Forwarded-For игнорируются, IP клиента берется из соединения.
+func parseTrustedProxies(value string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
 		}
-		log.WithField("channel", notification.Channel).Info("Listener: Received notification")
-		notifications <- notification.Payload
 	}
+	return proxies
 }
 
 // setupRouter настраивает маршруты и middleware
-func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, processService *services.BusinessProcessService) *gin.Engine {
+func setupRouter(ctx context.Context, broker *pubsub.Broker, relay *cluster.Relay, elector *cluster.Elector, listener *pubsub.Listener, sseConns *middleware.SSEConnections, db *gorm.DB, cfg *config.Config, processService *services.BusinessProcessService) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
-	r.SetTrustedProxies(nil)
+	if err := r.SetTrustedProxies(parseTrustedProxies(cfg.TrustedProxies)); err != nil {
+		logger.Default().WithError(err).WithField("value", cfg.TrustedProxies).Fatal("Invalid TRUSTED_PROXIES value")
+	}
 	r.Use(logger.GinLogger())
 	r.Use(middleware.CorsMiddleware())
 
@@ -165,21 +194,79 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 
 	repo := repository.NewRepository(db)
 
-	ticketService := services.NewTicketService(repo.Ticket, repo.Service, repo.ReceptionLog, repo.Patient, repo.Appointment, repo.RegistrarPriority)
-	doctorService := services.NewDoctorService(repo.Ticket, repo.Doctor, repo.Schedule, broker)
+	notifier, err := notification.NewChannel(cfg)
+	if err != nil {
+		logger.Default().WithError(err).Fatal("Failed to initialize notification channel")
+	}
+	bookingRulesService := services.NewBookingRulesService(repo.BookingRule, repo.Appointment, repo.Schedule, repo.Doctor, repo.Patient)
+	waitlistService, err := services.NewWaitlistService(repo.Waitlist, repo.Doctor, repo.Patient, bookingRulesService, notifier, cfg.WaitlistHoldDuration)
+	if err != nil {
+		logger.Default().WithError(err).Fatal("Failed to initialize Waitlist Service")
+	}
+
+	notificationService, err := services.NewNotificationService(repo.Notification, notifier, cfg.NotificationReminderLead, cfg.NotificationPollInterval, cfg.NotificationMaxAttempts)
+	if err != nil {
+		logger.Default().WithError(err).Fatal("Failed to initialize Notification Service")
+	}
+
+	checkInCodes, err := utils.NewCheckInCodeSigner(cfg.CheckInCodeSecret)
+	if err != nil {
+		logger.Default().WithError(err).Fatal("Failed to initialize check-in code signer")
+	}
+	if cfg.CheckInCodeSecret == cfg.JWTSecret {
+		logger.Default().Fatal("CHECKIN_CODE_SECRET must differ from JWT_SECRET")
+	}
+
+	calendarService := services.NewCalendarService(repo.Calendar, repo.Appointment, cfg.PublicNameFormat)
+	bookingService, err := services.NewBookingService(repo.Doctor, repo.Schedule, repo.Patient, repo.Appointment, waitlistService, bookingRulesService, calendarService, checkInCodes, cfg.BookingMaxActive, cfg.BookingHorizonDays, cfg.BookingCancelCutoff)
+	if err != nil {
+		logger.Default().WithError(err).Fatal("Failed to initialize Booking Service")
+	}
+	externalRateLimit, err := strconv.Atoi(cfg.ExternalRateLimit)
+	if err != nil || externalRateLimit <= 0 {
+		logger.Default().WithField("value", cfg.ExternalRateLimit).Fatal("Invalid EXTERNAL_RATE_LIMIT value")
+	}
+
+	ticketService, err := services.NewTicketService(repo.Ticket, repo.Service, repo.ReceptionLog, repo.Patient, repo.Appointment, repo.RegistrarPriority, checkInCodes, repo.CheckInLog, repo.RoutingRule, cfg.CheckInEarlyWindow, cfg.CheckInLateWindow)
+	if err != nil {
+		logger.Default().WithError(err).Fatal("Failed to initialize Ticket Service")
+	}
+	doctorService := services.NewDoctorService(repo.Ticket, repo.Doctor, repo.Schedule, relay, cfg.PublicNameFormat)
 	authService := services.NewAuthService(repo.Registrar, repo.Doctor, repo.Administrator, jwtManager)
 	databaseService := services.NewDatabaseService(repository.NewDatabaseRepository(db))
-	patientService := services.NewPatientService(repo.Patient)
-	appointmentService := services.NewAppointmentService(repo.Appointment, repo.Ticket)
+	referralService := services.NewReferralService(repo.Referral, repo.Appointment, repo.Schedule, repo.Doctor)
+	patientRetentionYears, err := strconv.Atoi(cfg.PatientRetentionYears)
+	if err != nil || patientRetentionYears < 0 {
+		logger.Default().WithField("value", cfg.PatientRetentionYears).Fatal("Invalid PATIENT_RETENTION_YEARS value")
+	}
+	patientAccessThreshold, err := strconv.Atoi(cfg.PatientAccessAnomalyLimit)
+	if err != nil || patientAccessThreshold <= 0 {
+		logger.Default().WithField("value", cfg.PatientAccessAnomalyLimit).Fatal("Invalid PATIENT_ACCESS_ANOMALY_THRESHOLD value")
+	}
+	patientAccessService := services.NewPatientAccessService(repo.PatientAccess, patientAccessThreshold)
+	patientService := services.NewPatientService(repo.Patient, repo.PatientAudit, repo.Visit, referralService, patientRetentionYears)
+	appointmentService := services.NewAppointmentService(repo.Appointment, repo.Ticket, repo.Registrar, waitlistService, bookingRulesService, referralService, checkInCodes)
 	cleanupService := services.NewCleanupService(repo.Cleanup)
-	tasksTimerService := services.NewTasksTimerService(cleanupService, cfg)
-	scheduleService := services.NewScheduleService(repo.Schedule, repo.Doctor)
-	adService := services.NewAdService(repo.Ad)
+	tasksTimerService := services.NewTasksTimerService(cleanupService, patientService, cfg)
+	scheduleService := services.NewScheduleService(repo.Schedule, repo.Doctor, waitlistService)
+	adService := services.NewAdService(repo.Ad, relay)
+	routingService := services.NewRoutingService(repo.RoutingRule, repo.Service)
 	registrarService := services.NewRegistrarService(repo.RegistrarPriority, repo.Service)
+	displayService := services.NewDisplayService(repo.Display, repo.Ad, relay)
+
+	go elector.Run(ctx, func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for _, job := range []func(context.Context){tasksTimerService.Start, waitlistService.StartHoldWatcher, notificationService.Start} {
+			wg.Add(1)
+			go func(job func(context.Context)) {
+				defer wg.Done()
+				job(ctx)
+			}(job)
+		}
+		wg.Wait()
+	})
 
-	go tasksTimerService.Start(context.Background())
-
-	ticketHandler := handlers.NewTicketHandler(ticketService, cfg)
+	ticketHandler := handlers.NewTicketHandler(ticketService, broker, cfg)
 	doctorHandler := handlers.NewDoctorHandler(doctorService, broker)
 	registrarHandler := handlers.NewRegistrarHandler(ticketService, registrarService)
 	authHandler := handlers.NewAuthHandler(authService)
@@ -190,12 +277,24 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 	scheduleHandler := handlers.NewScheduleHandler(scheduleService, broker)
 	processHandler := handlers.NewBusinessProcessHandler(processService)
 	adHandler := handlers.NewAdHandler(adService)
-
-	r.GET("/tickets", middleware.CheckBusinessProcess(processService, "reception"), sseHandler(broker, "reception_sse"))
+	routingHandler := handlers.NewRoutingHandler(routingService)
+	bookingRuleHandler := handlers.NewBookingRuleHandler(bookingRulesService)
+	referralHandler := handlers.NewReferralHandler(referralService)
+	waitlistHandler := handlers.NewWaitlistHandler(waitlistService)
+	bookingHandler := handlers.NewBookingHandler(bookingService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	patientAccessHandler := handlers.NewPatientAccessHandler(patientAccessService)
+	pubsubHandler := handlers.NewPubSubHandler(broker, listener, sseConns)
+	clusterHandler := handlers.NewClusterHandler(elector)
+	displayHandler := handlers.NewDisplayHandler(displayService, broker)
+
+	r.GET("/tickets", middleware.CheckBusinessProcess(processService, "reception"), middleware.LimitSSE(sseConns, "reception"), ticketHandler.ReceptionUpdates)
 
 	r.GET("/api/doctor/queue-all", middleware.CheckBusinessProcess(processService, "queue_doctor"), doctorHandler.GetAllDoctorQueues)
 
-	r.GET("/api/doctor/screen-updates/:cabinet_number", middleware.CheckBusinessProcess(processService, "queue_doctor"), doctorHandler.DoctorScreenUpdates)
+	r.GET("/api/doctor/screen-updates/:cabinet_number", middleware.CheckBusinessProcess(processService, "queue_doctor"), middleware.LimitSSE(sseConns, "doctor_screen"), doctorHandler.DoctorScreenUpdates)
+
+	r.GET("/api/calendar/doctor/:token", middleware.AuditPatientAccess(patientAccessService, "doctor"), calendarHandler.DoctorFeed)
 
 	auth := r.Group("/api/auth")
 	{
@@ -204,12 +303,17 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 		auth.POST("/login/administrator", authHandler.LoginAdministrator)
 	}
 
-	admin := r.Group("/api/admin").Use(middleware.RequireAPIKey(cfg.InternalAPIKey))
+	admin := r.Group("/api/admin").
+		Use(middleware.RequireAPIKey(cfg.InternalAPIKey)).
+		Use(middleware.AuditPatientAccess(patientAccessService, "admin"))
 	{
 		admin.POST("/create/doctor", authHandler.CreateDoctor)
 		admin.POST("/create/registrar", authHandler.CreateRegistrar)
 		admin.DELETE("/tickets/:id", registrarHandler.DeleteTicket)
 		admin.POST("/schedules", scheduleHandler.CreateSchedule)
+		admin.POST("/schedules/import", scheduleHandler.ImportSchedules)
+		admin.GET("/schedules/export", scheduleHandler.ExportSchedules)
+		admin.PATCH("/schedules/:id", scheduleHandler.UpdateSchedule)
 		admin.DELETE("/schedules/:id", scheduleHandler.DeleteSchedule)
 		admin.POST("/create/administrator", authHandler.CreateAdministrator)
 		admin.GET("/processes", processHandler.GetAllProcesses)
@@ -220,6 +324,36 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 		admin.GET("/ads/:id", adHandler.GetAdByID)
 		admin.PATCH("/ads/:id", adHandler.UpdateAd)
 		admin.DELETE("/ads/:id", adHandler.DeleteAd)
+
+		admin.GET("/displays", displayHandler.GetDisplays)
+		admin.POST("/displays", displayHandler.CreateDisplay)
+		admin.GET("/displays/:id", displayHandler.GetDisplay)
+		admin.PATCH("/displays/:id", displayHandler.UpdateDisplay)
+		admin.DELETE("/displays/:id", displayHandler.DeleteDisplay)
+		admin.POST("/displays/:id/token", displayHandler.RotateDisplayToken)
+
+		admin.GET("/routing-rules", routingHandler.GetRoutingRules)
+		admin.POST("/routing-rules", routingHandler.CreateRoutingRule)
+		admin.DELETE("/routing-rules/:id", routingHandler.DeleteRoutingRule)
+
+		admin.GET("/booking-rules", bookingRuleHandler.GetBookingRules)
+		admin.PUT("/booking-rules", bookingRuleHandler.UpsertBookingRule)
+		admin.DELETE("/booking-rules/:id", bookingRuleHandler.DeleteBookingRule)
+
+		admin.GET("/patients/duplicates", patientHandler.GetAllDuplicates)
+		admin.POST("/patients/merge", patientHandler.MergePatients)
+		admin.GET("/patients/merges", patientHandler.GetMerges)
+		admin.POST("/patients/pii/rotate", patientHandler.RotatePIIKeys)
+		admin.POST("/patients/:patient_id/anonymize", patientHandler.AnonymizePatient)
+		admin.POST("/patients/retention/run", patientHandler.RunRetention)
+		admin.GET("/patients/anonymizations", patientHandler.GetAnonymizations)
+		admin.GET("/patient-access", patientAccessHandler.GetAccessLog)
+		admin.GET("/patient-access/anomalies", patientAccessHandler.GetAnomalies)
+
+		admin.GET("/pubsub/stats", pubsubHandler.GetStats)
+		admin.GET("/sse/connections", pubsubHandler.GetConnections)
+		admin.GET("/pubsub/listener", pubsubHandler.GetListenerHealth)
+		admin.GET("/cluster", clusterHandler.GetStatus)
 	}
 
 	tickets := r.Group("/api/tickets").Use(middleware.CheckBusinessProcess(processService, "terminal"))
@@ -229,6 +363,7 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 		tickets.POST("/print/selection", ticketHandler.Selection)
 		tickets.POST("/print/confirmation", ticketHandler.Confirmation)
 		tickets.POST("/appointment/phone", ticketHandler.CheckInByPhone)
+		tickets.POST("/appointment/checkin", ticketHandler.CheckIn)
 		tickets.GET("/download/:ticket_number", ticketHandler.DownloadTicket)
 		tickets.GET("/view/:ticket_number", ticketHandler.ViewTicket)
 	}
@@ -242,7 +377,8 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 
 	protectedDoctorGroup := r.Group("/api/doctor").
 		Use(middleware.RequireRole(jwtManager, "doctor")).
-		Use(middleware.CheckBusinessProcess(processService, "doctor"))
+		Use(middleware.CheckBusinessProcess(processService, "doctor")).
+		Use(middleware.AuditPatientAccess(patientAccessService, "doctor"))
 	{
 		protectedDoctorGroup.GET("/tickets/registered", doctorHandler.GetRegisteredTickets)
 		protectedDoctorGroup.GET("/tickets/in-progress", doctorHandler.GetInProgressTickets)
@@ -252,11 +388,15 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 		protectedDoctorGroup.POST("/end-break", doctorHandler.EndBreak)
 		protectedDoctorGroup.POST("/set-active", doctorHandler.SetDoctorActive)
 		protectedDoctorGroup.POST("/set-inactive", doctorHandler.SetDoctorInactive)
+		protectedDoctorGroup.POST("/calendar/token", calendarHandler.IssueFeedToken)
+		protectedDoctorGroup.POST("/referrals", referralHandler.CreateReferral)
+		protectedDoctorGroup.GET("/patients/:patient_id/timeline", patientHandler.GetPatientTimeline)
 	}
 
 	registrar := r.Group("/api/registrar").
 		Use(middleware.RequireRole(jwtManager, "registrar")).
-		Use(middleware.CheckBusinessProcess(processService, "registry"))
+		Use(middleware.CheckBusinessProcess(processService, "registry")).
+		Use(middleware.AuditPatientAccess(patientAccessService, "registrar"))
 	{
 		registrar.POST("/call-next", registrarHandler.CallNext)
 		registrar.POST("/call-specific", registrarHandler.CallSpecific)
@@ -265,12 +405,29 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 		registrar.PATCH("/tickets/:id/status", registrarHandler.UpdateStatus)
 		registrar.GET("/patients/search", patientHandler.SearchPatients)
 		registrar.POST("/patients", patientHandler.CreatePatient)
+		registrar.PATCH("/patients/:patient_id", patientHandler.UpdatePatient)
+		registrar.GET("/patients/:patient_id/duplicates", patientHandler.GetPatientDuplicates)
+		registrar.GET("/patients/:patient_id/history", patientHandler.GetPatientHistory)
+		registrar.GET("/patients/:patient_id/timeline", patientHandler.GetPatientTimeline)
 		registrar.GET("/schedules/doctor/:doctor_id", appointmentHandler.GetDoctorSchedule)
 		registrar.POST("/appointments", appointmentHandler.CreateAppointment)
 		registrar.GET("/patients/:patient_id/appointments", appointmentHandler.GetPatientAppointments)
 		registrar.DELETE("/appointments/:id", appointmentHandler.DeleteAppointment)
 		registrar.PATCH("/appointments/:id/confirm", appointmentHandler.ConfirmAppointment)
+		registrar.PATCH("/appointments/:id/reschedule", appointmentHandler.RescheduleAppointment)
+		registrar.GET("/appointments/:id/ics", calendarHandler.AppointmentICS)
+		registrar.GET("/appointments/:id/checkin-code", appointmentHandler.GetCheckInCode)
+		registrar.PATCH("/patients/:patient_id/notifications", patientHandler.UpdateNotificationSettings)
+		registrar.GET("/patients/:patient_id/referrals", referralHandler.GetPatientReferrals)
+		registrar.POST("/referrals/:id/cancel", referralHandler.CancelReferral)
+		registrar.GET("/waitlist", waitlistHandler.GetEntries)
+		registrar.POST("/waitlist", waitlistHandler.CreateEntry)
+		registrar.POST("/waitlist/:id/confirm", waitlistHandler.ConfirmHold)
+		registrar.POST("/waitlist/:id/release", waitlistHandler.ReleaseHold)
+		registrar.DELETE("/waitlist/:id", waitlistHandler.CancelEntry)
 		registrar.GET("/reports/daily", registrarHandler.GetDailyReport)
+		registrar.GET("/reports/checkins", registrarHandler.GetCheckInReport)
+		registrar.GET("/reports/referrals/expired", referralHandler.GetExpiredReferralsReport)
 		registrar.GET("/services", registrarHandler.GetAllServices)
 		registrar.GET("/priorities", registrarHandler.GetPriorities)
 		registrar.POST("/priorities", registrarHandler.SetPriorities)
@@ -278,7 +435,8 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 
 	dbAPI := r.Group("/api/database").
 		Use(middleware.RequireAPIKey(cfg.ExternalAPIKey)).
-		Use(middleware.CheckBusinessProcess(processService, "database"))
+		Use(middleware.CheckBusinessProcess(processService, "database")).
+		Use(middleware.AuditPatientAccess(patientAccessService, "database"))
 	{
 		dbAPI.POST("/:table/select", databaseHandler.GetData)
 		dbAPI.POST("/:table/insert", databaseHandler.InsertData)
@@ -286,6 +444,21 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 		dbAPI.DELETE("/:table/delete", databaseHandler.DeleteData)
 	}
 
+	externalAPI := r.Group("/api/external").
+		Use(middleware.RequireAPIKey(cfg.ExternalAPIKey)).
+		Use(middleware.RateLimitByAPIKey(externalRateLimit, time.Minute)).
+		Use(middleware.CheckBusinessProcess(processService, "appointment")).
+		Use(middleware.AuditPatientAccess(patientAccessService, "external"))
+	{
+		externalAPI.GET("/specializations", bookingHandler.GetSpecializations)
+		externalAPI.GET("/doctors", bookingHandler.GetDoctors)
+		externalAPI.GET("/slots", bookingHandler.GetFreeSlots)
+		externalAPI.POST("/patients/appointments", bookingHandler.GetPatientAppointments)
+		externalAPI.POST("/appointments", bookingHandler.Book)
+		externalAPI.POST("/appointments/:id/cancel", bookingHandler.Cancel)
+		externalAPI.POST("/appointments/:id/ics", bookingHandler.AppointmentCalendar)
+	}
+
 	processes := r.Group("/api/processes")
 	{
 		processes.GET("/:name", processHandler.GetProcessStatusByName)
@@ -303,55 +476,17 @@ func setupRouter(broker *pubsub.Broker, db *gorm.DB, cfg *config.Config, process
 
 	scheduleGroup := r.Group("/api/schedules").Use(middleware.CheckBusinessProcess(processService, "schedule"))
 	{
-		scheduleGroup.GET("/today/updates", scheduleHandler.GetTodayScheduleUpdates)
+		scheduleGroup.GET("/today/updates", middleware.LimitSSE(sseConns, "schedule_today"), scheduleHandler.GetTodayScheduleUpdates)
 	}
 
-	return r
-}
-
-type NotificationPayload struct {
-	Action string                `json:"action"`
-	Data   models.TicketResponse `json:"data"`
-}
-
-func sseHandler(broker *pubsub.Broker, handlerID string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Content-Type", "text/event-stream")
-		c.Header("Cache-Control", "no-cache")
-		c.Header("Connection", "keep-alive")
-		log := logger.Default().WithField("handler_id", handlerID)
-
-		clientChan := broker.Subscribe()
-		defer broker.Unsubscribe(clientChan)
-
-		c.Stream(func(w io.Writer) bool {
-			select {
-			case payloadStr, ok := <-clientChan:
-				if !ok {
-					log.Info("Client channel closed.")
-					return false
-				}
-
-				if !strings.Contains(payloadStr, "ticket_number") {
-					return true
-				}
-
-				log.WithField("payload", payloadStr).Info("SSE Handler: Sending message to client")
-				var payload NotificationPayload
-				if err := json.Unmarshal([]byte(payloadStr), &payload); err != nil {
-					log.WithError(err).Warn("Failed to unmarshal notification payload, skipping.")
-					return true
-				}
-
-				c.SSEvent(payload.Action, payload.Data)
-				return true
-
-			case <-c.Request.Context().Done():
-				log.Info("Client disconnected.")
-				return false
-			}
-		})
+	displayGroup := r.Group("/api/display").Use(middleware.RequireDisplayToken(displayService))
+	{
+		displayGroup.GET("/config", displayHandler.GetConfig)
+		displayGroup.GET("/ads", displayHandler.GetPlaylist)
+		displayGroup.GET("/updates", middleware.LimitSSE(sseConns, "display"), displayHandler.Updates)
 	}
+
+	return r
 }
 
 func handleGracefulShutdown(db *gorm.DB, pool *pgxpool.Pool, cancel context.CancelFunc, log *logger.AsyncLogger) {
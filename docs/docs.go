@@ -265,6 +265,187 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/admin/booking-rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает ограничения записи по специальностям. Пересечение записей одного пациента по времени запрещено всегда и в списке не отображается.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить правила записи (Админ)",
+                "responses": {
+                    "200": {
+                        "description": "Список правил",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BookingRule"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает или полностью заменяет правила для специальности: максимум активных записей пациента, минимальный интервал между визитами в днях, допустимый возраст пациента (например, педиатрия — max_age=17). Незаполненное поле снимает ограничение.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Задать правила записи для специальности (Админ)",
+                "parameters": [
+                    {
+                        "description": "Правила специальности",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpsertBookingRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сохраненные правила",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/booking-rules/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Снимает все ограничения специальности, кроме запрета пересечения записей.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить правила записи для специальности (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID правил",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Правила удалены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Правила не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/cluster": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает ID экземпляра, обработавшего запрос, и является ли он лидером — экземпляром, который выполняет фоновые задания (очистку талонов, напоминания, снятие удержаний слотов). Лидер выбирается через advisory-блокировку PostgreSQL; при его отключении задания подхватывает другой экземпляр.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Роль экземпляра сервера",
+                "responses": {
+                    "200": {
+                        "description": "Роль экземпляра",
+                        "schema": {
+                            "$ref": "#/definitions/cluster.Status"
+                        }
+                    }
+                }
+            }
+        },
         "/api/admin/create/administrator": {
             "post": {
                 "security": [
@@ -439,28 +620,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/processes": {
+        "/api/admin/displays": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Возвращает список всех бизнес-процессов и их текущее состояние (включен/отключен).",
+                "description": "Возвращает все зарегистрированные табло, экраны у кабинетов и киоски с их конфигурацией и временем последнего обращения.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Получить статусы всех бизнес-процессов (Админ)",
+                "summary": "Получить список устройств отображения (Админ)",
                 "responses": {
                     "200": {
-                        "description": "Список процессов",
+                        "description": "Список устройств",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.BusinessProcess"
+                                "$ref": "#/definitions/models.DisplayResponse"
                             }
                         }
                     },
@@ -474,16 +655,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/admin/processes/{name}": {
-            "patch": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Включает или отключает указанный бизнес-процесс.",
+                "description": "Регистрирует устройство с назначением reception (табло регистратуры), cabinet (экран у кабинета), schedule (табло расписания) или kiosk (терминал) и выпускает ему токен. Токен показывается только в этом ответе; его нужно ввести на устройстве.",
                 "consumes": [
                     "application/json"
                 ],
@@ -493,34 +672,27 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Обновить статус бизнес-процесса (Админ)",
+                "summary": "Зарегистрировать устройство отображения (Админ)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя процесса",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Новое состояние",
+                        "description": "Назначение и конфигурация устройства",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdateProcessRequest"
+                            "$ref": "#/definitions/models.CreateDisplayRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Обновленный процесс",
+                    "201": {
+                        "description": "Устройство и его токен",
                         "schema": {
-                            "$ref": "#/definitions/models.BusinessProcess"
+                            "$ref": "#/definitions/models.DisplayTokenResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка в запросе",
+                        "description": "Ошибка: неверная конфигурация",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -529,7 +701,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Процесс не найден",
+                        "description": "Рекламный материал из плейлиста не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -549,53 +721,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/admin/schedules": {
-            "post": {
+        "/api/admin/displays/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создает новый временной слот для врача. Требует INTERNAL_API_KEY.",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Создать слот в расписании (Админ)",
+                "summary": "Получить устройство отображения (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для создания слота",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateScheduleRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID устройства",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Успешно созданный слот",
+                    "200": {
+                        "description": "Устройство",
                         "schema": {
-                            "$ref": "#/definitions/models.Schedule"
+                            "$ref": "#/definitions/models.DisplayResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный формат запроса",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Отсутствует ключ API",
+                        "description": "Неверный формат ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -603,8 +760,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Неверный ключ API",
+                    "404": {
+                        "description": "Устройство не найдено",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -622,27 +779,25 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/admin/schedules/{id}": {
+            },
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Удаляет временной слот из расписания по его ID. Требует INTERNAL_API_KEY.",
+                "description": "Удаляет устройство из реестра. Его токен перестает действовать, подключенное устройство получает событие display_revoked и отключается.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Удалить слот из расписания (Админ)",
+                "summary": "Удалить устройство отображения (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID слота расписания",
+                        "description": "ID устройства",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -650,7 +805,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Слот успешно удален",
+                        "description": "Устройство удалено",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -659,25 +814,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный ID",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Отсутствует ключ API",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "403": {
-                        "description": "Неверный ключ API",
+                        "description": "Неверный формат ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -686,7 +823,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Слот не найден",
+                        "description": "Устройство не найдено",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -704,39 +841,60 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/ads/enabled": {
-            "get": {
-                "description": "Возвращает список всех включенных рекламных материалов с изображениями.",
-                "produces": [
-                    "application/json"
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Меняет назначение, окна, кабинеты, плейлист рекламы, звук или тему устройства. Подключенное устройство сразу получает новую конфигурацию событием display_config.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "ads"
+                    "admin"
                 ],
-                "summary": "Получить активные рекламные материалы (Табло)",
+                "summary": "Изменить устройство отображения (Админ)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Тип экрана ('reception' или 'schedule')",
-                        "name": "screen",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "ID устройства",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateDisplayRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список активных рекламных материалов",
+                        "description": "Обновленное устройство",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.AdResponse"
-                            }
+                            "$ref": "#/definitions/models.DisplayResponse"
                         }
                     },
                     "400": {
-                        "description": "Неверный тип экрана",
+                        "description": "Ошибка: неверная конфигурация",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Устройство или рекламный материал не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -756,41 +914,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/audio/announce": {
-            "get": {
-                "description": "Создает и возвращает WAV файл с озвучкой номера талона и окна.",
+        "/api/admin/displays/{id}/token": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Заменяет токен устройства, например при замене оборудования. Прежний токен перестает действовать, подключенное с ним устройство получает display_revoked.",
                 "produces": [
-                    "audio/wav"
+                    "application/json"
                 ],
                 "tags": [
-                    "audio"
+                    "admin"
                 ],
-                "summary": "Сгенерировать звуковое оповещение",
+                "summary": "Выпустить устройству новый токен (Админ)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Номер талона (например, A007 или C21)",
-                        "name": "ticket",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Номер окна (например, 5)",
-                        "name": "window",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "ID устройства",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "WAV файл оповещения",
+                        "description": "Устройство и новый токен",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/models.DisplayTokenResponse"
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверные параметры",
+                        "description": "Неверный формат ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Устройство не найдено",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -810,42 +975,74 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/login/administrator": {
-            "post": {
-                "description": "Принимает логин и пароль, возвращает JWT токен.",
-                "consumes": [
-                    "application/json"
+        "/api/admin/patient-access": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Возвращает записи о чтении персональных данных пациентов: кто (роль и ID пользователя), через какой эндпоинт, с какого адреса и когда. Фильтруется по пациенту, по пользователю и по периоду; по умолчанию — за последние 30 дней. Новые записи первыми. Журнал только дополняется: изменить или удалить записи нельзя.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Аутентификация администратора",
+                "summary": "Журнал доступа к данным пациентов",
                 "parameters": [
                     {
-                        "description": "Учетные данные",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID пациента",
+                        "name": "patient_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль пользователя (registrar, doctor, admin, database)",
+                        "name": "actor_role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (YYYY-MM-DD)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (YYYY-MM-DD)",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы (с 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 50, не больше 500)",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ с токеном",
+                        "description": "Страница журнала",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.PatientAccessPage"
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный запрос",
+                        "description": "Ошибка: неверный формат параметров",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -853,8 +1050,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Ошибка: неверные учетные данные",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -865,40 +1062,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/login/doctor": {
-            "post": {
-                "description": "Принимает логин и пароль, возвращает JWT токен и информацию о враче.",
-                "consumes": [
-                    "application/json"
+        "/api/admin/patient-access/anomalies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Находит часы, за которые пользователь просмотрел данные больше threshold разных пациентов. По умолчанию порог берется из настройки PATIENT_ACCESS_ANOMALY_THRESHOLD, период — последние 30 дней.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Аутентификация врача",
+                "summary": "Отчет о подозрительном доступе к данным пациентов",
                 "parameters": [
                     {
-                        "description": "Учетные данные",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Начало периода (YYYY-MM-DD)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (YYYY-MM-DD)",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Допустимое число разных пациентов за час",
+                        "name": "threshold",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ с токеном и данными врача",
+                        "description": "Отчет",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.PatientAccessAnomalyReport"
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный запрос",
+                        "description": "Ошибка: неверный формат параметров",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -906,8 +1113,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Ошибка: неверные учетные данные",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -918,42 +1125,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/login/registrar": {
-            "post": {
-                "description": "Принимает логин и пароль, возвращает JWT токен.",
-                "consumes": [
-                    "application/json"
+        "/api/admin/patients/anonymizations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Возвращает анонимизации карточек за период — по решению администратора и по сроку хранения: кто, почему и что было очищено или сохранено. По умолчанию — за последние 30 дней.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Аутентификация регистратора",
+                "summary": "Журнал анонимизаций карточек пациентов",
                 "parameters": [
                     {
-                        "description": "Учетные данные",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Начало периода (YYYY-MM-DD)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (YYYY-MM-DD)",
+                        "name": "date_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ с токеном",
+                        "description": "Журнал анонимизаций",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PatientAuditLog"
                             }
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный запрос",
+                        "description": "Ошибка: неверный формат даты",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -961,8 +1173,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Ошибка: неверные учетные данные",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -973,70 +1185,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/database/{table}/delete": {
-            "delete": {
+        "/api/admin/patients/duplicates": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Позволяет удалить записи из указанной таблицы по заданным фильтрам.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает пары карточек, которые, вероятно, относятся к одному пациенту (тот же полис ОМС, тот же телефон или похожее ФИО и та же дата рождения), самые вероятные первыми. Документы и контакты замаскированы.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "database"
+                    "admin"
                 ],
-                "summary": "Удаление данных из таблицы",
+                "summary": "Возможные дубликаты по всей базе пациентов",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя таблицы для удаления (e.g., tickets, doctors)",
-                        "name": "table",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Фильтры для удаления",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DeleteRequest"
-                        }
+                        "type": "integer",
+                        "description": "Максимальное число пар (по умолчанию 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Данные успешно удалены",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Ошибка в запросе (например, удаление без фильтров)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Отсутствует ключ API",
+                        "description": "Пары возможных дубликатов",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DuplicatePair"
                             }
                         }
                     },
-                    "403": {
-                        "description": "Неверный ключ API",
+                    "400": {
+                        "description": "Ошибка: неверный параметр limit",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1056,14 +1239,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/database/{table}/insert": {
+        "/api/admin/patients/merge": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Позволяет вставить одну или несколько записей в указанную таблицу.",
+                "description": "Переносит записи на прием, лист ожидания, направления, уведомления и журналы регистраций с дубликата на оставшуюся карточку и удаляет дубликат. Записи дубликата в слоты, где уже записана оставшаяся карточка, удаляются. Пустые телефон и email оставшейся карточки заполняются из дубликата. Кто, почему и снимок удаленной карточки сохраняются в журнале.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1071,37 +1254,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "database"
+                    "admin"
                 ],
-                "summary": "Вставка данных в таблицу",
+                "summary": "Объединить карточку-дубликат с основной",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя таблицы для вставки (e.g., services, doctors)",
-                        "name": "table",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Данные для вставки",
+                        "description": "Карточки и причина объединения",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.InsertRequest"
+                            "$ref": "#/definitions/models.MergePatientsRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Данные успешно вставлены",
+                    "200": {
+                        "description": "Результат объединения",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.PatientMergeResult"
                         }
                     },
                     "400": {
-                        "description": "Ошибка в запросе",
+                        "description": "Ошибка: неверный формат запроса",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1109,8 +1284,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Отсутствует ключ API",
+                    "404": {
+                        "description": "Пациент не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1118,8 +1293,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Неверный ключ API",
+                    "409": {
+                        "description": "Одна из карточек анонимизирована",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1139,52 +1314,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/database/{table}/select": {
-            "post": {
+        "/api/admin/patients/merges": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Позволяет получить данные из указанной таблицы с фильтрацией и пагинацией.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает объединения карточек за период: кто, почему, снимок удаленной карточки и число перенесенных записей. По умолчанию — за последние 30 дней.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "database"
+                    "admin"
                 ],
-                "summary": "Получение данных из таблицы",
+                "summary": "Журнал объединений карточек пациентов",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Имя таблицы для получения данных (e.g., tickets, doctors)",
-                        "name": "table",
-                        "in": "path",
-                        "required": true
+                        "description": "Начало периода (YYYY-MM-DD)",
+                        "name": "date_from",
+                        "in": "query"
                     },
                     {
-                        "description": "Фильтры и параметры пагинации",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.GetDataRequest"
-                        }
+                        "type": "string",
+                        "description": "Конец периода (YYYY-MM-DD)",
+                        "name": "date_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ с данными",
+                        "description": "Журнал объединений",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PatientAuditLog"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Ошибка в запросе (неверная таблица, поле или оператор)",
+                        "description": "Ошибка: неверный формат даты",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1192,21 +1362,40 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Отсутствует ключ API",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    },
-                    "403": {
-                        "description": "Неверный ключ API",
+                    }
+                }
+            }
+        },
+        "/api/admin/patients/pii/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Перешифровывает паспорта и полисы ОМС, зашифрованные прежними ключами или сохраненные до включения шифрования. Выполняется после смены PII_ACTIVE_KEY; прежний ключ можно удалить из PII_ENCRYPTION_KEYS только после завершения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Перешифровать персональные данные активным ключом",
+                "responses": {
+                    "200": {
+                        "description": "Число перешифрованных карточек",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "type": "integer"
                             }
                         }
                     },
@@ -1222,14 +1411,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/database/{table}/update": {
-            "patch": {
+        "/api/admin/patients/retention/run": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Позволяет обновить записи в указанной таблице по заданным фильтрам.",
+                "description": "Анонимизирует карточки пациентов, у которых не было приемов дольше PATIENT_RETENTION_YEARS лет (задание также выполняется ежедневно во время обслуживания). За один запуск обрабатывается не больше 500 карточек, самые давние первыми; has_more показывает, что остались еще. С dry_run карточки только находятся.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1237,37 +1426,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "database"
+                    "admin"
                 ],
-                "summary": "Обновление данных в таблице",
+                "summary": "Запустить задание хранения данных пациентов",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя таблицы для обновления (e.g., tickets, doctors)",
-                        "name": "table",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Данные и фильтры для обновления",
+                        "description": "Кто запускает задание и нужно ли только найти карточки",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateRequest"
+                            "$ref": "#/definitions/models.RunRetentionRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Данные успешно обновлены",
+                        "description": "Отчет о запуске",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.RetentionReport"
                         }
                     },
                     "400": {
-                        "description": "Ошибка в запросе (например, обновление без фильтров)",
+                        "description": "Ошибка: неверный формат запроса или срок хранения не задан",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1275,17 +1456,63 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Отсутствует ключ API",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            }
+        },
+        "/api/admin/patients/{patient_id}/anonymize": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Заменяет ФИО токеном, сокращает дату рождения до года, удаляет телефон, email, паспорт и полис ОМС. Записи на прием, талоны, направления и журналы регистраций остаются привязанными к карточке, поэтому статистика не меняется. Заявки в листе ожидания, открытые направления и неотправленные уведомления отменяются, из текстов уведомлений и прежних записей журнала изменений удаляются данные пациента. Карточку с предстоящими записями на прием анонимизировать нельзя. Кто и почему анонимизировал карточку, сохраняется в журнале.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Анонимизировать карточку пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Кто и почему анонимизирует карточку",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AnonymizePatientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отчет об анонимизации",
+                        "schema": {
+                            "$ref": "#/definitions/models.PatientAnonymizationResult"
+                        }
                     },
-                    "403": {
-                        "description": "Неверный ключ API",
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1293,35 +1520,21 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "404": {
+                        "description": "Пациент не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/doctor/active": {
-            "get": {
-                "description": "Возвращает список всех врачей в системе. Используется для заполнения выпадающих списков на клиенте.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "doctor"
-                ],
-                "summary": "Получить список всех врачей",
-                "responses": {
-                    "200": {
-                        "description": "Массив моделей врачей",
+                    },
+                    "409": {
+                        "description": "Карточка уже анонимизирована или у пациента есть предстоящие записи",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Doctor"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -1337,23 +1550,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/doctor/cabinets/active": {
+        "/api/admin/processes": {
             "get": {
-                "description": "Возвращает список всех уникальных номеров кабинетов, когда-либо существовавших в расписании.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает список всех бизнес-процессов и их текущее состояние (включен/отключен).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
                 ],
-                "summary": "Получить список всех существующих кабинетов",
+                "summary": "Получить статусы всех бизнес-процессов (Админ)",
                 "responses": {
                     "200": {
-                        "description": "Массив номеров кабинетов",
+                        "description": "Список процессов",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "integer"
+                                "$ref": "#/definitions/models.BusinessProcess"
                             }
                         }
                     },
@@ -1369,14 +1587,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/doctor/complete-appointment": {
-            "post": {
+        "/api/admin/processes/{name}": {
+            "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Завершает прием пациента по талону. Статус талона должен быть 'на_приеме'.",
+                "description": "Включает или отключает указанный бизнес-процесс.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1384,30 +1602,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
                 ],
-                "summary": "Завершить прием пациента",
+                "summary": "Обновить статус бизнес-процесса (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для завершения приема",
+                        "type": "string",
+                        "description": "Имя процесса",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое состояние",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.CompleteAppointmentRequest"
+                            "$ref": "#/definitions/handlers.UpdateProcessRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Appointment completed successfully",
+                        "description": "Обновленный процесс",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.BusinessProcess"
                         }
                     },
                     "400": {
-                        "description": "Неверный запрос или статус талона",
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Процесс не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1418,117 +1660,89 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/doctor/end-break": {
-            "post": {
+        "/api/admin/pubsub/listener": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Завершает перерыв врача. Статус врача должен быть 'перерыв'.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает, подключен ли слушатель LISTEN/NOTIFY, с какого момента, когда пришло последнее уведомление, последнюю ошибку и число переподключений. Пока соединение не восстановлено, отвечает 503: табло не получают изменений из базы данных.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
                 ],
-                "summary": "Завершить перерыв врача",
-                "parameters": [
-                    {
-                        "description": "Данные для завершения перерыва",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Состояние слушателя уведомлений базы данных",
+                "responses": {
+                    "200": {
+                        "description": "Слушатель подключен",
                         "schema": {
-                            "$ref": "#/definitions/handlers.EndBreakRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Break ended successfully",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/pubsub.ListenerHealth"
                         }
                     },
-                    "400": {
-                        "description": "Неверный запрос или статус врача",
+                    "503": {
+                        "description": "Соединение с базой данных потеряно, идет переподключение",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/pubsub.ListenerHealth"
                         }
                     }
                 }
             }
         },
-        "/api/doctor/queue-all": {
+        "/api/admin/pubsub/stats": {
             "get": {
-                "description": "Возвращает список всех талонов со статусами 'зарегистрирован' и 'на_приеме' для всех кабинетов.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает общее число опубликованных, доставленных и пропущенных событий, число сигналов повторной синхронизации и отключенных медленных клиентов, а также счетчики каждого подключенного клиента SSE.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
                 ],
-                "summary": "Получить очередь ко всем врачебным кабинетам (для нового табло)",
+                "summary": "Счетчики брокера событий",
                 "responses": {
                     "200": {
-                        "description": "Массив талонов в очереди",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.DoctorQueueTicketResponse"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Счетчики брокера",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/pubsub.BrokerStats"
                         }
                     }
                 }
             }
         },
-        "/api/doctor/screen-updates/{cabinet_number}": {
+        "/api/admin/routing-rules": {
             "get": {
-                "description": "Отправляет начальное состояние и последующие обновления статуса приема через Server-Sent Events для конкретного кабинета.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает услуги и специальности, для которых регистрация по записи в киоске сразу направляет пациента в очередь кабинета врача.",
                 "produces": [
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "doctor"
-                ],
-                "summary": "Получить обновления для табло врача",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Номер кабинета",
-                        "name": "cabinet_number",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Получить правила прямой маршрутизации (Админ)",
                 "responses": {
                     "200": {
-                        "description": "Поток событий (см. реальную структуру ответа в коде)",
+                        "description": "Список правил",
                         "schema": {
-                            "$ref": "#/definitions/handlers.DoctorScreenResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DirectRoutingRule"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Неверный формат номера кабинета",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1537,11 +1751,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/doctor/set-active": {
+            },
             "post": {
-                "description": "Устанавливает статус врача как активный (при входе в систему).",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Включает прямую маршрутизацию для услуги терминала (service_id) или специальности врача (specialization): при своевременной регистрации через киоск талон сразу получает статус «зарегистрирован» в очереди кабинета. Указывается ровно одно поле.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1549,23 +1766,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
                 ],
-                "summary": "Установить статус врача как активный",
+                "summary": "Создать правило прямой маршрутизации (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для установки статуса",
+                        "description": "Услуга или специальность",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.SetActiveRequest"
+                            "$ref": "#/definitions/models.CreateDirectRoutingRuleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Doctor status set to active",
+                    "201": {
+                        "description": "Созданное правило",
+                        "schema": {
+                            "$ref": "#/definitions/models.DirectRoutingRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1573,45 +1796,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Неверный запрос",
+                    "404": {
+                        "description": "Услуга не найдена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/doctor/set-inactive": {
-            "post": {
-                "description": "Устанавливает статус врача как неактивный (при выходе из системы).",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "doctor"
-                ],
-                "summary": "Установить статус врача как неактивный",
-                "parameters": [
-                    {
-                        "description": "Данные для установки статуса",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.SetInactiveRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Doctor status set to inactive",
+                    },
+                    "409": {
+                        "description": "Правило уже существует",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1619,8 +1814,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Неверный запрос",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1631,45 +1826,60 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/doctor/start-appointment": {
-            "post": {
+        "/api/admin/routing-rules/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Начинает прием пациента по талону. Статус талона должен быть 'зарегистрирован'.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Удаляет правило; записи снова подтверждаются через регистратуру.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
                 ],
-                "summary": "Начать прием пациента",
+                "summary": "Удалить правило прямой маршрутизации (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для начала приема",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.StartAppointmentRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Appointment started successfully",
+                        "description": "Правило удалено",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Неверный запрос или статус талона",
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Правило не найдено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1680,14 +1890,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/doctor/start-break": {
+        "/api/admin/schedules": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Начинает перерыв врача. Статус врача должен быть 'активен'.",
+                "description": "Создает новый временной слот для врача. Для групповых процедур можно указать capacity — число пациентов в слоте (по умолчанию 1). Требует INTERNAL_API_KEY.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1695,23 +1905,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
                 ],
-                "summary": "Начать перерыв врача",
+                "summary": "Создать слот в расписании (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для начала перерыва",
+                        "description": "Данные для создания слота",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.StartBreakRequest"
+                            "$ref": "#/definitions/models.CreateScheduleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Break started successfully",
+                    "201": {
+                        "description": "Успешно созданный слот",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1719,35 +1935,21 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "Неверный запрос или статус врача",
+                    "401": {
+                        "description": "Отсутствует ключ API",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/doctor/tickets/in-progress": {
-            "get": {
-                "description": "Возвращает список талонов со статусом \"на_приеме\". Обычно это один талон.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "doctor"
-                ],
-                "summary": "Получить талоны на приеме",
-                "responses": {
-                    "200": {
-                        "description": "Список талонов",
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.TicketResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -1763,108 +1965,154 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/doctor/tickets/registered": {
+        "/api/admin/schedules/export": {
             "get": {
-                "description": "Возвращает список талонов со статусом \"зарегистрирован\", т.е. очередь непосредственно к врачу.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Выгружает слоты за период в том же формате, который принимает импорт, что позволяет отредактировать график и загрузить его обратно. По умолчанию — с сегодняшнего дня на 30 дней вперед. Требует INTERNAL_API_KEY.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "doctor"
+                    "admin"
+                ],
+                "summary": "Экспорт расписания в CSV/XLSX (Админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Формат файла: csv (по умолчанию) или xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (YYYY-MM-DD)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (YYYY-MM-DD)",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID врача",
+                        "name": "doctor_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получить очередь к врачу",
                 "responses": {
                     "200": {
-                        "description": "Список талонов",
+                        "description": "Файл расписания",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.TicketResponse"
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверные параметры",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "401": {
+                        "description": "Отсутствует ключ API",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/processes/{name}": {
-            "get": {
-                "description": "Возвращает текущее состояние (включен/отключен) для указанного бизнес-процесса.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "processes"
-                ],
-                "summary": "Получить статус конкретного бизнес-процесса",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Имя процесса",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Статус процесса",
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "boolean"
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/api/registrar/appointments": {
+        "/api/admin/schedules/import": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создает новую запись на прием для пациента, связывая ее со слотом в расписании и исходным талоном. Обновляет слот как занятый.",
+                "description": "Загружает файл расписания со столбцами: врач (логин или ФИО), дата, начало, окончание, кабинет. Строка заголовка необязательна. Каждая строка проверяется (неизвестный врач, некорректное время, пересечения с расписанием врача и занятостью кабинета). В режиме dry_run ничего не сохраняется; иначе все корректные строки создаются в одной транзакции. Возвращается результат по каждой строке. Требует INTERNAL_API_KEY.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "registrar"
+                    "admin"
                 ],
-                "summary": "Создать новую запись на прием",
+                "summary": "Импорт расписания из CSV/XLSX (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для создания записи",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateAppointmentRequest"
-                        }
+                        "type": "file",
+                        "description": "Файл расписания (.csv или .xlsx)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только проверить файл, не создавая слоты",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Успешно созданная запись",
+                    "200": {
+                        "description": "Результат импорта по строкам",
                         "schema": {
-                            "$ref": "#/definitions/models.Appointment"
+                            "$ref": "#/definitions/models.ScheduleImportReport"
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный формат запроса",
+                        "description": "Ошибка: файл не передан или не читается",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Отсутствует ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1873,7 +2121,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера (например, слот уже занят)",
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1884,25 +2132,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/registrar/appointments/{id}": {
+        "/api/admin/schedules/{id}": {
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Удаляет запись на прием и освобождает связанный с ней слот в расписании.",
+                "description": "Удаляет временной слот из расписания по его ID. Требует INTERNAL_API_KEY.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "registrar"
+                    "admin"
                 ],
-                "summary": "Удалить будущую запись",
+                "summary": "Удалить слот из расписания (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Записи",
+                        "description": "ID слота расписания",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1910,7 +2158,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Запись успешно удалена",
+                        "description": "Слот успешно удален",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1927,8 +2175,26 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Отсутствует ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
-                        "description": "Запись не найдена",
+                        "description": "Слот не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1946,16 +2212,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/registrar/appointments/{id}/confirm": {
+            },
             "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Привязывает существующую запись к новому талону, который пациент получил сегодня, и меняет статус талона на 'зарегистрирован', отправляя пациента в очередь к врачу.",
+                "description": "Блокирует или разблокирует слот (is_blocked). Блокировка хранится отдельно от занятости: доступность слота вычисляется как booked_count \u003c capacity и отсутствие блокировки, поэтому освобождение места не снимает блокировку. Разблокированный слот со свободными местами сразу предлагается пациентам из листа ожидания. Требует INTERNAL_API_KEY.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1963,32 +2227,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "registrar"
+                    "admin"
                 ],
-                "summary": "Подтвердить явку по записи",
+                "summary": "Заблокировать или разблокировать слот (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Записи для подтверждения",
+                        "description": "ID слота расписания",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "ID нового талона",
+                        "description": "Новая доступность слота",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.ConfirmAppointmentRequest"
+                            "$ref": "#/definitions/models.UpdateScheduleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Обновленная запись с привязанным талоном",
+                        "description": "Обновленный слот",
                         "schema": {
-                            "$ref": "#/definitions/models.Appointment"
+                            "$ref": "#/definitions/models.Schedule"
                         }
                     },
                     "400": {
@@ -2000,56 +2264,35 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Ошибка сервера (запись или талон не найдены, или запись уже подтверждена)",
+                    "401": {
+                        "description": "Отсутствует ключ API",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/registrar/patients": {
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Создает новую запись о пациенте в базе данных. Используется, когда пациент не найден через поиск.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "registrar"
-                ],
-                "summary": "Создать нового пациента",
-                "parameters": [
-                    {
-                        "description": "Данные нового пациента",
-                        "name": "patient",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
                         "schema": {
-                            "$ref": "#/definitions/models.CreatePatientRequest"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Успешно созданный пациент",
+                    },
+                    "404": {
+                        "description": "Слот не найден",
                         "schema": {
-                            "$ref": "#/definitions/models.Patient"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Ошибка: неверный формат запроса",
+                    "409": {
+                        "description": "Слот занят записью на прием",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2069,97 +2312,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/registrar/patients/search": {
+        "/api/admin/sse/connections": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Ищет пациентов по частичному совпадению в ФИО, номере полиса ОМС или полному номеру паспорта (серия + номер без пробелов). Возвращает до 10 совпадений.",
+                "description": "Возвращает число открытых потоков SSE по эндпоинтам (с разбивкой по кабинетам для табло врача) и по IP-адресам, число подключений, отклоненных из-за лимитов, и число соединений, закрытых как мертвые после неудачной записи.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "registrar"
-                ],
-                "summary": "Поиск пациентов по ФИО, ОМС или паспорту",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Строка для поиска (минимум 2 символа)",
-                        "name": "query",
-                        "in": "query",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Подключенные клиенты SSE",
                 "responses": {
                     "200": {
-                        "description": "Массив найденных пациентов",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Patient"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Ошибка: отсутствует или слишком короткий параметр поиска",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Сводка по соединениям",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/middleware.SSEStats"
                         }
                     }
                 }
             }
         },
-        "/api/registrar/patients/{patient_id}/appointments": {
+        "/api/ads/enabled": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Возвращает все прошлые и будущие записи для указанного пациента.",
+                "description": "Возвращает список всех включенных рекламных материалов с изображениями.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "registrar"
+                    "ads"
                 ],
-                "summary": "Получить историю записей пациента",
+                "summary": "Получить активные рекламные материалы (Табло)",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID Пациента",
-                        "name": "patient_id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Тип экрана ('reception' или 'schedule')",
+                        "name": "screen",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Массив записей пациента",
+                        "description": "Список активных рекламных материалов",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/services.AppointmentDetailsResponse"
+                                "$ref": "#/definitions/models.AdResponse"
                             }
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный ID пациента",
+                        "description": "Неверный тип экрана",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2179,49 +2387,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/registrar/schedules/doctor/{doctor_id}": {
+        "/api/audio/announce": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Возвращает все временные слоты врача на указанную дату, включая информацию о том, кто записан в занятые слоты.",
+                "description": "Создает и возвращает WAV файл с озвучкой номера талона и окна.",
                 "produces": [
-                    "application/json"
+                    "audio/wav"
                 ],
                 "tags": [
-                    "registrar"
+                    "audio"
                 ],
-                "summary": "Получить расписание врача с информацией о записях",
+                "summary": "Сгенерировать звуковое оповещение",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID Врача",
-                        "name": "doctor_id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Номер талона (например, A007 или C21)",
+                        "name": "ticket",
+                        "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Дата в формате YYYY-MM-DD",
-                        "name": "date",
+                        "description": "Номер окна (например, 5)",
+                        "name": "window",
                         "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Массив слотов расписания с информацией о записях",
+                        "description": "WAV файл оповещения",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ScheduleWithAppointmentInfo"
-                            }
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Ошибка: неверный ID или формат даты",
+                        "description": "Ошибка: неверные параметры",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2241,48 +2441,104 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/schedules/today/updates": {
-            "get": {
-                "description": "Отправляет начальное состояние расписания (` + "`" + `event: schedule_initial` + "`" + `) и последующие изменения (` + "`" + `event: schedule_update` + "`" + `) через Server-Sent Events.",
+        "/api/auth/login/administrator": {
+            "post": {
+                "description": "Принимает логин и пароль, возвращает JWT токен.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "auth"
+                ],
+                "summary": "Аутентификация администратора",
+                "parameters": [
+                    {
+                        "description": "Учетные данные",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LoginRequest"
+                        }
+                    }
                 ],
-                "summary": "Получить обновления расписания на сегодня",
                 "responses": {
                     "200": {
-                        "description": "Поток событий с состоянием расписания",
+                        "description": "Успешный ответ с токеном",
                         "schema": {
-                            "$ref": "#/definitions/services.TodayScheduleResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка: неверные учетные данные",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/api/tickets/active": {
-            "get": {
-                "description": "Возвращает список всех талонов в статусе 'ожидает' и 'приглашен' для первоначальной загрузки табло.",
+        "/api/auth/login/doctor": {
+            "post": {
+                "description": "Принимает логин и пароль, возвращает JWT токен и информацию о враче.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "auth"
+                ],
+                "summary": "Аутентификация врача",
+                "parameters": [
+                    {
+                        "description": "Учетные данные",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LoginRequest"
+                        }
+                    }
                 ],
-                "summary": "Получить все активные талоны",
                 "responses": {
                     "200": {
-                        "description": "Список активных талонов",
+                        "description": "Успешный ответ с токеном и данными врача",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.TicketResponse"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "401": {
+                        "description": "Ошибка: неверные учетные данные",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2293,9 +2549,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tickets/appointment/phone": {
+        "/api/auth/login/registrar": {
             "post": {
-                "description": "Проверяет наличие записи по номеру телефона и выдает приоритетный талон, если прием скоро",
+                "description": "Принимает логин и пароль, возвращает JWT токен.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2303,29 +2559,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "auth"
                 ],
-                "summary": "Регистрация на прием по номеру телефона",
+                "summary": "Аутентификация регистратора",
                 "parameters": [
                     {
-                        "description": "Номер телефона пациента",
-                        "name": "request",
+                        "description": "Учетные данные",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.CheckInByPhoneRequest"
+                            "$ref": "#/definitions/handlers.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Ответ с данными талона",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.ConfirmationResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Ошибка: не передан номер телефона",
+                        "description": "Успешный ответ с токеном",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2333,8 +2583,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Запись не найдена или еще не время",
+                    "400": {
+                        "description": "Ошибка: неверный запрос",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2342,8 +2592,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "401": {
+                        "description": "Ошибка: неверные учетные данные",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2354,34 +2604,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tickets/download/{ticket_number}": {
+        "/api/calendar/doctor/{token}": {
             "get": {
-                "description": "Позволяет скачать изображение талона по номеру",
+                "description": "Возвращает расписание врача в формате iCalendar для подписки в календаре телефона. Доступ по персональному токену из ссылки. ФИО пациентов выводится в виде PUBLIC_NAME_FORMAT.",
                 "produces": [
-                    "image/png"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "tickets"
+                    "calendar"
                 ],
-                "summary": "Скачать изображение талона",
+                "summary": "Календарь врача (.ics)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Номер талона",
-                        "name": "ticket_number",
+                        "description": "Персональный токен календаря (можно с суффиксом .ics)",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Изображение талона",
+                        "description": "Календарь в формате iCalendar",
                         "schema": {
-                            "type": "file"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Ошибка: не передан ticket_number",
+                    "404": {
+                        "description": "Календарь не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2389,8 +2639,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Талон не найден",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2401,9 +2651,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tickets/print/confirmation": {
-            "post": {
-                "description": "Обрабатывает подтверждение действия (печать талона или получение электронного)",
+        "/api/database/{table}/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Позволяет удалить записи из указанной таблицы по заданным фильтрам.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2411,29 +2666,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "database"
                 ],
-                "summary": "Подтверждение действия",
+                "summary": "Удаление данных из таблицы",
                 "parameters": [
                     {
-                        "description": "Данные для подтверждения действия",
+                        "type": "string",
+                        "description": "Имя таблицы для удаления (e.g., tickets, doctors)",
+                        "name": "table",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Фильтры для удаления",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.ConfirmationRequest"
+                            "$ref": "#/definitions/models.DeleteRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Ответ после подтверждения действия",
+                        "description": "Данные успешно удалены",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ConfirmationResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Ошибка: не передан service_id или action",
+                        "description": "Ошибка в запросе (например, удаление без фильтров)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Отсутствует ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2453,9 +2734,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tickets/print/selection": {
+        "/api/database/{table}/insert": {
             "post": {
-                "description": "Определяет следующий шаг после выбора услуги",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Позволяет вставить одну или несколько записей в указанную таблицу.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2463,29 +2749,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "database"
                 ],
-                "summary": "Выбор услуги",
+                "summary": "Вставка данных в таблицу",
                 "parameters": [
                     {
-                        "description": "Данные для выбора услуги",
+                        "type": "string",
+                        "description": "Имя таблицы для вставки (e.g., services, doctors)",
+                        "name": "table",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для вставки",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.ServiceSelectionRequest"
+                            "$ref": "#/definitions/models.InsertRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Следующий шаг после выбора услуги",
+                    "201": {
+                        "description": "Данные успешно вставлены",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ServiceSelectionResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Ошибка: не передан service_id",
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Отсутствует ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2496,9 +2817,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tickets/services": {
-            "get": {
-                "description": "Возвращает список доступных услуг",
+        "/api/database/{table}/select": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Позволяет получить данные из указанной таблицы с фильтрацией и пагинацией.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2506,28 +2832,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "database"
+                ],
+                "summary": "Получение данных из таблицы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя таблицы для получения данных (e.g., tickets, doctors)",
+                        "name": "table",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Фильтры и параметры пагинации",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GetDataRequest"
+                        }
+                    }
                 ],
-                "summary": "Получить список услуг",
                 "responses": {
                     "200": {
-                        "description": "Список услуг",
+                        "description": "Успешный ответ с данными",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе (неверная таблица, поле или оператор)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/models.Service"
-                                }
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Отсутствует ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/api/tickets/start": {
-            "get": {
-                "description": "Возвращает стартовую информацию для клиента (например, текст кнопки)",
+        "/api/database/{table}/update": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Позволяет обновить записи в указанной таблице по заданным фильтрам.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2535,12 +2915,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "database"
+                ],
+                "summary": "Обновление данных в таблице",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя таблицы для обновления (e.g., tickets, doctors)",
+                        "name": "table",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные и фильтры для обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRequest"
+                        }
+                    }
                 ],
-                "summary": "Получить стартовую информацию",
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ: текст кнопки",
+                        "description": "Данные успешно обновлены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе (например, обновление без фильтров)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Отсутствует ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный ключ API",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2551,34 +2983,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tickets/view/{ticket_number}": {
+        "/api/display/ads": {
             "get": {
-                "description": "Позволяет просмотреть изображение талона в браузере по номеру",
+                "description": "Возвращает рекламные материалы из плейлиста устройства в порядке показа. Если плейлист не задан, табло регистратуры и расписания получают все материалы, включенные для своего экрана.",
                 "produces": [
-                    "image/png"
+                    "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "display"
                 ],
-                "summary": "Просмотр изображения талона",
+                "summary": "Получить рекламу для устройства",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Номер талона",
-                        "name": "ticket_number",
-                        "in": "path",
+                        "description": "Токен устройства",
+                        "name": "X-Display-Token",
+                        "in": "header",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Изображение талона",
+                        "description": "Рекламные материалы",
                         "schema": {
-                            "type": "file"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AdResponse"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Ошибка: не передан ticket_number",
+                    "401": {
+                        "description": "Токен не указан",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2586,8 +3021,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Талон не найден",
+                    "403": {
+                        "description": "Неверный токен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -2597,616 +3041,5700 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.CheckInByPhoneRequest": {
-            "type": "object",
-            "required": [
-                "phone"
-            ],
-            "properties": {
-                "phone": {
+        },
+        "/api/display/config": {
+            "get": {
+                "description": "Возвращает устройству, авторизованному токеном, его назначение, окна или кабинеты, плейлист рекламы, настройки звука и тему.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "display"
+                ],
+                "summary": "Получить конфигурацию устройства",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен устройства",
+                        "name": "X-Display-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Конфигурация устройства",
+                        "schema": {
+                            "$ref": "#/definitions/models.DisplayResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Токен не указан",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный токен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/display/updates": {
+            "get": {
+                "description": "Сразу после подключения отправляет текущую конфигурацию (` + "`" + `event: display_config` + "`" + `) и затем каждую новую, когда администратор меняет устройство. Событие display_revoked означает, что устройство удалено или его токен заменен; после него поток закрывается. Токен можно передать параметром token, так как EventSource не позволяет задать заголовок. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "display"
+                ],
+                "summary": "Поток изменений конфигурации устройства",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен устройства",
+                        "name": "X-Display-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен устройства (для EventSource)",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий с конфигурацией",
+                        "schema": {
+                            "$ref": "#/definitions/models.DisplayResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Токен не указан",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный токен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит подключений",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/active": {
+            "get": {
+                "description": "Возвращает список всех врачей в системе. Используется для заполнения выпадающих списков на клиенте.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Получить список всех врачей",
+                "responses": {
+                    "200": {
+                        "description": "Массив моделей врачей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Doctor"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/cabinets/active": {
+            "get": {
+                "description": "Возвращает список всех уникальных номеров кабинетов, когда-либо существовавших в расписании.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Получить список всех существующих кабинетов",
+                "responses": {
+                    "200": {
+                        "description": "Массив номеров кабинетов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/calendar/token": {
+            "post": {
+                "description": "Выпускает новый персональный токен для подписки на календарь (.ics) с расписанием врача. Предыдущая ссылка перестает работать.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Получить ссылку на календарь врача",
+                "responses": {
+                    "200": {
+                        "description": "Токен и ссылка на календарь",
+                        "schema": {
+                            "$ref": "#/definitions/models.CalendarFeedResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/complete-appointment": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Завершает прием пациента по талону. Статус талона должен быть 'на_приеме'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Завершить прием пациента",
+                "parameters": [
+                    {
+                        "description": "Данные для завершения приема",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CompleteAppointmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Appointment completed successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или статус талона",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/end-break": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Завершает перерыв врача. Статус врача должен быть 'перерыв'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Завершить перерыв врача",
+                "parameters": [
+                    {
+                        "description": "Данные для завершения перерыва",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EndBreakRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Break ended successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или статус врача",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/patients/{patient_id}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Объединяет в одну хронологию записи на прием со связанными талонами (ожидание вызова и время обслуживания в регистратуре по reception_logs, длительность приема у врача, регистрация через киоск), итогом визита (запланирован, в очереди, на приеме, завершен, не обслужен, неявка) и выданные пациенту направления. Новые события первыми. Ночная очистка переносит прошедшие визиты в архив; чтобы их увидеть, передайте include_archived=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar",
+                    "doctor"
+                ],
+                "summary": "История пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только визиты к врачам этой специализации и направления к ним",
+                        "name": "specialization",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить архивные визиты (по умолчанию false)",
+                        "name": "include_archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы, начиная с 1 (по умолчанию 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, не более 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница истории пациента",
+                        "schema": {
+                            "$ref": "#/definitions/models.PatientTimelinePage"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID пациента или параметры запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/queue-all": {
+            "get": {
+                "description": "Возвращает список всех талонов со статусами 'зарегистрирован' и 'на_приеме' для всех кабинетов.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Получить очередь ко всем врачебным кабинетам (для нового табло)",
+                "responses": {
+                    "200": {
+                        "description": "Массив талонов в очереди",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DoctorQueueTicketResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/referrals": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Врач выдает направление пациенту по записи, прием по которой идет сейчас или завершен сегодня. Если срок действия не указан, он определяется срочностью: плановое — 30 дней, срочное — 7, экстренное — 1.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Выдать направление к специалисту",
+                "parameters": [
+                    {
+                        "description": "Данные направления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReferralRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданное направление",
+                        "schema": {
+                            "$ref": "#/definitions/models.Referral"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса или прием не активен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Запись относится к приему другого врача",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Запись или специальность не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/screen-updates/{cabinet_number}": {
+            "get": {
+                "description": "Отправляет начальное состояние и последующие обновления статуса приема через Server-Sent Events для конкретного кабинета. Каждое событие state_update содержит полное состояние экрана и ID последнего учтенного события; при переподключении с заголовком Last-Event-ID состояние отправляется повторно, только если за время разрыва в кабинете что-то изменилось. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat; клиент, не принимающий данные дольше SSE_WRITE_TIMEOUT, отключается.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Получить обновления для табло врача",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер кабинета",
+                        "name": "cabinet_number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий (см. реальную структуру ответа в коде)",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DoctorScreenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат номера кабинета",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит подключений",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/set-active": {
+            "post": {
+                "description": "Устанавливает статус врача как активный (при входе в систему).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Установить статус врача как активный",
+                "parameters": [
+                    {
+                        "description": "Данные для установки статуса",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetActiveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Doctor status set to active",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/set-inactive": {
+            "post": {
+                "description": "Устанавливает статус врача как неактивный (при выходе из системы).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Установить статус врача как неактивный",
+                "parameters": [
+                    {
+                        "description": "Данные для установки статуса",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetInactiveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Doctor status set to inactive",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/start-appointment": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Начинает прием пациента по талону. Статус талона должен быть 'зарегистрирован'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Начать прием пациента",
+                "parameters": [
+                    {
+                        "description": "Данные для начала приема",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StartAppointmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Appointment started successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или статус талона",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/start-break": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Начинает перерыв врача. Статус врача должен быть 'активен'.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Начать перерыв врача",
+                "parameters": [
+                    {
+                        "description": "Данные для начала перерыва",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StartBreakRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Break started successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или статус врача",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/tickets/in-progress": {
+            "get": {
+                "description": "Возвращает список талонов со статусом \"на_приеме\". Обычно это один талон.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Получить талоны на приеме",
+                "responses": {
+                    "200": {
+                        "description": "Список талонов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TicketResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/doctor/tickets/registered": {
+            "get": {
+                "description": "Возвращает список талонов со статусом \"зарегистрирован\", т.е. очередь непосредственно к врачу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctor"
+                ],
+                "summary": "Получить очередь к врачу",
+                "responses": {
+                    "200": {
+                        "description": "Список талонов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TicketResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/external/appointments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Идентифицирует пациента по номеру полиса ОМС и дате рождения и записывает его в свободный слот. Проверяются правила записи (пересечения, лимиты и интервалы по специальности, возраст), лимит активных записей и горизонт записи.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external"
+                ],
+                "summary": "Записаться на прием",
+                "parameters": [
+                    {
+                        "description": "Данные пациента и ID слота",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExternalBookingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная запись",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExternalAppointmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса или слот вне горизонта записи",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент или слот не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Слот занят, превышен лимит активных записей или нарушены правила записи",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BookingRulesErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/external/appointments/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Идентифицирует пациента и отменяет его запись, если до приема осталось не меньше установленного времени отсечки. Освободившийся слот предлагается листу ожидания.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external"
+                ],
+                "summary": "Отменить запись на прием",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для идентификации пациента",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExternalPatientIdentity"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Запись отменена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент или запись не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Отмена уже невозможна",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/external/appointments/{id}/ics": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Идентифицирует пациента и возвращает файл iCalendar с его записью. Для отмененной записи возвращается отмена события с тем же UID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "external"
+                ],
+                "summary": "Скачать запись на прием (.ics)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для идентификации пациента",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExternalPatientIdentity"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл в формате iCalendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент или запись не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/external/doctors": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает врачей, при необходимости отфильтрованных по специальности.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external"
+                ],
+                "summary": "Получить список врачей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Специальность",
+                        "name": "specialization",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Массив врачей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExternalDoctorResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "API ключ отсутствует",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный API ключ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/external/patients/appointments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Идентифицирует пациента по номеру полиса ОМС и дате рождения и возвращает его будущие записи.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external"
+                ],
+                "summary": "Получить активные записи пациента",
+                "parameters": [
+                    {
+                        "description": "Данные для идентификации пациента",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExternalPatientIdentity"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Массив записей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExternalAppointmentResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/external/slots": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает свободные будущие слоты в пределах горизонта записи. Можно отфильтровать по врачу, специальности и диапазону дат.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external"
+                ],
+                "summary": "Получить свободные слоты для записи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID врача",
+                        "name": "doctor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Специальность",
+                        "name": "specialization",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода в формате YYYY-MM-DD",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода в формате YYYY-MM-DD",
+                        "name": "date_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Массив свободных слотов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExternalSlotResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат параметров",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "API ключ отсутствует",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный API ключ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/external/specializations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает специальности врачей, на которые возможна запись.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external"
+                ],
+                "summary": "Получить список специальностей",
+                "responses": {
+                    "200": {
+                        "description": "Массив специальностей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "API ключ отсутствует",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный API ключ",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/processes/{name}": {
+            "get": {
+                "description": "Возвращает текущее состояние (включен/отключен) для указанного бизнес-процесса.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "processes"
+                ],
+                "summary": "Получить статус конкретного бизнес-процесса",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя процесса",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статус процесса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/appointments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает новую запись на прием для пациента, связывая ее со слотом в расписании и исходным талоном, и занимает место в слоте. Перед созданием проверяются правила записи: пересечение с другими записями пациента, лимит активных записей к специальности, минимальный интервал между визитами и возрастные ограничения. При нарушении возвращается 409 со списком нарушений; старший регистратор может записать пациента, передав override_rules=true и override_reason. С referral_id запись создается по направлению: оно должно быть открытым, выданным этому пациенту к специальности врача слота, и после записи считается исполненным.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Создать новую запись на прием",
+                "parameters": [
+                    {
+                        "description": "Данные для создания записи",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAppointmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Успешно созданная запись",
+                        "schema": {
+                            "$ref": "#/definitions/models.Appointment"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса или не указана причина переопределения",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Переопределять правила могут только старшие регистраторы",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BookingRulesErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Слот или пациент не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Нарушены правила записи, слот уже занят или направление недействительно",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BookingRulesErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/appointments/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет запись на прием и освобождает связанный с ней слот в расписании.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Удалить будущую запись",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Запись успешно удалена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Запись не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/appointments/{id}/checkin-code": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает подписанный код записи на прием. Код печатается в подтверждении записи в виде QR-кода; по нему пациент регистрируется в киоске без ввода данных.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Получить код записи для QR-кода",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Код записи",
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckInCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Запись не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/appointments/{id}/confirm": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Привязывает существующую запись к новому талону, который пациент получил сегодня, и меняет статус талона на 'зарегистрирован', отправляя пациента в очередь к врачу.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Подтвердить явку по записи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Записи для подтверждения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID нового талона",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfirmAppointmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленная запись с привязанным талоном",
+                        "schema": {
+                            "$ref": "#/definitions/models.Appointment"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера (запись или талон не найдены, или запись уже подтверждена)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/appointments/{id}/ics": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает файл iCalendar с записью на прием: время, врач и кабинет. Для отмененной записи возвращается отмена события с тем же UID.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Скачать запись на прием (.ics)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл в формате iCalendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Запись не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/appointments/{id}/reschedule": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Переносит неподтвержденную запись в другой свободный слот. Старый слот освобождается и предлагается листу ожидания, пациенту отправляется уведомление об изменении.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Перенести запись на другое время",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID нового слота",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RescheduleAppointmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Перенесенная запись",
+                        "schema": {
+                            "$ref": "#/definitions/models.Appointment"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Запись или слот не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Нарушены правила записи, слот занят или запись уже подтверждена",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BookingRulesErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает новую запись о пациенте в базе данных. Используется, когда пациент не найден через поиск. Паспорт, полис ОМС (с контрольной цифрой) и дата рождения проверяются; телефон сохраняется в виде +79001234567.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Создать нового пациента",
+                "parameters": [
+                    {
+                        "description": "Данные нового пациента",
+                        "name": "patient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePatientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Успешно созданный пациент",
+                        "schema": {
+                            "$ref": "#/definitions/models.Patient"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса или некорректные данные",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Пациент с таким паспортом уже существует",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Ищет пациентов по ФИО с учетом опечаток и без различия е/ё, по окончанию телефона, а также по полному номеру полиса ОМС или паспорта (документы хранятся зашифрованными, поэтому частичный поиск по ним невозможен). Первое слово считается фамилией; однобуквенные слова и слова с точкой — инициалами; четырехзначное число рядом с ФИО — годом рождения, дата вида 02.01.2006 — датой рождения. Например, «Иванов И 1985» найдет Ивановых с именем на «И», родившихся в 1985 году. Результаты упорядочены по релевантности и разбиты на страницы; у каждого пациента перечислены открытые действующие направления (open_referrals), по которым можно записать его через referral_id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Нечеткий поиск пациентов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Строка для поиска (минимум 2 символа)",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы, начиная с 1 (по умолчанию 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, не более 50)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница найденных пациентов",
+                        "schema": {
+                            "$ref": "#/definitions/models.PatientSearchPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: отсутствует параметр поиска или неверная пагинация",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/{patient_id}": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет переданные поля карточки пациента; пустые поля не меняются. Проверяются ФИО, формат паспорта (код региона, год бланка, номер), полис ОМС (16 цифр с контрольной цифрой), дата рождения (не в будущем и не старше 130 лет) и уникальность паспорта; телефон сохраняется в виде +79001234567. Старые и новые значения записываются в журнал изменений карточки.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Изменить данные пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "patient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePatientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный пациент",
+                        "schema": {
+                            "$ref": "#/definitions/models.Patient"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса или некорректные данные",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Пациент с таким паспортом уже существует или карточка анонимизирована",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/{patient_id}/appointments": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает все прошлые и будущие записи для указанного пациента.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Получить историю записей пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Массив записей пациента",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.AppointmentDetailsResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID пациента",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/{patient_id}/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает карточки с тем же полисом ОМС, тем же телефоном или похожим ФИО (без учета регистра и е/ё) и той же датой рождения. Для каждой указаны совпавшие признаки и сходство ФИО; самые вероятные дубликаты первыми.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Возможные дубликаты карточки пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Возможные дубликаты",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DuplicateCandidate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID пациента",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/{patient_id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает изменения карточки (кто, когда, старые и новые значения) и объединения с дубликатами, новые первыми.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Журнал изменений карточки пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Журнал изменений",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PatientAuditLog"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID пациента",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/{patient_id}/notifications": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Включает или отключает для пациента все уведомления: о записи, ее переносе и отмене, напоминания о приеме и предложения из листа ожидания.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Изменить настройки уведомлений пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Настройки уведомлений",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateNotificationSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный пациент",
+                        "schema": {
+                            "$ref": "#/definitions/models.Patient"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/{patient_id}/referrals": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает направления пациента, новые сначала. По умолчанию — только открытые; status=all возвращает все.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Получить направления пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "open (по умолчанию) или all",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список направлений",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Referral"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID пациента",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/patients/{patient_id}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Объединяет в одну хронологию записи на прием со связанными талонами (ожидание вызова и время обслуживания в регистратуре по reception_logs, длительность приема у врача, регистрация через киоск), итогом визита (запланирован, в очереди, на приеме, завершен, не обслужен, неявка) и выданные пациенту направления. Новые события первыми. Ночная очистка переносит прошедшие визиты в архив; чтобы их увидеть, передайте include_archived=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar",
+                    "doctor"
+                ],
+                "summary": "История пациента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пациента",
+                        "name": "patient_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только визиты к врачам этой специализации и направления к ним",
+                        "name": "specialization",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить архивные визиты (по умолчанию false)",
+                        "name": "include_archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы, начиная с 1 (по умолчанию 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 20, не более 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница истории пациента",
+                        "schema": {
+                            "$ref": "#/definitions/models.PatientTimelinePage"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID пациента или параметры запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/referrals/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отменяет открытое направление (например, если пациент отказался).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Отменить направление",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID направления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Направление отменено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Открытое направление не найдено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/reports/checkins": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает число регистраций через киоск за период по способу идентификации и исходу (вовремя, рано, опоздание) со средним отклонением от начала приема в минутах. По умолчанию — за сегодня.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Отчет по регистрациям по записи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (YYYY-MM-DD)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (YYYY-MM-DD)",
+                        "name": "date_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Строки отчета",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CheckInReportRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат даты",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/reports/referrals/expired": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает открытые направления, срок действия которых истек, а пациент так и не был записан, с контактами пациента и числом дней просрочки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Отчет по неисполненным направлениям",
+                "responses": {
+                    "200": {
+                        "description": "Строки отчета",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExpiredReferralRow"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/schedules/doctor/{doctor_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает все временные слоты врача на указанную дату, включая информацию о том, кто записан в занятые слоты. Для групповых слотов (capacity \u003e 1) все записи перечислены в appointments, число свободных мест — в free_places.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Получить расписание врача с информацией о записях",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Врача",
+                        "name": "doctor_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата в формате YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Массив слотов расписания с информацией о записях",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScheduleWithAppointmentInfo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID или формат даты",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/waitlist": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает заявки листа ожидания в порядке постановки. Без фильтра возвращает активные заявки ('ожидает' и 'предложено'), status=all возвращает все.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Получить лист ожидания",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус заявки: ожидает, предложено, подтверждено, отменено или all",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Массив заявок",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WaitlistEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает заявку в листе ожидания к конкретному врачу или на специальность с желаемым периодом дат. Когда подходящий слот освобождается, он удерживается за пациентом и ему отправляется предложение.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Поставить пациента в лист ожидания",
+                "parameters": [
+                    {
+                        "description": "Данные заявки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWaitlistEntryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная заявка",
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент или врач не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/waitlist/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отменяет заявку. Если за ней удерживается слот, он освобождается и предлагается следующему пациенту.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Отменить заявку в листе ожидания",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заявка отменена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Заявка не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Заявка уже закрыта",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/waitlist/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает запись на прием в слот, удерживаемый за заявкой из листа ожидания. Если запись нарушает правила, удержание снимается, заявка возвращается в лист ожидания, а слот предлагается следующему пациенту.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Подтвердить предложенный слот",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданная запись на прием",
+                        "schema": {
+                            "$ref": "#/definitions/models.Appointment"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Заявка не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "За заявкой нет удерживаемого слота или запись нарушает правила",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BookingRulesErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrar/waitlist/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Освобождает слот, удерживаемый за заявкой, и предлагает его следующему пациенту. При keep_waiting=true заявка остается в листе ожидания, иначе отменяется.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrar"
+                ],
+                "summary": "Снять удержание предложенного слота",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры снятия удержания",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReleaseHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Удержание снято",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Заявка не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "За заявкой нет удерживаемого слота",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/schedules/today/updates": {
+            "get": {
+                "description": "Отправляет начальное состояние расписания (` + "`" + `event: schedule_initial` + "`" + `) и последующие изменения (` + "`" + `event: schedule_update` + "`" + `) через Server-Sent Events. У каждого события есть ID; при переподключении с заголовком Last-Event-ID сервер досылает пропущенные изменения, а если их уже нет в буфере — снова отправляет schedule_initial. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat; клиент, не принимающий данные дольше SSE_WRITE_TIMEOUT, отключается.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Получить обновления расписания на сегодня",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий с состоянием расписания",
+                        "schema": {
+                            "$ref": "#/definitions/services.TodayScheduleResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит подключений",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/active": {
+            "get": {
+                "description": "Возвращает список всех талонов в статусе 'ожидает' и 'приглашен' для первоначальной загрузки табло.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Получить все активные талоны",
+                "responses": {
+                    "200": {
+                        "description": "Список активных талонов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TicketResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/appointment/checkin": {
+            "post": {
+                "description": "Идентифицирует пациента по телефону, полису ОМС (номер или содержимое штрих-кода полиса), серии и номеру паспорта или QR-коду из подтверждения записи и выдает талон. Регистрация открывается за CHECKIN_EARLY_WINDOW до начала приема: слишком раннему пациенту сообщается, к какому времени подойти. Пациент, опоздавший больше чем на CHECKIN_LATE_WINDOW, получает талон в регистратуру с отметкой is_late. Если данным соответствует несколько пациентов с записями на сегодня, возвращается 409 с action=confirm_birth_year — повторите запрос с birth_year. Если для услуги или специальности врача настроена прямая маршрутизация, талон сразу попадает в очередь кабинета, а в ответе возвращаются кабинет, время приема и врач. С action=print_ticket талон печатается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Регистрация на прием по записи",
+                "parameters": [
+                    {
+                        "description": "Способ идентификации и данные пациента",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.KioskCheckInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ответ с данными талона",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfirmationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: неверный формат запроса или данные не распознаны",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пациент или запись не найдены либо еще не время (action=come_back_later, come_back_at)",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckInErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Требуется подтвердить год рождения",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckInErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/appointment/phone": {
+            "post": {
+                "description": "Проверяет наличие записи по номеру телефона и выдает приоритетный талон, если прием скоро. Слишком раннему пациенту сообщается, к какому времени подойти; опоздавший получает талон в регистратуру с отметкой is_late. Если на номер записано несколько пациентов, возвращается 409 с action=confirm_birth_year — повторите запрос с birth_year.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Регистрация на прием по номеру телефона",
+                "parameters": [
+                    {
+                        "description": "Номер телефона пациента",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckInByPhoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ответ с данными талона",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfirmationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: не передан номер телефона",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Запись не найдена или еще не время (action=come_back_later, come_back_at)",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckInErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Требуется подтвердить год рождения",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckInErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/download/{ticket_number}": {
+            "get": {
+                "description": "Позволяет скачать изображение талона по номеру",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Скачать изображение талона",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Номер талона",
+                        "name": "ticket_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Изображение талона",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: не передан ticket_number",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Талон не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/print/confirmation": {
+            "post": {
+                "description": "Обрабатывает подтверждение действия (печать талона или получение электронного)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Подтверждение действия",
+                "parameters": [
+                    {
+                        "description": "Данные для подтверждения действия",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfirmationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ответ после подтверждения действия",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfirmationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: не передан service_id или action",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/print/selection": {
+            "post": {
+                "description": "Определяет следующий шаг после выбора услуги",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Выбор услуги",
+                "parameters": [
+                    {
+                        "description": "Данные для выбора услуги",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ServiceSelectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Следующий шаг после выбора услуги",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ServiceSelectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: не передан service_id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/services": {
+            "get": {
+                "description": "Возвращает список доступных услуг",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Получить список услуг",
+                "responses": {
+                    "200": {
+                        "description": "Список услуг",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/models.Service"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/start": {
+            "get": {
+                "description": "Возвращает стартовую информацию для клиента (например, текст кнопки)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Получить стартовую информацию",
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ: текст кнопки",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickets/view/{ticket_number}": {
+            "get": {
+                "description": "Позволяет просмотреть изображение талона в браузере по номеру",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Просмотр изображения талона",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Номер талона",
+                        "name": "ticket_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Изображение талона",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка: не передан ticket_number",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Талон не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tickets": {
+            "get": {
+                "description": "Отправляет через Server-Sent Events изменения талонов: событие insert, update или delete с данными талона. У каждого события есть ID; при переподключении браузер передает последний полученный ID в заголовке Last-Event-ID, и сервер досылает пропущенные события. Если пропущенных событий уже нет в буфере, отправляется событие snapshot со списком активных талонов. Событие resync означает, что клиент не успевал получать события; за ним следует snapshot. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat; клиент, не принимающий данные дольше SSE_WRITE_TIMEOUT, отключается.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Поток изменений талонов для табло регистратуры",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Только талоны указанного окна регистратуры",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий",
+                        "schema": {
+                            "$ref": "#/definitions/models.TicketResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный номер окна",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит подключений",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "cluster.Status": {
+            "type": "object",
+            "properties": {
+                "instance_id": {
+                    "type": "string",
+                    "example": "9f2c4e1a7b3d5f60"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "leader": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "leader_since": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.BookingRulesErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "overridable": {
+                    "type": "boolean"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingRuleViolation"
+                    }
+                }
+            }
+        },
+        "handlers.CheckInByPhoneRequest": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "birth_year": {
+                    "type": "integer",
+                    "maximum": 2100,
+                    "minimum": 1900,
+                    "example": 1985
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CheckInErrorResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "confirm_birth_year"
+                },
+                "come_back_at": {
+                    "type": "string",
+                    "example": "13:30"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "найдено несколько пациентов с записями на сегодня, подтвердите год рождения"
+                }
+            }
+        },
+        "handlers.CompleteAppointmentRequest": {
+            "type": "object",
+            "required": [
+                "ticket_id"
+            ],
+            "properties": {
+                "ticket_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.ConfirmAppointmentRequest": {
+            "type": "object",
+            "required": [
+                "ticket_id"
+            ],
+            "properties": {
+                "ticket_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ConfirmationRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "service_id"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "print_ticket"
+                },
+                "service_id": {
+                    "type": "string",
+                    "example": "make_appointment"
+                }
+            }
+        },
+        "handlers.ConfirmationResponse": {
+            "type": "object",
+            "properties": {
+                "appointment_time": {
+                    "type": "string",
+                    "example": "14:30"
+                },
+                "cabinet": {
+                    "description": "Cabinet, AppointmentTime и DoctorName заполняются, если талон сразу направлен в очередь кабинета врача.",
+                    "type": "integer",
+                    "example": 101
+                },
+                "doctor_name": {
+                    "type": "string",
+                    "example": "Иванов Иван Иванович"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Ваш электронный талон"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Записаться к врачу"
+                },
+                "ticket_number": {
+                    "type": "string",
+                    "example": "A001"
+                },
+                "timeout": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "handlers.CreateAdministratorRequest": {
+            "type": "object",
+            "required": [
+                "full_name",
+                "login",
+                "password"
+            ],
+            "properties": {
+                "full_name": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateDoctorRequest": {
+            "type": "object",
+            "required": [
+                "full_name",
+                "login",
+                "password",
+                "specialization"
+            ],
+            "properties": {
+                "full_name": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "specialization": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateRegistrarRequest": {
+            "type": "object",
+            "required": [
+                "login",
+                "password",
+                "window_number"
+            ],
+            "properties": {
+                "is_senior": {
+                    "description": "IsSenior — старший регистратор может записывать пациентов в обход правил записи.",
+                    "type": "boolean"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "window_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.DoctorScreenResponse": {
+            "type": "object",
+            "properties": {
+                "cabinet_number": {
+                    "type": "integer"
+                },
+                "doctor_name": {
+                    "type": "string"
+                },
+                "doctor_specialty": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DoctorQueueTicketResponse"
+                    }
+                }
+            }
+        },
+        "handlers.EndBreakRequest": {
+            "type": "object",
+            "required": [
+                "doctor_id"
+            ],
+            "properties": {
+                "doctor_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "login",
+                "password"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReleaseHoldRequest": {
+            "type": "object",
+            "properties": {
+                "keep_waiting": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handlers.ServiceSelectionRequest": {
+            "type": "object",
+            "required": [
+                "service_id"
+            ],
+            "properties": {
+                "service_id": {
+                    "type": "string",
+                    "example": "make_appointment"
+                }
+            }
+        },
+        "handlers.ServiceSelectionResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "confirm_print"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Записаться к врачу"
+                }
+            }
+        },
+        "handlers.SetActiveRequest": {
+            "type": "object",
+            "required": [
+                "doctor_id"
+            ],
+            "properties": {
+                "doctor_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.SetInactiveRequest": {
+            "type": "object",
+            "required": [
+                "doctor_id"
+            ],
+            "properties": {
+                "doctor_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.StartAppointmentRequest": {
+            "type": "object",
+            "required": [
+                "ticket_id"
+            ],
+            "properties": {
+                "ticket_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.StartBreakRequest": {
+            "type": "object",
+            "required": [
+                "doctor_id"
+            ],
+            "properties": {
+                "doctor_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.UpdateProcessRequest": {
+            "type": "object",
+            "properties": {
+                "is_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "middleware.SSECabinetStats": {
+            "type": "object",
+            "properties": {
+                "cabinet": {
+                    "type": "integer",
+                    "example": 101
+                },
+                "clients": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "middleware.SSEEndpointStats": {
+            "type": "object",
+            "properties": {
+                "cabinets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.SSECabinetStats"
+                    }
+                },
+                "clients": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "endpoint": {
+                    "type": "string",
+                    "example": "doctor_screen"
+                },
+                "rejected": {
+                    "description": "Rejected — сколько подключений отклонено из-за лимитов.",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "middleware.SSEIPStats": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "type": "string",
+                    "example": "10.0.0.15"
+                },
+                "clients": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "middleware.SSEStats": {
+            "type": "object",
+            "properties": {
+                "dead_disconnected": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.SSEEndpointStats"
+                    }
+                },
+                "heartbeat_seconds": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "ips": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.SSEIPStats"
+                    }
+                },
+                "max_per_endpoint": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "max_per_ip": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 25
+                }
+            }
+        },
+        "models.AdResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_sec": {
+                    "description": "omitempty уберет поле, если оно nil",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_enabled": {
+                    "type": "boolean"
+                },
+                "media_type": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "reception_on": {
+                    "type": "boolean"
+                },
+                "repeat_count": {
+                    "description": "omitempty уберет поле, если оно nil",
+                    "type": "integer"
+                },
+                "schedule_on": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "video": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AnonymizePatientRequest": {
+            "type": "object",
+            "required": [
+                "performed_by",
+                "reason"
+            ],
+            "properties": {
+                "performed_by": {
+                    "type": "string",
+                    "example": "Петров А.В."
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Заявление пациента об удалении персональных данных"
+                }
+            }
+        },
+        "models.Appointment": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "patient": {
+                    "$ref": "#/definitions/models.Patient"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "rules_override_by": {
+                    "description": "RulesOverrideBy и RulesOverrideReason заполняются, если старший регистратор записал пациента в обход правил.",
+                    "type": "integer"
+                },
+                "rules_override_reason": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "$ref": "#/definitions/models.Schedule"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "ticket": {
+                    "$ref": "#/definitions/models.Ticket"
+                },
+                "ticket_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BookingRule": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "max_active_bookings": {
+                    "type": "integer"
+                },
+                "max_age": {
+                    "type": "integer"
+                },
+                "min_age": {
+                    "type": "integer"
+                },
+                "min_interval_days": {
+                    "type": "integer"
+                },
+                "specialization": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingRuleCode": {
+            "type": "string",
+            "enum": [
+                "overlap",
+                "max_active",
+                "min_interval",
+                "age"
+            ],
+            "x-enum-varnames": [
+                "BookingRuleOverlap",
+                "BookingRuleMaxActive",
+                "BookingRuleMinInterval",
+                "BookingRuleAge"
+            ]
+        },
+        "models.BookingRuleViolation": {
+            "type": "object",
+            "properties": {
+                "conflicting_appointment_id": {
+                    "description": "ConflictingAppointmentID — запись пациента, из-за которой сработало правило.",
+                    "type": "integer",
+                    "example": 42
+                },
+                "message": {
+                    "type": "string",
+                    "example": "пациент уже записан на это время: Терапевт, 20.07.2025 09:00"
+                },
+                "rule": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BookingRuleCode"
+                        }
+                    ],
+                    "example": "overlap"
+                }
+            }
+        },
+        "models.BusinessProcess": {
+            "type": "object",
+            "properties": {
+                "is_enabled": {
+                    "type": "boolean"
+                },
+                "process_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CalendarFeedResponse": {
+            "type": "object",
+            "properties": {
+                "feed_url": {
+                    "type": "string",
+                    "example": "/api/calendar/doctor/3f1c9a....ics"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "3f1c9a..."
+                }
+            }
+        },
+        "models.CheckInCodeResponse": {
+            "type": "object",
+            "properties": {
+                "appointment_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "code": {
+                    "type": "string",
+                    "example": "EQA1.42.3f9a0c1d2b7e8f65"
+                }
+            }
+        },
+        "models.CheckInMethod": {
+            "type": "string",
+            "enum": [
+                "phone",
+                "oms",
+                "passport",
+                "qr"
+            ],
+            "x-enum-varnames": [
+                "CheckInByPhone",
+                "CheckInByOMS",
+                "CheckInByPassport",
+                "CheckInByQR"
+            ]
+        },
+        "models.CheckInOutcome": {
+            "type": "string",
+            "enum": [
+                "вовремя",
+                "рано",
+                "опоздание"
+            ],
+            "x-enum-varnames": [
+                "CheckInOnTime",
+                "CheckInEarly",
+                "CheckInLate"
+            ]
+        },
+        "models.CheckInReportRow": {
+            "type": "object",
+            "properties": {
+                "avg_offset_minutes": {
+                    "type": "number",
+                    "example": 22.5
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "method": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CheckInMethod"
+                        }
+                    ],
+                    "example": "oms"
+                },
+                "outcome": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CheckInOutcome"
+                        }
+                    ],
+                    "example": "опоздание"
+                }
+            }
+        },
+        "models.CreateAdRequest": {
+            "type": "object",
+            "properties": {
+                "duration_sec": {
+                    "description": "--- ИЗМЕНЕНИЕ: gt=0 на gte=1 ---",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "is_enabled": {
+                    "type": "boolean"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "reception_on": {
+                    "type": "boolean"
+                },
+                "repeat_count": {
+                    "description": "--- ИЗМЕНЕНИЕ: gt=0 на gte=1 ---",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "schedule_on": {
+                    "type": "boolean"
+                },
+                "video": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateAppointmentRequest": {
+            "type": "object",
+            "required": [
+                "schedule_id"
+            ],
+            "properties": {
+                "override_reason": {
+                    "description": "OverrideReason — обязательная при переопределении причина, сохраняется в записи.",
+                    "type": "string",
+                    "example": "По согласованию с заведующим"
+                },
+                "override_rules": {
+                    "description": "OverrideRules — записать пациента несмотря на нарушение правил записи (только для старших регистраторов).",
+                    "type": "boolean"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "referral_id": {
+                    "description": "ReferralID — направление, по которому создается запись; после записи оно считается исполненным.",
+                    "type": "integer",
+                    "example": 7
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "ticket_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateDirectRoutingRuleRequest": {
+            "type": "object",
+            "properties": {
+                "service_id": {
+                    "type": "string",
+                    "example": "confirm_appointment"
+                },
+                "specialization": {
+                    "type": "string",
+                    "example": "Терапевт"
+                }
+            }
+        },
+        "models.CreateDisplayRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "audio": {
+                    "$ref": "#/definitions/models.DisplayAudio"
+                },
+                "cabinets": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        101
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Коридор 2 этажа, кабинет 101"
+                },
+                "playlist": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DisplayRole"
+                        }
+                    ],
+                    "example": "cabinet"
+                },
+                "theme": {
+                    "$ref": "#/definitions/models.DisplayTheme"
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.CreatePatientRequest": {
+            "type": "object",
+            "required": [
+                "birth_date",
+                "full_name",
+                "oms_number",
+                "passport_number",
+                "passport_series"
+            ],
+            "properties": {
+                "birth_date": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "oms_number": {
+                    "type": "string"
+                },
+                "passport_number": {
+                    "type": "string"
+                },
+                "passport_series": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateReferralRequest": {
+            "type": "object",
+            "required": [
+                "appointment_id",
+                "target_specialization"
+            ],
+            "properties": {
+                "appointment_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "expires_at": {
+                    "description": "ExpiresAt — дата окончания действия (YYYY-MM-DD); по умолчанию зависит от срочности.",
+                    "type": "string",
+                    "example": "2025-08-20"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "ЭКГ, консультация по результатам"
+                },
+                "target_specialization": {
+                    "type": "string",
+                    "example": "Кардиолог"
+                },
+                "urgency": {
+                    "enum": [
+                        "плановое",
+                        "срочное",
+                        "экстренное"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ReferralUrgency"
+                        }
+                    ],
+                    "example": "плановое"
+                }
+            }
+        },
+        "models.CreateScheduleRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "doctor_id",
+                "end_time",
+                "start_time"
+            ],
+            "properties": {
+                "cabinet": {
+                    "type": "integer",
+                    "example": 101
+                },
+                "capacity": {
+                    "description": "Capacity — вместимость слота для групповых процедур; по умолчанию 1.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 1
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-07-20T00:00:00Z"
+                },
+                "doctor_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "2025-01-01T10:00:00Z"
+                },
+                "is_available": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2025-01-01T09:00:00Z"
+                }
+            }
+        },
+        "models.CreateWaitlistEntryRequest": {
+            "type": "object",
+            "required": [
+                "date_from",
+                "date_to",
+                "patient_id"
+            ],
+            "properties": {
+                "date_from": {
+                    "type": "string",
+                    "example": "2025-07-20T00:00:00Z"
+                },
+                "date_to": {
+                    "type": "string",
+                    "example": "2025-07-31T00:00:00Z"
+                },
+                "doctor_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "note": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "specialization": {
+                    "type": "string",
+                    "example": "Кардиолог"
+                }
+            }
+        },
+        "models.DeleteRequest": {
+            "type": "object",
+            "required": [
+                "filters"
+            ],
+            "properties": {
+                "filters": {
+                    "$ref": "#/definitions/models.Filters"
+                }
+            }
+        },
+        "models.DirectRoutingRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "service_id": {
+                    "type": "string"
+                },
+                "specialization": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DisplayAudio": {
+            "type": "object",
+            "properties": {
+                "background_music": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "volume": {
+                    "description": "Volume — громкость от 0 до 100.",
+                    "type": "integer",
+                    "example": 80
+                }
+            }
+        },
+        "models.DisplayResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Коридор 2 этажа, кабинет 101"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DisplayRole"
+                        }
+                    ],
+                    "example": "cabinet"
+                },
+                "settings": {
+                    "$ref": "#/definitions/models.DisplaySettings"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DisplayRole": {
+            "type": "string",
+            "enum": [
+                "reception",
+                "cabinet",
+                "schedule",
+                "kiosk"
+            ],
+            "x-enum-comments": {
+                "DisplayRoleCabinet": "экран у кабинета врача",
+                "DisplayRoleKiosk": "терминал самообслуживания",
+                "DisplayRoleReception": "табло регистратуры",
+                "DisplayRoleSchedule": "табло расписания"
+            },
+            "x-enum-varnames": [
+                "DisplayRoleReception",
+                "DisplayRoleCabinet",
+                "DisplayRoleSchedule",
+                "DisplayRoleKiosk"
+            ]
+        },
+        "models.DisplaySettings": {
+            "type": "object",
+            "properties": {
+                "audio": {
+                    "$ref": "#/definitions/models.DisplayAudio"
+                },
+                "cabinets": {
+                    "description": "Cabinets — кабинеты, для которых работает экран у кабинета.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        101
+                    ]
+                },
+                "playlist": {
+                    "description": "Playlist — ID рекламных материалов в порядке показа (пусто — все материалы, включенные для экрана).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        5
+                    ]
+                },
+                "theme": {
+                    "$ref": "#/definitions/models.DisplayTheme"
+                },
+                "windows": {
+                    "description": "Windows — окна регистратуры, талоны которых показывает табло регистратуры (пусто — все окна).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "models.DisplayTheme": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string",
+                    "example": "#1E88E5"
+                },
+                "font_scale": {
+                    "description": "FontScale — масштаб шрифта относительно стандартного, от 0.5 до 3.",
+                    "type": "number",
+                    "example": 1.25
+                },
+                "mode": {
+                    "description": "Mode — light или dark.",
+                    "type": "string",
+                    "example": "dark"
+                }
+            }
+        },
+        "models.DisplayTokenResponse": {
+            "type": "object",
+            "properties": {
+                "display": {
+                    "$ref": "#/definitions/models.DisplayResponse"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "9c1e4f..."
+                }
+            }
+        },
+        "models.Doctor": {
+            "type": "object",
+            "properties": {
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Schedule"
+                    }
+                },
+                "specialization": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.DoctorStatus"
+                }
+            }
+        },
+        "models.DoctorQueueTicketResponse": {
+            "type": "object",
+            "properties": {
+                "cabinet_number": {
+                    "type": "integer"
+                },
+                "patient_full_name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.TicketStatus"
+                },
+                "ticket_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DoctorStatus": {
+            "type": "string",
+            "enum": [
+                "активен",
+                "неактивен",
+                "перерыв"
+            ],
+            "x-enum-varnames": [
+                "DoctorStatusActive",
+                "DoctorStatusInactive",
+                "DoctorStatusOnBreak"
+            ]
+        },
+        "models.DuplicateCandidate": {
+            "type": "object",
+            "properties": {
+                "name_similarity": {
+                    "description": "NameSimilarity — сходство ФИО от 0 до 1.",
+                    "type": "number",
+                    "example": 0.93
+                },
+                "patient": {
+                    "$ref": "#/definitions/models.Patient"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DuplicateReason"
+                    }
+                }
+            }
+        },
+        "models.DuplicatePair": {
+            "type": "object",
+            "properties": {
+                "first": {
+                    "$ref": "#/definitions/models.Patient"
+                },
+                "name_similarity": {
+                    "type": "number",
+                    "example": 0.93
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DuplicateReason"
+                    }
+                },
+                "second": {
+                    "$ref": "#/definitions/models.Patient"
+                }
+            }
+        },
+        "models.DuplicateReason": {
+            "type": "string",
+            "enum": [
+                "same_oms",
+                "same_phone",
+                "similar_name_birth_date"
+            ],
+            "x-enum-varnames": [
+                "DuplicateSameOMS",
+                "DuplicateSamePhone",
+                "DuplicateNameBirthDate"
+            ]
+        },
+        "models.ExpiredReferralRow": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "days_overdue": {
+                    "type": "integer"
+                },
+                "doctor_name": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "patient_name": {
+                    "type": "string"
+                },
+                "patient_phone": {
+                    "type": "string"
+                },
+                "referral_id": {
+                    "type": "integer"
+                },
+                "target_specialization": {
+                    "type": "string"
+                },
+                "urgency": {
+                    "$ref": "#/definitions/models.ReferralUrgency"
+                }
+            }
+        },
+        "models.ExternalAppointmentResponse": {
+            "type": "object",
+            "properties": {
+                "appointment_id": {
+                    "type": "integer"
+                },
+                "cabinet": {
+                    "type": "integer"
+                },
+                "check_in_code": {
+                    "description": "CheckInCode — код для QR-кода, по которому пациент регистрируется на приеме через киоск.",
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "doctor_name": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "specialization": {
+                    "type": "string"
+                },
+                "start_time": {
                     "type": "string"
                 }
             }
         },
-        "handlers.CompleteAppointmentRequest": {
+        "models.ExternalBookingRequest": {
             "type": "object",
             "required": [
-                "ticket_id"
+                "birth_date",
+                "oms_number",
+                "schedule_id"
             ],
             "properties": {
-                "ticket_id": {
+                "birth_date": {
+                    "type": "string",
+                    "example": "1985-04-12"
+                },
+                "oms_number": {
+                    "type": "string",
+                    "example": "1234567890123456"
+                },
+                "schedule_id": {
                     "type": "integer",
-                    "example": 1
+                    "example": 12
                 }
             }
         },
-        "handlers.ConfirmAppointmentRequest": {
+        "models.ExternalDoctorResponse": {
             "type": "object",
-            "required": [
-                "ticket_id"
-            ],
             "properties": {
-                "ticket_id": {
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "specialization": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.ConfirmationRequest": {
+        "models.ExternalPatientIdentity": {
             "type": "object",
             "required": [
-                "action",
-                "service_id"
+                "birth_date",
+                "oms_number"
             ],
             "properties": {
-                "action": {
+                "birth_date": {
                     "type": "string",
-                    "example": "print_ticket"
+                    "example": "1985-04-12"
                 },
-                "service_id": {
+                "oms_number": {
                     "type": "string",
-                    "example": "make_appointment"
+                    "example": "1234567890123456"
                 }
             }
         },
-        "handlers.ConfirmationResponse": {
+        "models.ExternalSlotResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Ваш электронный талон"
+                "cabinet": {
+                    "type": "integer"
                 },
-                "service_name": {
-                    "type": "string",
-                    "example": "Записаться к врачу"
+                "check_in_code": {
+                    "description": "CheckInCode — код для QR-кода, по которому пациент регистрируется на приеме через киоск.",
+                    "type": "string"
                 },
-                "ticket_number": {
-                    "type": "string",
-                    "example": "A001"
+                "date": {
+                    "type": "string"
                 },
-                "timeout": {
-                    "type": "integer",
-                    "example": 10
+                "doctor_id": {
+                    "type": "integer"
+                },
+                "doctor_name": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "free_places": {
+                    "description": "FreePlaces — число свободных мест (больше 1 только у групповых слотов).",
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "specialization": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.CreateAdministratorRequest": {
+        "models.FilterCondition": {
             "type": "object",
-            "required": [
-                "full_name",
-                "login",
-                "password"
-            ],
             "properties": {
-                "full_name": {
+                "field": {
                     "type": "string"
                 },
-                "login": {
+                "operator": {
                     "type": "string"
                 },
-                "password": {
+                "value": {}
+            }
+        },
+        "models.Filters": {
+            "type": "object",
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FilterCondition"
+                    }
+                },
+                "logical_operator": {
                     "type": "string"
                 }
             }
         },
-        "handlers.CreateDoctorRequest": {
+        "models.GetDataRequest": {
             "type": "object",
-            "required": [
-                "full_name",
-                "login",
-                "password",
-                "specialization"
-            ],
             "properties": {
-                "full_name": {
-                    "type": "string"
+                "filters": {
+                    "$ref": "#/definitions/models.Filters"
                 },
-                "login": {
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string"
+                "page": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.InsertRequest": {
+            "type": "object",
+            "required": [
+                "data"
+            ],
+            "properties": {
+                "data": {}
+            }
+        },
+        "models.KioskCheckInRequest": {
+            "type": "object",
+            "required": [
+                "method",
+                "value"
+            ],
+            "properties": {
+                "action": {
+                    "description": "Action — print_ticket, чтобы напечатать талон; иначе талон только электронный.",
+                    "type": "string",
+                    "example": "print_ticket"
                 },
-                "specialization": {
-                    "type": "string"
+                "birth_year": {
+                    "description": "BirthYear — год рождения для уточнения, если по данным найдено несколько пациентов с записями на сегодня.",
+                    "type": "integer",
+                    "maximum": 2100,
+                    "minimum": 1900,
+                    "example": 1985
+                },
+                "method": {
+                    "description": "Method — способ идентификации: phone, oms (номер или штрих-код полиса), passport (серия и номер) или qr (код из подтверждения записи).",
+                    "enum": [
+                        "phone",
+                        "oms",
+                        "passport",
+                        "qr"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CheckInMethod"
+                        }
+                    ],
+                    "example": "oms"
+                },
+                "value": {
+                    "description": "Value — введенное или отсканированное значение.",
+                    "type": "string",
+                    "example": "1234567890123456"
                 }
             }
         },
-        "handlers.CreateRegistrarRequest": {
+        "models.MergePatientsRequest": {
             "type": "object",
             "required": [
-                "login",
-                "password",
-                "window_number"
+                "duplicate_patient_id",
+                "performed_by",
+                "reason",
+                "surviving_patient_id"
             ],
             "properties": {
-                "login": {
-                    "type": "string"
+                "duplicate_patient_id": {
+                    "type": "integer",
+                    "example": 47
                 },
-                "password": {
-                    "type": "string"
+                "performed_by": {
+                    "type": "string",
+                    "example": "Петров А.В."
                 },
-                "window_number": {
-                    "type": "integer"
+                "reason": {
+                    "type": "string",
+                    "example": "Повторная регистрация с опечаткой в ФИО"
+                },
+                "surviving_patient_id": {
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
-        "handlers.DoctorScreenResponse": {
+        "models.Patient": {
             "type": "object",
             "properties": {
-                "cabinet_number": {
+                "anonymized_at": {
+                    "description": "AnonymizedAt — время анонимизации карточки; у анонимизированной карточки вместо ФИО токен, документов и контактов нет.",
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "doctor_name": {
+                "notifications_opt_out": {
+                    "description": "NotificationsOptOut отключает все уведомления пациенту (напоминания, изменения записей).",
+                    "type": "boolean"
+                },
+                "oms_number": {
                     "type": "string"
                 },
-                "doctor_specialty": {
+                "passport_number": {
                     "type": "string"
                 },
-                "message": {
+                "passport_series": {
                     "type": "string"
                 },
-                "queue": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.DoctorQueueTicketResponse"
-                    }
+                "phone": {
+                    "type": "string"
+                },
+                "phone_invalid": {
+                    "description": "PhoneInvalid отмечает телефон, который не удалось привести к формату E.164 при миграции;\nномер нужно уточнить у пациента. Флаг снимается при изменении телефона.",
+                    "type": "boolean"
                 }
             }
         },
-        "handlers.EndBreakRequest": {
+        "models.PatientAccessAnomaly": {
             "type": "object",
-            "required": [
-                "doctor_id"
-            ],
             "properties": {
-                "doctor_id": {
+                "actor_id": {
                     "type": "integer",
-                    "example": 1
+                    "example": 3
+                },
+                "actor_name": {
+                    "type": "string",
+                    "example": "Смирнова Е.А."
+                },
+                "actor_role": {
+                    "type": "string",
+                    "example": "registrar"
+                },
+                "distinct_patients": {
+                    "type": "integer",
+                    "example": 84
+                },
+                "hour_start": {
+                    "description": "HourStart — начало часа, за который посчитаны обращения.",
+                    "type": "string"
+                },
+                "reads": {
+                    "type": "integer",
+                    "example": 131
                 }
             }
         },
-        "handlers.LoginRequest": {
+        "models.PatientAccessAnomalyReport": {
             "type": "object",
-            "required": [
-                "login",
-                "password"
-            ],
             "properties": {
-                "login": {
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PatientAccessAnomaly"
+                    }
+                },
+                "from": {
                     "type": "string"
                 },
-                "password": {
+                "threshold": {
+                    "description": "Threshold — сколько разных пациентов за час допустимо; в отчет попадают часы, где их больше.",
+                    "type": "integer",
+                    "example": 50
+                },
+                "to": {
                     "type": "string"
                 }
             }
         },
-        "handlers.ServiceSelectionRequest": {
+        "models.PatientAccessLog": {
             "type": "object",
-            "required": [
-                "service_id"
-            ],
             "properties": {
-                "service_id": {
+                "accessed_at": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "description": "ActorID — ID регистратора или врача; для доступа по ключу API не заполняется.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "actor_role": {
+                    "description": "ActorRole — роль пользователя (registrar, doctor) или тип ключа API (admin, database).",
                     "type": "string",
-                    "example": "make_appointment"
-                }
-            }
-        },
-        "handlers.ServiceSelectionResponse": {
-            "type": "object",
-            "properties": {
-                "action": {
+                    "example": "registrar"
+                },
+                "client_ip": {
                     "type": "string",
-                    "example": "confirm_print"
+                    "example": "10.0.0.15"
+                },
+                "endpoint": {
+                    "description": "Endpoint — метод и шаблон маршрута, например \"GET /api/registrar/patients/:patient_id/timeline\".",
+                    "type": "string",
+                    "example": "GET /api/registrar/patients/:patient_id/timeline"
+                },
+                "id": {
+                    "type": "integer"
                 },
-                "service_name": {
-                    "type": "string",
-                    "example": "Записаться к врачу"
+                "patient_id": {
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
-        "handlers.SetActiveRequest": {
+        "models.PatientAccessPage": {
             "type": "object",
-            "required": [
-                "doctor_id"
-            ],
             "properties": {
-                "doctor_id": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PatientAccessLog"
+                    }
+                },
+                "page": {
                     "type": "integer",
                     "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
                 }
             }
         },
-        "handlers.SetInactiveRequest": {
+        "models.PatientAnonymizationResult": {
             "type": "object",
-            "required": [
-                "doctor_id"
-            ],
             "properties": {
-                "doctor_id": {
+                "audit_entry": {
+                    "$ref": "#/definitions/models.PatientAuditLog"
+                },
+                "cancelled_notifications": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "cancelled_waitlist_entries": {
                     "type": "integer",
                     "example": 1
+                },
+                "cleared_fields": {
+                    "description": "ClearedFields — очищенные поля карточки; дата рождения сокращается до года.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "patient_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "preserved": {
+                    "description": "Preserved — число сохраненных для статистики записей, связанных с карточкой, по таблицам.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "redacted_audit_entries": {
+                    "description": "RedactedAuditEntries — прежние записи журнала изменений карточки, из которых удалены старые значения полей.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "redacted_notifications": {
+                    "description": "RedactedNotifications — уведомления, из текста которых удалены данные пациента.",
+                    "type": "integer",
+                    "example": 14
+                },
+                "token": {
+                    "description": "Token — обезличенное имя, которое карточка получила вместо ФИО.",
+                    "type": "string",
+                    "example": "Аноним 3F9A1C2B7E04"
                 }
             }
         },
-        "handlers.StartAppointmentRequest": {
-            "type": "object",
-            "required": [
-                "ticket_id"
+        "models.PatientAuditAction": {
+            "type": "string",
+            "enum": [
+                "изменение",
+                "объединение",
+                "анонимизация"
             ],
+            "x-enum-varnames": [
+                "PatientAuditUpdate",
+                "PatientAuditMerge",
+                "PatientAuditAnonymize"
+            ]
+        },
+        "models.PatientAuditLog": {
+            "type": "object",
             "properties": {
-                "ticket_id": {
-                    "type": "integer",
-                    "example": 1
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PatientAuditAction"
+                        }
+                    ],
+                    "example": "изменение"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "actor_name": {
+                    "type": "string"
+                },
+                "actor_role": {
+                    "type": "string",
+                    "example": "registrar"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Details — для изменения: старые и новые значения полей; для объединения: снимок удаленной карточки и число перенесенных записей;\nдля анонимизации: отчет об очищенных и сохраненных данных.",
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merged_patient_id": {
+                    "type": "integer"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.StartBreakRequest": {
+        "models.PatientMergeResult": {
             "type": "object",
-            "required": [
-                "doctor_id"
-            ],
             "properties": {
-                "doctor_id": {
-                    "type": "integer",
-                    "example": 1
+                "audit_entry": {
+                    "$ref": "#/definitions/models.PatientAuditLog"
+                },
+                "dropped_appointments": {
+                    "description": "DroppedAppointments — записи дубликата в те же слоты, что и у оставшейся карточки; они удалены.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "moved": {
+                    "description": "Moved — число перенесенных на оставшуюся карточку записей по таблицам.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "patient": {
+                    "$ref": "#/definitions/models.Patient"
                 }
             }
         },
-        "handlers.UpdateProcessRequest": {
+        "models.PatientSearchPage": {
             "type": "object",
             "properties": {
-                "is_enabled": {
-                    "type": "boolean"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PatientSearchResult"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
                 }
             }
         },
-        "models.AdResponse": {
+        "models.PatientSearchResult": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "anonymized_at": {
+                    "description": "AnonymizedAt — время анонимизации карточки; у анонимизированной карточки вместо ФИО токен, документов и контактов нет.",
                     "type": "string"
                 },
-                "duration_sec": {
-                    "description": "omitempty уберет поле, если оно nil",
-                    "type": "integer"
+                "birth_date": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "is_enabled": {
+                "notifications_opt_out": {
+                    "description": "NotificationsOptOut отключает все уведомления пациенту (напоминания, изменения записей).",
                     "type": "boolean"
                 },
-                "media_type": {
+                "oms_number": {
                     "type": "string"
                 },
-                "picture": {
+                "open_referrals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Referral"
+                    }
+                },
+                "passport_number": {
                     "type": "string"
                 },
-                "reception_on": {
-                    "type": "boolean"
+                "passport_series": {
+                    "type": "string"
                 },
-                "repeat_count": {
-                    "description": "omitempty уберет поле, если оно nil",
-                    "type": "integer"
+                "phone": {
+                    "type": "string"
                 },
-                "schedule_on": {
+                "phone_invalid": {
+                    "description": "PhoneInvalid отмечает телефон, который не удалось привести к формату E.164 при миграции;\nномер нужно уточнить у пациента. Флаг снимается при изменении телефона.",
                     "type": "boolean"
                 },
-                "updated_at": {
-                    "type": "string"
-                },
-                "video": {
-                    "type": "string"
+                "relevance": {
+                    "description": "Relevance — оценка соответствия поисковому запросу; результаты упорядочены по ней.",
+                    "type": "number",
+                    "example": 1.75
                 }
             }
         },
-        "models.Appointment": {
+        "models.PatientTimelineEntry": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "patient": {
-                    "$ref": "#/definitions/models.Patient"
-                },
-                "patient_id": {
-                    "type": "integer"
+                "archived": {
+                    "description": "Archived — визит взят из архива, его запись на прием уже удалена очисткой.",
+                    "type": "boolean"
                 },
-                "schedule": {
-                    "$ref": "#/definitions/models.Schedule"
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TimelineEntryKind"
+                        }
+                    ],
+                    "example": "visit"
                 },
-                "schedule_id": {
-                    "type": "integer"
+                "occurred_at": {
+                    "description": "OccurredAt — начало приема для визита, время выдачи для направления.",
+                    "type": "string"
                 },
-                "ticket": {
-                    "$ref": "#/definitions/models.Ticket"
+                "referral": {
+                    "$ref": "#/definitions/models.Referral"
                 },
-                "ticket_id": {
-                    "type": "integer"
+                "visit": {
+                    "$ref": "#/definitions/models.PatientVisit"
                 }
             }
         },
-        "models.BusinessProcess": {
+        "models.PatientTimelinePage": {
             "type": "object",
             "properties": {
-                "is_enabled": {
-                    "type": "boolean"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PatientTimelineEntry"
+                    }
                 },
-                "process_name": {
-                    "type": "string"
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
                 }
             }
         },
-        "models.CreateAdRequest": {
+        "models.PatientVisit": {
             "type": "object",
             "properties": {
-                "duration_sec": {
-                    "description": "--- ИЗМЕНЕНИЕ: gt=0 на gte=1 ---",
+                "appointment_id": {
                     "type": "integer",
-                    "minimum": 1
+                    "example": 42
                 },
-                "is_enabled": {
-                    "type": "boolean"
+                "archived_at": {
+                    "description": "ArchivedAt заполнено у визитов из архива.",
+                    "type": "string"
                 },
-                "picture": {
+                "arrival": {
+                    "description": "Arrival — результат регистрации по записи через киоск: вовремя, рано или опоздание.",
+                    "type": "string",
+                    "example": "вовремя"
+                },
+                "arrival_offset_minutes": {
+                    "type": "integer",
+                    "example": -5
+                },
+                "cabinet": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "called_at": {
                     "type": "string"
                 },
-                "reception_on": {
-                    "type": "boolean"
+                "completed_at": {
+                    "type": "string"
                 },
-                "repeat_count": {
-                    "description": "--- ИЗМЕНЕНИЕ: gt=0 на gte=1 ---",
+                "doctor_id": {
                     "type": "integer",
-                    "minimum": 1
+                    "example": 3
                 },
-                "schedule_on": {
+                "doctor_name": {
+                    "type": "string",
+                    "example": "Смирнова Е.А."
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "09:45:00"
+                },
+                "is_late": {
                     "type": "boolean"
                 },
-                "video": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.CreateAppointmentRequest": {
-            "type": "object",
-            "required": [
-                "schedule_id"
-            ],
-            "properties": {
-                "patient_id": {
-                    "type": "integer"
+                "outcome": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.VisitOutcome"
+                        }
+                    ],
+                    "example": "завершен"
                 },
-                "schedule_id": {
-                    "type": "integer"
+                "service_seconds": {
+                    "description": "ServiceSeconds — суммарное время обслуживания в окне регистратуры.",
+                    "type": "integer",
+                    "example": 180
                 },
-                "ticket_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.CreatePatientRequest": {
-            "type": "object",
-            "required": [
-                "birth_date",
-                "full_name",
-                "oms_number",
-                "passport_number",
-                "passport_series"
-            ],
-            "properties": {
-                "birth_date": {
+                "service_type": {
                     "type": "string"
                 },
-                "full_name": {
-                    "type": "string"
+                "specialization": {
+                    "description": "Specialization — специализация врача на момент визита.",
+                    "type": "string",
+                    "example": "Терапевт"
                 },
-                "oms_number": {
-                    "type": "string"
+                "start_time": {
+                    "type": "string",
+                    "example": "09:30:00"
                 },
-                "passport_number": {
+                "started_at": {
                     "type": "string"
                 },
-                "passport_series": {
+                "ticket_created_at": {
                     "type": "string"
                 },
-                "phone": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.CreateScheduleRequest": {
-            "type": "object",
-            "required": [
-                "date",
-                "doctor_id",
-                "end_time",
-                "start_time"
-            ],
-            "properties": {
-                "cabinet": {
+                "ticket_id": {
                     "type": "integer",
-                    "example": 101
+                    "example": 315
                 },
-                "date": {
+                "ticket_number": {
                     "type": "string",
-                    "example": "2025-07-20T00:00:00Z"
+                    "example": "A015"
                 },
-                "doctor_id": {
-                    "type": "integer",
-                    "example": 1
+                "ticket_status": {
+                    "$ref": "#/definitions/models.TicketStatus"
                 },
-                "end_time": {
-                    "type": "string",
-                    "example": "2025-01-01T10:00:00Z"
+                "visit_date": {
+                    "type": "string"
                 },
-                "is_available": {
-                    "type": "boolean",
-                    "example": true
+                "visit_seconds": {
+                    "description": "VisitSeconds — длительность приема у врача.",
+                    "type": "integer",
+                    "example": 900
                 },
-                "start_time": {
-                    "type": "string",
-                    "example": "2025-01-01T09:00:00Z"
-                }
-            }
-        },
-        "models.DeleteRequest": {
-            "type": "object",
-            "required": [
-                "filters"
-            ],
-            "properties": {
-                "filters": {
-                    "$ref": "#/definitions/models.Filters"
+                "wait_seconds": {
+                    "description": "WaitSeconds — от выдачи талона до первого вызова в окно регистратуры.",
+                    "type": "integer",
+                    "example": 540
+                },
+                "window_number": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "models.Doctor": {
+        "models.Referral": {
             "type": "object",
             "properties": {
-                "full_name": {
+                "appointment_id": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "doctor": {
+                    "$ref": "#/definitions/models.Doctor"
+                },
+                "doctor_id": {
                     "type": "integer"
                 },
-                "login": {
+                "expires_at": {
                     "type": "string"
                 },
-                "schedules": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Schedule"
-                    }
-                },
-                "specialization": {
+                "fulfilled_at": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/models.DoctorStatus"
-                }
-            }
-        },
-        "models.DoctorQueueTicketResponse": {
-            "type": "object",
-            "properties": {
-                "cabinet_number": {
+                "id": {
                     "type": "integer"
                 },
-                "patient_full_name": {
+                "note": {
                     "type": "string"
                 },
-                "start_time": {
-                    "type": "string"
+                "patient_id": {
+                    "type": "integer"
+                },
+                "source_appointment_id": {
+                    "type": "integer"
                 },
                 "status": {
-                    "$ref": "#/definitions/models.TicketStatus"
+                    "$ref": "#/definitions/models.ReferralStatus"
                 },
-                "ticket_number": {
+                "target_specialization": {
                     "type": "string"
+                },
+                "urgency": {
+                    "$ref": "#/definitions/models.ReferralUrgency"
                 }
             }
         },
-        "models.DoctorStatus": {
+        "models.ReferralStatus": {
             "type": "string",
             "enum": [
-                "активен",
-                "неактивен",
-                "перерыв"
+                "открыто",
+                "исполнено",
+                "отменено"
             ],
             "x-enum-varnames": [
-                "DoctorStatusActive",
-                "DoctorStatusInactive",
-                "DoctorStatusOnBreak"
+                "ReferralOpen",
+                "ReferralFulfilled",
+                "ReferralCancelled"
             ]
         },
-        "models.FilterCondition": {
-            "type": "object",
-            "properties": {
-                "field": {
-                    "type": "string"
-                },
-                "operator": {
-                    "type": "string"
-                },
-                "value": {}
-            }
+        "models.ReferralUrgency": {
+            "type": "string",
+            "enum": [
+                "плановое",
+                "срочное",
+                "экстренное"
+            ],
+            "x-enum-varnames": [
+                "ReferralRoutine",
+                "ReferralUrgent",
+                "ReferralEmergency"
+            ]
         },
-        "models.Filters": {
+        "models.RescheduleAppointmentRequest": {
             "type": "object",
+            "required": [
+                "schedule_id"
+            ],
             "properties": {
-                "conditions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.FilterCondition"
-                    }
-                },
-                "logical_operator": {
-                    "type": "string"
+                "schedule_id": {
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
-        "models.GetDataRequest": {
+        "models.RetentionCandidate": {
             "type": "object",
             "properties": {
-                "filters": {
-                    "$ref": "#/definitions/models.Filters"
-                },
-                "limit": {
-                    "type": "integer"
+                "last_activity": {
+                    "description": "LastActivity — дата последнего приема или, если приемов не было, регистрации карточки.",
+                    "type": "string"
                 },
-                "page": {
-                    "type": "integer"
+                "patient_id": {
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
-        "models.InsertRequest": {
+        "models.RetentionFailure": {
             "type": "object",
-            "required": [
-                "data"
-            ],
             "properties": {
-                "data": {}
+                "error": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "integer",
+                    "example": 12
+                }
             }
         },
-        "models.Patient": {
+        "models.RetentionReport": {
             "type": "object",
             "properties": {
-                "birth_date": {
-                    "type": "string"
+                "anonymized": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "full_name": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RetentionCandidate"
+                    }
+                },
+                "cutoff": {
+                    "description": "Cutoff — карточки без визитов с этой даты анонимизируются.",
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "dry_run": {
+                    "type": "boolean"
                 },
-                "oms_number": {
-                    "type": "string"
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RetentionFailure"
+                    }
                 },
-                "passport_number": {
+                "finished_at": {
                     "type": "string"
                 },
-                "passport_series": {
-                    "type": "string"
+                "has_more": {
+                    "description": "HasMore — кандидатов больше, чем обрабатывается за один запуск; остальные будут обработаны при следующем.",
+                    "type": "boolean"
                 },
-                "phone": {
+                "retention_years": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "started_at": {
                     "type": "string"
                 }
             }
         },
+        "models.RunRetentionRequest": {
+            "type": "object",
+            "required": [
+                "performed_by"
+            ],
+            "properties": {
+                "dry_run": {
+                    "description": "DryRun — только найти карточки с истекшим сроком хранения, не анонимизируя их.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "performed_by": {
+                    "type": "string",
+                    "example": "Петров А.В."
+                }
+            }
+        },
         "models.Schedule": {
             "type": "object",
             "properties": {
+                "booked_count": {
+                    "type": "integer"
+                },
                 "cabinet": {
                     "type": "integer"
                 },
+                "capacity": {
+                    "description": "Capacity — число пациентов, которых можно записать в слот; BookedCount — занятые места.",
+                    "type": "integer"
+                },
                 "date": {
                     "type": "string"
                 },
@@ -3222,6 +8750,10 @@ const docTemplate = `{
                 "is_available": {
                     "type": "boolean"
                 },
+                "is_blocked": {
+                    "description": "IsBlocked — слот закрыт администратором. IsAvailable вычисляется как\nBookedCount \u003c Capacity \u0026\u0026 !IsBlocked и ведется репозиторием.",
+                    "type": "boolean"
+                },
                 "schedule_id": {
                     "type": "integer"
                 },
@@ -3230,15 +8762,118 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ScheduleImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "invalid": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScheduleImportRowResult"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "valid": {
+                    "type": "integer",
+                    "example": 8
+                }
+            }
+        },
+        "models.ScheduleImportRowResult": {
+            "type": "object",
+            "properties": {
+                "cabinet": {
+                    "type": "integer",
+                    "example": 101
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-07-20"
+                },
+                "doctor": {
+                    "type": "string",
+                    "example": "ivanov"
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "09:30"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "schedule_id": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "09:00"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ScheduleImportRowStatus"
+                        }
+                    ],
+                    "example": "корректна"
+                }
+            }
+        },
+        "models.ScheduleImportRowStatus": {
+            "type": "string",
+            "enum": [
+                "корректна",
+                "создана",
+                "ошибка"
+            ],
+            "x-enum-varnames": [
+                "ScheduleImportRowValid",
+                "ScheduleImportRowCreated",
+                "ScheduleImportRowError"
+            ]
+        },
         "models.ScheduleWithAppointmentInfo": {
             "type": "object",
             "properties": {
                 "appointment": {
                     "$ref": "#/definitions/models.Appointment"
                 },
+                "appointments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Appointment"
+                    }
+                },
+                "booked_count": {
+                    "type": "integer"
+                },
                 "cabinet": {
                     "type": "integer"
                 },
+                "capacity": {
+                    "description": "Capacity — число пациентов, которых можно записать в слот; BookedCount — занятые места.",
+                    "type": "integer"
+                },
                 "date": {
                     "type": "string"
                 },
@@ -3251,9 +8886,16 @@ const docTemplate = `{
                 "end_time": {
                     "type": "string"
                 },
+                "free_places": {
+                    "type": "integer"
+                },
                 "is_available": {
                     "type": "boolean"
                 },
+                "is_blocked": {
+                    "description": "IsBlocked — слот закрыт администратором. IsAvailable вычисляется как\nBookedCount \u003c Capacity \u0026\u0026 !IsBlocked и ведется репозиторием.",
+                    "type": "boolean"
+                },
                 "schedule_id": {
                     "type": "integer"
                 },
@@ -3297,6 +8939,10 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "is_late": {
+                    "description": "IsLate отмечает талон пациента, пришедшего по записи позже окна регистрации.",
+                    "type": "boolean"
+                },
                 "qr_code": {
                     "type": "array",
                     "items": {
@@ -3335,6 +8981,9 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "is_late": {
+                    "type": "boolean"
+                },
                 "qr_code": {
                     "type": "array",
                     "items": {
@@ -3375,6 +9024,17 @@ const docTemplate = `{
                 "StatusRegistered"
             ]
         },
+        "models.TimelineEntryKind": {
+            "type": "string",
+            "enum": [
+                "visit",
+                "referral"
+            ],
+            "x-enum-varnames": [
+                "TimelineVisit",
+                "TimelineReferral"
+            ]
+        },
         "models.UpdateAdRequest": {
             "type": "object",
             "properties": {
@@ -3405,6 +9065,84 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateDisplayRequest": {
+            "type": "object",
+            "properties": {
+                "audio": {
+                    "$ref": "#/definitions/models.DisplayAudio"
+                },
+                "cabinets": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "playlist": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DisplayRole"
+                        }
+                    ],
+                    "example": "reception"
+                },
+                "theme": {
+                    "$ref": "#/definitions/models.DisplayTheme"
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.UpdateNotificationSettingsRequest": {
+            "type": "object",
+            "required": [
+                "opt_out"
+            ],
+            "properties": {
+                "opt_out": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.UpdatePatientRequest": {
+            "type": "object",
+            "properties": {
+                "birth_date": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "oms_number": {
+                    "type": "string"
+                },
+                "passport_number": {
+                    "type": "string"
+                },
+                "passport_series": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
         "models.UpdateRequest": {
             "type": "object",
             "required": [
@@ -3421,6 +9159,293 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateScheduleRequest": {
+            "type": "object",
+            "required": [
+                "is_available"
+            ],
+            "properties": {
+                "is_available": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.UpsertBookingRuleRequest": {
+            "type": "object",
+            "required": [
+                "specialization"
+            ],
+            "properties": {
+                "max_active_bookings": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "max_age": {
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0,
+                    "example": 17
+                },
+                "min_age": {
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0,
+                    "example": 0
+                },
+                "min_interval_days": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 14
+                },
+                "specialization": {
+                    "type": "string",
+                    "example": "Кардиолог"
+                }
+            }
+        },
+        "models.VisitOutcome": {
+            "type": "string",
+            "enum": [
+                "запланирован",
+                "в очереди",
+                "на приеме",
+                "завершен",
+                "не обслужен",
+                "неявка"
+            ],
+            "x-enum-varnames": [
+                "VisitPlanned",
+                "VisitInQueue",
+                "VisitInProgress",
+                "VisitCompleted",
+                "VisitNotServed",
+                "VisitNoShow"
+            ]
+        },
+        "models.WaitlistEntry": {
+            "type": "object",
+            "properties": {
+                "appointment_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date_from": {
+                    "type": "string"
+                },
+                "date_to": {
+                    "type": "string"
+                },
+                "doctor": {
+                    "$ref": "#/definitions/models.Doctor"
+                },
+                "doctor_id": {
+                    "type": "integer"
+                },
+                "held_schedule": {
+                    "$ref": "#/definitions/models.Schedule"
+                },
+                "held_schedule_id": {
+                    "type": "integer"
+                },
+                "hold_expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "offered_at": {
+                    "type": "string"
+                },
+                "patient": {
+                    "$ref": "#/definitions/models.Patient"
+                },
+                "patient_id": {
+                    "type": "integer"
+                },
+                "specialization": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WaitlistStatus"
+                }
+            }
+        },
+        "models.WaitlistStatus": {
+            "type": "string",
+            "enum": [
+                "ожидает",
+                "предложено",
+                "подтверждено",
+                "отменено"
+            ],
+            "x-enum-varnames": [
+                "WaitlistStatusWaiting",
+                "WaitlistStatusOffered",
+                "WaitlistStatusConfirmed",
+                "WaitlistStatusCancelled"
+            ]
+        },
+        "pubsub.BrokerStats": {
+            "type": "object",
+            "properties": {
+                "buffer_size": {
+                    "type": "integer",
+                    "example": 32
+                },
+                "delivered": {
+                    "type": "integer",
+                    "example": 9800
+                },
+                "disconnected": {
+                    "description": "Disconnected — сколько медленных клиентов отключено.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "dropped": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "last_event_id": {
+                    "description": "LastEventID — ID последнего опубликованного события.",
+                    "type": "integer",
+                    "example": 1718000000000123
+                },
+                "max_drops": {
+                    "description": "MaxDrops — после скольких пропущенных подряд событий клиент отключается (0 — не отключается).",
+                    "type": "integer",
+                    "example": 100
+                },
+                "published": {
+                    "type": "integer",
+                    "example": 1520
+                },
+                "resyncs": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "subscribers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pubsub.SubscriberStats"
+                    }
+                }
+            }
+        },
+        "pubsub.ListenerHealth": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "connected": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "connected_since": {
+                    "description": "ConnectedSince — когда установлено текущее соединение; DisconnectedSince — когда потеряно последнее.",
+                    "type": "string"
+                },
+                "disconnected_since": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "conn closed"
+                },
+                "last_notification": {
+                    "type": "string"
+                },
+                "notifications": {
+                    "type": "integer",
+                    "example": 1520
+                },
+                "reconnects": {
+                    "description": "Reconnects — сколько раз соединение восстанавливалось после потери.",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "pubsub.SubscriberStats": {
+            "type": "object",
+            "properties": {
+                "cabinet": {
+                    "type": "integer",
+                    "example": 101
+                },
+                "connected_at": {
+                    "type": "string"
+                },
+                "consecutive_drops": {
+                    "description": "ConsecutiveDrops — сколько событий подряд пропущено с последней успешной доставки.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "delivered": {
+                    "type": "integer",
+                    "example": 240
+                },
+                "display": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "dropped": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 17
+                },
+                "pending": {
+                    "description": "Pending — сколько событий ждет в буфере подписчика.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "resyncs": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pubsub.Topic"
+                    }
+                },
+                "window": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "pubsub.Topic": {
+            "type": "string",
+            "enum": [
+                "ticket",
+                "schedule",
+                "doctor_status",
+                "process",
+                "ads",
+                "display"
+            ],
+            "x-enum-varnames": [
+                "TopicTicket",
+                "TopicSchedule",
+                "TopicDoctorStatus",
+                "TopicProcess",
+                "TopicAds",
+                "TopicDisplay"
+            ]
+        },
         "services.AppointmentDetailsResponse": {
             "type": "object",
             "properties": {
@@ -3476,9 +9501,15 @@ const docTemplate = `{
         "services.TimeSlotModel": {
             "type": "object",
             "properties": {
+                "booked_count": {
+                    "type": "integer"
+                },
                 "cabinet": {
                     "type": "integer"
                 },
+                "capacity": {
+                    "type": "integer"
+                },
                 "end_time": {
                     "type": "string"
                 },
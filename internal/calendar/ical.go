@@ -0,0 +1,123 @@
+// Package calendar формирует календари в формате iCalendar (RFC 5545).
+package calendar
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ProdID идентифицирует приложение, создавшее календарь.
+	ProdID = "-//ElectronicQueue//Schedule//RU"
+	// uidDomain добавляется к UID событий, чтобы они были глобально уникальными.
+	uidDomain = "electronic-queue"
+
+	MethodPublish = "PUBLISH"
+	MethodCancel  = "CANCEL"
+
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event описывает одно событие календаря.
+// UID и Sequence должны быть стабильными: по ним календарь понимает, что событие обновлено или отменено.
+type Event struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	Status       string
+	LastModified time.Time
+}
+
+// ScheduleUID возвращает стабильный UID события для слота расписания.
+func ScheduleUID(scheduleID uint) string {
+	return "schedule-" + strconv.FormatUint(uint64(scheduleID), 10) + "@" + uidDomain
+}
+
+// AppointmentUID возвращает стабильный UID события для записи на прием.
+func AppointmentUID(appointmentID uint) string {
+	return "appointment-" + strconv.FormatUint(uint64(appointmentID), 10) + "@" + uidDomain
+}
+
+// TombstoneUID добавляет домен к UID из таблицы отмененных событий ("schedule-1" -> "schedule-1@...").
+func TombstoneUID(uid string) string {
+	return uid + "@" + uidDomain
+}
+
+// Render собирает календарь VCALENDAR с указанными событиями.
+func Render(name, method string, events []Event) []byte {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+ProdID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:"+method)
+	if name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escapeText(name))
+	}
+
+	stamp := formatTime(time.Now())
+	for _, e := range events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+e.UID)
+		writeLine(&b, "SEQUENCE:"+strconv.Itoa(e.Sequence))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+formatTime(e.Start))
+		writeLine(&b, "DTEND:"+formatTime(e.End))
+		writeLine(&b, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(&b, "LOCATION:"+escapeText(e.Location))
+		}
+		if e.Status != "" {
+			writeLine(&b, "STATUS:"+e.Status)
+		}
+		if !e.LastModified.IsZero() {
+			writeLine(&b, "LAST-MODIFIED:"+formatTime(e.LastModified))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText экранирует спецсимволы в текстовых значениях свойств.
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeLine записывает строку с переносом длинных строк по 75 байт, не разрывая символы UTF-8.
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Строка продолжения начинается с пробела, который тоже входит в лимит.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,157 @@
+package cluster
+
+import (
+	"ElectronicQueue/internal/logger"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JobsLockKey — ключ advisory-блокировки, которую держит экземпляр, выполняющий фоновые задания.
+const JobsLockKey int64 = 7_130_004_801
+
+// LockSource захватывает advisory-блокировки PostgreSQL.
+type LockSource interface {
+	// TryLock пытается захватить блокировку без ожидания. ok=false — блокировку держит другой экземпляр.
+	TryLock(ctx context.Context, key int64) (lock Lock, ok bool, err error)
+}
+
+// Lock — захваченная блокировка. Она действует, пока живо соединение, через которое получена.
+type Lock interface {
+	// Watch ждет, пока соединение не сообщит об ошибке, и возвращает ее: блокировка потеряна.
+	// При отмене ctx возвращает nil.
+	Watch(ctx context.Context) error
+	// Alive проверяет соединение запросом; ошибка означает, что блокировка потеряна.
+	Alive(ctx context.Context) error
+	Unlock()
+}
+
+// Status — роль экземпляра сервера в кластере.
+type Status struct {
+	InstanceID  string     `json:"instance_id" example:"9f2c4e1a7b3d5f60"`
+	Leader      bool       `json:"leader" example:"true"`
+	LeaderSince *time.Time `json:"leader_since,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Elector выбирает среди экземпляров сервера лидера, который выполняет фоновые задания (очистку,
+// напоминания, снятие удержаний), чтобы они не запускались на каждом экземпляре.
+type Elector struct {
+	instanceID string
+	source     LockSource
+	key        int64
+	interval   time.Duration
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewElector создает Elector. interval задается строкой вида "10s": с этим периодом резервный экземпляр
+// пытается захватить блокировку, а лидер проверяет, что не потерял ее.
+func NewElector(instanceID string, source LockSource, key int64, interval string) (*Elector, error) {
+	period, err := time.ParseDuration(interval)
+	if err != nil || period <= 0 {
+		return nil, fmt.Errorf("некорректный период выбора лидера %q", interval)
+	}
+	return &Elector{
+		instanceID: instanceID,
+		source:     source,
+		key:        key,
+		interval:   period,
+		status:     Status{InstanceID: instanceID},
+	}, nil
+}
+
+// Status возвращает текущую роль экземпляра.
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.status
+}
+
+// Run до отмены ctx пытается стать лидером. Став лидером, вызывает lead с контекстом, который отменяется
+// при потере блокировки; сама блокировка освобождается только после возврата из lead. При разрыве соединения
+// PostgreSQL снимает блокировку сразу, и другой экземпляр может стать лидером, пока задания этого еще
+// останавливаются, поэтому задания должны быть безопасны при одновременном запуске на двух экземплярах.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	log := logger.Default().WithField("module", "LEADER").WithField("instance_id", e.instanceID)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		lock, ok, err := e.source.TryLock(ctx, e.key)
+		switch {
+		case err != nil:
+			e.setError(err)
+			log.WithError(err).Warn("Leader: Failed to acquire lock")
+		case ok:
+			log.Info("Leader: This instance is now running scheduled jobs")
+			err := e.hold(ctx, lock, lead)
+			if ctx.Err() != nil {
+				return
+			}
+			e.setError(err)
+			log.WithError(err).Error("Leader: Lock lost, scheduled jobs stopped")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// hold выполняет lead, пока блокировка действует. Возвращает ошибку, из-за которой блокировка потеряна.
+// Между проверками соединение блокировки ожидает ошибку, поэтому разрыв, о котором сообщил сервер, останавливает
+// задания сразу; проверка Alive раз в interval обнаруживает соединения, оборвавшиеся без уведомления.
+func (e *Elector) hold(ctx context.Context, lock Lock, lead func(ctx context.Context)) error {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leaderCtx)
+	}()
+	e.setLeader(true)
+
+	var lost error
+	for lost == nil && ctx.Err() == nil {
+		waitCtx, stopWaiting := context.WithTimeout(ctx, e.interval)
+		lost = lock.Watch(waitCtx)
+		stopWaiting()
+		if lost == nil && ctx.Err() == nil {
+			aliveCtx, stopAlive := context.WithTimeout(ctx, e.interval)
+			lost = lock.Alive(aliveCtx)
+			stopAlive()
+		}
+	}
+
+	cancel()
+	<-done
+	e.setLeader(false)
+	lock.Unlock()
+	return lost
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.status.Leader = leader
+	e.status.LeaderSince = nil
+	if leader {
+		now := time.Now()
+		e.status.LeaderSince = &now
+		e.status.LastError = ""
+	}
+}
+
+func (e *Elector) setError(err error) {
+	if err == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status.LastError = err.Error()
+}
@@ -0,0 +1,271 @@
+package cluster
+
+import (
+	"ElectronicQueue/internal/logger"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init("")
+	code := m.Run()
+	logger.Sync()
+	os.Exit(code)
+}
+
+const testTimeout = 2 * time.Second
+
+// fakeLockSource выдает одну блокировку на всех: пока ее держит один экземпляр, остальные получают ok=false.
+type fakeLockSource struct {
+	mu      sync.Mutex
+	holder  *fakeLock
+	err     error
+	granted int
+}
+
+func (s *fakeLockSource) TryLock(ctx context.Context, key int64) (Lock, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	if s.holder != nil {
+		return nil, false, nil
+	}
+	s.granted++
+	s.holder = &fakeLock{source: s, broken: make(chan error, 1)}
+	return s.holder, true, nil
+}
+
+func (s *fakeLockSource) current() *fakeLock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.holder
+}
+
+func (s *fakeLockSource) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// fakeLockClient — подключение одного экземпляра к fakeLockSource; тест может отрезать его от базы.
+type fakeLockClient struct {
+	source *fakeLockSource
+	down   atomic.Bool
+}
+
+func (c *fakeLockClient) TryLock(ctx context.Context, key int64) (Lock, bool, error) {
+	if c.down.Load() {
+		return nil, false, errors.New("connection refused")
+	}
+	return c.source.TryLock(ctx, key)
+}
+
+// fakeLock — блокировка, которую тест может «потерять»: lost — соединение оборвалось без уведомления
+// и это видно только при проверке Alive, broken — сервер сообщил об ошибке соединения.
+type fakeLock struct {
+	source   *fakeLockSource
+	lost     atomic.Value
+	broken   chan error
+	unlocked atomic.Bool
+}
+
+func (l *fakeLock) Watch(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-l.broken:
+		return err
+	}
+}
+
+func (l *fakeLock) Alive(ctx context.Context) error {
+	if err, ok := l.lost.Load().(error); ok {
+		return err
+	}
+	return nil
+}
+
+func (l *fakeLock) Unlock() {
+	if l.unlocked.Swap(true) {
+		return
+	}
+	l.source.mu.Lock()
+	defer l.source.mu.Unlock()
+	if l.source.holder == l {
+		l.source.holder = nil
+	}
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+// leaderJobs считает экземпляры, одновременно выполняющие задания, и проверяет, что блокировка
+// не освобождается раньше, чем задания лидера остановились.
+type leaderJobs struct {
+	source  *fakeLockSource
+	running atomic.Int32
+	overlap atomic.Bool
+	early   atomic.Bool
+}
+
+func (j *leaderJobs) lead(ctx context.Context) {
+	lock := j.source.current()
+	if j.running.Add(1) > 1 {
+		j.overlap.Store(true)
+	}
+	<-ctx.Done()
+	if lock != nil && lock.unlocked.Load() {
+		j.early.Store(true)
+	}
+	j.running.Add(-1)
+}
+
+func TestElectorSingleLeaderAndFailover(t *testing.T) {
+	source := &fakeLockSource{}
+	jobs := &leaderJobs{source: source}
+
+	clients := map[string]*fakeLockClient{"first": {source: source}, "second": {source: source}}
+	first, err := NewElector("first", clients["first"], JobsLockKey, "5ms")
+	if err != nil {
+		t.Fatalf("NewElector: %v", err)
+	}
+	second, _ := NewElector("second", clients["second"], JobsLockKey, "5ms")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, e := range []*Elector{first, second} {
+		wg.Add(1)
+		go func(e *Elector) {
+			defer wg.Done()
+			e.Run(ctx, jobs.lead)
+		}(e)
+	}
+
+	waitUntil(t, "a leader", func() bool { return first.Status().Leader || second.Status().Leader })
+	leader, standby := first, second
+	if second.Status().Leader {
+		leader, standby = second, first
+	}
+	time.Sleep(20 * time.Millisecond)
+	if standby.Status().Leader || jobs.running.Load() != 1 {
+		t.Fatalf("expected exactly one leader, standby leader=%v running=%d", standby.Status().Leader, jobs.running.Load())
+	}
+
+	// Лидер теряет связь с базой: задания останавливаются, и лидером становится резервный экземпляр.
+	clients[leader.Status().InstanceID].down.Store(true)
+	source.current().lost.Store(errors.New("connection reset"))
+	waitUntil(t, "failover", func() bool { return standby.Status().Leader })
+	status := leader.Status()
+	if status.Leader || status.LeaderSince != nil || status.LastError == "" {
+		t.Errorf("old leader status = %+v, want follower with the lock error", status)
+	}
+	if standby.Status().LeaderSince == nil {
+		t.Error("new leader must report LeaderSince")
+	}
+
+	cancel()
+	wg.Wait()
+	if jobs.overlap.Load() {
+		t.Error("jobs of two instances overlapped")
+	}
+	if jobs.early.Load() {
+		t.Error("lock was released before the jobs stopped")
+	}
+	if source.current() != nil {
+		t.Error("lock must be released after Run returns")
+	}
+	if first.Status().Leader || second.Status().Leader {
+		t.Error("no instance must be leader after shutdown")
+	}
+}
+
+func TestElectorStopsJobsAsSoonAsConnectionFails(t *testing.T) {
+	source := &fakeLockSource{}
+	// Период проверки больше таймаута теста: задания должны остановиться по ошибке соединения, а не по Alive.
+	e, _ := NewElector("solo", source, JobsLockKey, "1h")
+
+	stopped, done := make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		e.Run(ctx, func(ctx context.Context) {
+			<-ctx.Done()
+			close(stopped)
+		})
+	}()
+
+	waitUntil(t, "leadership", func() bool { return e.Status().Leader })
+	lock := source.current()
+	lock.broken <- errors.New("terminating connection due to administrator command")
+
+	select {
+	case <-stopped:
+	case <-time.After(testTimeout):
+		t.Fatal("jobs were not stopped after the lock connection failed")
+	}
+	waitUntil(t, "lock release", func() bool { return lock.unlocked.Load() })
+	waitUntil(t, "status update", func() bool { return !e.Status().Leader })
+	if got := e.Status().LastError; got != "terminating connection due to administrator command" {
+		t.Errorf("LastError = %q, want the connection error", got)
+	}
+}
+
+func TestElectorReportsLockErrors(t *testing.T) {
+	source := &fakeLockSource{}
+	source.setErr(errors.New("database is down"))
+	jobs := &leaderJobs{source: source}
+	e, _ := NewElector("solo", source, JobsLockKey, "5ms")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.Run(ctx, jobs.lead)
+	}()
+
+	waitUntil(t, "lock error", func() bool { return e.Status().LastError == "database is down" })
+	if e.Status().Leader {
+		t.Fatal("instance must not lead without the lock")
+	}
+
+	// Когда база снова доступна, экземпляр становится лидером, и ошибка сбрасывается.
+	source.setErr(nil)
+	waitUntil(t, "leadership", func() bool { return e.Status().Leader })
+	if e.Status().LastError != "" {
+		t.Errorf("LastError = %q, want empty after becoming leader", e.Status().LastError)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(testTimeout):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestNewElectorRejectsInvalidInterval(t *testing.T) {
+	for _, interval := range []string{"", "0s", "-1s", "soon"} {
+		if _, err := NewElector("id", &fakeLockSource{}, JobsLockKey, interval); err == nil {
+			t.Errorf("NewElector(%q): expected error", interval)
+		}
+	}
+}
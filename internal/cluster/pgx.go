@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgxBackend отправляет NOTIFY и захватывает advisory-блокировки через пул pgx.
+type PgxBackend struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgxBackend создает PgxBackend на основе пула pgx.
+func NewPgxBackend(pool *pgxpool.Pool) *PgxBackend {
+	return &PgxBackend{pool: pool}
+}
+
+// Notify отправляет уведомление в канал channel.
+func (b *PgxBackend) Notify(ctx context.Context, channel, payload string) error {
+	_, err := b.pool.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
+
+// TryLock пытается захватить сессионную advisory-блокировку. Соединение удерживается до Unlock:
+// блокировка снимается, как только оно закрывается.
+func (b *PgxBackend) TryLock(ctx context.Context, key int64) (Lock, bool, error) {
+	conn, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+	return &pgxLock{conn: conn, key: key}, true, nil
+}
+
+type pgxLock struct {
+	conn *pgxpool.Conn
+	key  int64
+}
+
+// Watch ждет данных от сервера на соединении блокировки. Уведомлений на нем нет, поэтому чтение
+// завершается только ошибкой соединения (сервер закрыл сессию, сокет оборван) или отменой ctx; при отмене
+// соединение остается исправным.
+func (l *pgxLock) Watch(ctx context.Context) error {
+	for {
+		_, err := l.conn.Conn().WaitForNotification(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (l *pgxLock) Alive(ctx context.Context) error {
+	return l.conn.Ping(ctx)
+}
+
+// Unlock снимает блокировку и возвращает соединение в пул. Если снять блокировку не удалось,
+// соединение закрывается, и PostgreSQL снимает ее сам.
+func (l *pgxLock) Unlock() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", l.key); err != nil {
+		l.conn.Conn().Close(ctx)
+	}
+	l.conn.Release()
+}
@@ -0,0 +1,174 @@
+package cluster
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/pubsub"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Каналы NOTIFY, через которые экземпляры сервера обмениваются событиями и сбросами кэшей.
+const (
+	ChannelEvent      = "cluster_event"
+	ChannelInvalidate = "cluster_invalidate"
+)
+
+// notifyTimeout ограничивает время отправки NOTIFY, чтобы недоступная база не задерживала запрос пользователя.
+const notifyTimeout = 5 * time.Second
+
+// Notifier отправляет уведомление в канал NOTIFY.
+type Notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
+}
+
+// NewInstanceID возвращает случайный идентификатор экземпляра сервера.
+func NewInstanceID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Relay передает события сервисов и сбросы кэшей другим экземплярам сервера через PostgreSQL NOTIFY.
+// События, которые формирует триггер базы данных, и так приходят всем экземплярам; через Relay
+// публикуются события, известные только обработавшему запрос экземпляру: смена статуса врача,
+// включение бизнес-процесса, изменение рекламы.
+type Relay struct {
+	instanceID string
+	broker     *pubsub.Broker
+	notifier   Notifier
+
+	mu           sync.RWMutex
+	invalidators map[string][]func()
+}
+
+// NewRelay создает Relay и подписывает listener на каналы обмена между экземплярами.
+func NewRelay(instanceID string, broker *pubsub.Broker, notifier Notifier, listener *pubsub.Listener) *Relay {
+	r := &Relay{
+		instanceID:   instanceID,
+		broker:       broker,
+		notifier:     notifier,
+		invalidators: make(map[string][]func()),
+	}
+	listener.Handle(ChannelEvent, r.receiveEvent)
+	listener.Handle(ChannelInvalidate, r.receiveInvalidation)
+	listener.OnRecover(r.reloadAll)
+	return r
+}
+
+// envelope — уведомление, которым экземпляры обмениваются между собой.
+type envelope struct {
+	Origin  string          `json:"origin"`
+	Topic   pubsub.Topic    `json:"topic,omitempty"`
+	Name    string          `json:"name,omitempty"`
+	Cabinet *int            `json:"cabinet,omitempty"`
+	Window  *int            `json:"window,omitempty"`
+	Display *uint           `json:"display,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Cache   string          `json:"cache,omitempty"`
+}
+
+// Publish публикует событие подписчикам этого экземпляра и отправляет его остальным.
+func (r *Relay) Publish(event pubsub.Event) {
+	r.broker.Publish(event)
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		logger.Default().WithError(err).WithField("event", event.Name).Error("Cluster: Failed to encode event")
+		return
+	}
+	r.notify(ChannelEvent, envelope{
+		Origin:  r.instanceID,
+		Topic:   event.Topic,
+		Name:    event.Name,
+		Cabinet: event.Cabinet,
+		Window:  event.Window,
+		Display: event.Display,
+		Data:    data,
+	})
+}
+
+// Invalidate сообщает остальным экземплярам, что кэш cache устарел.
+func (r *Relay) Invalidate(cache string) {
+	r.notify(ChannelInvalidate, envelope{Origin: r.instanceID, Cache: cache})
+}
+
+// OnInvalidate добавляет функцию перезагрузки кэша cache. Она вызывается, когда кэш изменил другой
+// экземпляр, а также после восстановления соединения с базой данных, если сбросы могли быть пропущены.
+func (r *Relay) OnInvalidate(cache string, reload func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidators[cache] = append(r.invalidators[cache], reload)
+}
+
+func (r *Relay) notify(channel string, message envelope) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		logger.Default().WithError(err).WithField("channel", channel).Error("Cluster: Failed to encode notification")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := r.notifier.Notify(ctx, channel, string(payload)); err != nil {
+		logger.Default().WithError(err).WithField("channel", channel).Warn("Cluster: Failed to notify other instances")
+	}
+}
+
+func (r *Relay) receiveEvent(n pubsub.Notification) {
+	var message envelope
+	if err := json.Unmarshal([]byte(n.Payload), &message); err != nil {
+		logger.Default().WithError(err).Warn("Cluster: Failed to decode event, skipping.")
+		return
+	}
+	if message.Origin == r.instanceID {
+		return
+	}
+	r.broker.Publish(pubsub.Event{
+		Topic:   message.Topic,
+		Name:    message.Name,
+		Cabinet: message.Cabinet,
+		Window:  message.Window,
+		Display: message.Display,
+		Data:    message.Data,
+	})
+}
+
+func (r *Relay) receiveInvalidation(n pubsub.Notification) {
+	var message envelope
+	if err := json.Unmarshal([]byte(n.Payload), &message); err != nil {
+		logger.Default().WithError(err).Warn("Cluster: Failed to decode cache invalidation, skipping.")
+		return
+	}
+	if message.Origin == r.instanceID {
+		return
+	}
+	logger.Default().WithField("cache", message.Cache).WithField("origin", message.Origin).Info("Cluster: Cache invalidated by another instance")
+	r.reload(message.Cache)
+}
+
+func (r *Relay) reload(cache string) {
+	r.mu.RLock()
+	reloaders := r.invalidators[cache]
+	r.mu.RUnlock()
+	for _, reload := range reloaders {
+		reload()
+	}
+}
+
+func (r *Relay) reloadAll() {
+	r.mu.RLock()
+	caches := make([]string, 0, len(r.invalidators))
+	for cache := range r.invalidators {
+		caches = append(caches, cache)
+	}
+	r.mu.RUnlock()
+	for _, cache := range caches {
+		r.reload(cache)
+	}
+}
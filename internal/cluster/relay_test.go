@@ -0,0 +1,241 @@
+package cluster
+
+import (
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/pubsub"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBus — общий канал NOTIFY для экземпляров: Notify рассылает уведомление всем соединениям,
+// подписанным на канал, как это делает PostgreSQL.
+type fakeBus struct {
+	mu    sync.Mutex
+	conns map[*busConn]bool
+	err   error
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{conns: make(map[*busConn]bool)}
+}
+
+func (b *fakeBus) Notify(ctx context.Context, channel, payload string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	for conn := range b.conns {
+		if conn.listens(channel) {
+			conn.notifications <- pubsub.Notification{Channel: channel, Payload: payload}
+		}
+	}
+	return nil
+}
+
+func (b *fakeBus) setErr(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
+
+// source возвращает источник уведомлений одного экземпляра.
+func (b *fakeBus) source() *busSource {
+	return &busSource{bus: b}
+}
+
+type busSource struct {
+	bus  *fakeBus
+	mu   sync.Mutex
+	conn *busConn
+}
+
+func (s *busSource) Connect(ctx context.Context) (pubsub.NotificationConn, error) {
+	conn := &busConn{bus: s.bus, notifications: make(chan pubsub.Notification, 16), failures: make(chan error, 1)}
+	s.bus.mu.Lock()
+	s.bus.conns[conn] = true
+	s.bus.mu.Unlock()
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+	return conn, nil
+}
+
+// drop разрывает текущее соединение экземпляра.
+func (s *busSource) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conn.failures <- errors.New("connection reset")
+}
+
+type busConn struct {
+	bus           *fakeBus
+	notifications chan pubsub.Notification
+	failures      chan error
+
+	mu       sync.Mutex
+	channels []string
+}
+
+func (c *busConn) listens(channel string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, listened := range c.channels {
+		if listened == channel {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *busConn) Listen(ctx context.Context, channel string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.channels = append(c.channels, channel)
+	return nil
+}
+
+func (c *busConn) WaitForNotification(ctx context.Context) (pubsub.Notification, error) {
+	select {
+	case <-ctx.Done():
+		return pubsub.Notification{}, ctx.Err()
+	case err := <-c.failures:
+		return pubsub.Notification{}, err
+	case n := <-c.notifications:
+		return n, nil
+	}
+}
+
+func (c *busConn) Close() {
+	c.bus.mu.Lock()
+	defer c.bus.mu.Unlock()
+	delete(c.bus.conns, c)
+}
+
+// relayInstance — экземпляр сервера: брокер, слушатель уведомлений и Relay.
+type relayInstance struct {
+	broker   *pubsub.Broker
+	listener *pubsub.Listener
+	source   *busSource
+	relay    *Relay
+	reloads  atomic.Int32
+}
+
+func startRelayInstance(t *testing.T, ctx context.Context, bus *fakeBus, id string) *relayInstance {
+	t.Helper()
+	inst := &relayInstance{broker: pubsub.NewBroker(16, 0, 16), source: bus.source()}
+	listener, err := pubsub.NewListener(inst.source, inst.broker, nil, "5ms", "5ms")
+	if err != nil {
+		t.Fatalf("NewListener: %v", err)
+	}
+	inst.listener = listener
+	inst.relay = NewRelay(id, inst.broker, bus, listener)
+	inst.relay.OnInvalidate("ads", func() { inst.reloads.Add(1) })
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		listener.Run(ctx)
+	}()
+	// Слушатель должен остановиться до конца теста: тест отменяет ctx, после чего Cleanup ждет выхода.
+	t.Cleanup(func() { <-done })
+	waitUntil(t, id+" listening", func() bool { return listener.Health().Connected })
+	return inst
+}
+
+func nextEvent(t *testing.T, sub *pubsub.Subscriber) pubsub.Event {
+	t.Helper()
+	select {
+	case event := <-sub.Events:
+		return event
+	case <-time.After(testTimeout):
+		t.Fatal("no event received")
+		return pubsub.Event{}
+	}
+}
+
+func expectNoEvent(t *testing.T, sub *pubsub.Subscriber) {
+	t.Helper()
+	select {
+	case event := <-sub.Events:
+		t.Fatalf("unexpected event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRelayPublishReachesOtherInstancesOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := newFakeBus()
+	a := startRelayInstance(t, ctx, bus, "a")
+	b := startRelayInstance(t, ctx, bus, "b")
+
+	filter := pubsub.Filter{Topics: []pubsub.Topic{pubsub.TopicDoctorStatus}}
+	subA, subB := a.broker.Subscribe(filter), b.broker.Subscribe(filter)
+
+	cabinet := 101
+	a.relay.Publish(pubsub.DoctorStatusChanged(7, models.DoctorStatusActive, &cabinet))
+
+	local := nextEvent(t, subA)
+	remote := nextEvent(t, subB)
+	if remote.Name != local.Name || remote.Cabinet == nil || *remote.Cabinet != cabinet {
+		t.Fatalf("remote event = %+v, want copy of %+v", remote, local)
+	}
+	var data pubsub.DoctorStatusEvent
+	raw, _ := json.Marshal(remote.Data)
+	if err := json.Unmarshal(raw, &data); err != nil || data.DoctorID != 7 {
+		t.Errorf("remote event data = %s, want doctor 7", raw)
+	}
+	// Экземпляр-источник пропускает собственное уведомление, и событие не дублируется.
+	expectNoEvent(t, subA)
+	expectNoEvent(t, subB)
+}
+
+func TestRelayInvalidate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := newFakeBus()
+	a := startRelayInstance(t, ctx, bus, "a")
+	b := startRelayInstance(t, ctx, bus, "b")
+
+	a.relay.Invalidate("ads")
+	a.relay.Invalidate("unknown")
+	waitUntil(t, "reload on b", func() bool { return b.reloads.Load() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	if a.reloads.Load() != 0 || b.reloads.Load() != 1 {
+		t.Errorf("reloads a=%d b=%d, want 0 and 1", a.reloads.Load(), b.reloads.Load())
+	}
+}
+
+func TestRelayRecoversAfterConnectionLoss(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := newFakeBus()
+	a := startRelayInstance(t, ctx, bus, "a")
+	b := startRelayInstance(t, ctx, bus, "b")
+	sub := b.broker.Subscribe(pubsub.Filter{Topics: []pubsub.Topic{pubsub.TopicProcess}})
+
+	// Пока b отключен, сброс кэша до него не доходит; после переподключения b перезагружает все кэши
+	// и просит клиентов заново получить состояние.
+	b.source.drop()
+	waitUntil(t, "b reconnected", func() bool { return b.listener.Health().Reconnects == 1 })
+	waitUntil(t, "reload after reconnect", func() bool { return b.reloads.Load() == 1 })
+	select {
+	case <-sub.Resync:
+	case <-time.After(testTimeout):
+		t.Fatal("subscriber did not get a resync signal after reconnect")
+	}
+
+	// Ошибка NOTIFY не мешает доставке подписчикам своего экземпляра.
+	bus.setErr(errors.New("database is down"))
+	local := a.broker.Subscribe(pubsub.Filter{Topics: []pubsub.Topic{pubsub.TopicProcess}})
+	a.relay.Publish(pubsub.ProcessChanged("appointments", true))
+	if event := nextEvent(t, local); event.Topic != pubsub.TopicProcess {
+		t.Errorf("local event = %+v, want process event", event)
+	}
+	expectNoEvent(t, sub)
+}
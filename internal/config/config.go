@@ -18,6 +18,7 @@ type Config struct {
 	DBSSLMode                   string
 	BackendPort                 string
 	FrontendPort                string
+	TrustedProxies              string
 	JWTSecret                   string
 	JWTExpiration               string
 	TicketMode                  string
@@ -29,6 +30,43 @@ type Config struct {
 	PrinterName                 string
 	MaintenanceTime             string
 	AudioBackgroundMusicEnabled bool
+	WaitlistHoldDuration        string
+	NotificationChannel         string
+	NotificationFile            string
+	NotificationReminderLead    string
+	NotificationPollInterval    string
+	NotificationMaxAttempts     string
+	SMSGatewayURL               string
+	SMSGatewayToken             string
+	SMSSender                   string
+	SMTPHost                    string
+	SMTPPort                    string
+	SMTPUsername                string
+	SMTPPassword                string
+	SMTPFrom                    string
+	ExternalRateLimit           string
+	BookingMaxActive            string
+	BookingHorizonDays          string
+	BookingCancelCutoff         string
+	CheckInEarlyWindow          string
+	CheckInLateWindow           string
+	CheckInCodeSecret           string
+	PIIEncryptionKeys           string
+	PIIActiveKey                string
+	PIIIndexKey                 string
+	PublicNameFormat            string
+	PatientRetentionYears       string
+	PatientAccessAnomalyLimit   string
+	PubSubBufferSize            string
+	PubSubMaxDrops              string
+	PubSubReplaySize            string
+	SSEHeartbeatInterval        string
+	SSEWriteTimeout             string
+	SSEMaxClientsPerEndpoint    string
+	SSEMaxClientsPerIP          string
+	ListenerReconnectMin        string
+	ListenerReconnectMax        string
+	LeaderElectionInterval      string
 }
 
 // LoadConfig загружает переменные среды из .env и возвращает структуру Config
@@ -48,6 +86,7 @@ func LoadConfig() (*Config, error) {
 		DBSSLMode:                   getEnv("DB_SSLMODE", "disable"),
 		BackendPort:                 getEnv("BACKEND_PORT", "8080"),
 		FrontendPort:                getEnv("FRONTEND_PORT", "3000"),
+		TrustedProxies:              getEnv("TRUSTED_PROXIES"),
 		JWTSecret:                   getEnv("JWT_SECRET"),
 		JWTExpiration:               getEnv("JWT_EXPIRATION", "24h"),
 		TicketMode:                  getEnv("TICKET_MODE", "b/w"),
@@ -59,6 +98,43 @@ func LoadConfig() (*Config, error) {
 		PrinterName:                 getEnv("PRINTER"),
 		MaintenanceTime:             getEnv("MAINTENANCE_TIME", "00:00"),
 		AudioBackgroundMusicEnabled: getEnv("BACKGROUND_MUSIC", "true") == "true",
+		WaitlistHoldDuration:        getEnv("WAITLIST_HOLD_DURATION", "30m"),
+		NotificationChannel:         getEnv("NOTIFICATION_CHANNEL", "log"),
+		NotificationFile:            getEnv("NOTIFICATION_FILE", "logs/notifications.log"),
+		NotificationReminderLead:    getEnv("NOTIFICATION_REMINDER_LEAD", "24h"),
+		NotificationPollInterval:    getEnv("NOTIFICATION_POLL_INTERVAL", "15s"),
+		NotificationMaxAttempts:     getEnv("NOTIFICATION_MAX_ATTEMPTS", "5"),
+		SMSGatewayURL:               getEnv("SMS_GATEWAY_URL"),
+		SMSGatewayToken:             getEnv("SMS_GATEWAY_TOKEN"),
+		SMSSender:                   getEnv("SMS_SENDER"),
+		SMTPHost:                    getEnv("SMTP_HOST"),
+		SMTPPort:                    getEnv("SMTP_PORT", "587"),
+		SMTPUsername:                getEnv("SMTP_USERNAME"),
+		SMTPPassword:                getEnv("SMTP_PASSWORD"),
+		SMTPFrom:                    getEnv("SMTP_FROM"),
+		ExternalRateLimit:           getEnv("EXTERNAL_RATE_LIMIT", "60"),
+		BookingMaxActive:            getEnv("BOOKING_MAX_ACTIVE", "3"),
+		BookingHorizonDays:          getEnv("BOOKING_HORIZON_DAYS", "30"),
+		BookingCancelCutoff:         getEnv("BOOKING_CANCEL_CUTOFF", "2h"),
+		CheckInEarlyWindow:          getEnv("CHECKIN_EARLY_WINDOW", "1h"),
+		CheckInLateWindow:           getEnv("CHECKIN_LATE_WINDOW", "15m"),
+		CheckInCodeSecret:           getEnv("CHECKIN_CODE_SECRET"),
+		PIIEncryptionKeys:           getEnv("PII_ENCRYPTION_KEYS"),
+		PIIActiveKey:                getEnv("PII_ACTIVE_KEY"),
+		PIIIndexKey:                 getEnv("PII_INDEX_KEY"),
+		PublicNameFormat:            getEnv("PUBLIC_NAME_FORMAT", "surname_initial"),
+		PatientRetentionYears:       getEnv("PATIENT_RETENTION_YEARS", "0"),
+		PatientAccessAnomalyLimit:   getEnv("PATIENT_ACCESS_ANOMALY_THRESHOLD", "50"),
+		PubSubBufferSize:            getEnv("PUBSUB_BUFFER_SIZE", "32"),
+		PubSubMaxDrops:              getEnv("PUBSUB_MAX_DROPS", "100"),
+		PubSubReplaySize:            getEnv("PUBSUB_REPLAY_SIZE", "256"),
+		SSEHeartbeatInterval:        getEnv("SSE_HEARTBEAT_INTERVAL", "15s"),
+		SSEWriteTimeout:             getEnv("SSE_WRITE_TIMEOUT", "10s"),
+		SSEMaxClientsPerEndpoint:    getEnv("SSE_MAX_CLIENTS_PER_ENDPOINT", "500"),
+		SSEMaxClientsPerIP:          getEnv("SSE_MAX_CLIENTS_PER_IP", "0"),
+		ListenerReconnectMin:        getEnv("LISTENER_RECONNECT_MIN", "1s"),
+		ListenerReconnectMax:        getEnv("LISTENER_RECONNECT_MAX", "30s"),
+		LeaderElectionInterval:      getEnv("LEADER_ELECTION_INTERVAL", "10s"),
 	}
 
 	// Валидация обязательных полей
@@ -77,6 +153,9 @@ func LoadConfig() (*Config, error) {
 	if cfg.DBName == "" {
 		return nil, errors.New("DB_NAME is not set in the environment")
 	}
+	if cfg.PIIEncryptionKeys == "" || cfg.PIIActiveKey == "" || cfg.PIIIndexKey == "" {
+		return nil, errors.New("PII_ENCRYPTION_KEYS, PII_ACTIVE_KEY and PII_INDEX_KEY must be set in the environment")
+	}
 
 	return cfg, nil
 }
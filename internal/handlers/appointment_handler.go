@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/services"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,6 +19,25 @@ type AppointmentHandler struct {
 	service *services.AppointmentService
 }
 
+// BookingRulesErrorResponse описывает отказ в записи из-за нарушения правил.
+// Overridable = true, если старший регистратор может повторить запрос с override_rules и override_reason.
+type BookingRulesErrorResponse struct {
+	Error       string                        `json:"error"`
+	Violations  []models.BookingRuleViolation `json:"violations"`
+	Overridable bool                          `json:"overridable"`
+}
+
+// respondBookingRulesViolation отвечает 409 со списком нарушенных правил, если err — *services.BookingRulesError.
+// Используется там, где переопределение правил недоступно.
+func respondBookingRulesViolation(c *gin.Context, err error) bool {
+	var rulesErr *services.BookingRulesError
+	if !errors.As(err, &rulesErr) {
+		return false
+	}
+	c.JSON(http.StatusConflict, BookingRulesErrorResponse{Error: err.Error(), Violations: rulesErr.Violations})
+	return true
+}
+
 // NewAppointmentHandler создает новый экземпляр AppointmentHandler.
 func NewAppointmentHandler(service *services.AppointmentService) *AppointmentHandler {
 	return &AppointmentHandler{service: service}
@@ -24,7 +45,7 @@ func NewAppointmentHandler(service *services.AppointmentService) *AppointmentHan
 
 // GetDoctorSchedule godoc
 // @Summary      Получить расписание врача с информацией о записях
-// @Description  Возвращает все временные слоты врача на указанную дату, включая информацию о том, кто записан в занятые слоты.
+// @Description  Возвращает все временные слоты врача на указанную дату, включая информацию о том, кто записан в занятые слоты. Для групповых слотов (capacity > 1) все записи перечислены в appointments, число свободных мест — в free_places.
 // @Tags         registrar
 // @Produce      json
 // @Param        doctor_id path int true "ID Врача"
@@ -60,19 +81,32 @@ func (h *AppointmentHandler) GetDoctorSchedule(c *gin.Context) {
 		return
 	}
 
+	for _, slot := range schedule {
+		for _, appointment := range slot.Appointments {
+			if appointment.PatientID != nil {
+				middleware.MarkPatientAccess(c, *appointment.PatientID)
+			}
+		}
+		if slot.Appointment != nil && slot.Appointment.PatientID != nil {
+			middleware.MarkPatientAccess(c, *slot.Appointment.PatientID)
+		}
+	}
 	c.JSON(http.StatusOK, schedule)
 }
 
 // CreateAppointment godoc
 // @Summary      Создать новую запись на прием
-// @Description  Создает новую запись на прием для пациента, связывая ее со слотом в расписании и исходным талоном. Обновляет слот как занятый.
+// @Description  Создает новую запись на прием для пациента, связывая ее со слотом в расписании и исходным талоном, и занимает место в слоте. Перед созданием проверяются правила записи: пересечение с другими записями пациента, лимит активных записей к специальности, минимальный интервал между визитами и возрастные ограничения. При нарушении возвращается 409 со списком нарушений; старший регистратор может записать пациента, передав override_rules=true и override_reason. С referral_id запись создается по направлению: оно должно быть открытым, выданным этому пациенту к специальности врача слота, и после записи считается исполненным.
 // @Tags         registrar
 // @Accept       json
 // @Produce      json
 // @Param        request body models.CreateAppointmentRequest true "Данные для создания записи"
 // @Success      201 {object} models.Appointment "Успешно созданная запись"
-// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
-// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера (например, слот уже занят)"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса или не указана причина переопределения"
+// @Failure      403 {object} BookingRulesErrorResponse "Переопределять правила могут только старшие регистраторы"
+// @Failure      404 {object} map[string]string "Слот или пациент не найден"
+// @Failure      409 {object} BookingRulesErrorResponse "Нарушены правила записи, слот уже занят или направление недействительно"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Security     ApiKeyAuth
 // @Router       /api/registrar/appointments [post]
 func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
@@ -85,13 +119,35 @@ func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
 		return
 	}
 
-	appointment, err := h.service.CreateAppointment(&req)
+	registrarID, _ := c.Get("user_id")
+	registrarIDUint, _ := registrarID.(uint)
+
+	appointment, err := h.service.CreateAppointment(&req, registrarIDUint)
 	if err != nil {
-		log.WithError(err).Error("CreateAppointment: Failed to create appointment in service")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var rulesErr *services.BookingRulesError
+		switch {
+		case errors.As(err, &rulesErr):
+			status := http.StatusConflict
+			if rulesErr.OverrideDenied {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, BookingRulesErrorResponse{Error: err.Error(), Violations: rulesErr.Violations, Overridable: !rulesErr.OverrideDenied})
+		case strings.Contains(err.Error(), "укажите причину"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "уже занято") || strings.Contains(err.Error(), "уже записан") || strings.Contains(err.Error(), "направлени"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.WithError(err).Error("CreateAppointment: Failed to create appointment in service")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
+	if appointment.PatientID != nil {
+		middleware.MarkPatientAccess(c, *appointment.PatientID)
+	}
 	c.JSON(http.StatusCreated, appointment)
 }
 
@@ -120,9 +176,41 @@ func (h *AppointmentHandler) GetPatientAppointments(c *gin.Context) {
 		return
 	}
 
+	middleware.MarkPatientAccess(c, uint(patientID))
 	c.JSON(http.StatusOK, appointments)
 }
 
+// GetCheckInCode godoc
+// @Summary      Получить код записи для QR-кода
+// @Description  Возвращает подписанный код записи на прием. Код печатается в подтверждении записи в виде QR-кода; по нему пациент регистрируется в киоске без ввода данных.
+// @Tags         registrar
+// @Produce      json
+// @Param        id path int true "ID Записи"
+// @Success      200 {object} models.CheckInCodeResponse "Код записи"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Запись не найдена"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/appointments/{id}/checkin-code [get]
+func (h *AppointmentHandler) GetCheckInCode(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	code, err := h.service.GetCheckInCode(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "не найдена") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, code)
+}
+
 // DeleteAppointment godoc
 // @Summary      Удалить будущую запись
 // @Description  Удаляет запись на прием и освобождает связанный с ней слот в расписании.
@@ -155,6 +243,60 @@ func (h *AppointmentHandler) DeleteAppointment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Запись успешно удалена"})
 }
 
+// RescheduleAppointment godoc
+// @Summary      Перенести запись на другое время
+// @Description  Переносит неподтвержденную запись в другой свободный слот. Старый слот освобождается и предлагается листу ожидания, пациенту отправляется уведомление об изменении.
+// @Tags         registrar
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID Записи"
+// @Param        request body models.RescheduleAppointmentRequest true "ID нового слота"
+// @Success      200 {object} models.Appointment "Перенесенная запись"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Запись или слот не найдены"
+// @Failure      409 {object} BookingRulesErrorResponse "Нарушены правила записи, слот занят или запись уже подтверждена"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/appointments/{id}/reschedule [patch]
+func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context) {
+	log := logger.Default()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID записи"})
+		return
+	}
+
+	var req models.RescheduleAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).Warn("RescheduleAppointment: Failed to bind JSON")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	appointment, err := h.service.RescheduleAppointment(uint(id), req.ScheduleID)
+	if err != nil {
+		log.WithError(err).Error("RescheduleAppointment: Failed to reschedule appointment in service")
+		if respondBookingRulesViolation(c, err) {
+			return
+		}
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "уже занято") || strings.Contains(err.Error(), "уже записан") || strings.Contains(err.Error(), "уже подтверждена") || strings.Contains(err.Error(), "уже находится"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if appointment.PatientID != nil {
+		middleware.MarkPatientAccess(c, *appointment.PatientID)
+	}
+	c.JSON(http.StatusOK, appointment)
+}
+
 type ConfirmAppointmentRequest struct {
 	TicketID uint `json:"ticket_id" binding:"required"`
 }
@@ -191,5 +333,8 @@ func (h *AppointmentHandler) ConfirmAppointment(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось подтвердить запись: " + err.Error()})
 		return
 	}
+	if appointment.PatientID != nil {
+		middleware.MarkPatientAccess(c, *appointment.PatientID)
+	}
 	c.JSON(http.StatusOK, appointment)
 }
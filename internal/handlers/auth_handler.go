@@ -24,6 +24,8 @@ type CreateRegistrarRequest struct {
 	WindowNumber int    `json:"window_number" binding:"required"`
 	Login        string `json:"login" binding:"required"`
 	Password     string `json:"password" binding:"required"`
+	// IsSenior — старший регистратор может записывать пациентов в обход правил записи.
+	IsSenior bool `json:"is_senior,omitempty"`
 }
 
 type CreateDoctorRequest struct {
@@ -85,7 +87,7 @@ func (h *AuthHandler) CreateRegistrar(c *gin.Context) {
 		return
 	}
 
-	registrar, err := h.authService.CreateRegistrar(req.WindowNumber, req.Login, req.Password)
+	registrar, err := h.authService.CreateRegistrar(req.WindowNumber, req.Login, req.Password, req.IsSenior)
 	if err != nil {
 		// Проверяем, является ли ошибка конфликтом (логин занят)
 		if err.Error() == "логин '"+req.Login+"' уже занят" {
@@ -101,6 +103,7 @@ func (h *AuthHandler) CreateRegistrar(c *gin.Context) {
 		"registrar_id":  registrar.RegistrarID,
 		"login":         registrar.Login,
 		"window_number": registrar.WindowNumber,
+		"is_senior":     registrar.IsSenior,
 	})
 }
 
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookingHandler обрабатывает запросы внешнего API самостоятельной записи пациентов.
+type BookingHandler struct {
+	service *services.BookingService
+}
+
+// NewBookingHandler создает новый экземпляр BookingHandler.
+func NewBookingHandler(service *services.BookingService) *BookingHandler {
+	return &BookingHandler{service: service}
+}
+
+// GetSpecializations godoc
+// @Summary      Получить список специальностей
+// @Description  Возвращает специальности врачей, на которые возможна запись.
+// @Tags         external
+// @Produce      json
+// @Success      200 {array} string "Массив специальностей"
+// @Failure      401 {object} map[string]string "API ключ отсутствует"
+// @Failure      403 {object} map[string]string "Неверный API ключ"
+// @Failure      429 {object} map[string]string "Превышен лимит запросов"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/external/specializations [get]
+func (h *BookingHandler) GetSpecializations(c *gin.Context) {
+	specializations, err := h.service.GetSpecializations()
+	if err != nil {
+		logger.Default().WithError(err).Error("GetSpecializations: Failed to get specializations from service")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить список специальностей"})
+		return
+	}
+	if specializations == nil {
+		specializations = []string{}
+	}
+	c.JSON(http.StatusOK, specializations)
+}
+
+// GetDoctors godoc
+// @Summary      Получить список врачей
+// @Description  Возвращает врачей, при необходимости отфильтрованных по специальности.
+// @Tags         external
+// @Produce      json
+// @Param        specialization query string false "Специальность"
+// @Success      200 {array} models.ExternalDoctorResponse "Массив врачей"
+// @Failure      401 {object} map[string]string "API ключ отсутствует"
+// @Failure      403 {object} map[string]string "Неверный API ключ"
+// @Failure      429 {object} map[string]string "Превышен лимит запросов"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/external/doctors [get]
+func (h *BookingHandler) GetDoctors(c *gin.Context) {
+	doctors, err := h.service.GetDoctors(c.Query("specialization"))
+	if err != nil {
+		logger.Default().WithError(err).Error("GetExternalDoctors: Failed to get doctors from service")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить список врачей"})
+		return
+	}
+	c.JSON(http.StatusOK, doctors)
+}
+
+// GetFreeSlots godoc
+// @Summary      Получить свободные слоты для записи
+// @Description  Возвращает свободные будущие слоты в пределах горизонта записи. Можно отфильтровать по врачу, специальности и диапазону дат.
+// @Tags         external
+// @Produce      json
+// @Param        doctor_id query int false "ID врача"
+// @Param        specialization query string false "Специальность"
+// @Param        date_from query string false "Начало периода в формате YYYY-MM-DD"
+// @Param        date_to query string false "Конец периода в формате YYYY-MM-DD"
+// @Success      200 {array} models.ExternalSlotResponse "Массив свободных слотов"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат параметров"
+// @Failure      401 {object} map[string]string "API ключ отсутствует"
+// @Failure      403 {object} map[string]string "Неверный API ключ"
+// @Failure      429 {object} map[string]string "Превышен лимит запросов"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/external/slots [get]
+func (h *BookingHandler) GetFreeSlots(c *gin.Context) {
+	var doctorID *uint
+	if v := c.Query("doctor_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID врача"})
+			return
+		}
+		uid := uint(id)
+		doctorID = &uid
+	}
+
+	dateFrom, ok := parseOptionalDate(c, "date_from")
+	if !ok {
+		return
+	}
+	dateTo, ok := parseOptionalDate(c, "date_to")
+	if !ok {
+		return
+	}
+
+	slots, err := h.service.GetFreeSlots(doctorID, c.Query("specialization"), dateFrom, dateTo)
+	if err != nil {
+		logger.Default().WithError(err).Error("GetFreeSlots: Failed to get free slots from service")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить свободные слоты"})
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+// GetPatientAppointments godoc
+// @Summary      Получить активные записи пациента
+// @Description  Идентифицирует пациента по номеру полиса ОМС и дате рождения и возвращает его будущие записи.
+// @Tags         external
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ExternalPatientIdentity true "Данные для идентификации пациента"
+// @Success      200 {array} models.ExternalAppointmentResponse "Массив записей"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Пациент не найден"
+// @Failure      429 {object} map[string]string "Превышен лимит запросов"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/external/patients/appointments [post]
+func (h *BookingHandler) GetPatientAppointments(c *gin.Context) {
+	var req models.ExternalPatientIdentity
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	appointments, patientID, err := h.service.GetPatientAppointments(&req)
+	if err != nil {
+		logger.Default().WithError(err).Warn("GetExternalPatientAppointments: Failed to get appointments")
+		respondBookingError(c, err)
+		return
+	}
+	middleware.MarkPatientAccess(c, patientID)
+	c.JSON(http.StatusOK, appointments)
+}
+
+// Book godoc
+// @Summary      Записаться на прием
+// @Description  Идентифицирует пациента по номеру полиса ОМС и дате рождения и записывает его в свободный слот. Проверяются правила записи (пересечения, лимиты и интервалы по специальности, возраст), лимит активных записей и горизонт записи.
+// @Tags         external
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ExternalBookingRequest true "Данные пациента и ID слота"
+// @Success      201 {object} models.ExternalAppointmentResponse "Созданная запись"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса или слот вне горизонта записи"
+// @Failure      404 {object} map[string]string "Пациент или слот не найдены"
+// @Failure      409 {object} BookingRulesErrorResponse "Слот занят, превышен лимит активных записей или нарушены правила записи"
+// @Failure      429 {object} map[string]string "Превышен лимит запросов"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/external/appointments [post]
+func (h *BookingHandler) Book(c *gin.Context) {
+	var req models.ExternalBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	appointment, patientID, err := h.service.Book(&req)
+	if err != nil {
+		logger.Default().WithError(err).Warn("ExternalBook: Failed to book appointment")
+		respondBookingError(c, err)
+		return
+	}
+	middleware.MarkPatientAccess(c, patientID)
+	c.JSON(http.StatusCreated, appointment)
+}
+
+// Cancel godoc
+// @Summary      Отменить запись на прием
+// @Description  Идентифицирует пациента и отменяет его запись, если до приема осталось не меньше установленного времени отсечки. Освободившийся слот предлагается листу ожидания.
+// @Tags         external
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID записи"
+// @Param        request body models.ExternalPatientIdentity true "Данные для идентификации пациента"
+// @Success      200 {object} map[string]string "Запись отменена"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Пациент или запись не найдены"
+// @Failure      409 {object} map[string]string "Отмена уже невозможна"
+// @Failure      429 {object} map[string]string "Превышен лимит запросов"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/external/appointments/{id}/cancel [post]
+func (h *BookingHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID записи"})
+		return
+	}
+
+	var req models.ExternalPatientIdentity
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	patientID, err := h.service.Cancel(uint(id), &req)
+	if err != nil {
+		logger.Default().WithError(err).Warn("ExternalCancel: Failed to cancel appointment")
+		respondBookingError(c, err)
+		return
+	}
+	middleware.MarkPatientAccess(c, patientID)
+	c.JSON(http.StatusOK, gin.H{"message": "Запись отменена"})
+}
+
+func parseOptionalDate(c *gin.Context, param string) (*time.Time, bool) {
+	v := c.Query(param)
+	if v == "" {
+		return nil, true
+	}
+	date, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат даты " + param + ", используйте YYYY-MM-DD"})
+		return nil, false
+	}
+	return &date, true
+}
+
+func respondBookingError(c *gin.Context, err error) {
+	if respondBookingRulesViolation(c, err) {
+		return
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "неверный формат") || strings.Contains(msg, "не более чем на") || strings.Contains(msg, "прошедшее время"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	case strings.Contains(msg, "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "уже занято") || strings.Contains(msg, "уже записан") || strings.Contains(msg, "лимит") || strings.Contains(msg, "не позднее") || strings.Contains(msg, "уже пришел"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+	}
+}
+
+// AppointmentCalendar godoc
+// @Summary      Скачать запись на прием (.ics)
+// @Description  Идентифицирует пациента и возвращает файл iCalendar с его записью. Для отмененной записи возвращается отмена события с тем же UID.
+// @Tags         external
+// @Accept       json
+// @Produce      text/calendar
+// @Param        id path int true "ID записи"
+// @Param        request body models.ExternalPatientIdentity true "Данные для идентификации пациента"
+// @Success      200 {string} string "Файл в формате iCalendar"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Пациент или запись не найдены"
+// @Failure      429 {object} map[string]string "Превышен лимит запросов"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/external/appointments/{id}/ics [post]
+func (h *BookingHandler) AppointmentCalendar(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID записи"})
+		return
+	}
+
+	var req models.ExternalPatientIdentity
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	data, patientID, err := h.service.AppointmentCalendar(uint(id), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "неверный формат") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondCalendarError(c, err, "ExternalAppointmentCalendar")
+		return
+	}
+	middleware.MarkPatientAccess(c, patientID)
+	sendICSFile(c, uint(id), data)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookingRuleHandler обрабатывает запросы на управление правилами записи по специальностям.
+type BookingRuleHandler struct {
+	service *services.BookingRulesService
+}
+
+// NewBookingRuleHandler создает новый экземпляр BookingRuleHandler.
+func NewBookingRuleHandler(service *services.BookingRulesService) *BookingRuleHandler {
+	return &BookingRuleHandler{service: service}
+}
+
+// GetBookingRules godoc
+// @Summary      Получить правила записи (Админ)
+// @Description  Возвращает ограничения записи по специальностям. Пересечение записей одного пациента по времени запрещено всегда и в списке не отображается.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {array} models.BookingRule "Список правил"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/booking-rules [get]
+func (h *BookingRuleHandler) GetBookingRules(c *gin.Context) {
+	rules, err := h.service.GetAll()
+	if err != nil {
+		logger.Default().WithError(err).Error("GetBookingRules: Failed to get booking rules")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpsertBookingRule godoc
+// @Summary      Задать правила записи для специальности (Админ)
+// @Description  Создает или полностью заменяет правила для специальности: максимум активных записей пациента, минимальный интервал между визитами в днях, допустимый возраст пациента (например, педиатрия — max_age=17). Незаполненное поле снимает ограничение.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.UpsertBookingRuleRequest true "Правила специальности"
+// @Success      200 {object} models.BookingRule "Сохраненные правила"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/booking-rules [put]
+func (h *BookingRuleHandler) UpsertBookingRule(c *gin.Context) {
+	var req models.UpsertBookingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	rule, err := h.service.Upsert(&req)
+	if err != nil {
+		if strings.Contains(err.Error(), "укажите") || strings.Contains(err.Error(), "не может быть") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Default().WithError(err).Error("UpsertBookingRule: Failed to save booking rule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteBookingRule godoc
+// @Summary      Удалить правила записи для специальности (Админ)
+// @Description  Снимает все ограничения специальности, кроме запрета пересечения записей.
+// @Tags         admin
+// @Produce      json
+// @Param        id path int true "ID правил"
+// @Success      200 {object} map[string]string "Правила удалены"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Правила не найдены"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/booking-rules/{id} [delete]
+func (h *BookingRuleHandler) DeleteBookingRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "не найдены") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Default().WithError(err).Error("DeleteBookingRule: Failed to delete booking rule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Правила записи удалены"})
+}
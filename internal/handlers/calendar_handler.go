@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
+	"ElectronicQueue/internal/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler обрабатывает запросы на выгрузку расписания в формате iCalendar (.ics).
+type CalendarHandler struct {
+	service *services.CalendarService
+}
+
+// NewCalendarHandler создает новый экземпляр CalendarHandler.
+func NewCalendarHandler(service *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// IssueFeedToken godoc
+// @Summary      Получить ссылку на календарь врача
+// @Description  Выпускает новый персональный токен для подписки на календарь (.ics) с расписанием врача. Предыдущая ссылка перестает работать.
+// @Tags         doctor
+// @Produce      json
+// @Success      200 {object} models.CalendarFeedResponse "Токен и ссылка на календарь"
+// @Failure      401 {object} map[string]string "Ошибка авторизации"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router       /api/doctor/calendar/token [post]
+func (h *CalendarHandler) IssueFeedToken(c *gin.Context) {
+	doctorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ID врача не найден в токене"})
+		return
+	}
+
+	doctorIDUint, ok := doctorID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Неверный формат ID врача"})
+		return
+	}
+
+	feed, err := h.service.IssueDoctorToken(doctorIDUint)
+	if err != nil {
+		logger.Default().WithError(err).Error("IssueFeedToken: Failed to issue calendar token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
+// DoctorFeed godoc
+// @Summary      Календарь врача (.ics)
+// @Description  Возвращает расписание врача в формате iCalendar для подписки в календаре телефона. Доступ по персональному токену из ссылки. ФИО пациентов выводится в виде PUBLIC_NAME_FORMAT.
+// @Tags         calendar
+// @Produce      text/calendar
+// @Param        token path string true "Персональный токен календаря (можно с суффиксом .ics)"
+// @Success      200 {string} string "Календарь в формате iCalendar"
+// @Failure      404 {object} map[string]string "Календарь не найден"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router       /api/calendar/doctor/{token} [get]
+func (h *CalendarHandler) DoctorFeed(c *gin.Context) {
+	export, err := h.service.DoctorFeed(c.Param("token"))
+	if err != nil {
+		if strings.Contains(err.Error(), "не найден") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Default().WithError(err).Error("DoctorFeed: Failed to build calendar")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сформировать календарь"})
+		return
+	}
+
+	// Ссылку открывает сам врач, поэтому доступ к ФИО пациентов записывается в журнал от его имени.
+	c.Set("user_id", export.DoctorID)
+	middleware.MarkPatientAccess(c, export.PatientIDs...)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", export.Data)
+}
+
+// AppointmentICS godoc
+// @Summary      Скачать запись на прием (.ics)
+// @Description  Возвращает файл iCalendar с записью на прием: время, врач и кабинет. Для отмененной записи возвращается отмена события с тем же UID.
+// @Tags         registrar
+// @Produce      text/calendar
+// @Param        id path int true "ID Записи"
+// @Success      200 {string} string "Файл в формате iCalendar"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Запись не найдена"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/appointments/{id}/ics [get]
+func (h *CalendarHandler) AppointmentICS(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	data, err := h.service.AppointmentICS(uint(id), nil)
+	if err != nil {
+		respondCalendarError(c, err, "AppointmentICS")
+		return
+	}
+	sendICSFile(c, uint(id), data)
+}
+
+func sendICSFile(c *gin.Context, appointmentID uint, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="appointment-%d.ics"`, appointmentID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
+
+func respondCalendarError(c *gin.Context, err error, operation string) {
+	if strings.Contains(err.Error(), "не найден") {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Default().WithError(err).Error(operation + ": Failed to build calendar")
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сформировать календарь"})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/cluster"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClusterHandler отдает роль экземпляра сервера при работе нескольких экземпляров за балансировщиком.
+type ClusterHandler struct {
+	elector *cluster.Elector
+}
+
+// NewClusterHandler создает новый экземпляр ClusterHandler.
+func NewClusterHandler(elector *cluster.Elector) *ClusterHandler {
+	return &ClusterHandler{elector: elector}
+}
+
+// GetStatus godoc
+// @Summary      Роль экземпляра сервера
+// @Description  Возвращает ID экземпляра, обработавшего запрос, и является ли он лидером — экземпляром, который выполняет фоновые задания (очистку талонов, напоминания, снятие удержаний слотов). Лидер выбирается через advisory-блокировку PostgreSQL; при его отключении задания подхватывает другой экземпляр.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} cluster.Status "Роль экземпляра"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/cluster [get]
+func (h *ClusterHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.elector.Status())
+}
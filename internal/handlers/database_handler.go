@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/services"
 	"net/http"
@@ -49,6 +50,7 @@ func (h *DatabaseHandler) GetData(c *gin.Context) {
 		return
 	}
 
+	middleware.MarkPatientAccess(c, services.PatientIDsFromRows(tableName, data)...)
 	c.JSON(http.StatusOK, gin.H{
 		"page":  req.Page,
 		"limit": req.Limit,
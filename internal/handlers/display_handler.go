@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/pubsub"
+	"ElectronicQueue/internal/services"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisplayHandler обрабатывает запросы к реестру устройств отображения: управление устройствами
+// администратором и получение конфигурации самими устройствами.
+type DisplayHandler struct {
+	service *services.DisplayService
+	broker  *pubsub.Broker
+}
+
+// NewDisplayHandler создает новый экземпляр DisplayHandler.
+func NewDisplayHandler(service *services.DisplayService, broker *pubsub.Broker) *DisplayHandler {
+	return &DisplayHandler{service: service, broker: broker}
+}
+
+// GetDisplays godoc
+// @Summary      Получить список устройств отображения (Админ)
+// @Description  Возвращает все зарегистрированные табло, экраны у кабинетов и киоски с их конфигурацией и временем последнего обращения.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {array} models.DisplayResponse "Список устройств"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/displays [get]
+func (h *DisplayHandler) GetDisplays(c *gin.Context) {
+	displays, err := h.service.GetAll()
+	if err != nil {
+		logger.Default().WithError(err).Error("GetDisplays: Failed to get displays")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, displays)
+}
+
+// GetDisplay godoc
+// @Summary      Получить устройство отображения (Админ)
+// @Tags         admin
+// @Produce      json
+// @Param        id path int true "ID устройства"
+// @Success      200 {object} models.DisplayResponse "Устройство"
+// @Failure      400 {object} map[string]string "Неверный формат ID"
+// @Failure      404 {object} map[string]string "Устройство не найдено"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/displays/{id} [get]
+func (h *DisplayHandler) GetDisplay(c *gin.Context) {
+	id, ok := parseDisplayID(c)
+	if !ok {
+		return
+	}
+	display, err := h.service.GetByID(id)
+	if err != nil {
+		h.respondError(c, "GetDisplay", err)
+		return
+	}
+	c.JSON(http.StatusOK, display)
+}
+
+// CreateDisplay godoc
+// @Summary      Зарегистрировать устройство отображения (Админ)
+// @Description  Регистрирует устройство с назначением reception (табло регистратуры), cabinet (экран у кабинета), schedule (табло расписания) или kiosk (терминал) и выпускает ему токен. Токен показывается только в этом ответе; его нужно ввести на устройстве.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateDisplayRequest true "Назначение и конфигурация устройства"
+// @Success      201 {object} models.DisplayTokenResponse "Устройство и его токен"
+// @Failure      400 {object} map[string]string "Ошибка: неверная конфигурация"
+// @Failure      404 {object} map[string]string "Рекламный материал из плейлиста не найден"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/displays [post]
+func (h *DisplayHandler) CreateDisplay(c *gin.Context) {
+	var req models.CreateDisplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+	result, err := h.service.Create(&req)
+	if err != nil {
+		h.respondError(c, "CreateDisplay", err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// UpdateDisplay godoc
+// @Summary      Изменить устройство отображения (Админ)
+// @Description  Меняет назначение, окна, кабинеты, плейлист рекламы, звук или тему устройства. Подключенное устройство сразу получает новую конфигурацию событием display_config.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID устройства"
+// @Param        request body models.UpdateDisplayRequest true "Изменяемые поля"
+// @Success      200 {object} models.DisplayResponse "Обновленное устройство"
+// @Failure      400 {object} map[string]string "Ошибка: неверная конфигурация"
+// @Failure      404 {object} map[string]string "Устройство или рекламный материал не найден"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/displays/{id} [patch]
+func (h *DisplayHandler) UpdateDisplay(c *gin.Context) {
+	id, ok := parseDisplayID(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateDisplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+	display, err := h.service.Update(id, &req)
+	if err != nil {
+		h.respondError(c, "UpdateDisplay", err)
+		return
+	}
+	c.JSON(http.StatusOK, display)
+}
+
+// DeleteDisplay godoc
+// @Summary      Удалить устройство отображения (Админ)
+// @Description  Удаляет устройство из реестра. Его токен перестает действовать, подключенное устройство получает событие display_revoked и отключается.
+// @Tags         admin
+// @Produce      json
+// @Param        id path int true "ID устройства"
+// @Success      200 {object} map[string]string "Устройство удалено"
+// @Failure      400 {object} map[string]string "Неверный формат ID"
+// @Failure      404 {object} map[string]string "Устройство не найдено"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/displays/{id} [delete]
+func (h *DisplayHandler) DeleteDisplay(c *gin.Context) {
+	id, ok := parseDisplayID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(id); err != nil {
+		h.respondError(c, "DeleteDisplay", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Устройство удалено"})
+}
+
+// RotateDisplayToken godoc
+// @Summary      Выпустить устройству новый токен (Админ)
+// @Description  Заменяет токен устройства, например при замене оборудования. Прежний токен перестает действовать, подключенное с ним устройство получает display_revoked.
+// @Tags         admin
+// @Produce      json
+// @Param        id path int true "ID устройства"
+// @Success      200 {object} models.DisplayTokenResponse "Устройство и новый токен"
+// @Failure      400 {object} map[string]string "Неверный формат ID"
+// @Failure      404 {object} map[string]string "Устройство не найдено"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/displays/{id}/token [post]
+func (h *DisplayHandler) RotateDisplayToken(c *gin.Context) {
+	id, ok := parseDisplayID(c)
+	if !ok {
+		return
+	}
+	result, err := h.service.RotateToken(id)
+	if err != nil {
+		h.respondError(c, "RotateDisplayToken", err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetConfig godoc
+// @Summary      Получить конфигурацию устройства
+// @Description  Возвращает устройству, авторизованному токеном, его назначение, окна или кабинеты, плейлист рекламы, настройки звука и тему.
+// @Tags         display
+// @Produce      json
+// @Param        X-Display-Token header string true "Токен устройства"
+// @Success      200 {object} models.DisplayResponse "Конфигурация устройства"
+// @Failure      401 {object} map[string]string "Токен не указан"
+// @Failure      403 {object} map[string]string "Неверный токен"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router       /api/display/config [get]
+func (h *DisplayHandler) GetConfig(c *gin.Context) {
+	config, err := middleware.DisplayFrom(c).ToResponse()
+	if err != nil {
+		logger.Default().WithError(err).Error("GetDisplayConfig: Failed to decode display settings")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Поврежденная конфигурация устройства"})
+		return
+	}
+	c.JSON(http.StatusOK, config)
+}
+
+// GetPlaylist godoc
+// @Summary      Получить рекламу для устройства
+// @Description  Возвращает рекламные материалы из плейлиста устройства в порядке показа. Если плейлист не задан, табло регистратуры и расписания получают все материалы, включенные для своего экрана.
+// @Tags         display
+// @Produce      json
+// @Param        X-Display-Token header string true "Токен устройства"
+// @Success      200 {array} models.AdResponse "Рекламные материалы"
+// @Failure      401 {object} map[string]string "Токен не указан"
+// @Failure      403 {object} map[string]string "Неверный токен"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router       /api/display/ads [get]
+func (h *DisplayHandler) GetPlaylist(c *gin.Context) {
+	ads, err := h.service.Playlist(middleware.DisplayFrom(c))
+	if err != nil {
+		logger.Default().WithError(err).Error("GetDisplayPlaylist: Failed to get playlist")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response := make([]models.AdResponse, 0, len(ads))
+	for _, ad := range ads {
+		response = append(response, ad.ToResponse())
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Updates godoc
+// @Summary      Поток изменений конфигурации устройства
+// @Description  Сразу после подключения отправляет текущую конфигурацию (`event: display_config`) и затем каждую новую, когда администратор меняет устройство. Событие display_revoked означает, что устройство удалено или его токен заменен; после него поток закрывается. Токен можно передать параметром token, так как EventSource не позволяет задать заголовок. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat.
+// @Tags         display
+// @Produce      text/event-stream
+// @Param        X-Display-Token header string false "Токен устройства"
+// @Param        token query string false "Токен устройства (для EventSource)"
+// @Success      200 {object} models.DisplayResponse "Поток событий с конфигурацией"
+// @Failure      401 {object} map[string]string "Токен не указан"
+// @Failure      403 {object} map[string]string "Неверный токен"
+// @Failure      429 {object} map[string]string "Превышен лимит подключений"
+// @Router       /api/display/updates [get]
+func (h *DisplayHandler) Updates(c *gin.Context) {
+	display := middleware.DisplayFrom(c)
+	config, err := display.ToResponse()
+	if err != nil {
+		logger.Default().WithError(err).Error("DisplayUpdates: Failed to decode display settings")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Поврежденная конфигурация устройства"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	log := logger.Default().WithField("module", "SSE_DISPLAY").WithField("display_id", display.ID)
+
+	sub := h.broker.Subscribe(pubsub.Filter{Topics: []pubsub.Topic{pubsub.TopicDisplay}, Display: &display.ID})
+	defer h.broker.Unsubscribe(sub)
+
+	if !writeSSE(c, sub.Cursor, pubsub.EventDisplayConfig, config) {
+		return
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				log.Info("Канал уведомлений закрыт для устройства.")
+				return false
+			}
+			log.WithField("event", event.Name).Info("Отправка изменения конфигурации устройству")
+			if !writeSSE(c, event.ID, event.Name, event.Data) {
+				return false
+			}
+			return event.Name != pubsub.EventDisplayRevoked
+
+		case <-sub.Resync:
+			// Часть событий пропущена: конфигурация перечитывается из реестра.
+			sub.Drain()
+			id := h.broker.LastID()
+			current, err := h.service.GetByID(display.ID)
+			if err != nil {
+				if strings.Contains(err.Error(), "не найдено") {
+					writeSSE(c, id, pubsub.EventDisplayRevoked, gin.H{"display_id": display.ID})
+				} else {
+					log.WithError(err).Error("Не удалось получить конфигурацию устройства для повторной синхронизации")
+				}
+				return false
+			}
+			return writeSSE(c, id, pubsub.EventDisplayConfig, current)
+
+		case <-sseHeartbeats(c):
+			return writeHeartbeat(c)
+
+		case <-c.Request.Context().Done():
+			log.Info("Устройство отключилось.")
+			return false
+		}
+	})
+}
+
+// respondError переводит ошибку DisplayService в HTTP-ответ.
+func (h *DisplayHandler) respondError(c *gin.Context, operation string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "некорректн"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "не найден"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		logger.Default().WithError(err).Error(operation + ": Display request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseDisplayID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
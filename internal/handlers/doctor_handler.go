@@ -380,12 +380,14 @@ func (h *DoctorHandler) SetDoctorInactive(c *gin.Context) {
 
 // DoctorScreenUpdates - SSE эндпоинт для табло у кабинета врача.
 // @Summary      Получить обновления для табло врача
-// @Description  Отправляет начальное состояние и последующие обновления статуса приема через Server-Sent Events для конкретного кабинета.
+// @Description  Отправляет начальное состояние и последующие обновления статуса приема через Server-Sent Events для конкретного кабинета. Каждое событие state_update содержит полное состояние экрана и ID последнего учтенного события; при переподключении с заголовком Last-Event-ID состояние отправляется повторно, только если за время разрыва в кабинете что-то изменилось. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat; клиент, не принимающий данные дольше SSE_WRITE_TIMEOUT, отключается.
 // @Tags         doctor
 // @Produce      text/event-stream
 // @Param        cabinet_number path int true "Номер кабинета"
+// @Param        Last-Event-ID header int false "ID последнего полученного события"
 // @Success      200 {object} DoctorScreenResponse "Поток событий (см. реальную структуру ответа в коде)"
 // @Failure      400 {object} map[string]string "Неверный формат номера кабинета"
+// @Failure      429 {object} map[string]string "Превышен лимит подключений"
 // @Router       /api/doctor/screen-updates/{cabinet_number} [get]
 func (h *DoctorHandler) DoctorScreenUpdates(c *gin.Context) {
 	cabinetNumberStr := c.Param("cabinet_number")
@@ -400,11 +402,16 @@ func (h *DoctorHandler) DoctorScreenUpdates(c *gin.Context) {
 	c.Header("Connection", "keep-alive")
 	log := logger.Default().WithField("module", "SSE_DOCTOR").WithField("cabinet", cabinetNumber)
 
-	clientChan := h.broker.Subscribe()
-	defer h.broker.Unsubscribe(clientChan)
+	// Табло перерисовывается только при изменениях своего кабинета: талонов, записанных в него,
+	// статуса принимающего врача и слотов расписания.
+	sub, missed, resumed := subscribeSSE(c, h.broker, pubsub.Filter{
+		Topics:  []pubsub.Topic{pubsub.TopicTicket, pubsub.TopicDoctorStatus, pubsub.TopicSchedule},
+		Cabinet: &cabinetNumber,
+	})
+	defer h.broker.Unsubscribe(sub)
 
-	// Функция для получения и отправки текущего состояния экрана врача
-	sendCurrentState := func() bool {
+	// Функция для получения и отправки текущего состояния экрана врача. id — последнее учтенное событие.
+	sendCurrentState := func(id uint64) bool {
 		schedule, queue, err := h.doctorService.GetDoctorScreenState(cabinetNumber)
 		if err != nil {
 			// Если произошла критическая ошибка в сервисе, логируем и прекращаем.
@@ -431,32 +438,42 @@ func (h *DoctorHandler) DoctorScreenUpdates(c *gin.Context) {
 		}
 
 		log.WithField("queue_size", len(queue)).Info("Отправка обновления состояния экрана врача")
-		c.SSEvent("state_update", response)
-
-		// Проверяем, жив ли клиент, и сбрасываем буфер.
-		if f, ok := c.Writer.(http.Flusher); ok {
-			f.Flush()
-			return c.Writer.Status() != http.StatusNotFound
-		}
-		return c.Writer.Status() < http.StatusInternalServerError
+		return writeSSE(c, id, "state_update", response)
 	}
 
-	// Отправляем начальное состояние сразу после подключения
-	if !sendCurrentState() {
-		log.Info("Клиент отключился сразу после отправки начального состояния.")
-		return
+	// Отправляем начальное состояние сразу после подключения. Переподключившемуся клиенту, который
+	// ничего не пропустил, состояние не отправляется: оно у него актуально.
+	initialID := sub.Cursor
+	if resumed && len(missed) > 0 {
+		initialID = missed[len(missed)-1].ID
+	}
+	if !resumed || len(missed) > 0 {
+		if !sendCurrentState(initialID) {
+			log.Info("Клиент отключился сразу после отправки начального состояния.")
+			return
+		}
 	}
 
 	// Запускаем стрим для отправки обновлений
 	c.Stream(func(w io.Writer) bool {
 		select {
-		case _, ok := <-clientChan:
+		case event, ok := <-sub.Events:
 			if !ok {
 				log.Info("Канал уведомления закрыт для экрана врача.")
 				return false
 			}
-			log.Info("Получено уведомление об обновлении талона, обновление состояния экрана врача.")
-			return sendCurrentState()
+			log.WithField("topic", event.Topic).Info("Получено событие кабинета, обновление состояния экрана врача.")
+			return sendCurrentState(event.ID)
+
+		case <-sub.Resync:
+			// Часть событий пропущена: накопленные уже не нужны, состояние экрана отправляется целиком.
+			sub.Drain()
+			log.Warn("События для экрана врача пропущены, повторная синхронизация.")
+			id := h.broker.LastID()
+			return writeSSE(c, id, pubsub.EventResync, gin.H{"reason": "events_dropped"}) && sendCurrentState(id)
+
+		case <-sseHeartbeats(c):
+			return writeHeartbeat(c)
 
 		case <-c.Request.Context().Done():
 			log.Info("Клиент отключился от экрана врача.")
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatientAccessHandler обрабатывает запросы администратора к журналу доступа к данным пациентов.
+type PatientAccessHandler struct {
+	service *services.PatientAccessService
+}
+
+// NewPatientAccessHandler создает новый экземпляр PatientAccessHandler.
+func NewPatientAccessHandler(service *services.PatientAccessService) *PatientAccessHandler {
+	return &PatientAccessHandler{service: service}
+}
+
+// GetAccessLog godoc
+// @Summary      Журнал доступа к данным пациентов
+// @Description  Возвращает записи о чтении персональных данных пациентов: кто (роль и ID пользователя), через какой эндпоинт, с какого адреса и когда. Фильтруется по пациенту, по пользователю и по периоду; по умолчанию — за последние 30 дней. Новые записи первыми. Журнал только дополняется: изменить или удалить записи нельзя.
+// @Tags         admin
+// @Produce      json
+// @Param        patient_id query int false "ID пациента"
+// @Param        actor_role query string false "Роль пользователя (registrar, doctor, admin, database)"
+// @Param        actor_id query int false "ID пользователя"
+// @Param        date_from query string false "Начало периода (YYYY-MM-DD)"
+// @Param        date_to query string false "Конец периода (YYYY-MM-DD)"
+// @Param        page query int false "Номер страницы (с 1)"
+// @Param        page_size query int false "Размер страницы (по умолчанию 50, не больше 500)"
+// @Success      200 {object} models.PatientAccessPage "Страница журнала"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат параметров"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patient-access [get]
+func (h *PatientAccessHandler) GetAccessLog(c *gin.Context) {
+	from, to, ok := parseAuditPeriod(c)
+	if !ok {
+		return
+	}
+	filter := &models.PatientAccessFilter{ActorRole: c.Query("actor_role"), From: from, To: to}
+	if filter.PatientID, ok = parseOptionalUint(c, "patient_id"); !ok {
+		return
+	}
+	if filter.ActorID, ok = parseOptionalUint(c, "actor_id"); !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	result, err := h.service.Find(filter, page, pageSize)
+	if err != nil {
+		logger.Default().WithError(err).Error("GetPatientAccessLog: Failed to get access log")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAnomalies godoc
+// @Summary      Отчет о подозрительном доступе к данным пациентов
+// @Description  Находит часы, за которые пользователь просмотрел данные больше threshold разных пациентов. По умолчанию порог берется из настройки PATIENT_ACCESS_ANOMALY_THRESHOLD, период — последние 30 дней.
+// @Tags         admin
+// @Produce      json
+// @Param        date_from query string false "Начало периода (YYYY-MM-DD)"
+// @Param        date_to query string false "Конец периода (YYYY-MM-DD)"
+// @Param        threshold query int false "Допустимое число разных пациентов за час"
+// @Success      200 {object} models.PatientAccessAnomalyReport "Отчет"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат параметров"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patient-access/anomalies [get]
+func (h *PatientAccessHandler) GetAnomalies(c *gin.Context) {
+	from, to, ok := parseAuditPeriod(c)
+	if !ok {
+		return
+	}
+	threshold := 0
+	if raw := c.Query("threshold"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold должен быть положительным целым числом"})
+			return
+		}
+		threshold = value
+	}
+
+	report, err := h.service.GetAnomalies(from, to, threshold)
+	if err != nil {
+		logger.Default().WithError(err).Error("GetPatientAccessAnomalies: Failed to build report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// parseOptionalUint читает необязательный числовой параметр запроса. При ошибке отвечает 400 и возвращает ok=false.
+func parseOptionalUint(c *gin.Context, key string) (*uint, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат параметра " + key})
+		return nil, false
+	}
+	id := uint(value)
+	return &id, true
+}
@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/services"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,56 +22,67 @@ func NewPatientHandler(service *services.PatientService) *PatientHandler {
 }
 
 // SearchPatients godoc
-// @Summary      Поиск пациентов по ФИО, ОМС или паспорту
-// @Description  Ищет пациентов по частичному совпадению в ФИО, номере полиса ОМС или полному номеру паспорта (серия + номер без пробелов). Возвращает до 10 совпадений.
+// @Summary      Нечеткий поиск пациентов
+// @Description  Ищет пациентов по ФИО с учетом опечаток и без различия е/ё, по окончанию телефона, а также по полному номеру полиса ОМС или паспорта (документы хранятся зашифрованными, поэтому частичный поиск по ним невозможен). Первое слово считается фамилией; однобуквенные слова и слова с точкой — инициалами; четырехзначное число рядом с ФИО — годом рождения, дата вида 02.01.2006 — датой рождения. Например, «Иванов И 1985» найдет Ивановых с именем на «И», родившихся в 1985 году. Результаты упорядочены по релевантности и разбиты на страницы; у каждого пациента перечислены открытые действующие направления (open_referrals), по которым можно записать его через referral_id.
 // @Tags         registrar
 // @Produce      json
 // @Param        query query string true "Строка для поиска (минимум 2 символа)"
-// @Success      200 {array} models.Patient "Массив найденных пациентов"
-// @Failure      400 {object} map[string]string "Ошибка: отсутствует или слишком короткий параметр поиска"
+// @Param        page query int false "Номер страницы, начиная с 1 (по умолчанию 1)"
+// @Param        page_size query int false "Размер страницы (по умолчанию 20, не более 50)"
+// @Success      200 {object} models.PatientSearchPage "Страница найденных пациентов"
+// @Failure      400 {object} map[string]string "Ошибка: отсутствует параметр поиска или неверная пагинация"
 // @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Security     ApiKeyAuth
 // @Router       /api/registrar/patients/search [get]
 func (h *PatientHandler) SearchPatients(c *gin.Context) {
 	log := logger.Default()
-	query := c.Query("query")
+	query := strings.TrimSpace(c.Query("query"))
 	if query == "" {
 		log.Warn("SearchPatients: Query parameter 'query' is missing")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр 'query' для поиска обязателен"})
 		return
 	}
 
-	// Добавим проверку на минимальную длину запроса для снижения нагрузки на БД
-	if len(query) < 2 {
-		c.JSON(http.StatusOK, []models.Patient{})
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр page должен быть положительным числом"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр page_size должен быть числом от 1 до 50"})
 		return
 	}
 
-	patients, err := h.service.SearchPatients(query)
+	// Слишком короткий запрос не отправляется в БД
+	if len([]rune(query)) < 2 {
+		c.JSON(http.StatusOK, models.PatientSearchPage{Items: []models.PatientSearchResult{}, Page: page, PageSize: pageSize})
+		return
+	}
+
+	result, err := h.service.SearchPatients(query, page, pageSize)
 	if err != nil {
 		log.WithError(err).Error("SearchPatients: Failed to search patients in service")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить поиск пациентов"})
 		return
 	}
 
-	// Всегда возвращаем JSON-массив, даже если он пустой, это лучшая практика для API.
-	if patients == nil {
-		c.JSON(http.StatusOK, []models.Patient{})
-		return
+	for _, item := range result.Items {
+		middleware.MarkPatientAccess(c, item.ID)
 	}
-
-	c.JSON(http.StatusOK, patients)
+	c.JSON(http.StatusOK, result)
 }
 
 // CreatePatient godoc
 // @Summary      Создать нового пациента
-// @Description  Создает новую запись о пациенте в базе данных. Используется, когда пациент не найден через поиск.
+// @Description  Создает новую запись о пациенте в базе данных. Используется, когда пациент не найден через поиск. Паспорт, полис ОМС (с контрольной цифрой) и дата рождения проверяются; телефон сохраняется в виде +79001234567.
 // @Tags         registrar
 // @Accept       json
 // @Produce      json
 // @Param        patient body models.CreatePatientRequest true "Данные нового пациента"
 // @Success      201 {object} models.Patient "Успешно созданный пациент"
-// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса или некорректные данные"
+// @Failure      409 {object} map[string]string "Пациент с таким паспортом уже существует"
 // @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Security     ApiKeyAuth
 // @Router       /api/registrar/patients [post]
@@ -83,10 +98,527 @@ func (h *PatientHandler) CreatePatient(c *gin.Context) {
 
 	patient, err := h.service.CreatePatient(&req)
 	if err != nil {
-		log.WithError(err).Error("CreatePatient: Failed to create patient in service")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать пациента"})
+		switch {
+		case strings.Contains(err.Error(), "некорректн"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "уже существует"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.WithError(err).Error("CreatePatient: Failed to create patient in service")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось создать пациента"})
+		}
 		return
 	}
 
+	middleware.MarkPatientAccess(c, patient.ID)
 	c.JSON(http.StatusCreated, patient)
 }
+
+// UpdateNotificationSettings godoc
+// @Summary      Изменить настройки уведомлений пациента
+// @Description  Включает или отключает для пациента все уведомления: о записи, ее переносе и отмене, напоминания о приеме и предложения из листа ожидания.
+// @Tags         registrar
+// @Accept       json
+// @Produce      json
+// @Param        patient_id path int true "ID Пациента"
+// @Param        request body models.UpdateNotificationSettingsRequest true "Настройки уведомлений"
+// @Success      200 {object} models.Patient "Обновленный пациент"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Пациент не найден"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/patients/{patient_id}/notifications [patch]
+func (h *PatientHandler) UpdateNotificationSettings(c *gin.Context) {
+	log := logger.Default()
+
+	patientID, err := strconv.ParseUint(c.Param("patient_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пациента"})
+		return
+	}
+
+	var req models.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).Warn("UpdateNotificationSettings: Failed to bind JSON")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	patient, err := h.service.SetNotificationsOptOut(uint(patientID), *req.OptOut)
+	if err != nil {
+		log.WithError(err).Error("UpdateNotificationSettings: Failed to update settings in service")
+		if strings.Contains(err.Error(), "не найден") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить настройки уведомлений"})
+		return
+	}
+
+	middleware.MarkPatientAccess(c, patient.ID)
+	c.JSON(http.StatusOK, patient)
+}
+
+// UpdatePatient godoc
+// @Summary      Изменить данные пациента
+// @Description  Изменяет переданные поля карточки пациента; пустые поля не меняются. Проверяются ФИО, формат паспорта (код региона, год бланка, номер), полис ОМС (16 цифр с контрольной цифрой), дата рождения (не в будущем и не старше 130 лет) и уникальность паспорта; телефон сохраняется в виде +79001234567. Старые и новые значения записываются в журнал изменений карточки.
+// @Tags         registrar
+// @Accept       json
+// @Produce      json
+// @Param        patient_id path int true "ID Пациента"
+// @Param        patient body models.UpdatePatientRequest true "Изменяемые поля"
+// @Success      200 {object} models.Patient "Обновленный пациент"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса или некорректные данные"
+// @Failure      404 {object} map[string]string "Пациент не найден"
+// @Failure      409 {object} map[string]string "Пациент с таким паспортом уже существует или карточка анонимизирована"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/patients/{patient_id} [patch]
+func (h *PatientHandler) UpdatePatient(c *gin.Context) {
+	log := logger.Default()
+
+	patientID, err := strconv.ParseUint(c.Param("patient_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пациента"})
+		return
+	}
+	registrarID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ID регистратора не найден в токене"})
+		return
+	}
+	registrarIDUint, _ := registrarID.(uint)
+
+	var req models.UpdatePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).Warn("UpdatePatient: Failed to bind JSON")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	patient, err := h.service.UpdatePatient(uint(patientID), registrarIDUint, &req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "некорректн"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "уже существует") || strings.Contains(err.Error(), "анонимизирована"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.WithError(err).Error("UpdatePatient: Failed to update patient in service")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить данные пациента"})
+		}
+		return
+	}
+
+	middleware.MarkPatientAccess(c, patient.ID)
+	c.JSON(http.StatusOK, patient)
+}
+
+// GetPatientHistory godoc
+// @Summary      Журнал изменений карточки пациента
+// @Description  Возвращает изменения карточки (кто, когда, старые и новые значения) и объединения с дубликатами, новые первыми.
+// @Tags         registrar
+// @Produce      json
+// @Param        patient_id path int true "ID Пациента"
+// @Success      200 {array} models.PatientAuditLog "Журнал изменений"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID пациента"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/patients/{patient_id}/history [get]
+func (h *PatientHandler) GetPatientHistory(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("patient_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пациента"})
+		return
+	}
+
+	entries, err := h.service.GetHistory(uint(patientID))
+	if err != nil {
+		logger.Default().WithError(err).Error("GetPatientHistory: Failed to get history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.MarkPatientAccess(c, uint(patientID))
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetPatientDuplicates godoc
+// @Summary      Возможные дубликаты карточки пациента
+// @Description  Возвращает карточки с тем же полисом ОМС, тем же телефоном или похожим ФИО (без учета регистра и е/ё) и той же датой рождения. Для каждой указаны совпавшие признаки и сходство ФИО; самые вероятные дубликаты первыми.
+// @Tags         registrar
+// @Produce      json
+// @Param        patient_id path int true "ID Пациента"
+// @Success      200 {array} models.DuplicateCandidate "Возможные дубликаты"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID пациента"
+// @Failure      404 {object} map[string]string "Пациент не найден"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/patients/{patient_id}/duplicates [get]
+func (h *PatientHandler) GetPatientDuplicates(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("patient_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пациента"})
+		return
+	}
+
+	candidates, err := h.service.FindDuplicates(uint(patientID))
+	if err != nil {
+		if strings.Contains(err.Error(), "не найден") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Default().WithError(err).Error("GetPatientDuplicates: Failed to find duplicates")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.MarkPatientAccess(c, uint(patientID))
+	for _, candidate := range candidates {
+		middleware.MarkPatientAccess(c, candidate.Patient.ID)
+	}
+	c.JSON(http.StatusOK, candidates)
+}
+
+// GetAllDuplicates godoc
+// @Summary      Возможные дубликаты по всей базе пациентов
+// @Description  Возвращает пары карточек, которые, вероятно, относятся к одному пациенту (тот же полис ОМС, тот же телефон или похожее ФИО и та же дата рождения), самые вероятные первыми. Документы и контакты замаскированы.
+// @Tags         admin
+// @Produce      json
+// @Param        limit query int false "Максимальное число пар (по умолчанию 100)"
+// @Success      200 {array} models.DuplicatePair "Пары возможных дубликатов"
+// @Failure      400 {object} map[string]string "Ошибка: неверный параметр limit"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patients/duplicates [get]
+func (h *PatientHandler) GetAllDuplicates(c *gin.Context) {
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр limit должен быть положительным числом"})
+			return
+		}
+		limit = parsed
+	}
+
+	pairs, err := h.service.FindAllDuplicates(limit)
+	if err != nil {
+		logger.Default().WithError(err).Error("GetAllDuplicates: Failed to find duplicates")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Администратору документы и контакты показываются замаскированными.
+	for i := range pairs {
+		pairs[i].First.MaskPII()
+		pairs[i].Second.MaskPII()
+	}
+	for _, pair := range pairs {
+		middleware.MarkPatientAccess(c, pair.First.ID, pair.Second.ID)
+	}
+	c.JSON(http.StatusOK, pairs)
+}
+
+// MergePatients godoc
+// @Summary      Объединить карточку-дубликат с основной
+// @Description  Переносит записи на прием, лист ожидания, направления, уведомления и журналы регистраций с дубликата на оставшуюся карточку и удаляет дубликат. Записи дубликата в слоты, где уже записана оставшаяся карточка, удаляются. Пустые телефон и email оставшейся карточки заполняются из дубликата. Кто, почему и снимок удаленной карточки сохраняются в журнале.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MergePatientsRequest true "Карточки и причина объединения"
+// @Success      200 {object} models.PatientMergeResult "Результат объединения"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Пациент не найден"
+// @Failure      409 {object} map[string]string "Одна из карточек анонимизирована"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patients/merge [post]
+func (h *PatientHandler) MergePatients(c *gin.Context) {
+	log := logger.Default()
+
+	var req models.MergePatientsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.MergePatients(&req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "саму с собой") || strings.Contains(err.Error(), "не указано"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "анонимизирована"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.WithError(err).Error("MergePatients: Failed to merge patients")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	result.Patient.MaskPII()
+	log.WithFields(map[string]interface{}{
+		"surviving_patient_id": req.SurvivingPatientID,
+		"merged_patient_id":    req.DuplicatePatientID,
+		"performed_by":         req.PerformedBy,
+	}).Info("Patient records merged")
+	middleware.MarkPatientAccess(c, req.SurvivingPatientID, req.DuplicatePatientID)
+	c.JSON(http.StatusOK, result)
+}
+
+// GetMerges godoc
+// @Summary      Журнал объединений карточек пациентов
+// @Description  Возвращает объединения карточек за период: кто, почему, снимок удаленной карточки и число перенесенных записей. По умолчанию — за последние 30 дней.
+// @Tags         admin
+// @Produce      json
+// @Param        date_from query string false "Начало периода (YYYY-MM-DD)"
+// @Param        date_to query string false "Конец периода (YYYY-MM-DD)"
+// @Success      200 {array} models.PatientAuditLog "Журнал объединений"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат даты"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patients/merges [get]
+func (h *PatientHandler) GetMerges(c *gin.Context) {
+	from, to, ok := parseAuditPeriod(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.GetMerges(from, to)
+	if err != nil {
+		logger.Default().WithError(err).Error("GetMerges: Failed to get merges")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, entry := range entries {
+		middleware.MarkPatientAccess(c, entry.PatientID)
+		if entry.MergedPatientID != nil {
+			middleware.MarkPatientAccess(c, *entry.MergedPatientID)
+		}
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// RotatePIIKeys godoc
+// @Summary      Перешифровать персональные данные активным ключом
+// @Description  Перешифровывает паспорта и полисы ОМС, зашифрованные прежними ключами или сохраненные до включения шифрования. Выполняется после смены PII_ACTIVE_KEY; прежний ключ можно удалить из PII_ENCRYPTION_KEYS только после завершения.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} map[string]int "Число перешифрованных карточек"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patients/pii/rotate [post]
+func (h *PatientHandler) RotatePIIKeys(c *gin.Context) {
+	count, err := h.service.RotatePIIKeys()
+	if err != nil {
+		logger.Default().WithError(err).WithField("reencrypted", count).Error("RotatePIIKeys: Failed to re-encrypt patient data")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Default().WithField("reencrypted", count).Info("Patient personal data re-encrypted with the active key")
+	c.JSON(http.StatusOK, gin.H{"reencrypted": count})
+}
+
+// AnonymizePatient godoc
+// @Summary      Анонимизировать карточку пациента
+// @Description  Заменяет ФИО токеном, сокращает дату рождения до года, удаляет телефон, email, паспорт и полис ОМС. Записи на прием, талоны, направления и журналы регистраций остаются привязанными к карточке, поэтому статистика не меняется. Заявки в листе ожидания, открытые направления и неотправленные уведомления отменяются, из текстов уведомлений и прежних записей журнала изменений удаляются данные пациента. Карточку с предстоящими записями на прием анонимизировать нельзя. Кто и почему анонимизировал карточку, сохраняется в журнале.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        patient_id path int true "ID Пациента"
+// @Param        request body models.AnonymizePatientRequest true "Кто и почему анонимизирует карточку"
+// @Success      200 {object} models.PatientAnonymizationResult "Отчет об анонимизации"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Пациент не найден"
+// @Failure      409 {object} map[string]string "Карточка уже анонимизирована или у пациента есть предстоящие записи"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patients/{patient_id}/anonymize [post]
+func (h *PatientHandler) AnonymizePatient(c *gin.Context) {
+	log := logger.Default()
+
+	patientID, err := strconv.ParseUint(c.Param("patient_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пациента"})
+		return
+	}
+	var req models.AnonymizePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.AnonymizePatient(uint(patientID), &req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "не указано"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "анонимизирована") || strings.Contains(err.Error(), "предстоящие записи"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.WithError(err).Error("AnonymizePatient: Failed to anonymize patient")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	log.WithFields(map[string]interface{}{
+		"patient_id":   patientID,
+		"performed_by": req.PerformedBy,
+	}).Info("Patient record anonymized")
+	c.JSON(http.StatusOK, result)
+}
+
+// RunRetention godoc
+// @Summary      Запустить задание хранения данных пациентов
+// @Description  Анонимизирует карточки пациентов, у которых не было приемов дольше PATIENT_RETENTION_YEARS лет (задание также выполняется ежедневно во время обслуживания). За один запуск обрабатывается не больше 500 карточек, самые давние первыми; has_more показывает, что остались еще. С dry_run карточки только находятся.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.RunRetentionRequest true "Кто запускает задание и нужно ли только найти карточки"
+// @Success      200 {object} models.RetentionReport "Отчет о запуске"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса или срок хранения не задан"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patients/retention/run [post]
+func (h *PatientHandler) RunRetention(c *gin.Context) {
+	log := logger.Default()
+
+	var req models.RunRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	report, err := h.service.RunRetention("admin", strings.TrimSpace(req.PerformedBy), req.DryRun)
+	if err != nil {
+		if strings.Contains(err.Error(), "не задан") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.WithError(err).Error("RunRetention: Failed to run patient data retention")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.WithFields(map[string]interface{}{
+		"performed_by": req.PerformedBy,
+		"dry_run":      report.DryRun,
+		"candidates":   len(report.Candidates),
+		"anonymized":   len(report.Anonymized),
+		"failed":       len(report.Failed),
+	}).Info("Patient data retention run completed")
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAnonymizations godoc
+// @Summary      Журнал анонимизаций карточек пациентов
+// @Description  Возвращает анонимизации карточек за период — по решению администратора и по сроку хранения: кто, почему и что было очищено или сохранено. По умолчанию — за последние 30 дней.
+// @Tags         admin
+// @Produce      json
+// @Param        date_from query string false "Начало периода (YYYY-MM-DD)"
+// @Param        date_to query string false "Конец периода (YYYY-MM-DD)"
+// @Success      200 {array} models.PatientAuditLog "Журнал анонимизаций"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат даты"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/patients/anonymizations [get]
+func (h *PatientHandler) GetAnonymizations(c *gin.Context) {
+	from, to, ok := parseAuditPeriod(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.GetAnonymizations(from, to)
+	if err != nil {
+		logger.Default().WithError(err).Error("GetAnonymizations: Failed to get anonymizations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// parseAuditPeriod разбирает параметры date_from и date_to журнала карточек и возвращает полуинтервал [from, to).
+// По умолчанию — последние 30 дней. При ошибке отвечает 400 и возвращает ok = false.
+func parseAuditPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	dateFrom, ok := parseOptionalDate(c, "date_from")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	dateTo, ok := parseOptionalDate(c, "date_to")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if dateTo != nil {
+		to = *dateTo
+	}
+	from := to.AddDate(0, 0, -30)
+	if dateFrom != nil {
+		from = *dateFrom
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to не может быть раньше date_from"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to.AddDate(0, 0, 1), true
+}
+
+// GetPatientTimeline godoc
+// @Summary      История пациента
+// @Description  Объединяет в одну хронологию записи на прием со связанными талонами (ожидание вызова и время обслуживания в регистратуре по reception_logs, длительность приема у врача, регистрация через киоск), итогом визита (запланирован, в очереди, на приеме, завершен, не обслужен, неявка) и выданные пациенту направления. Новые события первыми. Ночная очистка переносит прошедшие визиты в архив; чтобы их увидеть, передайте include_archived=true.
+// @Tags         registrar
+// @Tags         doctor
+// @Produce      json
+// @Param        patient_id path int true "ID Пациента"
+// @Param        specialization query string false "Только визиты к врачам этой специализации и направления к ним"
+// @Param        include_archived query bool false "Добавить архивные визиты (по умолчанию false)"
+// @Param        page query int false "Номер страницы, начиная с 1 (по умолчанию 1)"
+// @Param        page_size query int false "Размер страницы (по умолчанию 20, не более 100)"
+// @Success      200 {object} models.PatientTimelinePage "Страница истории пациента"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID пациента или параметры запроса"
+// @Failure      404 {object} map[string]string "Пациент не найден"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/patients/{patient_id}/timeline [get]
+// @Router       /api/doctor/patients/{patient_id}/timeline [get]
+func (h *PatientHandler) GetPatientTimeline(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("patient_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пациента"})
+		return
+	}
+	includeArchived, err := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр include_archived должен быть true или false"})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр page должен быть положительным числом"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр page_size должен быть числом от 1 до 100"})
+		return
+	}
+
+	timeline, err := h.service.GetTimeline(uint(patientID), c.Query("specialization"), includeArchived, page, pageSize)
+	if err != nil {
+		if strings.Contains(err.Error(), "не найден") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Default().WithError(err).WithField("patient_id", patientID).Error("GetPatientTimeline: Failed to build patient timeline")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить историю пациента"})
+		return
+	}
+	middleware.MarkPatientAccess(c, uint(patientID))
+	c.JSON(http.StatusOK, timeline)
+}
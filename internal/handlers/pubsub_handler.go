@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/middleware"
+	"ElectronicQueue/internal/pubsub"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PubSubHandler отдает состояние брокера событий и соединений SSE для мониторинга табло.
+type PubSubHandler struct {
+	broker   *pubsub.Broker
+	listener *pubsub.Listener
+	conns    *middleware.SSEConnections
+}
+
+// NewPubSubHandler создает новый экземпляр PubSubHandler.
+func NewPubSubHandler(broker *pubsub.Broker, listener *pubsub.Listener, conns *middleware.SSEConnections) *PubSubHandler {
+	return &PubSubHandler{broker: broker, listener: listener, conns: conns}
+}
+
+// GetStats godoc
+// @Summary      Счетчики брокера событий
+// @Description  Возвращает общее число опубликованных, доставленных и пропущенных событий, число сигналов повторной синхронизации и отключенных медленных клиентов, а также счетчики каждого подключенного клиента SSE.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} pubsub.BrokerStats "Счетчики брокера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/pubsub/stats [get]
+func (h *PubSubHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.broker.Stats())
+}
+
+// GetConnections godoc
+// @Summary      Подключенные клиенты SSE
+// @Description  Возвращает число открытых потоков SSE по эндпоинтам (с разбивкой по кабинетам для табло врача) и по IP-адресам, число подключений, отклоненных из-за лимитов, и число соединений, закрытых как мертвые после неудачной записи.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} middleware.SSEStats "Сводка по соединениям"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/sse/connections [get]
+func (h *PubSubHandler) GetConnections(c *gin.Context) {
+	c.JSON(http.StatusOK, h.conns.Stats())
+}
+
+// GetListenerHealth godoc
+// @Summary      Состояние слушателя уведомлений базы данных
+// @Description  Возвращает, подключен ли слушатель LISTEN/NOTIFY, с какого момента, когда пришло последнее уведомление, последнюю ошибку и число переподключений. Пока соединение не восстановлено, отвечает 503: табло не получают изменений из базы данных.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} pubsub.ListenerHealth "Слушатель подключен"
+// @Failure      503 {object} pubsub.ListenerHealth "Соединение с базой данных потеряно, идет переподключение"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/pubsub/listener [get]
+func (h *PubSubHandler) GetListenerHealth(c *gin.Context) {
+	health := h.listener.Health()
+	if !health.Connected {
+		c.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+	c.JSON(http.StatusOK, health)
+}
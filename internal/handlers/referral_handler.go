@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReferralHandler обрабатывает запросы, связанные с направлениями к специалистам.
+type ReferralHandler struct {
+	service *services.ReferralService
+}
+
+// NewReferralHandler создает новый экземпляр ReferralHandler.
+func NewReferralHandler(service *services.ReferralService) *ReferralHandler {
+	return &ReferralHandler{service: service}
+}
+
+// CreateReferral godoc
+// @Summary      Выдать направление к специалисту
+// @Description  Врач выдает направление пациенту по записи, прием по которой идет сейчас или завершен сегодня. Если срок действия не указан, он определяется срочностью: плановое — 30 дней, срочное — 7, экстренное — 1.
+// @Tags         doctor
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateReferralRequest true "Данные направления"
+// @Success      201 {object} models.Referral "Созданное направление"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса или прием не активен"
+// @Failure      403 {object} map[string]string "Запись относится к приему другого врача"
+// @Failure      404 {object} map[string]string "Запись или специальность не найдены"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/doctor/referrals [post]
+func (h *ReferralHandler) CreateReferral(c *gin.Context) {
+	doctorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ID врача не найден в токене"})
+		return
+	}
+	doctorIDUint, _ := doctorID.(uint)
+
+	var req models.CreateReferralRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	referral, err := h.service.Create(doctorIDUint, &req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "на свой прием"):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "можно выдать") || strings.Contains(err.Error(), "не указан") ||
+			strings.Contains(err.Error(), "формат") || strings.Contains(err.Error(), "в прошлом"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logger.Default().WithError(err).Error("CreateReferral: Failed to create referral")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, referral)
+}
+
+// GetPatientReferrals godoc
+// @Summary      Получить направления пациента
+// @Description  Возвращает направления пациента, новые сначала. По умолчанию — только открытые; status=all возвращает все.
+// @Tags         registrar
+// @Produce      json
+// @Param        patient_id path int true "ID пациента"
+// @Param        status query string false "open (по умолчанию) или all"
+// @Success      200 {array} models.Referral "Список направлений"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID пациента"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/patients/{patient_id}/referrals [get]
+func (h *ReferralHandler) GetPatientReferrals(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("patient_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пациента"})
+		return
+	}
+
+	referrals, err := h.service.GetByPatient(uint(patientID), c.Query("status") != "all")
+	if err != nil {
+		logger.Default().WithError(err).Error("GetPatientReferrals: Failed to get referrals")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.MarkPatientAccess(c, uint(patientID))
+	c.JSON(http.StatusOK, referrals)
+}
+
+// CancelReferral godoc
+// @Summary      Отменить направление
+// @Description  Отменяет открытое направление (например, если пациент отказался).
+// @Tags         registrar
+// @Produce      json
+// @Param        id path int true "ID направления"
+// @Success      200 {object} map[string]string "Направление отменено"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Открытое направление не найдено"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/referrals/{id}/cancel [post]
+func (h *ReferralHandler) CancelReferral(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	if err := h.service.Cancel(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "не найдено") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Default().WithError(err).Error("CancelReferral: Failed to cancel referral")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Направление отменено"})
+}
+
+// GetExpiredReferralsReport godoc
+// @Summary      Отчет по неисполненным направлениям
+// @Description  Возвращает открытые направления, срок действия которых истек, а пациент так и не был записан, с контактами пациента и числом дней просрочки.
+// @Tags         registrar
+// @Produce      json
+// @Success      200 {array} models.ExpiredReferralRow "Строки отчета"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/reports/referrals/expired [get]
+func (h *ReferralHandler) GetExpiredReferralsReport(c *gin.Context) {
+	rows, err := h.service.GetExpiredReport()
+	if err != nil {
+		logger.Default().WithError(err).Error("GetExpiredReferralsReport: Failed to build report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить отчет по направлениям"})
+		return
+	}
+	for _, row := range rows {
+		middleware.MarkPatientAccess(c, row.PatientID)
+	}
+	c.JSON(http.StatusOK, rows)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -183,6 +184,50 @@ func (h *RegistrarHandler) GetDailyReport(c *gin.Context) {
 	c.JSON(http.StatusOK, reportData)
 }
 
+// GetCheckInReport godoc
+// @Summary      Отчет по регистрациям по записи
+// @Description  Возвращает число регистраций через киоск за период по способу идентификации и исходу (вовремя, рано, опоздание) со средним отклонением от начала приема в минутах. По умолчанию — за сегодня.
+// @Tags         registrar
+// @Produce      json
+// @Param        date_from query string false "Начало периода (YYYY-MM-DD)"
+// @Param        date_to query string false "Конец периода (YYYY-MM-DD)"
+// @Success      200 {array} models.CheckInReportRow "Строки отчета"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат даты"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/reports/checkins [get]
+func (h *RegistrarHandler) GetCheckInReport(c *gin.Context) {
+	dateFrom, ok := parseOptionalDate(c, "date_from")
+	if !ok {
+		return
+	}
+	dateTo, ok := parseOptionalDate(c, "date_to")
+	if !ok {
+		return
+	}
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if dateFrom != nil {
+		from = *dateFrom
+	}
+	to := from
+	if dateTo != nil {
+		to = *dateTo
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to не может быть раньше date_from"})
+		return
+	}
+
+	rows, err := h.ticketService.GetCheckInReport(from, to)
+	if err != nil {
+		logger.Default().WithError(err).Error("GetCheckInReport: Failed to get check-in report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить отчет по регистрациям"})
+		return
+	}
+	c.JSON(http.StatusOK, rows)
+}
+
 func (h *RegistrarHandler) GetAllServices(c *gin.Context) {
 	services, err := h.registrarService.GetAllServices()
 	if err != nil {
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoutingHandler обрабатывает запросы на управление прямой маршрутизацией записей в очередь врача.
+type RoutingHandler struct {
+	service *services.RoutingService
+}
+
+// NewRoutingHandler создает новый экземпляр RoutingHandler.
+func NewRoutingHandler(service *services.RoutingService) *RoutingHandler {
+	return &RoutingHandler{service: service}
+}
+
+// GetRoutingRules godoc
+// @Summary      Получить правила прямой маршрутизации (Админ)
+// @Description  Возвращает услуги и специальности, для которых регистрация по записи в киоске сразу направляет пациента в очередь кабинета врача.
+// @Tags         admin
+// @Produce      json
+// @Success      200 {array} models.DirectRoutingRule "Список правил"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/routing-rules [get]
+func (h *RoutingHandler) GetRoutingRules(c *gin.Context) {
+	rules, err := h.service.GetAll()
+	if err != nil {
+		logger.Default().WithError(err).Error("GetRoutingRules: Failed to get routing rules")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRoutingRule godoc
+// @Summary      Создать правило прямой маршрутизации (Админ)
+// @Description  Включает прямую маршрутизацию для услуги терминала (service_id) или специальности врача (specialization): при своевременной регистрации через киоск талон сразу получает статус «зарегистрирован» в очереди кабинета. Указывается ровно одно поле.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateDirectRoutingRuleRequest true "Услуга или специальность"
+// @Success      201 {object} models.DirectRoutingRule "Созданное правило"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      409 {object} map[string]string "Правило уже существует"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/routing-rules [post]
+func (h *RoutingHandler) CreateRoutingRule(c *gin.Context) {
+	var req models.CreateDirectRoutingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	rule, err := h.service.Create(&req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "укажите"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "не найдена"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "уже существует"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.Default().WithError(err).Error("CreateRoutingRule: Failed to create routing rule")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteRoutingRule godoc
+// @Summary      Удалить правило прямой маршрутизации (Админ)
+// @Description  Удаляет правило; записи снова подтверждаются через регистратуру.
+// @Tags         admin
+// @Produce      json
+// @Param        id path int true "ID правила"
+// @Success      200 {object} map[string]string "Правило удалено"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Правило не найдено"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/routing-rules/{id} [delete]
+func (h *RoutingHandler) DeleteRoutingRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "не найдено") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Default().WithError(err).Error("DeleteRoutingRule: Failed to delete routing rule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Правило маршрутизации удалено"})
+}
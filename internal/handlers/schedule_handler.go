@@ -5,11 +5,13 @@ import (
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/pubsub"
 	"ElectronicQueue/internal/services"
-	"encoding/json"
+	"ElectronicQueue/internal/spreadsheet"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,7 +27,7 @@ func NewScheduleHandler(service *services.ScheduleService, broker *pubsub.Broker
 
 // CreateSchedule godoc
 // @Summary      Создать слот в расписании (Админ)
-// @Description  Создает новый временной слот для врача. Требует INTERNAL_API_KEY.
+// @Description  Создает новый временной слот для врача. Для групповых процедур можно указать capacity — число пациентов в слоте (по умолчанию 1). Требует INTERNAL_API_KEY.
 // @Tags         admin
 // @Accept       json
 // @Produce      json
@@ -94,12 +96,209 @@ func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Слот расписания успешно удален"})
 }
 
+// UpdateSchedule godoc
+// @Summary      Заблокировать или разблокировать слот (Админ)
+// @Description  Блокирует или разблокирует слот (is_blocked). Блокировка хранится отдельно от занятости: доступность слота вычисляется как booked_count < capacity и отсутствие блокировки, поэтому освобождение места не снимает блокировку. Разблокированный слот со свободными местами сразу предлагается пациентам из листа ожидания. Требует INTERNAL_API_KEY.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID слота расписания"
+// @Param        request body models.UpdateScheduleRequest true "Новая доступность слота"
+// @Success      200 {object} models.Schedule "Обновленный слот"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      401 {object} map[string]string "Отсутствует ключ API"
+// @Failure      403 {object} map[string]string "Неверный ключ API"
+// @Failure      404 {object} map[string]string "Слот не найден"
+// @Failure      409 {object} map[string]string "Слот занят записью на прием"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/schedules/{id} [patch]
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	log := logger.Default()
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Warn("UpdateSchedule: Invalid ID format")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	var req models.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).Warn("UpdateSchedule: Failed to bind JSON")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	schedule, err := h.service.UpdateScheduleAvailability(uint(id), *req.IsAvailable)
+	if err != nil {
+		log.WithError(err).Error("UpdateSchedule: Failed to update schedule in service")
+		switch {
+		case strings.Contains(err.Error(), "не найден"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "слот занят"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// maxScheduleImportSize ограничивает размер загружаемого файла расписания.
+const maxScheduleImportSize = 5 << 20
+
+// ImportSchedules godoc
+// @Summary      Импорт расписания из CSV/XLSX (Админ)
+// @Description  Загружает файл расписания со столбцами: врач (логин или ФИО), дата, начало, окончание, кабинет. Строка заголовка необязательна. Каждая строка проверяется (неизвестный врач, некорректное время, пересечения с расписанием врача и занятостью кабинета). В режиме dry_run ничего не сохраняется; иначе все корректные строки создаются в одной транзакции. Возвращается результат по каждой строке. Требует INTERNAL_API_KEY.
+// @Tags         admin
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file formData file true "Файл расписания (.csv или .xlsx)"
+// @Param        dry_run query bool false "Только проверить файл, не создавая слоты"
+// @Success      200 {object} models.ScheduleImportReport "Результат импорта по строкам"
+// @Failure      400 {object} map[string]string "Ошибка: файл не передан или не читается"
+// @Failure      401 {object} map[string]string "Отсутствует ключ API"
+// @Failure      403 {object} map[string]string "Неверный ключ API"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/schedules/import [post]
+func (h *ScheduleHandler) ImportSchedules(c *gin.Context) {
+	log := logger.Default()
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверное значение dry_run"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл расписания не передан (поле file)"})
+		return
+	}
+	if fileHeader.Size > maxScheduleImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Файл слишком большой: не более %d МБ", maxScheduleImportSize>>20)})
+		return
+	}
+	format, err := spreadsheet.FormatFromFilename(fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.WithError(err).Error("ImportSchedules: Failed to open uploaded file")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.WithError(err).Error("ImportSchedules: Failed to read uploaded file")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось прочитать файл"})
+		return
+	}
+
+	report, err := h.service.ImportSchedules(format, data, dryRun)
+	if err != nil {
+		if strings.Contains(err.Error(), "CSV") || strings.Contains(err.Error(), "XLSX") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.WithError(err).Error("ImportSchedules: Failed to import schedules")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.WithFields(map[string]interface{}{
+		"file":    fileHeader.Filename,
+		"dry_run": dryRun,
+		"total":   report.Total,
+		"valid":   report.Valid,
+		"created": report.Created,
+	}).Info("ImportSchedules: Schedule file processed")
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportSchedules godoc
+// @Summary      Экспорт расписания в CSV/XLSX (Админ)
+// @Description  Выгружает слоты за период в том же формате, который принимает импорт, что позволяет отредактировать график и загрузить его обратно. По умолчанию — с сегодняшнего дня на 30 дней вперед. Требует INTERNAL_API_KEY.
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format query string false "Формат файла: csv (по умолчанию) или xlsx"
+// @Param        date_from query string false "Начало периода (YYYY-MM-DD)"
+// @Param        date_to query string false "Конец периода (YYYY-MM-DD)"
+// @Param        doctor_id query int false "ID врача"
+// @Success      200 {file} file "Файл расписания"
+// @Failure      400 {object} map[string]string "Ошибка: неверные параметры"
+// @Failure      401 {object} map[string]string "Отсутствует ключ API"
+// @Failure      403 {object} map[string]string "Неверный ключ API"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/admin/schedules/export [get]
+func (h *ScheduleHandler) ExportSchedules(c *gin.Context) {
+	format := spreadsheet.Format(strings.ToLower(c.DefaultQuery("format", string(spreadsheet.FormatCSV))))
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат, используйте csv или xlsx"})
+		return
+	}
+
+	dateFrom, ok := parseOptionalDate(c, "date_from")
+	if !ok {
+		return
+	}
+	dateTo, ok := parseOptionalDate(c, "date_to")
+	if !ok {
+		return
+	}
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if dateFrom != nil {
+		from = *dateFrom
+	}
+	to := from.AddDate(0, 0, 30)
+	if dateTo != nil {
+		to = *dateTo
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to не может быть раньше date_from"})
+		return
+	}
+
+	var doctorID *uint
+	if v := c.Query("doctor_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат doctor_id"})
+			return
+		}
+		uid := uint(id)
+		doctorID = &uid
+	}
+
+	data, err := h.service.ExportSchedules(format, from, to, doctorID)
+	if err != nil {
+		logger.Default().WithError(err).Error("ExportSchedules: Failed to export schedules")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("schedule_%s_%s.%s", from.Format("2006-01-02"), to.Format("2006-01-02"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, spreadsheet.ContentType(format), data)
+}
+
 // GetTodayScheduleUpdates godoc
 // @Summary      Получить обновления расписания на сегодня
-// @Description  Отправляет начальное состояние расписания (`event: schedule_initial`) и последующие изменения (`event: schedule_update`) через Server-Sent Events.
+// @Description  Отправляет начальное состояние расписания (`event: schedule_initial`) и последующие изменения (`event: schedule_update`) через Server-Sent Events. У каждого события есть ID; при переподключении с заголовком Last-Event-ID сервер досылает пропущенные изменения, а если их уже нет в буфере — снова отправляет schedule_initial. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat; клиент, не принимающий данные дольше SSE_WRITE_TIMEOUT, отключается.
 // @Tags         schedule
 // @Produce      text/event-stream
+// @Param        Last-Event-ID header int false "ID последнего полученного события"
 // @Success      200 {object} services.TodayScheduleResponse "Поток событий с состоянием расписания"
+// @Failure      429 {object} map[string]string "Превышен лимит подключений"
 // @Router       /api/schedules/today/updates [get]
 func (h *ScheduleHandler) GetTodayScheduleUpdates(c *gin.Context) {
 	c.Header("Content-Type", "text/event-stream")
@@ -107,10 +306,21 @@ func (h *ScheduleHandler) GetTodayScheduleUpdates(c *gin.Context) {
 	c.Header("Connection", "keep-alive")
 	log := logger.Default().WithField("module", "SSE_SCHEDULE")
 
-	clientChan := h.broker.Subscribe()
-	defer h.broker.Unsubscribe(clientChan)
+	sub, missed, resumed := subscribeSSE(c, h.broker, pubsub.Filter{Topics: []pubsub.Topic{pubsub.TopicSchedule}})
+	defer h.broker.Unsubscribe(sub)
+
+	// --- 1. Отправка пропущенных изменений или начального состояния ---
+	if resumed {
+		log.WithField("missed", len(missed)).Info("Клиент переподключился, отправка пропущенных изменений расписания")
+		for _, event := range missed {
+			if !writeSSE(c, event.ID, event.Name, event.Data) {
+				return
+			}
+		}
+		h.streamScheduleUpdates(c, sub)
+		return
+	}
 
-	// --- 1. Отправка начального состояния ---
 	initialState, err := h.service.GetTodayScheduleState()
 	if err != nil {
 		log.WithError(err).Error("Критическая ошибка в GetTodayScheduleState")
@@ -122,43 +332,44 @@ func (h *ScheduleHandler) GetTodayScheduleUpdates(c *gin.Context) {
 	}
 
 	log.Info("Отправка начального состояния расписания")
-	c.SSEvent("schedule_initial", initialState)
-	if f, ok := c.Writer.(http.Flusher); ok {
-		f.Flush()
-		_, err := c.Writer.Write([]byte{})
-		if err != nil {
-			log.WithError(err).Info("Клиент отключился сразу после отправки начального состояния.")
-			return
-		}
+	if !writeSSE(c, sub.Cursor, "schedule_initial", initialState) {
+		log.Info("Клиент отключился сразу после отправки начального состояния.")
+		return
 	}
 
 	// --- 2. Ожидание и отправка обновлений ---
+	h.streamScheduleUpdates(c, sub)
+}
+
+// streamScheduleUpdates передает клиенту изменения расписания до его отключения.
+func (h *ScheduleHandler) streamScheduleUpdates(c *gin.Context, sub *pubsub.Subscriber) {
+	log := logger.Default().WithField("module", "SSE_SCHEDULE")
 	c.Stream(func(w io.Writer) bool {
 		select {
-		case msg, ok := <-clientChan:
+		case event, ok := <-sub.Events:
 			if !ok {
 				log.Info("Канал уведомлений закрыт для расписания.")
 				return false
 			}
 
-			if !strings.Contains(msg, "\"operation\"") {
-				return true
-			}
-
-			log.WithField("payload", msg).Info("Получено уведомление, отправка обновления клиенту.")
+			log.WithField("event", event.Name).Info("Получено уведомление, отправка обновления клиенту.")
+			return writeSSE(c, event.ID, event.Name, event.Data)
 
-			var rawData json.RawMessage
-			if err := json.Unmarshal([]byte(msg), &rawData); err != nil {
-				log.WithError(err).Warn("Получено невалидное JSON-уведомление от PostgreSQL, пропуск.")
-				return true
+		case <-sub.Resync:
+			// Часть событий пропущена: клиент получает расписание на сегодня целиком.
+			sub.Drain()
+			log.Warn("События расписания пропущены, повторная синхронизация.")
+			id := h.broker.LastID()
+			state, err := h.service.GetTodayScheduleState()
+			if err != nil {
+				log.WithError(err).Error("Не удалось получить расписание для повторной синхронизации")
+				return false
 			}
+			return writeSSE(c, id, pubsub.EventResync, gin.H{"reason": "events_dropped"}) &&
+				writeSSE(c, id, "schedule_initial", state)
 
-			c.SSEvent("schedule_update", rawData)
-
-			if f, ok := w.(http.Flusher); ok {
-				f.Flush()
-			}
-			return true
+		case <-sseHeartbeats(c):
+			return writeHeartbeat(c)
 
 		case <-c.Request.Context().Done():
 			log.Info("Клиент отключился от расписания.")
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/middleware"
+	"ElectronicQueue/internal/pubsub"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// lastEventID читает ID последнего полученного клиентом события из заголовка Last-Event-ID,
+// который браузер передает при автоматическом переподключении EventSource.
+func lastEventID(c *gin.Context) (uint64, bool) {
+	raw := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if raw == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	return id, err == nil
+}
+
+// subscribeSSE подписывает клиента SSE на события. Переподключившемуся клиенту возвращаются пропущенные
+// события; resumed=false означает, что продолжить поток нельзя и клиенту нужно полное состояние.
+func subscribeSSE(c *gin.Context, broker *pubsub.Broker, filter pubsub.Filter) (*pubsub.Subscriber, []pubsub.Event, bool) {
+	if lastID, ok := lastEventID(c); ok {
+		return broker.Resume(filter, lastID)
+	}
+	return broker.Subscribe(filter), nil, false
+}
+
+// writeSSE отправляет событие SSE с ID, по которому клиент сможет продолжить поток после переподключения.
+// Возвращает false, если запись не удалась: соединение мертво и поток нужно завершить.
+func writeSSE(c *gin.Context, id uint64, name string, data interface{}) bool {
+	return writeWithDeadline(c, func(w io.Writer) error {
+		return sse.Encode(w, sse.Event{Id: strconv.FormatUint(id, 10), Event: name, Data: data})
+	})
+}
+
+// writeHeartbeat отправляет комментарий SSE, который клиент игнорирует, а прокси считает активностью.
+func writeHeartbeat(c *gin.Context) bool {
+	return writeWithDeadline(c, func(w io.Writer) error {
+		_, err := io.WriteString(w, ": ping\n\n")
+		return err
+	})
+}
+
+// writeWithDeadline выполняет запись и сброс буфера с таймаутом соединения, заданным middleware.LimitSSE.
+// Клиент, который не принимает данные до истечения таймаута, считается отключившимся.
+func writeWithDeadline(c *gin.Context, write func(w io.Writer) error) bool {
+	client := middleware.SSEClientFrom(c)
+	rc := http.NewResponseController(c.Writer)
+	if deadline := client.WriteDeadline(); !deadline.IsZero() {
+		_ = rc.SetWriteDeadline(deadline)
+		defer rc.SetWriteDeadline(time.Time{})
+	}
+	if err := write(c.Writer); err != nil {
+		client.MarkDead()
+		return false
+	}
+	if err := rc.Flush(); err != nil {
+		client.MarkDead()
+		return false
+	}
+	return true
+}
+
+// sseHeartbeats возвращает канал heartbeat соединения.
+func sseHeartbeats(c *gin.Context) <-chan time.Time {
+	return middleware.SSEClientFrom(c).Heartbeats()
+}
@@ -4,9 +4,12 @@ import (
 	"ElectronicQueue/internal/config"
 	"ElectronicQueue/internal/logger"
 	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/pubsub"
 	"ElectronicQueue/internal/services"
 	"ElectronicQueue/internal/utils"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -23,11 +26,12 @@ import (
 // @Produce      json
 type TicketHandler struct {
 	service *services.TicketService
+	broker  *pubsub.Broker
 	config  *config.Config
 }
 
-func NewTicketHandler(service *services.TicketService, cfg *config.Config) *TicketHandler {
-	return &TicketHandler{service: service, config: cfg}
+func NewTicketHandler(service *services.TicketService, broker *pubsub.Broker, cfg *config.Config) *TicketHandler {
+	return &TicketHandler{service: service, broker: broker, config: cfg}
 }
 
 type ServiceSelectionRequest struct {
@@ -49,10 +53,24 @@ type ConfirmationResponse struct {
 	TicketNumber string `json:"ticket_number,omitempty" example:"A001"`
 	Message      string `json:"message" example:"Ваш электронный талон"`
 	Timeout      int    `json:"timeout" example:"10"`
+	// Cabinet, AppointmentTime и DoctorName заполняются, если талон сразу направлен в очередь кабинета врача.
+	Cabinet         *int   `json:"cabinet,omitempty" example:"101"`
+	AppointmentTime string `json:"appointment_time,omitempty" example:"14:30"`
+	DoctorName      string `json:"doctor_name,omitempty" example:"Иванов Иван Иванович"`
 }
 
 type CheckInByPhoneRequest struct {
-	Phone string `json:"phone" binding:"required"`
+	Phone     string `json:"phone" binding:"required"`
+	BirthYear *int   `json:"birth_year,omitempty" binding:"omitempty,min=1900,max=2100" example:"1985"`
+}
+
+// CheckInErrorResponse описывает отказ в регистрации. При неоднозначном совпадении возвращается
+// действие confirm_birth_year, и киоск должен повторить запрос с годом рождения.
+// Если пациент пришел слишком рано, возвращается действие come_back_later и время, когда открывается регистрация.
+type CheckInErrorResponse struct {
+	Error      string `json:"error" example:"найдено несколько пациентов с записями на сегодня, подтвердите год рождения"`
+	Action     string `json:"action,omitempty" example:"confirm_birth_year"`
+	ComeBackAt string `json:"come_back_at,omitempty" example:"13:30"`
 }
 
 // StartPage godoc
@@ -142,51 +160,12 @@ func (h *TicketHandler) Confirmation(c *gin.Context) {
 	serviceName := h.service.MapServiceIDToName(req.ServiceID)
 
 	if req.Action == "print_ticket" {
-		height := 800
-		if h.config != nil && h.config.TicketHeight != "" {
-			if parsed, err := strconv.Atoi(h.config.TicketHeight); err == nil {
-				height = parsed
-			}
-		}
-		qrData := []byte(fmt.Sprintf("Талон: %s\nВремя: %s\nУслуга: %s",
-			ticket.TicketNumber,
-			ticket.CreatedAt.Format("02.01.2006 15:04:05"),
-			serviceName))
-		imageBytes, err := h.service.GenerateTicketImage(height, ticket, serviceName, h.config.TicketMode, qrData)
-		if err != nil {
-			logger.Default().Error(fmt.Sprintf("Confirmation: image generation failed: %v", err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Image generation failed: %v", err)})
-			return
-		}
-
-		ticket.QRCode = qrData
-		if err := h.service.UpdateTicket(ticket); err != nil {
-			logger.Default().Error(fmt.Sprintf("Confirmation: failed to update ticket with image: %v", err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update ticket with image"})
-			return
-		}
-
-		dir := h.config.TicketDir
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			logger.Default().Error(fmt.Sprintf("Confirmation: failed to create tickets directory: %v", err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create tickets directory"})
-			return
-		}
-
-		filePath := filepath.Join(dir, ticket.TicketNumber+".png")
-		if err := os.WriteFile(filePath, imageBytes, 0644); err != nil {
-			logger.Default().Error(fmt.Sprintf("Confirmation: failed to save image: %v", err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save image"})
+		if err := h.printTicket(ticket, serviceName, ""); err != nil {
+			logger.Default().Error(fmt.Sprintf("Confirmation: %v", err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		printerName := h.config.PrinterName
-		if printerName != "" {
-			if err := utils.PrintFile(printerName, filePath); err != nil {
-				logger.Default().Error(fmt.Sprintf("Confirmation: failed to print ticket: %v", err))
-			}
-		}
-
 		resp := ConfirmationResponse{
 			ServiceName:  serviceName,
 			TicketNumber: ticket.TicketNumber,
@@ -208,14 +187,15 @@ func (h *TicketHandler) Confirmation(c *gin.Context) {
 
 // CheckInByPhone godoc
 // @Summary      Регистрация на прием по номеру телефона
-// @Description  Проверяет наличие записи по номеру телефона и выдает приоритетный талон, если прием скоро
+// @Description  Проверяет наличие записи по номеру телефона и выдает приоритетный талон, если прием скоро. Слишком раннему пациенту сообщается, к какому времени подойти; опоздавший получает талон в регистратуру с отметкой is_late. Если на номер записано несколько пациентов, возвращается 409 с action=confirm_birth_year — повторите запрос с birth_year.
 // @Tags         tickets
 // @Accept       json
 // @Produce      json
 // @Param        request body CheckInByPhoneRequest true "Номер телефона пациента"
 // @Success      200 {object} ConfirmationResponse "Ответ с данными талона"
 // @Failure      400 {object} map[string]string "Ошибка: не передан номер телефона"
-// @Failure      404 {object} map[string]string "Запись не найдена или еще не время"
+// @Failure      404 {object} CheckInErrorResponse "Запись не найдена или еще не время (action=come_back_later, come_back_at)"
+// @Failure      409 {object} CheckInErrorResponse "Требуется подтвердить год рождения"
 // @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router       /api/tickets/appointment/phone [post]
 func (h *TicketHandler) CheckInByPhone(c *gin.Context) {
@@ -225,25 +205,141 @@ func (h *TicketHandler) CheckInByPhone(c *gin.Context) {
 		return
 	}
 
-	ticket, err := h.service.CheckInByPhone(req.Phone)
+	result, err := h.service.CheckInByPhone(req.Phone, req.BirthYear)
 	if err != nil {
-		if strings.Contains(err.Error(), "не найден") || strings.Contains(err.Error(), "нет предстоящих записей") || strings.Contains(err.Error(), "ваша запись на") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		respondCheckInError(c, err)
+		return
+	}
+	h.respondCheckIn(c, result, "")
+}
+
+// CheckIn godoc
+// @Summary      Регистрация на прием по записи
+// @Description  Идентифицирует пациента по телефону, полису ОМС (номер или содержимое штрих-кода полиса), серии и номеру паспорта или QR-коду из подтверждения записи и выдает талон. Регистрация открывается за CHECKIN_EARLY_WINDOW до начала приема: слишком раннему пациенту сообщается, к какому времени подойти. Пациент, опоздавший больше чем на CHECKIN_LATE_WINDOW, получает талон в регистратуру с отметкой is_late. Если данным соответствует несколько пациентов с записями на сегодня, возвращается 409 с action=confirm_birth_year — повторите запрос с birth_year. Если для услуги или специальности врача настроена прямая маршрутизация, талон сразу попадает в очередь кабинета, а в ответе возвращаются кабинет, время приема и врач. С action=print_ticket талон печатается.
+// @Tags         tickets
+// @Accept       json
+// @Produce      json
+// @Param        request body models.KioskCheckInRequest true "Способ идентификации и данные пациента"
+// @Success      200 {object} ConfirmationResponse "Ответ с данными талона"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса или данные не распознаны"
+// @Failure      404 {object} CheckInErrorResponse "Пациент или запись не найдены либо еще не время (action=come_back_later, come_back_at)"
+// @Failure      409 {object} CheckInErrorResponse "Требуется подтвердить год рождения"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router       /api/tickets/appointment/checkin [post]
+func (h *TicketHandler) CheckIn(c *gin.Context) {
+	var req models.KioskCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
 		return
 	}
 
+	result, err := h.service.CheckIn(&req)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+	h.respondCheckIn(c, result, req.Action)
+}
+
+func (h *TicketHandler) respondCheckIn(c *gin.Context, result *models.CheckInResult, action string) {
+	ticket := result.Ticket
+	serviceName := h.service.MapServiceIDToName(*ticket.ServiceType)
 	resp := ConfirmationResponse{
-		ServiceName:  h.service.MapServiceIDToName(*ticket.ServiceType),
+		ServiceName:  serviceName,
 		TicketNumber: ticket.TicketNumber,
 		Message:      "Ваш электронный талон",
 		Timeout:      10,
 	}
+
+	footer := ""
+	switch {
+	case ticket.IsLate:
+		resp.Message = "Вы опоздали к началу приема. Пожалуйста, обратитесь в регистратуру"
+	case result.DirectToDoctor:
+		appointmentTime := result.AppointmentTime.Format("15:04")
+		resp.AppointmentTime = appointmentTime
+		resp.DoctorName = result.DoctorName
+		resp.Cabinet = result.Cabinet
+		if result.Cabinet != nil {
+			resp.Message = fmt.Sprintf("Проходите к кабинету %d, прием в %s", *result.Cabinet, appointmentTime)
+			footer = fmt.Sprintf("Кабинет %d · прием в %s", *result.Cabinet, appointmentTime)
+		} else {
+			resp.Message = fmt.Sprintf("Проходите к кабинету врача, прием в %s", appointmentTime)
+			footer = fmt.Sprintf("Прием в %s", appointmentTime)
+		}
+	}
+
+	if action == "print_ticket" {
+		if err := h.printTicket(ticket, serviceName, footer); err != nil {
+			// Талон уже создан и привязан к записи, поэтому ошибка печати не отменяет регистрацию.
+			logger.Default().Error(fmt.Sprintf("CheckIn: %v", err))
+		} else {
+			resp.Timeout = 5
+		}
+	}
 	c.JSON(http.StatusOK, resp)
 }
 
+// printTicket генерирует изображение талона, сохраняет его в каталог талонов и отправляет на принтер.
+func (h *TicketHandler) printTicket(ticket *models.Ticket, serviceName, footer string) error {
+	height := 800
+	if h.config != nil && h.config.TicketHeight != "" {
+		if parsed, err := strconv.Atoi(h.config.TicketHeight); err == nil {
+			height = parsed
+		}
+	}
+	qrData := []byte(fmt.Sprintf("Талон: %s\nВремя: %s\nУслуга: %s",
+		ticket.TicketNumber,
+		ticket.CreatedAt.Format("02.01.2006 15:04:05"),
+		serviceName))
+	imageBytes, err := h.service.GenerateTicketImage(height, ticket, serviceName, h.config.TicketMode, qrData, footer)
+	if err != nil {
+		return fmt.Errorf("image generation failed: %v", err)
+	}
+
+	ticket.QRCode = qrData
+	if err := h.service.UpdateTicket(ticket); err != nil {
+		return fmt.Errorf("failed to update ticket with image: %v", err)
+	}
+
+	dir := h.config.TicketDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create tickets directory: %v", err)
+	}
+
+	filePath := filepath.Join(dir, ticket.TicketNumber+".png")
+	if err := os.WriteFile(filePath, imageBytes, 0644); err != nil {
+		return fmt.Errorf("failed to save image: %v", err)
+	}
+
+	printerName := h.config.PrinterName
+	if printerName != "" {
+		if err := utils.PrintFile(printerName, filePath); err != nil {
+			logger.Default().Error(fmt.Sprintf("printTicket: failed to print ticket: %v", err))
+		}
+	}
+	return nil
+}
+
+func respondCheckInError(c *gin.Context, err error) {
+	msg := err.Error()
+	var tooEarly *services.CheckInTooEarlyError
+	switch {
+	case errors.As(err, &tooEarly):
+		c.JSON(http.StatusNotFound, CheckInErrorResponse{Error: msg, Action: "come_back_later", ComeBackAt: tooEarly.OpensAt.Format("15:04")})
+	case strings.Contains(msg, "подтвердите год рождения"):
+		c.JSON(http.StatusConflict, CheckInErrorResponse{Error: msg, Action: "confirm_birth_year"})
+	case strings.Contains(msg, "не найден") || strings.Contains(msg, "нет предстоящих записей") || strings.Contains(msg, "ваша запись на"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.Contains(msg, "не распознан") || strings.Contains(msg, "недействителен") || strings.Contains(msg, "не указан") || strings.Contains(msg, "некорректный номер") ||
+		strings.Contains(msg, "должны содержать") || strings.Contains(msg, "уже зарегистрированы") || strings.Contains(msg, "обратитесь в регистратуру"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	default:
+		logger.Default().WithError(err).Error("CheckIn: Failed to check in patient")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
+
 // DownloadTicket godoc
 // @Summary      Скачать изображение талона
 // @Description  Позволяет скачать изображение талона по номеру
@@ -329,3 +425,89 @@ func (h *TicketHandler) GetAllActive(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// ReceptionUpdates godoc
+// @Summary      Поток изменений талонов для табло регистратуры
+// @Description  Отправляет через Server-Sent Events изменения талонов: событие insert, update или delete с данными талона. У каждого события есть ID; при переподключении браузер передает последний полученный ID в заголовке Last-Event-ID, и сервер досылает пропущенные события. Если пропущенных событий уже нет в буфере, отправляется событие snapshot со списком активных талонов. Событие resync означает, что клиент не успевал получать события; за ним следует snapshot. Каждые SSE_HEARTBEAT_INTERVAL отправляется комментарий-heartbeat; клиент, не принимающий данные дольше SSE_WRITE_TIMEOUT, отключается.
+// @Tags         tickets
+// @Produce      text/event-stream
+// @Param        window query int false "Только талоны указанного окна регистратуры"
+// @Param        Last-Event-ID header int false "ID последнего полученного события"
+// @Success      200 {object} models.TicketResponse "Поток событий"
+// @Failure      400 {object} map[string]string "Неверный номер окна"
+// @Failure      429 {object} map[string]string "Превышен лимит подключений"
+// @Router       /tickets [get]
+func (h *TicketHandler) ReceptionUpdates(c *gin.Context) {
+	filter := pubsub.Filter{Topics: []pubsub.Topic{pubsub.TopicTicket}}
+	if windowStr := c.Query("window"); windowStr != "" {
+		window, err := strconv.Atoi(windowStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный номер окна"})
+			return
+		}
+		filter.Window = &window
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	log := logger.Default().WithField("handler_id", "reception_sse")
+
+	sub, missed, resumed := subscribeSSE(c, h.broker, filter)
+	defer h.broker.Unsubscribe(sub)
+
+	if _, reconnected := lastEventID(c); reconnected && !resumed {
+		log.Info("SSE Handler: Missed events are no longer buffered, sending snapshot")
+		if !h.sendActiveSnapshot(c, sub.Cursor, filter.Window) {
+			return
+		}
+	}
+	for _, event := range missed {
+		if !writeSSE(c, event.ID, event.Name, event.Data) {
+			return
+		}
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				log.Info("Client channel closed.")
+				return false
+			}
+			log.WithField("event", event.Name).Info("SSE Handler: Sending message to client")
+			return writeSSE(c, event.ID, event.Name, event.Data)
+
+		case <-sub.Resync:
+			sub.Drain()
+			log.Warn("SSE Handler: Events dropped, resyncing client")
+			id := h.broker.LastID()
+			return writeSSE(c, id, pubsub.EventResync, gin.H{"reason": "events_dropped"}) &&
+				h.sendActiveSnapshot(c, id, filter.Window)
+
+		case <-sseHeartbeats(c):
+			return writeHeartbeat(c)
+
+		case <-c.Request.Context().Done():
+			log.Info("Client disconnected.")
+			return false
+		}
+	})
+}
+
+// sendActiveSnapshot отправляет событие snapshot со списком активных талонов (при window — только этого окна).
+func (h *TicketHandler) sendActiveSnapshot(c *gin.Context, id uint64, window *int) bool {
+	tickets, err := h.service.GetAllActiveTickets()
+	if err != nil {
+		logger.Default().WithError(err).Error("ReceptionUpdates: Failed to get active tickets for snapshot")
+		return false
+	}
+	response := make([]models.TicketResponse, 0, len(tickets))
+	for _, t := range tickets {
+		if window != nil && (t.WindowNumber == nil || *t.WindowNumber != *window) {
+			continue
+		}
+		response = append(response, t.ToResponse())
+	}
+	return writeSSE(c, id, "snapshot", response)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/middleware"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WaitlistHandler обрабатывает HTTP-запросы для листа ожидания.
+type WaitlistHandler struct {
+	service *services.WaitlistService
+}
+
+// NewWaitlistHandler создает новый экземпляр WaitlistHandler.
+func NewWaitlistHandler(service *services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{service: service}
+}
+
+// ReleaseHoldRequest описывает запрос на снятие удержания слота.
+type ReleaseHoldRequest struct {
+	KeepWaiting bool `json:"keep_waiting" example:"true"`
+}
+
+// CreateEntry godoc
+// @Summary      Поставить пациента в лист ожидания
+// @Description  Создает заявку в листе ожидания к конкретному врачу или на специальность с желаемым периодом дат. Когда подходящий слот освобождается, он удерживается за пациентом и ему отправляется предложение.
+// @Tags         registrar
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateWaitlistEntryRequest true "Данные заявки"
+// @Success      201 {object} models.WaitlistEntry "Созданная заявка"
+// @Failure      400 {object} map[string]string "Ошибка: неверный формат запроса"
+// @Failure      404 {object} map[string]string "Пациент или врач не найден"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/waitlist [post]
+func (h *WaitlistHandler) CreateEntry(c *gin.Context) {
+	log := logger.Default()
+
+	var req models.CreateWaitlistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.WithError(err).Warn("CreateWaitlistEntry: Failed to bind JSON")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+		return
+	}
+
+	entry, err := h.service.CreateEntry(&req)
+	if err != nil {
+		log.WithError(err).Error("CreateWaitlistEntry: Failed to create entry in service")
+		if strings.Contains(err.Error(), "не найден") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.Contains(err.Error(), "необходимо указать") || strings.Contains(err.Error(), "не может быть раньше") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	middleware.MarkPatientAccess(c, entry.PatientID)
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetEntries godoc
+// @Summary      Получить лист ожидания
+// @Description  Возвращает заявки листа ожидания в порядке постановки. Без фильтра возвращает активные заявки ('ожидает' и 'предложено'), status=all возвращает все.
+// @Tags         registrar
+// @Produce      json
+// @Param        status query string false "Статус заявки: ожидает, предложено, подтверждено, отменено или all"
+// @Success      200 {array} models.WaitlistEntry "Массив заявок"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/waitlist [get]
+func (h *WaitlistHandler) GetEntries(c *gin.Context) {
+	entries, err := h.service.GetEntries(c.Query("status"))
+	if err != nil {
+		logger.Default().WithError(err).Error("GetWaitlistEntries: Failed to get entries from service")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить лист ожидания"})
+		return
+	}
+
+	if entries == nil {
+		entries = []models.WaitlistEntry{}
+	}
+	for _, entry := range entries {
+		middleware.MarkPatientAccess(c, entry.PatientID)
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// ConfirmHold godoc
+// @Summary      Подтвердить предложенный слот
+// @Description  Создает запись на прием в слот, удерживаемый за заявкой из листа ожидания. Если запись нарушает правила, удержание снимается, заявка возвращается в лист ожидания, а слот предлагается следующему пациенту.
+// @Tags         registrar
+// @Produce      json
+// @Param        id path int true "ID заявки"
+// @Success      200 {object} models.Appointment "Созданная запись на прием"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Заявка не найдена"
+// @Failure      409 {object} BookingRulesErrorResponse "За заявкой нет удерживаемого слота или запись нарушает правила"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/waitlist/{id}/confirm [post]
+func (h *WaitlistHandler) ConfirmHold(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	appointment, err := h.service.ConfirmHold(uint(id))
+	if err != nil {
+		logger.Default().WithError(err).Error("ConfirmWaitlistHold: Failed to confirm hold")
+		if respondBookingRulesViolation(c, err) {
+			return
+		}
+		respondWaitlistError(c, err)
+		return
+	}
+
+	if appointment.PatientID != nil {
+		middleware.MarkPatientAccess(c, *appointment.PatientID)
+	}
+	c.JSON(http.StatusOK, appointment)
+}
+
+// ReleaseHold godoc
+// @Summary      Снять удержание предложенного слота
+// @Description  Освобождает слот, удерживаемый за заявкой, и предлагает его следующему пациенту. При keep_waiting=true заявка остается в листе ожидания, иначе отменяется.
+// @Tags         registrar
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID заявки"
+// @Param        request body ReleaseHoldRequest false "Параметры снятия удержания"
+// @Success      200 {object} map[string]string "Удержание снято"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Заявка не найдена"
+// @Failure      409 {object} map[string]string "За заявкой нет удерживаемого слота"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/waitlist/{id}/release [post]
+func (h *WaitlistHandler) ReleaseHold(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	var req ReleaseHoldRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса: " + err.Error()})
+			return
+		}
+	}
+
+	if err := h.service.ReleaseHold(uint(id), req.KeepWaiting); err != nil {
+		logger.Default().WithError(err).Error("ReleaseWaitlistHold: Failed to release hold")
+		respondWaitlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Удержание слота снято"})
+}
+
+// CancelEntry godoc
+// @Summary      Отменить заявку в листе ожидания
+// @Description  Отменяет заявку. Если за ней удерживается слот, он освобождается и предлагается следующему пациенту.
+// @Tags         registrar
+// @Produce      json
+// @Param        id path int true "ID заявки"
+// @Success      200 {object} map[string]string "Заявка отменена"
+// @Failure      400 {object} map[string]string "Ошибка: неверный ID"
+// @Failure      404 {object} map[string]string "Заявка не найдена"
+// @Failure      409 {object} map[string]string "Заявка уже закрыта"
+// @Failure      500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Router       /api/registrar/waitlist/{id} [delete]
+func (h *WaitlistHandler) CancelEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		return
+	}
+
+	if err := h.service.CancelEntry(uint(id)); err != nil {
+		logger.Default().WithError(err).Error("CancelWaitlistEntry: Failed to cancel entry")
+		respondWaitlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка отменена"})
+}
+
+func respondWaitlistError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "не найдена"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "нет удерживаемого слота") || strings.Contains(err.Error(), "уже закрыта") || strings.Contains(err.Error(), "удален из расписания"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// displayKey — ключ контекста, в котором RequireDisplayToken передает обработчику устройство.
+const displayKey = "display"
+
+// RequireDisplayToken проверяет токен устройства отображения из заголовка X-Display-Token.
+// Для потоков SSE, где браузер не позволяет задать заголовок, токен можно передать параметром token.
+func RequireDisplayToken(service *services.DisplayService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("X-Display-Token")
+		if token == "" {
+			token = c.Query("token")
+		}
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Токен устройства не указан"})
+			return
+		}
+
+		display, err := service.Authenticate(token)
+		if err != nil {
+			if strings.Contains(err.Error(), "не найдено") {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Неверный токен устройства"})
+				return
+			}
+			logger.Default().WithError(err).Error("RequireDisplayToken: Failed to authenticate display")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Не удалось проверить токен устройства"})
+			return
+		}
+		c.Set(displayKey, display)
+		c.Next()
+	}
+}
+
+// DisplayFrom возвращает устройство, авторизованное RequireDisplayToken.
+func DisplayFrom(c *gin.Context) *models.Display {
+	if value, ok := c.Get(displayKey); ok {
+		return value.(*models.Display)
+	}
+	return nil
+}
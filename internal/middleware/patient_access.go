@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// patientAccessKey — ключ контекста, в котором обработчик передает ID пациентов, чьи данные вернул.
+const patientAccessKey = "patient_access_ids"
+
+// MarkPatientAccess отмечает, что ответ содержит персональные данные указанных пациентов.
+// Обработчик вызывает его перед отправкой успешного ответа; запись в журнал делает AuditPatientAccess.
+func MarkPatientAccess(c *gin.Context, patientIDs ...uint) {
+	if len(patientIDs) == 0 {
+		return
+	}
+	if existing, ok := c.Get(patientAccessKey); ok {
+		patientIDs = append(existing.([]uint), patientIDs...)
+	}
+	c.Set(patientAccessKey, patientIDs)
+}
+
+// AuditPatientAccess записывает в журнал доступа чтение персональных данных пациентов, отмеченное обработчиком
+// через MarkPatientAccess. Пишутся только успешные ответы. Роль и ID пользователя берутся из JWT; для групп
+// с доступом по ключу API роль задается defaultRole, а ID не заполняется.
+func AuditPatientAccess(accessService *services.PatientAccessService, defaultRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		value, ok := c.Get(patientAccessKey)
+		if !ok || c.Writer.Status() >= 400 {
+			return
+		}
+		role := defaultRole
+		if r, ok := c.Get("role"); ok {
+			if roleStr, ok := r.(string); ok && roleStr != "" {
+				role = roleStr
+			}
+		}
+		var actorID *uint
+		if id, ok := c.Get("user_id"); ok {
+			if idUint, ok := id.(uint); ok {
+				actorID = &idUint
+			}
+		}
+
+		endpoint := c.Request.Method + " " + c.FullPath()
+		if err := accessService.Record(role, actorID, endpoint, c.ClientIP(), value.([]uint)); err != nil {
+			logger.Default().WithError(err).WithField("endpoint", endpoint).Error("AuditPatientAccess: Failed to record patient data access")
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitByAPIKey ограничивает число запросов с одним API-ключом за фиксированное окно времени.
+// Должен подключаться после RequireAPIKey, чтобы счетчики заводились только для проверенных ключей.
+func RateLimitByAPIKey(limit int, window time.Duration) gin.HandlerFunc {
+	limiter := &windowLimiter{
+		limit:    limit,
+		window:   window,
+		counters: make(map[string]*windowCounter),
+	}
+
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.allow(c.GetHeader("X-API-KEY"), time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+type windowCounter struct {
+	start time.Time
+	count int
+}
+
+type windowLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	counters map[string]*windowCounter
+}
+
+// allow учитывает запрос и сообщает, укладывается ли он в лимит.
+// Если нет, возвращает время до начала следующего окна.
+func (l *windowLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counter, ok := l.counters[key]
+	if !ok || now.Sub(counter.start) >= l.window {
+		l.counters[key] = &windowCounter{start: now, count: 1}
+		return true, 0
+	}
+	if counter.count >= l.limit {
+		return false, counter.start.Add(l.window).Sub(now)
+	}
+	counter.count++
+	return true, 0
+}
@@ -0,0 +1,266 @@
+package middleware
+
+import (
+	"ElectronicQueue/internal/logger"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseClientKey — ключ контекста, в котором LimitSSE передает обработчику зарегистрированное соединение.
+const sseClientKey = "sse_client"
+
+// SSEConnections учитывает открытые соединения SSE, ограничивает их число на эндпоинт и на IP-адрес
+// и задает соединениям период heartbeat и таймаут записи.
+type SSEConnections struct {
+	heartbeat      time.Duration
+	writeTimeout   time.Duration
+	maxPerEndpoint int
+	maxPerIP       int
+
+	mu          sync.Mutex
+	nextID      uint64
+	clients     map[uint64]*SSEClient
+	perEndpoint map[string]int
+	perIP       map[string]int
+	rejected    map[string]uint64
+
+	dead atomic.Uint64
+}
+
+// SSEClient — открытое соединение SSE.
+type SSEClient struct {
+	ID          uint64
+	Endpoint    string
+	ClientIP    string
+	Cabinet     *int
+	Window      *int
+	ConnectedAt time.Time
+
+	writeTimeout time.Duration
+	ticker       *time.Ticker
+	owner        *SSEConnections
+	dead         atomic.Bool
+}
+
+// NewSSEConnections создает учет соединений SSE. heartbeat и writeTimeout задаются строками вида "15s";
+// нулевой лимит отключает соответствующее ограничение.
+func NewSSEConnections(heartbeat, writeTimeout string, maxPerEndpoint, maxPerIP int) (*SSEConnections, error) {
+	heartbeatInterval, err := time.ParseDuration(heartbeat)
+	if err != nil || heartbeatInterval <= 0 {
+		return nil, fmt.Errorf("некорректный период heartbeat SSE %q", heartbeat)
+	}
+	timeout, err := time.ParseDuration(writeTimeout)
+	if err != nil || timeout <= 0 {
+		return nil, fmt.Errorf("некорректный таймаут записи SSE %q", writeTimeout)
+	}
+	return &SSEConnections{
+		heartbeat:      heartbeatInterval,
+		writeTimeout:   timeout,
+		maxPerEndpoint: maxPerEndpoint,
+		maxPerIP:       maxPerIP,
+		clients:        make(map[uint64]*SSEClient),
+		perEndpoint:    make(map[string]int),
+		perIP:          make(map[string]int),
+		rejected:       make(map[string]uint64),
+	}, nil
+}
+
+// LimitSSE регистрирует соединение SSE с эндпоинтом endpoint и снимает его с учета после отключения клиента.
+// Если лимит соединений эндпоинта или IP-адреса исчерпан, отвечает 429. Кабинет и окно соединения берутся
+// из параметра пути cabinet_number и параметра запроса window. IP клиента определяется с учетом
+// TRUSTED_PROXIES: без него за прокси все клиенты получают IP прокси.
+func LimitSSE(conns *SSEConnections, endpoint string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := &SSEClient{
+			Endpoint:     endpoint,
+			ClientIP:     c.ClientIP(),
+			Cabinet:      optionalInt(c.Param("cabinet_number")),
+			Window:       optionalInt(c.Query("window")),
+			ConnectedAt:  time.Now(),
+			writeTimeout: conns.writeTimeout,
+			owner:        conns,
+		}
+		if reason := conns.acquire(client); reason != "" {
+			logger.Default().WithField("endpoint", endpoint).WithField("client_ip", client.ClientIP).
+				Warn("SSE: Connection rejected: " + reason)
+			c.Header("Retry-After", strconv.Itoa(int(conns.heartbeat.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много подключений: " + reason})
+			return
+		}
+		defer conns.release(client)
+
+		c.Set(sseClientKey, client)
+		c.Next()
+	}
+}
+
+// SSEClientFrom возвращает соединение, зарегистрированное LimitSSE, или nil, если middleware не подключен.
+func SSEClientFrom(c *gin.Context) *SSEClient {
+	if value, ok := c.Get(sseClientKey); ok {
+		return value.(*SSEClient)
+	}
+	return nil
+}
+
+// Heartbeats возвращает канал, в который с периодом heartbeat приходят сигналы отправить клиенту комментарий,
+// чтобы прокси не закрыл простаивающее соединение. Для nil возвращается канал, который никогда не срабатывает.
+func (cl *SSEClient) Heartbeats() <-chan time.Time {
+	if cl == nil {
+		return nil
+	}
+	return cl.ticker.C
+}
+
+// WriteDeadline возвращает крайний срок для очередной записи в соединение. Запись, не завершившаяся
+// к этому сроку, считается признаком мертвого соединения. Нулевое время — без ограничения.
+func (cl *SSEClient) WriteDeadline() time.Time {
+	if cl == nil {
+		return time.Time{}
+	}
+	return time.Now().Add(cl.writeTimeout)
+}
+
+// MarkDead отмечает, что запись в соединение не удалась и клиент отключается.
+func (cl *SSEClient) MarkDead() {
+	if cl != nil && cl.dead.CompareAndSwap(false, true) {
+		cl.owner.dead.Add(1)
+		logger.Default().WithField("endpoint", cl.Endpoint).WithField("client_ip", cl.ClientIP).
+			Warn("SSE: Write failed, dropping dead connection")
+	}
+}
+
+// acquire регистрирует соединение. Возвращает причину отказа, если лимит исчерпан.
+func (s *SSEConnections) acquire(client *SSEClient) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxPerEndpoint > 0 && s.perEndpoint[client.Endpoint] >= s.maxPerEndpoint {
+		s.rejected[client.Endpoint]++
+		return fmt.Sprintf("к эндпоинту уже подключено %d клиентов", s.maxPerEndpoint)
+	}
+	if s.maxPerIP > 0 && s.perIP[client.ClientIP] >= s.maxPerIP {
+		s.rejected[client.Endpoint]++
+		return fmt.Sprintf("с адреса %s уже открыто %d подключений", client.ClientIP, s.maxPerIP)
+	}
+	s.nextID++
+	client.ID = s.nextID
+	client.ticker = time.NewTicker(s.heartbeat)
+	s.clients[client.ID] = client
+	s.perEndpoint[client.Endpoint]++
+	s.perIP[client.ClientIP]++
+	return ""
+}
+
+func (s *SSEConnections) release(client *SSEClient) {
+	client.ticker.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, client.ID)
+	if s.perEndpoint[client.Endpoint]--; s.perEndpoint[client.Endpoint] <= 0 {
+		delete(s.perEndpoint, client.Endpoint)
+	}
+	if s.perIP[client.ClientIP]--; s.perIP[client.ClientIP] <= 0 {
+		delete(s.perIP, client.ClientIP)
+	}
+}
+
+func optionalInt(raw string) *int {
+	if raw == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// SSECabinetStats — число клиентов эндпоинта, подключенных к табло одного кабинета.
+type SSECabinetStats struct {
+	Cabinet int `json:"cabinet" example:"101"`
+	Clients int `json:"clients" example:"1"`
+}
+
+// SSEEndpointStats — клиенты одного эндпоинта SSE.
+type SSEEndpointStats struct {
+	Endpoint string `json:"endpoint" example:"doctor_screen"`
+	Clients  int    `json:"clients" example:"12"`
+	// Rejected — сколько подключений отклонено из-за лимитов.
+	Rejected uint64            `json:"rejected" example:"0"`
+	Cabinets []SSECabinetStats `json:"cabinets,omitempty"`
+}
+
+// SSEIPStats — число соединений с одного IP-адреса.
+type SSEIPStats struct {
+	ClientIP string `json:"client_ip" example:"10.0.0.15"`
+	Clients  int    `json:"clients" example:"2"`
+}
+
+// SSEStats — сводка по открытым соединениям SSE.
+type SSEStats struct {
+	Total            int                `json:"total" example:"25"`
+	HeartbeatSeconds int                `json:"heartbeat_seconds" example:"15"`
+	MaxPerEndpoint   int                `json:"max_per_endpoint" example:"500"`
+	MaxPerIP         int                `json:"max_per_ip" example:"20"`
+	DeadDisconnected uint64             `json:"dead_disconnected" example:"3"`
+	Endpoints        []SSEEndpointStats `json:"endpoints"`
+	IPs              []SSEIPStats       `json:"ips"`
+}
+
+// Stats возвращает число подключенных клиентов по эндпоинтам, кабинетам и IP-адресам.
+func (s *SSEConnections) Stats() SSEStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SSEStats{
+		Total:            len(s.clients),
+		HeartbeatSeconds: int(s.heartbeat.Seconds()),
+		MaxPerEndpoint:   s.maxPerEndpoint,
+		MaxPerIP:         s.maxPerIP,
+		DeadDisconnected: s.dead.Load(),
+		Endpoints:        []SSEEndpointStats{},
+		IPs:              make([]SSEIPStats, 0, len(s.perIP)),
+	}
+
+	cabinets := make(map[string]map[int]int)
+	for _, client := range s.clients {
+		if client.Cabinet == nil {
+			continue
+		}
+		if cabinets[client.Endpoint] == nil {
+			cabinets[client.Endpoint] = make(map[int]int)
+		}
+		cabinets[client.Endpoint][*client.Cabinet]++
+	}
+
+	endpoints := make(map[string]bool)
+	for endpoint := range s.perEndpoint {
+		endpoints[endpoint] = true
+	}
+	for endpoint := range s.rejected {
+		endpoints[endpoint] = true
+	}
+	for endpoint := range endpoints {
+		item := SSEEndpointStats{Endpoint: endpoint, Clients: s.perEndpoint[endpoint], Rejected: s.rejected[endpoint]}
+		for cabinet, count := range cabinets[endpoint] {
+			item.Cabinets = append(item.Cabinets, SSECabinetStats{Cabinet: cabinet, Clients: count})
+		}
+		sort.Slice(item.Cabinets, func(i, j int) bool { return item.Cabinets[i].Cabinet < item.Cabinets[j].Cabinet })
+		stats.Endpoints = append(stats.Endpoints, item)
+	}
+	sort.Slice(stats.Endpoints, func(i, j int) bool { return stats.Endpoints[i].Endpoint < stats.Endpoints[j].Endpoint })
+
+	for ip, count := range s.perIP {
+		stats.IPs = append(stats.IPs, SSEIPStats{ClientIP: ip, Clients: count})
+	}
+	sort.Slice(stats.IPs, func(i, j int) bool { return stats.IPs[i].Clients > stats.IPs[j].Clients })
+	return stats
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"ElectronicQueue/internal/logger"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init("")
+	gin.SetMode(gin.TestMode)
+	code := m.Run()
+	logger.Sync()
+	os.Exit(code)
+}
+
+// sseTestServer держит открытые соединения до закрытия release, имитируя поток SSE.
+type sseTestServer struct {
+	router  *gin.Engine
+	opened  chan *SSEClient
+	release chan struct{}
+}
+
+func newSSETestServer(t *testing.T, conns *SSEConnections) *sseTestServer {
+	t.Helper()
+	s := &sseTestServer{router: gin.New(), opened: make(chan *SSEClient, 16), release: make(chan struct{})}
+	if err := s.router.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+	stream := func(c *gin.Context) {
+		s.opened <- SSEClientFrom(c)
+		<-s.release
+	}
+	s.router.GET("/doctor/:cabinet_number", LimitSSE(conns, "doctor_screen"), stream)
+	s.router.GET("/reception", LimitSSE(conns, "reception"), stream)
+	return s
+}
+
+// open открывает соединение в отдельной горутине и ждет, пока оно будет принято или отклонено.
+func (s *sseTestServer) open(t *testing.T, path, ip string) (*SSEClient, *httptest.ResponseRecorder, chan struct{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":40000"
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		s.router.ServeHTTP(rec, req)
+		close(done)
+	}()
+	select {
+	case client := <-s.opened:
+		return client, rec, done
+	case <-done:
+		return nil, rec, done
+	case <-time.After(2 * time.Second):
+		t.Fatalf("request %s from %s neither opened nor finished", path, ip)
+		return nil, nil, nil
+	}
+}
+
+func TestLimitSSE(t *testing.T) {
+	conns, err := NewSSEConnections("15s", "5s", 2, 1)
+	if err != nil {
+		t.Fatalf("NewSSEConnections: %v", err)
+	}
+	s := newSSETestServer(t, conns)
+
+	first, _, firstDone := s.open(t, "/doctor/101", "10.0.0.1")
+	if first == nil || first.Cabinet == nil || *first.Cabinet != 101 || first.ClientIP != "10.0.0.1" {
+		t.Fatalf("first connection = %+v, want cabinet 101 from 10.0.0.1", first)
+	}
+
+	// Лимит на IP: второе соединение с того же адреса отклоняется.
+	if client, rec, _ := s.open(t, "/reception", "10.0.0.1"); client != nil || rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second connection from the same IP: code %d, want 429", rec.Code)
+	} else if rec.Header().Get("Retry-After") != "15" {
+		t.Errorf("Retry-After = %q, want 15", rec.Header().Get("Retry-After"))
+	}
+
+	// Лимит на эндпоинт: третье соединение с doctor_screen отклоняется, другой эндпоинт доступен.
+	if client, _, _ := s.open(t, "/doctor/102", "10.0.0.2"); client == nil {
+		t.Fatal("second doctor_screen connection must be accepted")
+	}
+	if client, rec, _ := s.open(t, "/doctor/103", "10.0.0.3"); client != nil || rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third doctor_screen connection: code %d, want 429", rec.Code)
+	}
+	if client, _, _ := s.open(t, "/reception?window=2", "10.0.0.3"); client == nil || client.Window == nil || *client.Window != 2 {
+		t.Fatalf("reception connection = %+v, want window 2", client)
+	}
+
+	stats := conns.Stats()
+	if stats.Total != 3 || len(stats.Endpoints) != 2 {
+		t.Fatalf("stats total=%d endpoints=%d, want 3 and 2", stats.Total, len(stats.Endpoints))
+	}
+	doctor := stats.Endpoints[0]
+	if doctor.Endpoint != "doctor_screen" || doctor.Clients != 2 || doctor.Rejected != 1 || len(doctor.Cabinets) != 2 {
+		t.Errorf("doctor_screen stats = %+v, want 2 clients, 1 rejected, 2 cabinets", doctor)
+	}
+	if reception := stats.Endpoints[1]; reception.Clients != 1 || reception.Rejected != 1 {
+		t.Errorf("reception stats = %+v, want 1 client, 1 rejected", reception)
+	}
+
+	// Закрытые соединения снимаются с учета, и адрес снова может подключиться.
+	first.MarkDead()
+	first.MarkDead()
+	close(s.release)
+	<-firstDone
+	waitForSSE(t, func() bool { return conns.Stats().Total == 0 })
+	if stats := conns.Stats(); stats.DeadDisconnected != 1 || len(stats.IPs) != 0 {
+		t.Errorf("after release: dead=%d ips=%d, want 1 and 0", stats.DeadDisconnected, len(stats.IPs))
+	}
+	again := newSSETestServer(t, conns)
+	defer close(again.release)
+	if client, _, _ := again.open(t, "/reception", "10.0.0.1"); client == nil {
+		t.Error("connection from a released IP must be accepted")
+	}
+}
+
+func TestNewSSEConnectionsRejectsInvalidDurations(t *testing.T) {
+	for _, tt := range []struct{ heartbeat, timeout string }{{"0s", "5s"}, {"abc", "5s"}, {"15s", ""}, {"15s", "-1s"}} {
+		if _, err := NewSSEConnections(tt.heartbeat, tt.timeout, 0, 0); err == nil {
+			t.Errorf("NewSSEConnections(%q, %q): expected error", tt.heartbeat, tt.timeout)
+		}
+	}
+}
+
+func waitForSSE(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -11,9 +11,14 @@ type Appointment struct {
 	PatientID  *uint     `gorm:"column:patient_id" json:"patient_id,omitempty"`
 	TicketID   *uint     `gorm:"column:ticket_id" json:"ticket_id,omitempty"`
 	CreatedAt  time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
-	Patient    Patient   `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
-	Schedule   Schedule  `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
-	Ticket     Ticket    `gorm:"foreignKey:TicketID" json:"ticket,omitempty"`
+	// RulesOverrideBy и RulesOverrideReason заполняются, если старший регистратор записал пациента в обход правил.
+	RulesOverrideBy     *uint   `gorm:"column:rules_override_by" json:"rules_override_by,omitempty"`
+	RulesOverrideReason *string `gorm:"column:rules_override_reason" json:"rules_override_reason,omitempty"`
+	// CalendarSequence — версия события календаря (SEQUENCE), ведется триггерами в БД.
+	CalendarSequence int      `gorm:"column:calendar_sequence;->" json:"-"`
+	Patient          Patient  `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+	Schedule         Schedule `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	Ticket           Ticket   `gorm:"foreignKey:TicketID" json:"ticket,omitempty"`
 }
 
 // CreateAppointmentRequest определяет структуру для создания новой записи на прием.
@@ -21,6 +26,19 @@ type CreateAppointmentRequest struct {
 	ScheduleID uint  `json:"schedule_id" binding:"required"`
 	PatientID  *uint `json:"patient_id"`
 	TicketID   *uint `json:"ticket_id"`
+	// OverrideRules — записать пациента несмотря на нарушение правил записи (только для старших регистраторов).
+	OverrideRules bool `json:"override_rules,omitempty"`
+	// OverrideReason — обязательная при переопределении причина, сохраняется в записи.
+	OverrideReason string `json:"override_reason,omitempty" example:"По согласованию с заведующим"`
+	// ReferralID — направление, по которому создается запись; после записи оно считается исполненным.
+	ReferralID *uint `json:"referral_id,omitempty" example:"7"`
+	// RulesOverrideBy заполняется сервисом после проверки прав регистратора.
+	RulesOverrideBy *uint `json:"-"`
+}
+
+// RescheduleAppointmentRequest определяет структуру для переноса записи в другой слот.
+type RescheduleAppointmentRequest struct {
+	ScheduleID uint `json:"schedule_id" binding:"required" example:"12"`
 }
 
 // AppointmentResponse определяет данные, возвращаемые API.
@@ -36,9 +54,12 @@ type UpdateAppointmentRequest struct {
 }
 
 // ScheduleWithAppointmentInfo объединяет информацию о слоте расписания и записи на прием.
-// Используется для отображения журнала-планировщика.
+// Используется для отображения журнала-планировщика. Appointment и TicketNumber относятся
+// к первой записи в слоте; для групповых слотов полный список — в Appointments.
 type ScheduleWithAppointmentInfo struct {
 	Schedule
-	Appointment  *Appointment `json:"appointment,omitempty"`
-	TicketNumber *string      `json:"ticket_number,omitempty"`
+	Appointment  *Appointment  `json:"appointment,omitempty"`
+	TicketNumber *string       `json:"ticket_number,omitempty"`
+	Appointments []Appointment `json:"appointments,omitempty"`
+	FreePlaces   int           `json:"free_places"`
 }
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// FreeSlotFilter определяет параметры поиска свободных слотов для записи.
+type FreeSlotFilter struct {
+	DoctorID       *uint
+	Specialization string
+	DateFrom       time.Time
+	DateTo         time.Time
+}
+
+// ExternalPatientIdentity определяет данные, по которым внешний клиент идентифицирует пациента.
+type ExternalPatientIdentity struct {
+	OmsNumber string `json:"oms_number" binding:"required,len=16" example:"1234567890123456"`
+	BirthDate string `json:"birth_date" binding:"required" example:"1985-04-12"`
+}
+
+// ExternalBookingRequest определяет структуру для самостоятельной записи пациента на прием.
+type ExternalBookingRequest struct {
+	ExternalPatientIdentity
+	ScheduleID uint `json:"schedule_id" binding:"required" example:"12"`
+}
+
+// ExternalDoctorResponse определяет данные о враче, доступные внешним клиентам.
+type ExternalDoctorResponse struct {
+	ID             uint   `json:"id"`
+	FullName       string `json:"full_name"`
+	Specialization string `json:"specialization"`
+}
+
+// ExternalSlotResponse определяет свободный слот, доступный для записи.
+type ExternalSlotResponse struct {
+	ScheduleID     uint   `json:"schedule_id"`
+	DoctorID       uint   `json:"doctor_id"`
+	DoctorName     string `json:"doctor_name"`
+	Specialization string `json:"specialization"`
+	Date           string `json:"date"`
+	StartTime      string `json:"start_time"`
+	EndTime        string `json:"end_time"`
+	Cabinet        *int   `json:"cabinet,omitempty"`
+	// FreePlaces — число свободных мест (больше 1 только у групповых слотов).
+	FreePlaces int `json:"free_places"`
+	// CheckInCode — код для QR-кода, по которому пациент регистрируется на приеме через киоск.
+	CheckInCode string `json:"check_in_code"`
+}
+
+// ExternalAppointmentResponse определяет запись на прием, возвращаемую внешним клиентам.
+type ExternalAppointmentResponse struct {
+	AppointmentID  uint   `json:"appointment_id"`
+	ScheduleID     uint   `json:"schedule_id"`
+	DoctorName     string `json:"doctor_name"`
+	Specialization string `json:"specialization"`
+	Date           string `json:"date"`
+	StartTime      string `json:"start_time"`
+	EndTime        string `json:"end_time"`
+	Cabinet        *int   `json:"cabinet,omitempty"`
+	// CheckInCode — код для QR-кода, по которому пациент регистрируется на приеме через киоск.
+	CheckInCode string `json:"check_in_code"`
+}
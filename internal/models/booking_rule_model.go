@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// BookingRule задает ограничения записи к врачам одной специальности.
+// Незаполненное поле означает, что соответствующее ограничение не действует.
+type BookingRule struct {
+	ID                uint      `gorm:"primaryKey;autoIncrement;column:rule_id" json:"id"`
+	Specialization    string    `gorm:"type:varchar(100);not null;unique;column:specialization" json:"specialization"`
+	MaxActiveBookings *int      `gorm:"column:max_active_bookings" json:"max_active_bookings,omitempty"`
+	MinIntervalDays   *int      `gorm:"column:min_interval_days" json:"min_interval_days,omitempty"`
+	MinAge            *int      `gorm:"column:min_age" json:"min_age,omitempty"`
+	MaxAge            *int      `gorm:"column:max_age" json:"max_age,omitempty"`
+	UpdatedAt         time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName указывает GORM имя таблицы для модели BookingRule.
+func (BookingRule) TableName() string {
+	return "booking_rules"
+}
+
+// UpsertBookingRuleRequest определяет структуру для создания или замены правил записи по специальности.
+type UpsertBookingRuleRequest struct {
+	Specialization    string `json:"specialization" binding:"required" example:"Кардиолог"`
+	MaxActiveBookings *int   `json:"max_active_bookings,omitempty" binding:"omitempty,min=1" example:"1"`
+	MinIntervalDays   *int   `json:"min_interval_days,omitempty" binding:"omitempty,min=1" example:"14"`
+	MinAge            *int   `json:"min_age,omitempty" binding:"omitempty,min=0,max=150" example:"0"`
+	MaxAge            *int   `json:"max_age,omitempty" binding:"omitempty,min=0,max=150" example:"17"`
+}
+
+// BookingRuleCode определяет нарушенное правило записи.
+type BookingRuleCode string
+
+const (
+	BookingRuleOverlap     BookingRuleCode = "overlap"
+	BookingRuleMaxActive   BookingRuleCode = "max_active"
+	BookingRuleMinInterval BookingRuleCode = "min_interval"
+	BookingRuleAge         BookingRuleCode = "age"
+)
+
+// BookingRuleViolation описывает одно нарушение правил записи для показа регистратору.
+type BookingRuleViolation struct {
+	Rule    BookingRuleCode `json:"rule" example:"overlap"`
+	Message string          `json:"message" example:"пациент уже записан на это время: Терапевт, 20.07.2025 09:00"`
+	// ConflictingAppointmentID — запись пациента, из-за которой сработало правило.
+	ConflictingAppointmentID *uint `json:"conflicting_appointment_id,omitempty" example:"42"`
+}
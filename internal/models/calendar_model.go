@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// CalendarTombstone хранит отмененное событие календаря (удаленный слот или запись),
+// чтобы подписанные календари получили отмену с тем же UID. Записи создаются триггерами в БД.
+type CalendarTombstone struct {
+	UID           string    `gorm:"primaryKey;type:varchar(128);column:uid" json:"uid"`
+	DoctorID      *uint     `gorm:"column:doctor_id" json:"doctor_id,omitempty"`
+	AppointmentID *uint     `gorm:"column:appointment_id" json:"appointment_id,omitempty"`
+	PatientID     *uint     `gorm:"column:patient_id" json:"patient_id,omitempty"`
+	StartsAt      time.Time `gorm:"type:timestamp;not null;column:starts_at" json:"starts_at"`
+	EndsAt        time.Time `gorm:"type:timestamp;not null;column:ends_at" json:"ends_at"`
+	Sequence      int       `gorm:"not null;column:sequence" json:"sequence"`
+	CancelledAt   time.Time `gorm:"column:cancelled_at" json:"cancelled_at"`
+}
+
+// TableName явно задает имя таблицы для GORM.
+func (CalendarTombstone) TableName() string {
+	return "calendar_tombstones"
+}
+
+// CalendarFeedResponse возвращает врачу ссылку на персональный календарь.
+type CalendarFeedResponse struct {
+	Token   string `json:"token" example:"3f1c9a..."`
+	FeedURL string `json:"feed_url" example:"/api/calendar/doctor/3f1c9a....ics"`
+}
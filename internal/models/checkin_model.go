@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// CheckInMethod определяет способ идентификации пациента при регистрации на прием через киоск.
+type CheckInMethod string
+
+const (
+	CheckInByPhone    CheckInMethod = "phone"
+	CheckInByOMS      CheckInMethod = "oms"
+	CheckInByPassport CheckInMethod = "passport"
+	CheckInByQR       CheckInMethod = "qr"
+)
+
+// KioskCheckInRequest определяет структуру запроса на регистрацию по записи через киоск.
+type KioskCheckInRequest struct {
+	// Method — способ идентификации: phone, oms (номер или штрих-код полиса), passport (серия и номер) или qr (код из подтверждения записи).
+	Method CheckInMethod `json:"method" binding:"required,oneof=phone oms passport qr" example:"oms"`
+	// Value — введенное или отсканированное значение.
+	Value string `json:"value" binding:"required" example:"1234567890123456"`
+	// BirthYear — год рождения для уточнения, если по данным найдено несколько пациентов с записями на сегодня.
+	BirthYear *int `json:"birth_year,omitempty" binding:"omitempty,min=1900,max=2100" example:"1985"`
+	// Action — print_ticket, чтобы напечатать талон; иначе талон только электронный.
+	Action string `json:"action,omitempty" example:"print_ticket"`
+}
+
+// CheckInCodeResponse содержит код записи на прием для QR-кода в подтверждении записи.
+type CheckInCodeResponse struct {
+	AppointmentID uint   `json:"appointment_id" example:"42"`
+	Code          string `json:"code" example:"EQA1.42.3f9a0c1d2b7e8f65"`
+}
+
+// CheckInOutcome определяет результат регистрации по записи относительно времени начала слота.
+type CheckInOutcome string
+
+const (
+	CheckInOnTime CheckInOutcome = "вовремя"
+	CheckInEarly  CheckInOutcome = "рано"
+	CheckInLate   CheckInOutcome = "опоздание"
+)
+
+// CheckInLog представляет собой запись журнала регистраций по записи через киоск.
+type CheckInLog struct {
+	ID            uint           `gorm:"primaryKey;autoIncrement;column:checkin_log_id" json:"id"`
+	AppointmentID *uint          `gorm:"column:appointment_id" json:"appointment_id,omitempty"`
+	PatientID     *uint          `gorm:"column:patient_id" json:"patient_id,omitempty"`
+	TicketID      *uint          `gorm:"column:ticket_id" json:"ticket_id,omitempty"`
+	Method        CheckInMethod  `gorm:"type:varchar(20);not null;column:method" json:"method"`
+	Outcome       CheckInOutcome `gorm:"type:varchar(20);not null;column:outcome" json:"outcome"`
+	SlotStart     time.Time      `gorm:"not null;column:slot_start" json:"slot_start"`
+	// OffsetMinutes — разница между временем прихода и началом слота; отрицательная, если пациент пришел раньше.
+	OffsetMinutes int       `gorm:"not null;column:offset_minutes" json:"offset_minutes"`
+	CreatedAt     time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName указывает GORM имя таблицы для модели CheckInLog.
+func (CheckInLog) TableName() string {
+	return "checkin_logs"
+}
+
+// CheckInReportRow представляет одну строку отчета по регистрациям: способ, результат и статистику отклонений.
+type CheckInReportRow struct {
+	Method           CheckInMethod  `json:"method" example:"oms"`
+	Outcome          CheckInOutcome `json:"outcome" example:"опоздание"`
+	Count            int64          `json:"count" example:"4"`
+	AvgOffsetMinutes float64        `json:"avg_offset_minutes" example:"22.5"`
+}
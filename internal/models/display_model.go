@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DisplayRole — назначение устройства отображения.
+type DisplayRole string
+
+const (
+	DisplayRoleReception DisplayRole = "reception" // табло регистратуры
+	DisplayRoleCabinet   DisplayRole = "cabinet"   // экран у кабинета врача
+	DisplayRoleSchedule  DisplayRole = "schedule"  // табло расписания
+	DisplayRoleKiosk     DisplayRole = "kiosk"     // терминал самообслуживания
+)
+
+// IsValid проверяет, что назначение устройства известно.
+func (r DisplayRole) IsValid() bool {
+	switch r {
+	case DisplayRoleReception, DisplayRoleCabinet, DisplayRoleSchedule, DisplayRoleKiosk:
+		return true
+	}
+	return false
+}
+
+// Display — зарегистрированное устройство отображения. Конфигурация устройства хранится на сервере,
+// и администратор может изменить ее удаленно.
+type Display struct {
+	ID        uint        `gorm:"primaryKey;autoIncrement;column:display_id" json:"id"`
+	Name      string      `gorm:"type:varchar(100);column:name;not null" json:"name"`
+	Role      DisplayRole `gorm:"type:varchar(20);column:role;not null" json:"role"`
+	TokenHash string      `gorm:"type:varchar(64);column:token_hash;not null" json:"-"`
+	// Settings — DisplaySettings в формате JSON.
+	Settings   json.RawMessage `gorm:"type:jsonb;column:settings;not null" json:"settings" swaggertype:"object"`
+	LastSeenAt *time.Time      `gorm:"column:last_seen_at" json:"last_seen_at,omitempty"`
+	CreatedAt  time.Time       `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time       `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName указывает GORM имя таблицы для модели Display.
+func (Display) TableName() string {
+	return "displays"
+}
+
+// DisplayAudio — настройки звуковых оповещений устройства.
+type DisplayAudio struct {
+	Enabled bool `json:"enabled" example:"true"`
+	// Volume — громкость от 0 до 100.
+	Volume          int  `json:"volume" example:"80"`
+	BackgroundMusic bool `json:"background_music" example:"false"`
+}
+
+// DisplayTheme — оформление экрана устройства.
+type DisplayTheme struct {
+	// Mode — light или dark.
+	Mode        string `json:"mode" example:"dark"`
+	AccentColor string `json:"accent_color,omitempty" example:"#1E88E5"`
+	// FontScale — масштаб шрифта относительно стандартного, от 0.5 до 3.
+	FontScale float64 `json:"font_scale,omitempty" example:"1.25"`
+}
+
+// DisplaySettings — конфигурация устройства отображения.
+type DisplaySettings struct {
+	// Windows — окна регистратуры, талоны которых показывает табло регистратуры (пусто — все окна).
+	Windows []int `json:"windows" example:"1,2"`
+	// Cabinets — кабинеты, для которых работает экран у кабинета.
+	Cabinets []int `json:"cabinets" example:"101"`
+	// Playlist — ID рекламных материалов в порядке показа (пусто — все материалы, включенные для экрана).
+	Playlist []uint       `json:"playlist" example:"3,5"`
+	Audio    DisplayAudio `json:"audio"`
+	Theme    DisplayTheme `json:"theme"`
+}
+
+// DisplayResponse — устройство отображения с разобранной конфигурацией.
+type DisplayResponse struct {
+	ID         uint            `json:"id" example:"4"`
+	Name       string          `json:"name" example:"Коридор 2 этажа, кабинет 101"`
+	Role       DisplayRole     `json:"role" example:"cabinet"`
+	Settings   DisplaySettings `json:"settings"`
+	LastSeenAt *time.Time      `json:"last_seen_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// ToResponse разбирает конфигурацию устройства.
+func (d *Display) ToResponse() (DisplayResponse, error) {
+	resp := DisplayResponse{
+		ID:         d.ID,
+		Name:       d.Name,
+		Role:       d.Role,
+		LastSeenAt: d.LastSeenAt,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+	if len(d.Settings) > 0 {
+		if err := json.Unmarshal(d.Settings, &resp.Settings); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// CreateDisplayRequest — DTO для регистрации устройства отображения.
+type CreateDisplayRequest struct {
+	Name     string        `json:"name" binding:"required" example:"Коридор 2 этажа, кабинет 101"`
+	Role     DisplayRole   `json:"role" binding:"required" example:"cabinet"`
+	Windows  []int         `json:"windows,omitempty"`
+	Cabinets []int         `json:"cabinets,omitempty" example:"101"`
+	Playlist []uint        `json:"playlist,omitempty"`
+	Audio    *DisplayAudio `json:"audio,omitempty"`
+	Theme    *DisplayTheme `json:"theme,omitempty"`
+}
+
+// UpdateDisplayRequest — DTO для изменения устройства. Передаются только изменяемые поля;
+// пустой список окон, кабинетов или плейлиста очищает его.
+type UpdateDisplayRequest struct {
+	Name     *string       `json:"name,omitempty"`
+	Role     *DisplayRole  `json:"role,omitempty" example:"reception"`
+	Windows  *[]int        `json:"windows,omitempty"`
+	Cabinets *[]int        `json:"cabinets,omitempty"`
+	Playlist *[]uint       `json:"playlist,omitempty"`
+	Audio    *DisplayAudio `json:"audio,omitempty"`
+	Theme    *DisplayTheme `json:"theme,omitempty"`
+}
+
+// DisplayTokenResponse — устройство и его токен. Токен показывается только при выпуске.
+type DisplayTokenResponse struct {
+	Display DisplayResponse `json:"display"`
+	Token   string          `json:"token" example:"9c1e4f..."`
+}
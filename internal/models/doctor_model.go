@@ -17,6 +17,7 @@ type Doctor struct {
 	Login          string       `gorm:"column:login;unique" json:"login,omitempty"`
 	PasswordHash   string       `gorm:"column:password_hash" json:"-"`
 	Status         DoctorStatus `gorm:"type:varchar(20);default:'активен';column:status" json:"status"`
+	CalendarToken  *string      `gorm:"type:varchar(64);column:calendar_token" json:"-"`
 	Schedules      []Schedule   `gorm:"foreignKey:DoctorID;constraint:OnDelete:SET NULL" json:"schedules,omitempty"`
 }
 
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationEvent определяет событие, по которому пациенту отправляется уведомление.
+type NotificationEvent string
+
+const (
+	NotificationEventAppointmentCreated   NotificationEvent = "appointment_created"
+	NotificationEventAppointmentChanged   NotificationEvent = "appointment_changed"
+	NotificationEventAppointmentCancelled NotificationEvent = "appointment_cancelled"
+	NotificationEventAppointmentReminder  NotificationEvent = "appointment_reminder"
+)
+
+// NotificationStatus определяет статус уведомления в очереди на отправку.
+type NotificationStatus string
+
+const (
+	NotificationStatusPending   NotificationStatus = "ожидает"
+	NotificationStatusSent      NotificationStatus = "отправлено"
+	NotificationStatusFailed    NotificationStatus = "ошибка"
+	NotificationStatusCancelled NotificationStatus = "отменено"
+	// NotificationStatusSuperseded означает, что напоминание относится к прежнему времени перенесенной записи.
+	NotificationStatusSuperseded NotificationStatus = "заменено"
+)
+
+// NotificationOutbox представляет уведомление пациенту в очереди на отправку (outbox).
+// Записи создаются в той же транзакции, что и изменение записи на прием,
+// а отправляются фоновым диспетчером.
+type NotificationOutbox struct {
+	ID            uint               `gorm:"primaryKey;autoIncrement;column:notification_id" json:"id"`
+	PatientID     uint               `gorm:"not null;column:patient_id" json:"patient_id"`
+	AppointmentID *uint              `gorm:"column:appointment_id" json:"appointment_id,omitempty"`
+	ScheduleID    *uint              `gorm:"column:schedule_id" json:"schedule_id,omitempty"`
+	Event         NotificationEvent  `gorm:"type:varchar(32);not null;column:event" json:"event"`
+	Subject       string             `gorm:"type:varchar(255);not null;column:subject" json:"subject"`
+	Body          string             `gorm:"type:text;not null;column:body" json:"body"`
+	Status        NotificationStatus `gorm:"type:varchar(20);not null;default:'ожидает';column:status" json:"status"`
+	Attempts      int                `gorm:"not null;default:0;column:attempts" json:"attempts"`
+	NextAttemptAt time.Time          `gorm:"not null;column:next_attempt_at" json:"next_attempt_at"`
+	LastError     *string            `gorm:"column:last_error" json:"last_error,omitempty"`
+	CreatedAt     time.Time          `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+	SentAt        *time.Time         `gorm:"column:sent_at" json:"sent_at,omitempty"`
+	ClaimedAt     *time.Time         `gorm:"column:claimed_at" json:"-"` // Когда диспетчер забрал уведомление на отправку
+	Patient       Patient            `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+}
+
+// TableName явно задает имя таблицы для GORM.
+func (NotificationOutbox) TableName() string {
+	return "notification_outbox"
+}
+
+// NewAppointmentNotification формирует уведомление о записи на прием.
+// Текст собирается сразу, чтобы уведомление об отмене не зависело от удаленной записи.
+// Слот должен быть загружен вместе с врачом.
+func NewAppointmentNotification(event NotificationEvent, patientID, appointmentID uint, schedule *Schedule) *NotificationOutbox {
+	cabinet := "не указан"
+	if schedule.Cabinet != nil {
+		cabinet = fmt.Sprintf("%d", *schedule.Cabinet)
+	}
+	startTime := schedule.StartTime
+	if len(startTime) >= 5 {
+		startTime = startTime[:5]
+	}
+	details := fmt.Sprintf("%s в %s, врач %s (%s), кабинет %s",
+		schedule.Date.Format("02.01.2006"), startTime, schedule.Doctor.FullName, schedule.Doctor.Specialization, cabinet)
+
+	var subject, body string
+	switch event {
+	case NotificationEventAppointmentCreated:
+		subject = "Вы записаны на прием"
+		body = "Вы записаны на прием: " + details + "."
+	case NotificationEventAppointmentChanged:
+		subject = "Время приема изменено"
+		body = "Ваша запись на прием перенесена. Новое время: " + details + "."
+	case NotificationEventAppointmentCancelled:
+		subject = "Запись на прием отменена"
+		body = "Ваша запись на прием отменена: " + details + "."
+	case NotificationEventAppointmentReminder:
+		subject = "Напоминание о приеме"
+		body = "Напоминаем о приеме: " + details + ". Если вы не сможете прийти, пожалуйста, сообщите в регистратуру."
+	}
+
+	scheduleID := schedule.ID
+	return &NotificationOutbox{
+		PatientID:     patientID,
+		AppointmentID: &appointmentID,
+		ScheduleID:    &scheduleID,
+		Event:         event,
+		Subject:       subject,
+		Body:          body,
+		Status:        NotificationStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+}
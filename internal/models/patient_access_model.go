@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// PatientAccessLog — запись журнала чтения персональных данных пациента.
+type PatientAccessLog struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement;column:access_id" json:"id"`
+	PatientID uint   `gorm:"column:patient_id;not null" json:"patient_id" example:"12"`
+	// ActorRole — роль пользователя (registrar, doctor) или тип ключа API (admin, database).
+	ActorRole string `gorm:"type:varchar(20);column:actor_role;not null" json:"actor_role" example:"registrar"`
+	// ActorID — ID регистратора или врача; для доступа по ключу API не заполняется.
+	ActorID *uint `gorm:"column:actor_id" json:"actor_id,omitempty" example:"3"`
+	// Endpoint — метод и шаблон маршрута, например "GET /api/registrar/patients/:patient_id/timeline".
+	Endpoint   string    `gorm:"type:varchar(255);column:endpoint;not null" json:"endpoint" example:"GET /api/registrar/patients/:patient_id/timeline"`
+	ClientIP   *string   `gorm:"type:varchar(45);column:client_ip" json:"client_ip,omitempty" example:"10.0.0.15"`
+	AccessedAt time.Time `gorm:"column:accessed_at;default:CURRENT_TIMESTAMP" json:"accessed_at"`
+}
+
+// TableName указывает GORM имя таблицы для модели PatientAccessLog.
+func (PatientAccessLog) TableName() string {
+	return "patient_access_log"
+}
+
+// PatientAccessFilter — условия выборки из журнала доступа. Незаданные условия не ограничивают выборку.
+type PatientAccessFilter struct {
+	PatientID *uint
+	ActorRole string
+	ActorID   *uint
+	// From и To задают полуинтервал [From, To).
+	From time.Time
+	To   time.Time
+}
+
+// PatientAccessPage — страница журнала доступа, новые записи первыми.
+type PatientAccessPage struct {
+	Items    []PatientAccessLog `json:"items"`
+	Total    int64              `json:"total" example:"120"`
+	Page     int                `json:"page" example:"1"`
+	PageSize int                `json:"page_size" example:"50"`
+}
+
+// PatientAccessAnomaly — час, за который пользователь просмотрел больше допустимого числа разных пациентов.
+type PatientAccessAnomaly struct {
+	ActorRole string  `gorm:"column:actor_role" json:"actor_role" example:"registrar"`
+	ActorID   *uint   `gorm:"column:actor_id" json:"actor_id,omitempty" example:"3"`
+	ActorName *string `gorm:"column:actor_name" json:"actor_name,omitempty" example:"Смирнова Е.А."`
+	// HourStart — начало часа, за который посчитаны обращения.
+	HourStart        time.Time `gorm:"column:hour_start" json:"hour_start"`
+	DistinctPatients int64     `gorm:"column:distinct_patients" json:"distinct_patients" example:"84"`
+	Reads            int64     `gorm:"column:reads" json:"reads" example:"131"`
+}
+
+// PatientAccessAnomalyReport — отчет о подозрительно частом просмотре данных пациентов за период.
+type PatientAccessAnomalyReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Threshold — сколько разных пациентов за час допустимо; в отчет попадают часы, где их больше.
+	Threshold int                    `json:"threshold" example:"50"`
+	Anomalies []PatientAccessAnomaly `json:"anomalies"`
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PatientAuditAction описывает тип изменения карточки пациента.
+type PatientAuditAction string
+
+const (
+	PatientAuditUpdate PatientAuditAction = "изменение"
+	PatientAuditMerge  PatientAuditAction = "объединение"
+	// PatientAuditAnonymize — замена персональных данных карточки токеном по запросу или по сроку хранения.
+	PatientAuditAnonymize PatientAuditAction = "анонимизация"
+)
+
+// PatientAuditLog — запись журнала изменений карточки пациента.
+type PatientAuditLog struct {
+	ID              uint               `gorm:"primaryKey;autoIncrement;column:audit_id" json:"id"`
+	PatientID       uint               `gorm:"column:patient_id;not null" json:"patient_id"`
+	Action          PatientAuditAction `gorm:"type:varchar(20);column:action;not null" json:"action" example:"изменение"`
+	MergedPatientID *uint              `gorm:"column:merged_patient_id" json:"merged_patient_id,omitempty"`
+	ActorRole       string             `gorm:"type:varchar(20);column:actor_role;not null" json:"actor_role" example:"registrar"`
+	ActorID         *uint              `gorm:"column:actor_id" json:"actor_id,omitempty"`
+	ActorName       *string            `gorm:"type:varchar(100);column:actor_name" json:"actor_name,omitempty"`
+	Reason          *string            `gorm:"type:text;column:reason" json:"reason,omitempty"`
+	// Details — для изменения: старые и новые значения полей; для объединения: снимок удаленной карточки и число перенесенных записей;
+	// для анонимизации: отчет об очищенных и сохраненных данных.
+	Details   json.RawMessage `gorm:"type:jsonb;column:details;not null" json:"details" swaggertype:"object"`
+	CreatedAt time.Time       `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName указывает GORM имя таблицы для модели PatientAuditLog.
+func (PatientAuditLog) TableName() string {
+	return "patient_audit_log"
+}
+
+// PatientFieldChange — старое и новое значение измененного поля карточки.
+type PatientFieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// DuplicateReason описывает признак, по которому карточка считается возможным дубликатом.
+type DuplicateReason string
+
+const (
+	DuplicateSameOMS       DuplicateReason = "same_oms"
+	DuplicateSamePhone     DuplicateReason = "same_phone"
+	DuplicateNameBirthDate DuplicateReason = "similar_name_birth_date"
+)
+
+// DuplicateCandidate — карточка, похожая на проверяемую, с признаками совпадения.
+type DuplicateCandidate struct {
+	Patient Patient           `json:"patient"`
+	Reasons []DuplicateReason `json:"reasons"`
+	// NameSimilarity — сходство ФИО от 0 до 1.
+	NameSimilarity float64 `json:"name_similarity" example:"0.93"`
+}
+
+// DuplicatePair — пара карточек, которые, вероятно, относятся к одному пациенту.
+type DuplicatePair struct {
+	First          Patient           `json:"first"`
+	Second         Patient           `json:"second"`
+	Reasons        []DuplicateReason `json:"reasons"`
+	NameSimilarity float64           `json:"name_similarity" example:"0.93"`
+}
+
+// MergePatientsRequest определяет структуру запроса на объединение карточек пациента.
+type MergePatientsRequest struct {
+	SurvivingPatientID uint   `json:"surviving_patient_id" binding:"required" example:"12"`
+	DuplicatePatientID uint   `json:"duplicate_patient_id" binding:"required" example:"47"`
+	PerformedBy        string `json:"performed_by" binding:"required" example:"Петров А.В."`
+	Reason             string `json:"reason" binding:"required" example:"Повторная регистрация с опечаткой в ФИО"`
+}
+
+// PatientMergeResult описывает результат объединения карточек.
+type PatientMergeResult struct {
+	Patient *Patient `json:"patient"`
+	// Moved — число перенесенных на оставшуюся карточку записей по таблицам.
+	Moved map[string]int64 `json:"moved"`
+	// DroppedAppointments — записи дубликата в те же слоты, что и у оставшейся карточки; они удалены.
+	DroppedAppointments []uint          `json:"dropped_appointments"`
+	AuditEntry          PatientAuditLog `json:"audit_entry"`
+}
+
+// AnonymizePatientRequest определяет структуру запроса на анонимизацию карточки пациента.
+type AnonymizePatientRequest struct {
+	PerformedBy string `json:"performed_by" binding:"required" example:"Петров А.В."`
+	Reason      string `json:"reason" binding:"required" example:"Заявление пациента об удалении персональных данных"`
+}
+
+// PatientAnonymizationResult — отчет об анонимизации карточки пациента.
+type PatientAnonymizationResult struct {
+	PatientID uint `json:"patient_id" example:"12"`
+	// Token — обезличенное имя, которое карточка получила вместо ФИО.
+	Token string `json:"token" example:"Аноним 3F9A1C2B7E04"`
+	// ClearedFields — очищенные поля карточки; дата рождения сокращается до года.
+	ClearedFields            []string `json:"cleared_fields"`
+	CancelledWaitlistEntries int64    `json:"cancelled_waitlist_entries" example:"1"`
+	CancelledNotifications   int64    `json:"cancelled_notifications" example:"2"`
+	// RedactedNotifications — уведомления, из текста которых удалены данные пациента.
+	RedactedNotifications int64 `json:"redacted_notifications" example:"14"`
+	// RedactedAuditEntries — прежние записи журнала изменений карточки, из которых удалены старые значения полей.
+	RedactedAuditEntries int64 `json:"redacted_audit_entries" example:"3"`
+	// Preserved — число сохраненных для статистики записей, связанных с карточкой, по таблицам.
+	Preserved  map[string]int64 `json:"preserved"`
+	AuditEntry PatientAuditLog  `json:"audit_entry"`
+}
+
+// RetentionCandidate — карточка пациента без визитов дольше срока хранения.
+type RetentionCandidate struct {
+	PatientID uint `gorm:"column:patient_id" json:"patient_id" example:"12"`
+	// LastActivity — дата последнего приема или, если приемов не было, регистрации карточки.
+	LastActivity time.Time `gorm:"column:last_activity" json:"last_activity"`
+}
+
+// RetentionFailure — карточка, которую задание хранения не смогло анонимизировать.
+type RetentionFailure struct {
+	PatientID uint   `json:"patient_id" example:"12"`
+	Error     string `json:"error"`
+}
+
+// RunRetentionRequest определяет структуру запроса на ручной запуск задания хранения данных.
+type RunRetentionRequest struct {
+	PerformedBy string `json:"performed_by" binding:"required" example:"Петров А.В."`
+	// DryRun — только найти карточки с истекшим сроком хранения, не анонимизируя их.
+	DryRun bool `json:"dry_run" example:"true"`
+}
+
+// RetentionReport — отчет о запуске задания хранения данных пациентов.
+type RetentionReport struct {
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	RetentionYears int       `json:"retention_years" example:"5"`
+	// Cutoff — карточки без визитов с этой даты анонимизируются.
+	Cutoff     time.Time            `json:"cutoff"`
+	DryRun     bool                 `json:"dry_run"`
+	Candidates []RetentionCandidate `json:"candidates"`
+	Anonymized []uint               `json:"anonymized"`
+	Failed     []RetentionFailure   `json:"failed"`
+	// HasMore — кандидатов больше, чем обрабатывается за один запуск; остальные будут обработаны при следующем.
+	HasMore bool `json:"has_more"`
+}
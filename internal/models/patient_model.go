@@ -5,14 +5,35 @@ import (
 )
 
 // Patient представляет собой модель пациента в базе данных.
+// Паспорт и полис ОМС хранятся только в зашифрованном виде: поля PassportSeries, PassportNumber и OmsNumber
+// заполняются при чтении и шифруются при сохранении (см. patient_pii.go).
 type Patient struct {
 	ID             uint      `gorm:"primaryKey;autoIncrement;column:patient_id" json:"id"`
-	PassportSeries string    `gorm:"type:varchar(4);not null;uniqueIndex:idx_passport;column:passport_series" json:"passport_series"`
-	PassportNumber string    `gorm:"type:varchar(6);not null;uniqueIndex:idx_passport;column:passport_number" json:"passport_number"`
+	PassportSeries string    `gorm:"-" json:"passport_series"`
+	PassportNumber string    `gorm:"-" json:"passport_number"`
 	FullName       string    `gorm:"type:varchar(100);not null;column:full_name" json:"full_name"`
 	BirthDate      time.Time `gorm:"type:date;column:birth_date" json:"birth_date"`
 	Phone          string    `gorm:"type:varchar(20)" json:"phone"`
-	OmsNumber      string    `gorm:"type:varchar(16);not null;column:oms_number" json:"oms_number"`
+	OmsNumber      string    `gorm:"-" json:"oms_number"`
+	Email          string    `gorm:"type:varchar(100);column:email" json:"email,omitempty"`
+	// PhoneInvalid отмечает телефон, который не удалось привести к формату E.164 при миграции;
+	// номер нужно уточнить у пациента. Флаг снимается при изменении телефона.
+	PhoneInvalid bool `gorm:"column:phone_invalid;not null;default:false" json:"phone_invalid"`
+	// NotificationsOptOut отключает все уведомления пациенту (напоминания, изменения записей).
+	NotificationsOptOut bool `gorm:"column:notifications_opt_out;not null;default:false" json:"notifications_opt_out"`
+	// AnonymizedAt — время анонимизации карточки; у анонимизированной карточки вместо ФИО токен, документов и контактов нет.
+	AnonymizedAt *time.Time `gorm:"column:anonymized_at" json:"anonymized_at,omitempty"`
+
+	// PassportEncrypted — серия и номер паспорта ("4510123456"), зашифрованные активным ключом.
+	PassportEncrypted string `gorm:"type:text;column:passport_encrypted" json:"-"`
+	// PassportIndex — слепой индекс паспорта для точного поиска и проверки уникальности.
+	PassportIndex string `gorm:"type:varchar(64);column:passport_bidx" json:"-"`
+	OmsEncrypted  string `gorm:"type:text;column:oms_encrypted" json:"-"`
+	OmsIndex      string `gorm:"type:varchar(64);column:oms_bidx" json:"-"`
+	// Незашифрованные значения, оставшиеся от записей до включения шифрования; очищаются при сохранении.
+	LegacyPassportSeries *string `gorm:"column:passport_series" json:"-"`
+	LegacyPassportNumber *string `gorm:"column:passport_number" json:"-"`
+	LegacyOmsNumber      *string `gorm:"column:oms_number" json:"-"`
 }
 
 // PatientResponse определяет данные, возвращаемые API.
@@ -32,6 +53,7 @@ type CreatePatientRequest struct {
 	BirthDate      time.Time `json:"birth_date" binding:"required"`
 	Phone          string    `json:"phone"`
 	OmsNumber      string    `json:"oms_number" binding:"required,len=16"`
+	Email          string    `json:"email" binding:"omitempty,email"`
 }
 
 // UpdatePatientRequest определяет структуру для обновления существующего пациента.
@@ -42,4 +64,36 @@ type UpdatePatientRequest struct {
 	BirthDate      *time.Time `json:"birth_date,omitempty"`
 	Phone          string     `json:"phone,omitempty"`
 	OmsNumber      string     `json:"oms_number,omitempty" binding:"omitempty,len=16"`
+	Email          string     `json:"email,omitempty" binding:"omitempty,email"`
+}
+
+// UpdateNotificationSettingsRequest определяет структуру для включения или отключения уведомлений пациенту.
+type UpdateNotificationSettingsRequest struct {
+	OptOut *bool `json:"opt_out" binding:"required" example:"true"`
+}
+
+// PatientSearchQuery — разобранная строка поиска пациента. Все заданные условия должны выполняться одновременно.
+type PatientSearchQuery struct {
+	// NameWords — слова ФИО в нижнем регистре с «е» вместо «ё»; первое считается фамилией.
+	NameWords []string
+	// Initials — первые буквы имени и отчества по порядку.
+	Initials  []string
+	BirthYear *int
+	BirthDate *time.Time
+	// Digits — цифры запроса: полный номер полиса ОМС или паспорта либо окончание телефона.
+	Digits string
+}
+
+// PatientSearchHit — найденный пациент с оценкой соответствия запросу.
+type PatientSearchHit struct {
+	Patient
+	Relevance float64 `gorm:"column:relevance"`
+}
+
+// PatientSearchPage — страница результатов поиска пациентов.
+type PatientSearchPage struct {
+	Items    []PatientSearchResult `json:"items"`
+	Total    int64                 `json:"total" example:"42"`
+	Page     int                   `json:"page" example:"1"`
+	PageSize int                   `json:"page_size" example:"20"`
 }
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"ElectronicQueue/internal/utils"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Домены слепых индексов: одинаковые цифры в разных полях дают разные индексы.
+const (
+	passportIndexDomain = "passport"
+	omsIndexDomain      = "oms"
+)
+
+var piiCipher *utils.PIICipher
+
+// SetPIICipher задает шифр персональных данных пациентов. Вызывается один раз при запуске приложения.
+func SetPIICipher(c *utils.PIICipher) {
+	piiCipher = c
+}
+
+func requirePIICipher() (*utils.PIICipher, error) {
+	if piiCipher == nil {
+		return nil, errors.New("шифрование персональных данных не настроено")
+	}
+	return piiCipher, nil
+}
+
+// PassportIndex возвращает слепой индекс паспорта для поиска по серии и номеру.
+func PassportIndex(series, number string) string {
+	if piiCipher == nil {
+		return ""
+	}
+	return piiCipher.BlindIndex(passportIndexDomain, series+number)
+}
+
+// OMSIndex возвращает слепой индекс полиса ОМС для поиска по номеру.
+func OMSIndex(number string) string {
+	if piiCipher == nil {
+		return ""
+	}
+	return piiCipher.BlindIndex(omsIndexDomain, number)
+}
+
+// PIIActivePrefix возвращает префикс значений, зашифрованных активным ключом.
+func PIIActivePrefix() (string, error) {
+	c, err := requirePIICipher()
+	if err != nil {
+		return "", err
+	}
+	return c.ActivePrefix(), nil
+}
+
+// BeforeSave шифрует паспорт и полис ОМС активным ключом, пересчитывает слепые индексы
+// и очищает незашифрованные значения, оставшиеся от старых записей.
+func (p *Patient) BeforeSave(tx *gorm.DB) error {
+	c, err := requirePIICipher()
+	if err != nil {
+		return err
+	}
+	passport := p.PassportSeries + p.PassportNumber
+	if p.PassportEncrypted, err = c.Encrypt(passport); err != nil {
+		return fmt.Errorf("ошибка шифрования паспорта: %w", err)
+	}
+	if p.OmsEncrypted, err = c.Encrypt(p.OmsNumber); err != nil {
+		return fmt.Errorf("ошибка шифрования полиса ОМС: %w", err)
+	}
+	p.PassportIndex = c.BlindIndex(passportIndexDomain, passport)
+	p.OmsIndex = c.BlindIndex(omsIndexDomain, p.OmsNumber)
+	p.LegacyPassportSeries, p.LegacyPassportNumber, p.LegacyOmsNumber = nil, nil, nil
+	return nil
+}
+
+// AfterFind расшифровывает паспорт и полис ОМС после загрузки карточки.
+func (p *Patient) AfterFind(tx *gorm.DB) error {
+	return p.DecryptPII()
+}
+
+// DecryptPII заполняет PassportSeries, PassportNumber и OmsNumber из зашифрованных полей, а для записей,
+// еще не перешифрованных после включения шифрования, — из старых незашифрованных колонок.
+// Вызывается явно для результатов Scan, для которых GORM не выполняет хуки.
+func (p *Patient) DecryptPII() error {
+	if p.PassportEncrypted == "" && p.OmsEncrypted == "" {
+		p.PassportSeries = derefString(p.LegacyPassportSeries)
+		p.PassportNumber = derefString(p.LegacyPassportNumber)
+		p.OmsNumber = derefString(p.LegacyOmsNumber)
+		return nil
+	}
+	c, err := requirePIICipher()
+	if err != nil {
+		return err
+	}
+	passport, err := c.Decrypt(p.PassportEncrypted)
+	if err != nil {
+		return fmt.Errorf("пациент %d: паспорт: %w", p.ID, err)
+	}
+	if len(passport) == 10 {
+		p.PassportSeries, p.PassportNumber = passport[:4], passport[4:]
+	}
+	if p.OmsNumber, err = c.Decrypt(p.OmsEncrypted); err != nil {
+		return fmt.Errorf("пациент %d: полис ОМС: %w", p.ID, err)
+	}
+	return nil
+}
+
+// MaskPII скрывает документы и контакты пациента для ролей, которым не нужны полные данные:
+// от паспорта остаются код региона и последние цифры номера, от полиса и телефона — последние 4 цифры.
+func (p *Patient) MaskPII() {
+	p.PassportSeries, p.PassportNumber = utils.MaskPassport(p.PassportSeries, p.PassportNumber)
+	p.OmsNumber = utils.MaskTail(p.OmsNumber, 4)
+	p.Phone = utils.MaskPhone(p.Phone)
+	p.Email = utils.MaskEmail(p.Email)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
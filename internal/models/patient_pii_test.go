@@ -0,0 +1,110 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"ElectronicQueue/internal/utils"
+)
+
+func testPIIKey(t *testing.T) string {
+	t.Helper()
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatalf("rand: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func setTestPIICipher(t *testing.T, keys, active, index string) {
+	t.Helper()
+	c, err := utils.NewPIICipher(keys, active, index)
+	if err != nil {
+		t.Fatalf("NewPIICipher: %v", err)
+	}
+	previous := piiCipher
+	SetPIICipher(c)
+	t.Cleanup(func() { SetPIICipher(previous) })
+}
+
+// TestPatientPIIReencryption повторяет то, что делает ReencryptPII для каждой карточки:
+// загрузка (AfterFind) расшифровывает значения прежним ключом, сохранение (BeforeSave) шифрует активным.
+func TestPatientPIIReencryption(t *testing.T) {
+	oldKey, newKey, indexKey := testPIIKey(t), testPIIKey(t), testPIIKey(t)
+
+	setTestPIICipher(t, "1:"+oldKey, "1", indexKey)
+	stored := Patient{ID: 7, PassportSeries: "4510", PassportNumber: "123456", OmsNumber: "1234567890123452"}
+	if err := stored.BeforeSave(nil); err != nil {
+		t.Fatalf("BeforeSave: %v", err)
+	}
+	if !strings.HasPrefix(stored.PassportEncrypted, "k1:") || !strings.HasPrefix(stored.OmsEncrypted, "k1:") {
+		t.Fatalf("values must be encrypted with key 1: %q, %q", stored.PassportEncrypted, stored.OmsEncrypted)
+	}
+	passportIndex, omsIndex := stored.PassportIndex, stored.OmsIndex
+
+	setTestPIICipher(t, "1:"+oldKey+",2:"+newKey, "2", indexKey)
+	loaded := Patient{ID: 7, PassportEncrypted: stored.PassportEncrypted, OmsEncrypted: stored.OmsEncrypted}
+	if err := loaded.AfterFind(nil); err != nil {
+		t.Fatalf("AfterFind: %v", err)
+	}
+	if loaded.PassportSeries != "4510" || loaded.PassportNumber != "123456" || loaded.OmsNumber != "1234567890123452" {
+		t.Fatalf("decrypted = %q %q %q", loaded.PassportSeries, loaded.PassportNumber, loaded.OmsNumber)
+	}
+
+	if err := loaded.BeforeSave(nil); err != nil {
+		t.Fatalf("BeforeSave after rotation: %v", err)
+	}
+	prefix, err := PIIActivePrefix()
+	if err != nil {
+		t.Fatalf("PIIActivePrefix: %v", err)
+	}
+	if prefix != "k2:" || !strings.HasPrefix(loaded.PassportEncrypted, prefix) || !strings.HasPrefix(loaded.OmsEncrypted, prefix) {
+		t.Fatalf("values must be re-encrypted with key 2: %q, %q", loaded.PassportEncrypted, loaded.OmsEncrypted)
+	}
+	if loaded.PassportIndex != passportIndex || loaded.OmsIndex != omsIndex {
+		t.Error("blind indexes must not change on re-encryption")
+	}
+	if PassportIndex("4510", "123456") != passportIndex || OMSIndex("1234567890123452") != omsIndex {
+		t.Error("search indexes must match stored indexes")
+	}
+}
+
+func TestPatientPIILegacyPlaintext(t *testing.T) {
+	setTestPIICipher(t, "1:"+testPIIKey(t), "1", testPIIKey(t))
+
+	series, number, oms := "4510", "123456", "1234567890123452"
+	legacy := Patient{LegacyPassportSeries: &series, LegacyPassportNumber: &number, LegacyOmsNumber: &oms}
+	if err := legacy.DecryptPII(); err != nil {
+		t.Fatalf("DecryptPII: %v", err)
+	}
+	if legacy.PassportSeries != series || legacy.PassportNumber != number || legacy.OmsNumber != oms {
+		t.Fatalf("legacy values not read: %q %q %q", legacy.PassportSeries, legacy.PassportNumber, legacy.OmsNumber)
+	}
+
+	if err := legacy.BeforeSave(nil); err != nil {
+		t.Fatalf("BeforeSave: %v", err)
+	}
+	if legacy.LegacyPassportSeries != nil || legacy.LegacyPassportNumber != nil || legacy.LegacyOmsNumber != nil {
+		t.Error("plaintext columns must be cleared on save")
+	}
+	if legacy.PassportEncrypted == "" || legacy.OmsEncrypted == "" {
+		t.Error("legacy values must be encrypted on save")
+	}
+}
+
+func TestPatientMaskPII(t *testing.T) {
+	p := Patient{
+		PassportSeries: "4510",
+		PassportNumber: "123456",
+		OmsNumber:      "1234567890123452",
+		Phone:          "+79001234567",
+		Email:          "ivanov@mail.ru",
+	}
+	p.MaskPII()
+	if p.PassportSeries != "45**" || p.PassportNumber != "***456" || p.OmsNumber != "************3452" ||
+		p.Phone != "+7******4567" || p.Email != "i***@mail.ru" {
+		t.Errorf("MaskPII = %+v", p)
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// ReferralUrgency определяет срочность направления.
+type ReferralUrgency string
+
+const (
+	ReferralRoutine   ReferralUrgency = "плановое"
+	ReferralUrgent    ReferralUrgency = "срочное"
+	ReferralEmergency ReferralUrgency = "экстренное"
+)
+
+// ReferralStatus определяет статус направления.
+type ReferralStatus string
+
+const (
+	ReferralOpen      ReferralStatus = "открыто"
+	ReferralFulfilled ReferralStatus = "исполнено"
+	ReferralCancelled ReferralStatus = "отменено"
+)
+
+// Referral представляет направление пациента к специалисту, выданное врачом на приеме.
+// При записи по направлению в AppointmentID сохраняется созданная запись.
+type Referral struct {
+	ID                   uint            `gorm:"primaryKey;autoIncrement;column:referral_id" json:"id"`
+	PatientID            uint            `gorm:"not null;column:patient_id" json:"patient_id"`
+	DoctorID             *uint           `gorm:"column:doctor_id" json:"doctor_id,omitempty"`
+	SourceAppointmentID  *uint           `gorm:"column:source_appointment_id" json:"source_appointment_id,omitempty"`
+	TargetSpecialization string          `gorm:"type:varchar(100);not null;column:target_specialization" json:"target_specialization"`
+	Urgency              ReferralUrgency `gorm:"type:varchar(20);not null;default:'плановое';column:urgency" json:"urgency"`
+	ExpiresAt            time.Time       `gorm:"type:date;not null;column:expires_at" json:"expires_at"`
+	Note                 *string         `gorm:"column:note" json:"note,omitempty"`
+	Status               ReferralStatus  `gorm:"type:varchar(20);not null;default:'открыто';column:status" json:"status"`
+	AppointmentID        *uint           `gorm:"column:appointment_id" json:"appointment_id,omitempty"`
+	CreatedAt            time.Time       `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+	FulfilledAt          *time.Time      `gorm:"column:fulfilled_at" json:"fulfilled_at,omitempty"`
+	Doctor               *Doctor         `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+}
+
+// TableName указывает GORM имя таблицы для модели Referral.
+func (Referral) TableName() string {
+	return "referrals"
+}
+
+// CreateReferralRequest определяет структуру для выдачи направления врачом.
+type CreateReferralRequest struct {
+	AppointmentID        uint            `json:"appointment_id" binding:"required" example:"42"`
+	TargetSpecialization string          `json:"target_specialization" binding:"required" example:"Кардиолог"`
+	Urgency              ReferralUrgency `json:"urgency,omitempty" binding:"omitempty,oneof=плановое срочное экстренное" example:"плановое"`
+	// ExpiresAt — дата окончания действия (YYYY-MM-DD); по умолчанию зависит от срочности.
+	ExpiresAt string `json:"expires_at,omitempty" example:"2025-08-20"`
+	Note      string `json:"note,omitempty" example:"ЭКГ, консультация по результатам"`
+}
+
+// ExpiredReferralRow описывает неисполненное направление с истекшим сроком для отчета.
+type ExpiredReferralRow struct {
+	ReferralID           uint            `json:"referral_id"`
+	PatientID            uint            `json:"patient_id"`
+	PatientName          string          `json:"patient_name"`
+	PatientPhone         string          `json:"patient_phone"`
+	DoctorName           *string         `json:"doctor_name,omitempty"`
+	TargetSpecialization string          `json:"target_specialization"`
+	Urgency              ReferralUrgency `json:"urgency"`
+	CreatedAt            time.Time       `json:"created_at"`
+	ExpiresAt            time.Time       `json:"expires_at"`
+	DaysOverdue          int             `json:"days_overdue"`
+}
+
+// PatientSearchResult — найденный пациент вместе с его открытыми направлениями.
+type PatientSearchResult struct {
+	Patient
+	OpenReferrals []Referral `json:"open_referrals"`
+	// Relevance — оценка соответствия поисковому запросу; результаты упорядочены по ней.
+	Relevance float64 `json:"relevance,omitempty" example:"1.75"`
+}
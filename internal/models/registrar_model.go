@@ -8,5 +8,6 @@ type Registrar struct {
 	Login        string    `gorm:"column:login;unique;not null"`
 	PasswordHash string    `gorm:"column:password_hash;not null"`
 	IsActive     bool      `gorm:"column:is_active;default:true"`
+	IsSenior     bool      `gorm:"column:is_senior;default:false"`
 	CreatedAt    time.Time `gorm:"column:created_at"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// DirectRoutingRule определяет, какие записи при регистрации через киоск направляются сразу в очередь
+// кабинета врача, минуя регистратуру. Правило задается либо для услуги терминала, либо для специальности врача.
+type DirectRoutingRule struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement;column:rule_id" json:"id"`
+	ServiceID      *string   `gorm:"type:varchar(64);column:service_id" json:"service_id,omitempty"`
+	Specialization *string   `gorm:"type:varchar(100);column:specialization" json:"specialization,omitempty"`
+	CreatedAt      time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName указывает GORM имя таблицы для модели DirectRoutingRule.
+func (DirectRoutingRule) TableName() string {
+	return "direct_routing_rules"
+}
+
+// CreateDirectRoutingRuleRequest определяет структуру для создания правила прямой маршрутизации.
+// Должно быть указано ровно одно из полей.
+type CreateDirectRoutingRuleRequest struct {
+	ServiceID      string `json:"service_id,omitempty" example:"confirm_appointment"`
+	Specialization string `json:"specialization,omitempty" example:"Терапевт"`
+}
+
+// CheckInResult описывает результат регистрации по записи через киоск.
+type CheckInResult struct {
+	Ticket *Ticket
+	// DirectToDoctor — талон сразу направлен в очередь кабинета врача, минуя регистратуру.
+	DirectToDoctor  bool
+	Cabinet         *int
+	AppointmentTime time.Time
+	DoctorName      string
+}
@@ -13,7 +13,45 @@ type Schedule struct {
 	EndTime     string    `gorm:"type:time;not null;column:end_time" json:"end_time"`
 	IsAvailable bool      `gorm:"default:true;column:is_available" json:"is_available"`
 	Cabinet     *int      `gorm:"column:cabinet" json:"cabinet,omitempty"`
-	Doctor      Doctor    `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+	// Capacity — число пациентов, которых можно записать в слот; BookedCount — занятые места.
+	Capacity    int `gorm:"not null;default:1;column:capacity" json:"capacity"`
+	BookedCount int `gorm:"not null;default:0;column:booked_count" json:"booked_count"`
+	// IsBlocked — слот закрыт администратором. IsAvailable вычисляется как
+	// BookedCount < Capacity && !IsBlocked и ведется репозиторием.
+	IsBlocked bool `gorm:"not null;default:false;column:is_blocked" json:"is_blocked"`
+	// CalendarSequence — версия события календаря (SEQUENCE), ведется триггерами в БД.
+	CalendarSequence int    `gorm:"column:calendar_sequence;->" json:"-"`
+	Doctor           Doctor `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+}
+
+// FreePlaces возвращает число свободных мест в слоте.
+func (s Schedule) FreePlaces() int {
+	if s.BookedCount >= s.Capacity {
+		return 0
+	}
+	return s.Capacity - s.BookedCount
+}
+
+// StartsAt возвращает момент начала слота в локальном часовом поясе сервера.
+func (s Schedule) StartsAt() (time.Time, error) {
+	return slotMoment(s.Date, s.StartTime)
+}
+
+// EndsAt возвращает момент окончания слота в локальном часовом поясе сервера.
+func (s Schedule) EndsAt() (time.Time, error) {
+	return slotMoment(s.Date, s.EndTime)
+}
+
+// slotMoment объединяет дату слота и время вида "15:04:05" (или "15:04") в один момент времени.
+func slotMoment(date time.Time, clock string) (time.Time, error) {
+	if len(clock) > 8 {
+		clock = clock[:8]
+	}
+	layout := "2006-01-02 15:04:05"
+	if len(clock) == 5 {
+		layout = "2006-01-02 15:04"
+	}
+	return time.ParseInLocation(layout, date.Format("2006-01-02")+" "+clock, time.Local)
 }
 
 // ScheduleResponse определяет данные, возвращаемые API, возможно с информацией о враче.
@@ -24,7 +62,10 @@ type ScheduleResponse struct {
 	StartTime   string    `json:"start_time"`
 	EndTime     string    `json:"end_time"`
 	IsAvailable bool      `json:"is_available"`
+	IsBlocked   bool      `json:"is_blocked"`
 	Cabinet     *int      `json:"cabinet,omitempty"`
+	Capacity    int       `json:"capacity"`
+	BookedCount int       `json:"booked_count"`
 }
 
 // CreateScheduleRequest определяет структуру для создания нового слота в расписании.
@@ -35,9 +76,43 @@ type CreateScheduleRequest struct {
 	EndTime     time.Time `json:"end_time" binding:"required" example:"2025-01-01T10:00:00Z"`
 	IsAvailable *bool     `json:"is_available" example:"true"`
 	Cabinet     *int      `json:"cabinet" example:"101"`
+	// Capacity — вместимость слота для групповых процедур; по умолчанию 1.
+	Capacity *int `json:"capacity,omitempty" binding:"omitempty,min=1,max=100" example:"1"`
 }
 
 // UpdateScheduleRequest определяет структуру для обновления статуса слота (например, блокировка).
 type UpdateScheduleRequest struct {
 	IsAvailable *bool `json:"is_available" binding:"required"`
 }
+
+// ScheduleImportRowStatus определяет результат обработки строки файла импорта расписания.
+type ScheduleImportRowStatus string
+
+const (
+	ScheduleImportRowValid   ScheduleImportRowStatus = "корректна"
+	ScheduleImportRowCreated ScheduleImportRowStatus = "создана"
+	ScheduleImportRowError   ScheduleImportRowStatus = "ошибка"
+)
+
+// ScheduleImportRowResult описывает результат проверки одной строки файла импорта.
+type ScheduleImportRowResult struct {
+	Row        int                     `json:"row" example:"2"`
+	Doctor     string                  `json:"doctor" example:"ivanov"`
+	Date       string                  `json:"date" example:"2025-07-20"`
+	StartTime  string                  `json:"start_time" example:"09:00"`
+	EndTime    string                  `json:"end_time" example:"09:30"`
+	Cabinet    *int                    `json:"cabinet,omitempty" example:"101"`
+	Status     ScheduleImportRowStatus `json:"status" example:"корректна"`
+	Errors     []string                `json:"errors,omitempty"`
+	ScheduleID *uint                   `json:"schedule_id,omitempty" example:"15"`
+}
+
+// ScheduleImportReport содержит итог импорта расписания с результатом по каждой строке.
+type ScheduleImportReport struct {
+	DryRun  bool                      `json:"dry_run"`
+	Total   int                       `json:"total" example:"10"`
+	Valid   int                       `json:"valid" example:"8"`
+	Invalid int                       `json:"invalid" example:"2"`
+	Created int                       `json:"created" example:"8"`
+	Rows    []ScheduleImportRowResult `json:"rows"`
+}
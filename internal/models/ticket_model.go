@@ -27,6 +27,8 @@ type Ticket struct {
 	CalledAt     *time.Time   `gorm:"column:called_at" json:"called_at,omitempty"`
 	StartedAt    *time.Time   `gorm:"column:started_at" json:"started_at,omitempty"`
 	CompletedAt  *time.Time   `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	// IsLate отмечает талон пациента, пришедшего по записи позже окна регистрации.
+	IsLate bool `gorm:"column:is_late;not null;default:false" json:"is_late"`
 }
 
 // TicketResponse определяет данные, возвращаемые API.
@@ -41,6 +43,7 @@ type TicketResponse struct {
 	CalledAt     *time.Time   `json:"called_at,omitempty"`
 	StartedAt    *time.Time   `json:"started_at,omitempty"`
 	CompletedAt  *time.Time   `json:"completed_at,omitempty"`
+	IsLate       bool         `json:"is_late"`
 }
 
 // RegistrarTicketResponse расширяет Ticket, добавляя время записи для нужд регистратуры.
@@ -70,6 +73,7 @@ type DailyReportRow struct {
 	CalledAt             *time.Time   `json:"called_at"`
 	CompletedAt          *time.Time   `json:"completed_at"`
 	Duration             *string      `json:"duration"`
+	IsLate               bool         `json:"is_late"`
 }
 
 // ToResponse преобразует модель Ticket в объект ответа TicketResponse (DTO)
@@ -85,5 +89,6 @@ func (t *Ticket) ToResponse() TicketResponse {
 		CalledAt:     t.CalledAt,
 		StartedAt:    t.StartedAt,
 		CompletedAt:  t.CompletedAt,
+		IsLate:       t.IsLate,
 	}
 }
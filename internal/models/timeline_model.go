@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// VisitOutcome описывает итог записи на прием в истории пациента.
+type VisitOutcome string
+
+const (
+	// VisitPlanned — прием еще не наступил, пациент не пришел.
+	VisitPlanned VisitOutcome = "запланирован"
+	// VisitInQueue — пациент пришел, талон еще не обслужен врачом.
+	VisitInQueue VisitOutcome = "в очереди"
+	// VisitInProgress — пациент на приеме у врача.
+	VisitInProgress VisitOutcome = "на приеме"
+	VisitCompleted  VisitOutcome = "завершен"
+	// VisitNotServed — пациент пришел в прошедший день приема, но талон так и не был обслужен.
+	VisitNotServed VisitOutcome = "не обслужен"
+	// VisitNoShow — день приема прошел, а пациент так и не получил талон.
+	VisitNoShow VisitOutcome = "неявка"
+)
+
+// PatientVisit — запись на прием пациента с талоном, временем ожидания и обслуживания и итогом визита.
+// Та же структура хранится в архиве визитов (visit_archive), куда записи попадают перед ночной очисткой.
+type PatientVisit struct {
+	PatientID     uint          `gorm:"column:patient_id" json:"-"`
+	AppointmentID uint          `gorm:"column:appointment_id" json:"appointment_id" example:"42"`
+	TicketID      *uint         `gorm:"column:ticket_id" json:"ticket_id,omitempty" example:"315"`
+	TicketNumber  *string       `gorm:"column:ticket_number" json:"ticket_number,omitempty" example:"A015"`
+	ServiceType   *string       `gorm:"column:service_type" json:"service_type,omitempty"`
+	TicketStatus  *TicketStatus `gorm:"column:ticket_status" json:"ticket_status,omitempty"`
+	IsLate        bool          `gorm:"column:is_late" json:"is_late"`
+	DoctorID      *uint         `gorm:"column:doctor_id" json:"doctor_id,omitempty" example:"3"`
+	DoctorName    *string       `gorm:"column:doctor_name" json:"doctor_name,omitempty" example:"Смирнова Е.А."`
+	// Specialization — специализация врача на момент визита.
+	Specialization *string      `gorm:"column:specialization" json:"specialization,omitempty" example:"Терапевт"`
+	Cabinet        *int         `gorm:"column:cabinet" json:"cabinet,omitempty" example:"12"`
+	VisitDate      time.Time    `gorm:"column:visit_date" json:"visit_date"`
+	StartTime      string       `gorm:"column:start_time" json:"start_time" example:"09:30:00"`
+	EndTime        string       `gorm:"column:end_time" json:"end_time" example:"09:45:00"`
+	Outcome        VisitOutcome `gorm:"column:outcome" json:"outcome" example:"завершен"`
+	// Arrival — результат регистрации по записи через киоск: вовремя, рано или опоздание.
+	Arrival              *string    `gorm:"column:arrival" json:"arrival,omitempty" example:"вовремя"`
+	ArrivalOffsetMinutes *int       `gorm:"column:arrival_offset_minutes" json:"arrival_offset_minutes,omitempty" example:"-5"`
+	TicketCreatedAt      *time.Time `gorm:"column:ticket_created_at" json:"ticket_created_at,omitempty"`
+	CalledAt             *time.Time `gorm:"column:called_at" json:"called_at,omitempty"`
+	StartedAt            *time.Time `gorm:"column:started_at" json:"started_at,omitempty"`
+	CompletedAt          *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	WindowNumber         *int       `gorm:"column:window_number" json:"window_number,omitempty" example:"2"`
+	// WaitSeconds — от выдачи талона до первого вызова в окно регистратуры.
+	WaitSeconds *int `gorm:"column:wait_seconds" json:"wait_seconds,omitempty" example:"540"`
+	// ServiceSeconds — суммарное время обслуживания в окне регистратуры.
+	ServiceSeconds *int `gorm:"column:service_seconds" json:"service_seconds,omitempty" example:"180"`
+	// VisitSeconds — длительность приема у врача.
+	VisitSeconds *int `gorm:"column:visit_seconds" json:"visit_seconds,omitempty" example:"900"`
+	// ArchivedAt заполнено у визитов из архива.
+	ArchivedAt *time.Time `gorm:"column:archived_at" json:"archived_at,omitempty"`
+}
+
+// StartsAt возвращает момент начала приема в локальном часовом поясе сервера.
+func (v PatientVisit) StartsAt() (time.Time, error) {
+	return slotMoment(v.VisitDate, v.StartTime)
+}
+
+// TimelineEntryKind определяет тип события в истории пациента.
+type TimelineEntryKind string
+
+const (
+	TimelineVisit    TimelineEntryKind = "visit"
+	TimelineReferral TimelineEntryKind = "referral"
+)
+
+// PatientTimelineEntry — событие в истории пациента: визит (запись на прием с талоном) или выданное направление.
+type PatientTimelineEntry struct {
+	Kind TimelineEntryKind `json:"kind" example:"visit"`
+	// OccurredAt — начало приема для визита, время выдачи для направления.
+	OccurredAt time.Time `json:"occurred_at"`
+	// Archived — визит взят из архива, его запись на прием уже удалена очисткой.
+	Archived bool          `json:"archived"`
+	Visit    *PatientVisit `json:"visit,omitempty"`
+	Referral *Referral     `json:"referral,omitempty"`
+}
+
+// PatientTimelinePage — страница истории пациента, новые события первыми.
+type PatientTimelinePage struct {
+	Items    []PatientTimelineEntry `json:"items"`
+	Total    int                    `json:"total" example:"42"`
+	Page     int                    `json:"page" example:"1"`
+	PageSize int                    `json:"page_size" example:"20"`
+}
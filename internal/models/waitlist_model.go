@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// WaitlistStatus определяет статус заявки в листе ожидания.
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting   WaitlistStatus = "ожидает"
+	WaitlistStatusOffered   WaitlistStatus = "предложено"
+	WaitlistStatusConfirmed WaitlistStatus = "подтверждено"
+	WaitlistStatusCancelled WaitlistStatus = "отменено"
+)
+
+// WaitlistEntry представляет заявку пациента в листе ожидания к врачу или специальности.
+type WaitlistEntry struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement;column:waitlist_id" json:"id"`
+	PatientID      uint           `gorm:"not null;column:patient_id" json:"patient_id"`
+	DoctorID       *uint          `gorm:"column:doctor_id" json:"doctor_id,omitempty"`
+	Specialization *string        `gorm:"type:varchar(100);column:specialization" json:"specialization,omitempty"`
+	DateFrom       time.Time      `gorm:"type:date;not null;column:date_from" json:"date_from"`
+	DateTo         time.Time      `gorm:"type:date;not null;column:date_to" json:"date_to"`
+	Status         WaitlistStatus `gorm:"type:varchar(20);not null;default:'ожидает';column:status" json:"status"`
+	Note           string         `gorm:"column:note" json:"note,omitempty"`
+	HeldScheduleID *uint          `gorm:"column:held_schedule_id" json:"held_schedule_id,omitempty"`
+	HoldExpiresAt  *time.Time     `gorm:"column:hold_expires_at" json:"hold_expires_at,omitempty"`
+	OfferedAt      *time.Time     `gorm:"column:offered_at" json:"offered_at,omitempty"`
+	AppointmentID  *uint          `gorm:"column:appointment_id" json:"appointment_id,omitempty"`
+	CreatedAt      time.Time      `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Patient        Patient        `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+	Doctor         *Doctor        `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+	HeldSchedule   *Schedule      `gorm:"foreignKey:HeldScheduleID" json:"held_schedule,omitempty"`
+}
+
+// TableName явно задает имя таблицы для GORM.
+func (WaitlistEntry) TableName() string {
+	return "waitlist_entries"
+}
+
+// CreateWaitlistEntryRequest определяет структуру для постановки пациента в лист ожидания.
+// Должен быть указан либо конкретный врач, либо специальность.
+type CreateWaitlistEntryRequest struct {
+	PatientID      uint      `json:"patient_id" binding:"required" example:"1"`
+	DoctorID       *uint     `json:"doctor_id,omitempty" example:"2"`
+	Specialization string    `json:"specialization,omitempty" example:"Кардиолог"`
+	DateFrom       time.Time `json:"date_from" binding:"required" example:"2025-07-20T00:00:00Z"`
+	DateTo         time.Time `json:"date_to" binding:"required" example:"2025-07-31T00:00:00Z"`
+	Note           string    `json:"note,omitempty"`
+}
@@ -0,0 +1,58 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileChannel дописывает уведомления построчно в файл. Используется для локального тестирования.
+type FileChannel struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileChannel создает новый экземпляр FileChannel и при необходимости создает каталог для файла.
+func NewFileChannel(path string) (*FileChannel, error) {
+	if path == "" {
+		return nil, fmt.Errorf("notification file path is not set")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create notification file directory: %w", err)
+	}
+	return &FileChannel{path: path}, nil
+}
+
+func (c *FileChannel) Name() string {
+	return "file"
+}
+
+func (c *FileChannel) AddressKind() AddressKind {
+	return AddressPhone
+}
+
+func (c *FileChannel) Send(ctx context.Context, msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open notification file: %w", err)
+	}
+	defer f.Close()
+
+	line := strings.Join([]string{
+		time.Now().Format(time.RFC3339),
+		msg.Recipient,
+		msg.Subject,
+		strings.ReplaceAll(msg.Body, "\n", " "),
+	}, "\t")
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
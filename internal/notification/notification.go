@@ -0,0 +1,79 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"ElectronicQueue/internal/config"
+	"ElectronicQueue/internal/logger"
+)
+
+// Message описывает одно сообщение для пациента.
+type Message struct {
+	Recipient string // Номер телефона или адрес, в зависимости от канала
+	Subject   string
+	Body      string
+}
+
+// AddressKind определяет, какой контакт пациента нужен каналу доставки.
+type AddressKind int
+
+const (
+	AddressPhone AddressKind = iota
+	AddressEmail
+)
+
+// Channel — канал доставки уведомлений. Реализации подключаются через конфиг.
+type Channel interface {
+	Name() string
+	AddressKind() AddressKind
+	Send(ctx context.Context, msg Message) error
+}
+
+// Recipient выбирает из контактов пациента адрес, подходящий для канала.
+// Возвращает пустую строку, если нужного контакта нет.
+func Recipient(ch Channel, phone, email string) string {
+	if ch.AddressKind() == AddressEmail {
+		return email
+	}
+	return phone
+}
+
+// NewChannel возвращает канал доставки, выбранный в конфигурации.
+func NewChannel(cfg *config.Config) (Channel, error) {
+	switch cfg.NotificationChannel {
+	case "", "log":
+		return NewLogChannel(), nil
+	case "file":
+		return NewFileChannel(cfg.NotificationFile)
+	case "sms":
+		return NewSMSChannel(cfg.SMSGatewayURL, cfg.SMSGatewayToken, cfg.SMSSender)
+	case "email":
+		return NewSMTPChannel(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	default:
+		return nil, fmt.Errorf("unknown notification channel: %s", cfg.NotificationChannel)
+	}
+}
+
+// LogChannel пишет уведомления в лог приложения. Используется для локальной отладки.
+type LogChannel struct {
+	log *logger.AsyncLogger
+}
+
+// NewLogChannel создает новый экземпляр LogChannel.
+func NewLogChannel() *LogChannel {
+	return &LogChannel{log: logger.Default().WithField("module", "notification")}
+}
+
+func (c *LogChannel) Name() string {
+	return "log"
+}
+
+func (c *LogChannel) AddressKind() AddressKind {
+	return AddressPhone
+}
+
+func (c *LogChannel) Send(ctx context.Context, msg Message) error {
+	c.log.WithField("recipient", msg.Recipient).WithField("subject", msg.Subject).Info(msg.Body)
+	return nil
+}
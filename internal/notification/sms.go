@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SMSChannel отправляет SMS через HTTP-шлюз. Шлюз принимает POST с JSON вида
+// {"to": "...", "text": "...", "sender": "..."} и авторизацию по Bearer-токену.
+type SMSChannel struct {
+	url    string
+	token  string
+	sender string
+	client *http.Client
+}
+
+// NewSMSChannel создает новый экземпляр SMSChannel.
+func NewSMSChannel(url, token, sender string) (*SMSChannel, error) {
+	if url == "" {
+		return nil, fmt.Errorf("SMS_GATEWAY_URL is not set")
+	}
+	return &SMSChannel{
+		url:    url,
+		token:  token,
+		sender: sender,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (c *SMSChannel) Name() string {
+	return "sms"
+}
+
+func (c *SMSChannel) AddressKind() AddressKind {
+	return AddressPhone
+}
+
+type smsRequest struct {
+	To     string `json:"to"`
+	Text   string `json:"text"`
+	Sender string `json:"sender,omitempty"`
+}
+
+func (c *SMSChannel) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(smsRequest{To: msg.Recipient, Text: msg.Body, Sender: c.sender})
+	if err != nil {
+		return fmt.Errorf("failed to marshal sms request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create sms request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms gateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms gateway returned status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
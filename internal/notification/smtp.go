@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPChannel отправляет уведомления по электронной почте через SMTP-сервер.
+type SMTPChannel struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPChannel создает новый экземпляр SMTPChannel.
+// Если имя пользователя не задано, письма отправляются без авторизации.
+func NewSMTPChannel(host, port, username, password, from string) (*SMTPChannel, error) {
+	if host == "" {
+		return nil, fmt.Errorf("SMTP_HOST is not set")
+	}
+	if from == "" {
+		return nil, fmt.Errorf("SMTP_FROM is not set")
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPChannel{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}, nil
+}
+
+func (c *SMTPChannel) Name() string {
+	return "email"
+}
+
+func (c *SMTPChannel) AddressKind() AddressKind {
+	return AddressEmail
+}
+
+// Send отправляет письмо. net/smtp не поддерживает контекст, поэтому отмена
+// проверяется только перед началом отправки.
+func (c *SMTPChannel) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + c.from + "\r\n")
+	b.WriteString("To: " + msg.Recipient + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	if err := smtp.SendMail(c.addr, c.auth, c.from, []string{msg.Recipient}, []byte(b.String())); err != nil {
+		return fmt.Errorf("smtp send failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package pubsub
+
+import (
+	"ElectronicQueue/internal/models"
+	"encoding/json"
+	"fmt"
+)
+
+// Каналы LISTEN/NOTIFY, в которые пишут триггеры базы данных.
+const (
+	ChannelTicketUpdate   = "ticket_update"
+	ChannelScheduleUpdate = "schedule_update"
+)
+
+// Имена событий, которые публикуют сервисы.
+const (
+	EventScheduleUpdate     = "schedule_update"
+	EventDoctorStatusUpdate = "doctor_status_update"
+	EventProcessUpdate      = "process_update"
+	EventAdsUpdate          = "ads_update"
+	// EventDisplayConfig несет устройству отображения его новую конфигурацию; EventDisplayRevoked сообщает,
+	// что устройство удалено из реестра или его токен заменен.
+	EventDisplayConfig  = "display_config"
+	EventDisplayRevoked = "display_revoked"
+	// EventResync сообщает клиенту, что часть событий пропущена и нужно заново загрузить полное состояние.
+	EventResync = "resync"
+)
+
+// Notification — уведомление PostgreSQL: канал и JSON, сформированный триггером.
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// DoctorStatusEvent — данные события смены статуса врача.
+type DoctorStatusEvent struct {
+	DoctorID uint                `json:"doctor_id"`
+	Status   models.DoctorStatus `json:"status"`
+}
+
+// ProcessEvent — данные события включения или отключения бизнес-процесса.
+type ProcessEvent struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// AdsEvent — данные события изменения рекламного материала.
+type AdsEvent struct {
+	AdID   uint   `json:"ad_id"`
+	Action string `json:"action"` // create, update, delete
+}
+
+// ticketNotification — JSON триггера notify_ticket_change.
+type ticketNotification struct {
+	Action  string                `json:"action"`
+	Cabinet *int                  `json:"cabinet"`
+	Data    models.TicketResponse `json:"data"`
+}
+
+// scheduleNotification — поля JSON триггера notify_schedule_change, нужные для ключа события.
+type scheduleNotification struct {
+	Operation string `json:"operation"`
+	Data      struct {
+		Doctors []struct {
+			Slots []struct {
+				Cabinet *int `json:"cabinet"`
+			} `json:"slots"`
+		} `json:"doctors"`
+	} `json:"data"`
+}
+
+// DecodeNotification преобразует уведомление PostgreSQL в событие шины.
+func DecodeNotification(n Notification) (Event, error) {
+	switch n.Channel {
+	case ChannelTicketUpdate:
+		var payload ticketNotification
+		if err := json.Unmarshal([]byte(n.Payload), &payload); err != nil {
+			return Event{}, fmt.Errorf("некорректное уведомление о талоне: %w", err)
+		}
+		return TicketEvent(payload.Action, payload.Data, payload.Cabinet), nil
+
+	case ChannelScheduleUpdate:
+		var payload scheduleNotification
+		if err := json.Unmarshal([]byte(n.Payload), &payload); err != nil {
+			return Event{}, fmt.Errorf("некорректное уведомление о расписании: %w", err)
+		}
+		var cabinet *int
+		if len(payload.Data.Doctors) > 0 && len(payload.Data.Doctors[0].Slots) > 0 {
+			cabinet = payload.Data.Doctors[0].Slots[0].Cabinet
+		}
+		// Клиенты табло расписания ожидают JSON триггера без изменений.
+		return Event{Topic: TopicSchedule, Name: EventScheduleUpdate, Cabinet: cabinet, Data: json.RawMessage(n.Payload)}, nil
+	}
+	return Event{}, fmt.Errorf("неизвестный канал уведомлений %q", n.Channel)
+}
+
+// TicketEvent создает событие об изменении талона. Имя события — действие (insert, update, delete),
+// ключи — окно регистратуры талона и кабинет, к которому он записан.
+func TicketEvent(action string, ticket models.TicketResponse, cabinet *int) Event {
+	return Event{Topic: TopicTicket, Name: action, Cabinet: cabinet, Window: ticket.WindowNumber, Data: ticket}
+}
+
+// DoctorStatusChanged создает событие смены статуса врача для табло кабинета.
+func DoctorStatusChanged(doctorID uint, status models.DoctorStatus, cabinet *int) Event {
+	return Event{
+		Topic:   TopicDoctorStatus,
+		Name:    EventDoctorStatusUpdate,
+		Cabinet: cabinet,
+		Data:    DoctorStatusEvent{DoctorID: doctorID, Status: status},
+	}
+}
+
+// ProcessChanged создает событие включения или отключения бизнес-процесса.
+func ProcessChanged(name string, enabled bool) Event {
+	return Event{Topic: TopicProcess, Name: EventProcessUpdate, Data: ProcessEvent{Name: name, Enabled: enabled}}
+}
+
+// AdsChanged создает событие изменения рекламного материала.
+func AdsChanged(adID uint, action string) Event {
+	return Event{Topic: TopicAds, Name: EventAdsUpdate, Data: AdsEvent{AdID: adID, Action: action}}
+}
+
+// DisplayConfigChanged создает событие с новой конфигурацией устройства отображения.
+func DisplayConfigChanged(displayID uint, config interface{}) Event {
+	return Event{Topic: TopicDisplay, Name: EventDisplayConfig, Display: &displayID, Data: config}
+}
+
+// DisplayRevoked создает событие отзыва доступа устройства отображения.
+func DisplayRevoked(displayID uint) Event {
+	return Event{Topic: TopicDisplay, Name: EventDisplayRevoked, Display: &displayID, Data: map[string]uint{"display_id": displayID}}
+}
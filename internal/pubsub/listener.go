@@ -0,0 +1,216 @@
+package pubsub
+
+import (
+	"ElectronicQueue/internal/logger"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NotificationSource открывает соединения, через которые приходят уведомления LISTEN/NOTIFY.
+// В работе это PgxSource; для проверки Listener без базы данных достаточно подставить свою реализацию.
+type NotificationSource interface {
+	Connect(ctx context.Context) (NotificationConn, error)
+}
+
+// NotificationConn — одно соединение с подпиской на каналы уведомлений.
+type NotificationConn interface {
+	// Listen подписывает соединение на канал.
+	Listen(ctx context.Context, channel string) error
+	// WaitForNotification блокируется до следующего уведомления. Ошибка означает, что соединение
+	// больше непригодно и его нужно закрыть.
+	WaitForNotification(ctx context.Context) (Notification, error)
+	Close()
+}
+
+// ListenerHealth — состояние слушателя уведомлений для мониторинга.
+type ListenerHealth struct {
+	Connected bool     `json:"connected" example:"true"`
+	Channels  []string `json:"channels"`
+	// ConnectedSince — когда установлено текущее соединение; DisconnectedSince — когда потеряно последнее.
+	ConnectedSince    *time.Time `json:"connected_since,omitempty"`
+	DisconnectedSince *time.Time `json:"disconnected_since,omitempty"`
+	LastNotification  *time.Time `json:"last_notification,omitempty"`
+	LastError         string     `json:"last_error,omitempty" example:"conn closed"`
+	// Reconnects — сколько раз соединение восстанавливалось после потери.
+	Reconnects    uint64 `json:"reconnects" example:"2"`
+	Notifications uint64 `json:"notifications" example:"1520"`
+}
+
+// Listener слушает каналы уведомлений PostgreSQL и публикует события через брокер. При потере
+// соединения он переподключается с экспоненциальной задержкой, заново выполняет LISTEN на всех каналах
+// и, поскольку уведомления за время разрыва потеряны, отправляет всем клиентам сигнал повторной синхронизации.
+type Listener struct {
+	source     NotificationSource
+	broker     *Broker
+	channels   []string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	// after отсчитывает задержку перед переподключением; в тестах подменяется, чтобы не ждать реального времени.
+	after func(time.Duration) <-chan time.Time
+
+	// handlers — обработчики каналов, уведомления которых не публикуются в брокер напрямую;
+	// onRecover вызываются после восстановления соединения.
+	handlers  map[string]func(Notification)
+	onRecover []func()
+
+	mu     sync.RWMutex
+	health ListenerHealth
+}
+
+// NewListener создает слушатель каналов channels. minBackoff и maxBackoff задаются строками вида "1s":
+// первая повторная попытка подключения выполняется через minBackoff, каждая следующая — вдвое позже,
+// но не позже maxBackoff.
+func NewListener(source NotificationSource, broker *Broker, channels []string, minBackoff, maxBackoff string) (*Listener, error) {
+	minDelay, err := time.ParseDuration(minBackoff)
+	if err != nil || minDelay <= 0 {
+		return nil, fmt.Errorf("некорректная минимальная задержка переподключения %q", minBackoff)
+	}
+	maxDelay, err := time.ParseDuration(maxBackoff)
+	if err != nil || maxDelay < minDelay {
+		return nil, fmt.Errorf("некорректная максимальная задержка переподключения %q", maxBackoff)
+	}
+	return &Listener{
+		source:     source,
+		broker:     broker,
+		channels:   channels,
+		minBackoff: minDelay,
+		maxBackoff: maxDelay,
+		after:      time.After,
+		handlers:   make(map[string]func(Notification)),
+		health:     ListenerHealth{Channels: channels},
+	}, nil
+}
+
+// Handle подписывает слушатель на канал channel, уведомления которого передаются в handler.
+// Вызывается до Run.
+func (l *Listener) Handle(channel string, handler func(Notification)) {
+	if _, exists := l.handlers[channel]; !exists {
+		l.channels = append(l.channels, channel)
+		l.health.Channels = l.channels
+	}
+	l.handlers[channel] = handler
+}
+
+// OnRecover добавляет функцию, которая вызывается после восстановления потерянного соединения:
+// уведомления за время разрыва потеряны, и зависящее от них состояние нужно загрузить заново. Вызывается до Run.
+func (l *Listener) OnRecover(fn func()) {
+	l.onRecover = append(l.onRecover, fn)
+}
+
+// Run слушает уведомления до отмены ctx, восстанавливая соединение после ошибок.
+func (l *Listener) Run(ctx context.Context) {
+	log := logger.Default().WithField("module", "LISTENER")
+	backoff := l.minBackoff
+	recovering := false
+
+	for {
+		err := l.session(ctx, recovering)
+		if ctx.Err() != nil {
+			log.Info("Listener context cancelled, shutting down.")
+			return
+		}
+		if l.lost(err) {
+			// Соединение работало: ошибки начинают новую серию, задержка отсчитывается заново.
+			backoff = l.minBackoff
+		}
+		recovering = true
+
+		log.WithError(err).WithField("retry_in", backoff.String()).Error("Listener: Connection lost, reconnecting")
+		select {
+		case <-ctx.Done():
+			log.Info("Listener context cancelled, shutting down.")
+			return
+		case <-l.after(backoff):
+		}
+		backoff = min(backoff*2, l.maxBackoff)
+	}
+}
+
+// Health возвращает текущее состояние слушателя.
+func (l *Listener) Health() ListenerHealth {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.health
+}
+
+// session открывает соединение, подписывается на каналы и передает уведомления в брокер до первой ошибки.
+func (l *Listener) session(ctx context.Context, recovering bool) error {
+	conn, err := l.source.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось подключиться: %w", err)
+	}
+	defer conn.Close()
+
+	for _, channel := range l.channels {
+		if err := conn.Listen(ctx, channel); err != nil {
+			return fmt.Errorf("не удалось выполнить LISTEN %s: %w", channel, err)
+		}
+	}
+	l.connected(recovering)
+	logger.Default().WithField("channels", l.channels).Info("Listener: Listening to channels")
+	if recovering {
+		l.broker.Resync()
+		for _, fn := range l.onRecover {
+			fn()
+		}
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		l.received()
+		if handler, ok := l.handlers[n.Channel]; ok {
+			handler(n)
+			continue
+		}
+		event, err := DecodeNotification(n)
+		if err != nil {
+			logger.Default().WithError(err).WithField("channel", n.Channel).Warn("Listener: Failed to decode notification, skipping.")
+			continue
+		}
+		l.broker.Publish(event)
+	}
+}
+
+func (l *Listener) connected(recovering bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.health.Connected = true
+	l.health.ConnectedSince = &now
+	l.health.LastError = ""
+	if recovering {
+		l.health.Reconnects++
+	}
+}
+
+func (l *Listener) received() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.health.LastNotification = &now
+	l.health.Notifications++
+}
+
+// lost отмечает потерю соединения. Возвращает true, если до ошибки соединение было установлено.
+func (l *Listener) lost(err error) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	wasConnected := l.health.Connected
+	if wasConnected {
+		now := time.Now()
+		l.health.DisconnectedSince = &now
+	}
+	l.health.Connected = false
+	if err != nil {
+		l.health.LastError = err.Error()
+	}
+	return wasConnected
+}
@@ -0,0 +1,338 @@
+package pubsub
+
+import (
+	"ElectronicQueue/internal/logger"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init("")
+	code := m.Run()
+	logger.Sync()
+	os.Exit(code)
+}
+
+const testTimeout = 2 * time.Second
+
+// fakeSource выдает заранее заданные результаты подключения по очереди: ошибку или соединение.
+// Когда очередь пуста, Connect ждет отмены контекста.
+type fakeSource struct {
+	mu    sync.Mutex
+	steps []interface{}
+}
+
+func (s *fakeSource) Connect(ctx context.Context) (NotificationConn, error) {
+	s.mu.Lock()
+	if len(s.steps) == 0 {
+		s.mu.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	step := s.steps[0]
+	s.steps = s.steps[1:]
+	s.mu.Unlock()
+
+	if err, ok := step.(error); ok {
+		return nil, err
+	}
+	return step.(*fakeConn), nil
+}
+
+// fakeConn — соединение, в которое тест отправляет уведомления и ошибку разрыва.
+type fakeConn struct {
+	notifications chan Notification
+	failures      chan error
+
+	mu       sync.Mutex
+	listened []string
+	closed   bool
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{notifications: make(chan Notification), failures: make(chan error, 1)}
+}
+
+func (c *fakeConn) Listen(_ context.Context, channel string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listened = append(c.listened, channel)
+	return nil
+}
+
+func (c *fakeConn) WaitForNotification(ctx context.Context) (Notification, error) {
+	select {
+	case n := <-c.notifications:
+		return n, nil
+	case err := <-c.failures:
+		return Notification{}, err
+	case <-ctx.Done():
+		return Notification{}, ctx.Err()
+	}
+}
+
+func (c *fakeConn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+func (c *fakeConn) Listened() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.listened...)
+}
+
+func (c *fakeConn) Closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// fakeDelay заменяет таймер переподключения: тест получает каждую запрошенную задержку
+// и сам решает, когда она истекает.
+type fakeDelay struct {
+	requests chan delayRequest
+}
+
+type delayRequest struct {
+	d    time.Duration
+	fire chan time.Time
+}
+
+func (f *fakeDelay) after(d time.Duration) <-chan time.Time {
+	fire := make(chan time.Time, 1)
+	f.requests <- delayRequest{d: d, fire: fire}
+	return fire
+}
+
+// expect дожидается следующей задержки переподключения, проверяет ее длительность и запускает
+// переподключение после вызова check, пока слушатель еще ждет.
+func (f *fakeDelay) expect(t *testing.T, want time.Duration, check func()) {
+	t.Helper()
+	select {
+	case req := <-f.requests:
+		if req.d != want {
+			t.Fatalf("задержка переподключения = %s, ожидалось %s", req.d, want)
+		}
+		if check != nil {
+			check()
+		}
+		req.fire <- time.Now()
+	case <-time.After(testTimeout):
+		t.Fatalf("слушатель не начал ждать переподключения (ожидалась задержка %s)", want)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("не дождались: %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func expectResync(t *testing.T, sub *Subscriber) {
+	t.Helper()
+	select {
+	case <-sub.Resync:
+	case <-time.After(testTimeout):
+		t.Fatal("подписчик не получил сигнал resync")
+	}
+}
+
+func expectNoResync(t *testing.T, sub *Subscriber) {
+	t.Helper()
+	select {
+	case <-sub.Resync:
+		t.Fatal("подписчик получил лишний сигнал resync")
+	default:
+	}
+}
+
+func newTestListener(t *testing.T, source NotificationSource, broker *Broker) (*Listener, *fakeDelay) {
+	t.Helper()
+	listener, err := NewListener(source, broker, []string{ChannelTicketUpdate, ChannelScheduleUpdate}, "10ms", "40ms")
+	if err != nil {
+		t.Fatalf("NewListener: %v", err)
+	}
+	delay := &fakeDelay{requests: make(chan delayRequest)}
+	listener.after = delay.after
+	return listener, delay
+}
+
+func runListener(t *testing.T, listener *Listener) (cancel func()) {
+	t.Helper()
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		listener.Run(ctx)
+		close(done)
+	}()
+	return func() {
+		cancelCtx()
+		select {
+		case <-done:
+		case <-time.After(testTimeout):
+			t.Fatal("Run не завершился после отмены контекста")
+		}
+	}
+}
+
+func TestListenerReconnectsWithBackoffAndResyncs(t *testing.T) {
+	first, second, third := newFakeConn(), newFakeConn(), newFakeConn()
+	refused := errors.New("connection refused")
+	source := &fakeSource{steps: []interface{}{first, refused, refused, refused, second, third}}
+
+	broker := NewBroker(8, 0, 16)
+	sub := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}})
+	defer broker.Unsubscribe(sub)
+
+	listener, delay := newTestListener(t, source, broker)
+	var handled []string
+	var handledMu sync.Mutex
+	listener.Handle("custom", func(n Notification) {
+		handledMu.Lock()
+		handled = append(handled, n.Payload)
+		handledMu.Unlock()
+	})
+	var recovered int
+	var recoveredMu sync.Mutex
+	listener.OnRecover(func() {
+		recoveredMu.Lock()
+		recovered++
+		recoveredMu.Unlock()
+	})
+	wantChannels := []string{ChannelTicketUpdate, ChannelScheduleUpdate, "custom"}
+
+	stop := runListener(t, listener)
+	defer stop()
+
+	// Первое подключение: LISTEN на всех каналах, без resync.
+	waitFor(t, "подключение первого соединения", func() bool { return listener.Health().Connected })
+	if got := first.Listened(); !reflect.DeepEqual(got, wantChannels) {
+		t.Fatalf("LISTEN первого соединения = %v, ожидалось %v", got, wantChannels)
+	}
+	expectNoResync(t, sub)
+
+	first.notifications <- Notification{Channel: ChannelTicketUpdate, Payload: `{"action":"ticket_called","data":{"ticket_number":"A001"}}`}
+	select {
+	case event := <-sub.Events:
+		if event.Topic != TopicTicket || event.Name != "ticket_called" {
+			t.Fatalf("получено событие %s/%s, ожидалось ticket/ticket_called", event.Topic, event.Name)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("уведомление не опубликовано в брокер")
+	}
+	first.notifications <- Notification{Channel: "custom", Payload: "payload"}
+	waitFor(t, "обработка уведомления своего канала", func() bool {
+		handledMu.Lock()
+		defer handledMu.Unlock()
+		return len(handled) == 1
+	})
+
+	health := listener.Health()
+	if health.Reconnects != 0 || health.Notifications != 2 || health.LastNotification == nil {
+		t.Fatalf("состояние после первого соединения: %+v", health)
+	}
+
+	// Разрыв: задержки растут вдвое до максимума, пока подключение не удается.
+	first.failures <- errors.New("conn closed")
+	delay.expect(t, 10*time.Millisecond, func() {
+		health := listener.Health()
+		if health.Connected || health.LastError != "conn closed" || health.DisconnectedSince == nil {
+			t.Fatalf("состояние после разрыва: %+v", health)
+		}
+		if !first.Closed() {
+			t.Fatal("потерянное соединение не закрыто")
+		}
+	})
+	delay.expect(t, 20*time.Millisecond, func() {
+		if health := listener.Health(); health.Connected || health.LastError == "" {
+			t.Fatalf("состояние после неудачного подключения: %+v", health)
+		}
+	})
+	delay.expect(t, 40*time.Millisecond, nil)
+	delay.expect(t, 40*time.Millisecond, nil)
+
+	// Восстановление: LISTEN заново на всех каналах, resync брокера и обработчики восстановления.
+	waitFor(t, "подключение второго соединения", func() bool { return listener.Health().Connected })
+	if got := second.Listened(); !reflect.DeepEqual(got, wantChannels) {
+		t.Fatalf("LISTEN после переподключения = %v, ожидалось %v", got, wantChannels)
+	}
+	expectResync(t, sub)
+	recoveredMu.Lock()
+	if recovered != 1 {
+		t.Fatalf("обработчики восстановления вызваны %d раз, ожидался 1", recovered)
+	}
+	recoveredMu.Unlock()
+
+	health = listener.Health()
+	if health.Reconnects != 1 || health.LastError != "" || health.Notifications != 2 {
+		t.Fatalf("состояние после восстановления: %+v", health)
+	}
+
+	second.notifications <- Notification{Channel: ChannelTicketUpdate, Payload: `{"action":"ticket_completed","data":{"ticket_number":"A001"}}`}
+	waitFor(t, "учет уведомления после восстановления", func() bool { return listener.Health().Notifications == 3 })
+
+	// После работавшего соединения задержка снова начинается с минимальной.
+	second.failures <- errors.New("conn closed")
+	delay.expect(t, 10*time.Millisecond, nil)
+	waitFor(t, "подключение третьего соединения", func() bool { return listener.Health().Reconnects == 2 })
+	if got := third.Listened(); !reflect.DeepEqual(got, wantChannels) {
+		t.Fatalf("LISTEN третьего соединения = %v, ожидалось %v", got, wantChannels)
+	}
+	expectResync(t, sub)
+	if !listener.Health().Connected {
+		t.Fatal("слушатель не подключен после восстановления")
+	}
+}
+
+func TestListenerSkipsUndecodableNotifications(t *testing.T) {
+	conn := newFakeConn()
+	broker := NewBroker(8, 0, 16)
+	sub := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}})
+	defer broker.Unsubscribe(sub)
+
+	listener, _ := newTestListener(t, &fakeSource{steps: []interface{}{conn}}, broker)
+	stop := runListener(t, listener)
+	defer stop()
+
+	waitFor(t, "подключение", func() bool { return listener.Health().Connected })
+	conn.notifications <- Notification{Channel: ChannelTicketUpdate, Payload: "not json"}
+	conn.notifications <- Notification{Channel: ChannelTicketUpdate, Payload: `{"action":"ticket_called","data":{}}`}
+
+	select {
+	case event := <-sub.Events:
+		if event.Name != "ticket_called" {
+			t.Fatalf("получено событие %s, ожидалось ticket_called", event.Name)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("слушатель перестал публиковать события после некорректного уведомления")
+	}
+	if health := listener.Health(); !health.Connected || health.Notifications != 2 {
+		t.Fatalf("состояние после некорректного уведомления: %+v", health)
+	}
+}
+
+func TestNewListenerValidatesBackoff(t *testing.T) {
+	broker := NewBroker(8, 0, 16)
+	for _, tc := range []struct{ min, max string }{
+		{"0s", "1s"},
+		{"abc", "1s"},
+		{"2s", "1s"},
+		{"1s", "abc"},
+	} {
+		if _, err := NewListener(&fakeSource{}, broker, nil, tc.min, tc.max); err == nil {
+			t.Errorf("NewListener(%q, %q) не вернул ошибку", tc.min, tc.max)
+		}
+	}
+}
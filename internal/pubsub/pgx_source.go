@@ -0,0 +1,56 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgxSource получает уведомления через соединение из пула pgx.
+type PgxSource struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgxSource создает источник уведомлений на основе пула pgx.
+func NewPgxSource(pool *pgxpool.Pool) *PgxSource {
+	return &PgxSource{pool: pool}
+}
+
+// Connect забирает соединение из пула. Соединение держится, пока слушатель его не закроет.
+func (s *PgxSource) Connect(ctx context.Context) (NotificationConn, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgxConn{conn: conn}, nil
+}
+
+type pgxConn struct {
+	conn *pgxpool.Conn
+}
+
+func (c *pgxConn) Listen(ctx context.Context, channel string) error {
+	_, err := c.conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+	return err
+}
+
+func (c *pgxConn) WaitForNotification(ctx context.Context) (Notification, error) {
+	n, err := c.conn.Conn().WaitForNotification(ctx)
+	if err != nil {
+		return Notification{}, err
+	}
+	return Notification{Channel: n.Channel, Payload: n.Payload}, nil
+}
+
+// Close снимает подписки и возвращает соединение в пул. Если соединение неисправно, оно закрывается,
+// и пул заменит его новым.
+func (c *pgxConn) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.conn.Exec(ctx, "UNLISTEN *"); err != nil {
+		c.conn.Conn().Close(ctx)
+	}
+	c.conn.Release()
+}
@@ -2,67 +2,362 @@ package pubsub
 
 import (
 	"ElectronicQueue/internal/logger"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Topic — тема событий. Подписчик получает события только тех тем, на которые подписан.
+type Topic string
+
+const (
+	TopicTicket       Topic = "ticket"
+	TopicSchedule     Topic = "schedule"
+	TopicDoctorStatus Topic = "doctor_status"
+	TopicProcess      Topic = "process"
+	TopicAds          Topic = "ads"
+	TopicDisplay      Topic = "display"
+)
+
+// Event — типизированное событие шины.
+type Event struct {
+	// ID — монотонно возрастающий номер события, который брокер присваивает при публикации.
+	// Клиент SSE возвращает его в заголовке Last-Event-ID при переподключении.
+	ID    uint64
+	Topic Topic
+	// Name — имя события, под которым оно отправляется клиенту через SSE.
+	Name string
+	// Cabinet, Window и Display — ключи события: кабинет врача, окно регистратуры и устройство отображения,
+	// к которым оно относится.
+	Cabinet *int
+	Window  *int
+	Display *uint
+	// Data — полезная нагрузка: models.TicketResponse, json.RawMessage, DoctorStatusEvent и т.д. в зависимости от темы.
+	Data interface{}
+}
+
+// Filter задает, какие события нужны подписчику. Если ключ (кабинет, окно или устройство) указан, подписчик
+// получает только события с тем же значением этого ключа; события без ключа до него не доходят.
+type Filter struct {
+	Topics  []Topic
+	Cabinet *int
+	Window  *int
+	Display *uint
+}
+
+func (f *Filter) matches(e *Event) bool {
+	if f.Cabinet != nil && (e.Cabinet == nil || *e.Cabinet != *f.Cabinet) {
+		return false
+	}
+	if f.Window != nil && (e.Window == nil || *e.Window != *f.Window) {
+		return false
+	}
+	if f.Display != nil && (e.Display == nil || *e.Display != *f.Display) {
+		return false
+	}
+	for _, topic := range f.Topics {
+		if topic == e.Topic {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscriber — подписка на события. События приходят в канал Events. Если из-за переполнения буфера
+// подписчик пропустил события, в канал Resync приходит сигнал: клиенту нужно заново получить полное состояние.
+// После Unsubscribe или отключения медленного клиента канал Events закрывается.
+type Subscriber struct {
+	Events <-chan Event
+	Resync <-chan struct{}
+	// Cursor — ID последнего события на момент подписки. Полное состояние, отправленное клиенту сразу
+	// после подписки, помечается этим ID: все более поздние события придут через Events.
+	Cursor uint64
+
+	id          uint64
+	filter      Filter
+	connectedAt time.Time
+	ch          chan Event
+	resync      chan struct{}
+
+	// queued — номер очереди последнего события, назначенного подписчику. Меняется только под Broker.mu,
+	// поэтому номера идут в порядке ID событий.
+	queued uint64
+
+	// mu защищает отправку в ch от одновременного закрытия канала и счетчики подписчика.
+	mu sync.Mutex
+	// turn будит публикации, ждущие своей очереди; sent — номер последнего обработанного события.
+	turn             *sync.Cond
+	sent             uint64
+	closed           bool
+	delivered        uint64
+	dropped          uint64
+	consecutiveDrops int
+	resyncs          uint64
+}
+
+// Drain выбрасывает накопившиеся в буфере события. Вызывается перед отправкой полного состояния,
+// которое их заменяет.
+func (s *Subscriber) Drain() {
+	for {
+		select {
+		case _, ok := <-s.Events:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// closeLocked закрывает канал событий. Вызывается под s.mu.
+func (s *Subscriber) closeLocked() {
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// Publisher публикует события. Его реализуют Broker (только подписчикам этого экземпляра сервера)
+// и cluster.Relay (подписчикам всех экземпляров).
+type Publisher interface {
+	Publish(event Event)
+}
+
 type Broker struct {
-	mu          sync.Mutex
-	subscribers map[chan string]bool
+	// mu защищает список подписчиков. Publish держит его монопольно только пока присваивает ID, пишет
+	// событие в буфер повтора и выбирает получателей, поэтому подписка с повтором не пропускает и
+	// не дублирует события. Отправка выполняется уже без блокировки.
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]bool
+	nextID      uint64
+
+	// seq — ID последнего опубликованного события. Отсчет начинается с текущего времени в микросекундах,
+	// чтобы ID не повторялись после перезапуска сервера; firstID — ID первого события этого запуска.
+	seq     atomic.Uint64
+	firstID uint64
+	replay  map[Topic]*replayBuffer
+
+	// bufferSize — емкость канала подписчика; maxDrops — сколько событий подряд подписчик может пропустить,
+	// прежде чем будет отключен как хронически медленный (0 — не отключать).
+	bufferSize int
+	maxDrops   int
+
+	published    atomic.Uint64
+	delivered    atomic.Uint64
+	dropped      atomic.Uint64
+	resyncs      atomic.Uint64
+	disconnected atomic.Uint64
 }
 
-func NewBroker() *Broker {
-	return &Broker{
-		subscribers: make(map[chan string]bool),
+// NewBroker создает брокер. replaySize — сколько последних событий каждой темы хранится для повтора
+// при переподключении клиента.
+func NewBroker(bufferSize, maxDrops, replaySize int) *Broker {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	b := &Broker{
+		subscribers: make(map[*Subscriber]bool),
+		bufferSize:  bufferSize,
+		maxDrops:    maxDrops,
+		firstID:     uint64(time.Now().UnixMicro()),
+		replay:      make(map[Topic]*replayBuffer),
+	}
+	b.seq.Store(b.firstID - 1)
+	for _, topic := range []Topic{TopicTicket, TopicSchedule, TopicDoctorStatus, TopicProcess, TopicAds, TopicDisplay} {
+		b.replay[topic] = newReplayBuffer(replaySize)
 	}
+	return b
 }
 
-// Subscribe добавляет нового подписчика (клиента).
-// Возвращает канал, который будет получать сообщения.
-func (b *Broker) Subscribe() chan string {
+// LastID возвращает ID последнего опубликованного события.
+func (b *Broker) LastID() uint64 {
+	return b.seq.Load()
+}
+
+// Subscribe добавляет нового подписчика (клиента) на события, подходящие под фильтр.
+func (b *Broker) Subscribe(filter Filter) *Subscriber {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-
-	ch := make(chan string, 10) // Буферизированный канал, чтобы не блокировать рассылку
-	b.subscribers[ch] = true
-	logger.Default().Info("PubSub: New client subscribed.")
-	return ch
+	return b.register(filter)
 }
 
-// Unsubscribe удаляет подписчика.
-func (b *Broker) Unsubscribe(ch chan string) {
+// Resume подписывает клиента, который переподключился после события lastID, и возвращает пропущенные им
+// события по возрастанию ID. Если часть пропущенных событий уже вытеснена из буфера повтора или lastID
+// относится к прошлому запуску сервера, возвращается ok=false: клиенту нужно отправить полное состояние.
+func (b *Broker) Resume(filter Filter, lastID uint64) (sub *Subscriber, missed []Event, ok bool) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if _, ok := b.subscribers[ch]; ok {
-		delete(b.subscribers, ch)
-		close(ch)
-		logger.Default().Info("PubSub: Client unsubscribed.")
+	sub = b.register(filter)
+	if lastID < b.firstID-1 || lastID > sub.Cursor {
+		return sub, nil, false
+	}
+	for _, topic := range filter.Topics {
+		buf, exists := b.replay[topic]
+		if !exists {
+			return sub, nil, false
+		}
+		events, complete := buf.since(lastID, &filter)
+		if !complete {
+			return sub, nil, false
+		}
+		missed = append(missed, events...)
 	}
+	sort.Slice(missed, func(i, j int) bool { return missed[i].ID < missed[j].ID })
+	return sub, missed, true
+}
+
+// register создает подписчика. Вызывается под b.mu.
+func (b *Broker) register(filter Filter) *Subscriber {
+	ch := make(chan Event, b.bufferSize)
+	resync := make(chan struct{}, 1)
+	sub := &Subscriber{Events: ch, Resync: resync, filter: filter, connectedAt: time.Now(), ch: ch, resync: resync}
+	sub.turn = sync.NewCond(&sub.mu)
+
+	b.nextID++
+	sub.id = b.nextID
+	sub.Cursor = b.seq.Load()
+	b.subscribers[sub] = true
+
+	logger.Default().WithField("subscriber_id", sub.id).WithField("topics", filter.Topics).Info("PubSub: New client subscribed.")
+	return sub
 }
 
-// Publish отправляет сообщение всем активным подписчикам.
-func (b *Broker) Publish(msg string) {
+// Unsubscribe удаляет подписчика.
+func (b *Broker) Unsubscribe(sub *Subscriber) {
 	b.mu.Lock()
-	defer b.mu.Unlock()
+	_, ok := b.subscribers[sub]
+	delete(b.subscribers, sub)
+	b.mu.Unlock()
+
+	sub.mu.Lock()
+	sub.closeLocked()
+	sub.mu.Unlock()
+	if ok {
+		logger.Default().WithField("subscriber_id", sub.id).Info("PubSub: Client unsubscribed.")
+	}
+}
 
-	log := logger.Default()
-	log.WithField("subscribers", len(b.subscribers)).Info("PubSub: Publishing message.")
+// delivery — событие, ожидающее отправки подписчику, и его номер в очереди подписчика.
+type delivery struct {
+	sub  *Subscriber
+	turn uint64
+}
 
-	for ch := range b.subscribers {
-		// Используем неблокирующую отправку, чтобы один "медленный" клиент
-		// не затормозил рассылку для всех остальных.
-		select {
-		case ch <- msg:
-		default:
-			log.Warn("PubSub: Message channel for a client is full. Message dropped.")
+// Publish отправляет событие подписчикам, фильтр которых ему соответствует. Под b.mu событию присваивается
+// ID, оно пишется в буфер повтора, а каждому получателю выдается следующий номер в его очереди.
+// Отправка идет без общей блокировки: параллельные публикации раздают события одновременно, но каждому
+// подписчику событие отправляется только после всех более ранних, поэтому порядок ID сохраняется.
+// Отправка не ждет медленных клиентов, поэтому очередь подписчика продвигается быстро.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	event.ID = b.seq.Add(1)
+	if buf, ok := b.replay[event.Topic]; ok {
+		buf.add(event)
+	}
+	var targets []delivery
+	for sub := range b.subscribers {
+		if sub.filter.matches(&event) {
+			sub.queued++
+			targets = append(targets, delivery{sub: sub, turn: sub.queued})
+		}
+	}
+	b.mu.Unlock()
+
+	delivered := 0
+	for _, target := range targets {
+		ok, slow := b.deliver(target.sub, target.turn, &event)
+		if ok {
+			delivered++
+		}
+		if slow {
+			b.disconnectSlow(target.sub)
 		}
 	}
+
+	b.published.Add(1)
+	logger.Default().WithField("topic", event.Topic).WithField("event", event.Name).WithField("id", event.ID).
+		WithField("subscribers", delivered).Info("PubSub: Event published.")
+}
+
+// deliver дожидается очереди turn и отправляет событие подписчику без ожидания. Если буфер полон,
+// событие пропускается, а подписчику отправляется сигнал ресинхронизации. Возвращает, доставлено ли событие
+// и надо ли отключить подписчика как хронически медленного.
+func (b *Broker) deliver(sub *Subscriber, turn uint64, event *Event) (bool, bool) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	// Более ранние события этого подписчика еще отправляются другими публикациями.
+	for sub.sent != turn-1 {
+		sub.turn.Wait()
+	}
+	defer sub.turn.Broadcast()
+	sub.sent = turn
+
+	if sub.closed {
+		return false, false
+	}
+	select {
+	case sub.ch <- *event:
+		sub.delivered++
+		sub.consecutiveDrops = 0
+		b.delivered.Add(1)
+		return true, false
+	default:
+	}
+
+	sub.dropped++
+	sub.consecutiveDrops++
+	b.dropped.Add(1)
+	if b.maxDrops > 0 && sub.consecutiveDrops >= b.maxDrops {
+		sub.closeLocked()
+		return false, true
+	}
+	select {
+	case sub.resync <- struct{}{}:
+		sub.resyncs++
+		b.resyncs.Add(1)
+	default:
+		// Сигнал уже ждет обработки.
+	}
+	return false, false
+}
+
+// disconnectSlow удаляет хронически медленного подписчика, канал которого уже закрыт.
+func (b *Broker) disconnectSlow(sub *Subscriber) {
+	b.mu.Lock()
+	delete(b.subscribers, sub)
+	b.mu.Unlock()
+
+	b.disconnected.Add(1)
+	logger.Default().WithField("subscriber_id", sub.id).WithField("max_drops", b.maxDrops).
+		Warn("PubSub: Client is too slow and has been disconnected.")
 }
 
-// ListenAndPublish - это горутина, которая слушает входящий канал
-// от PostgreSQL и публикует сообщения через брокер.
-func (b *Broker) ListenAndPublish(notifications <-chan string) {
-	for msg := range notifications {
-		b.Publish(msg)
+// Resync сообщает всем подписчикам, что часть событий могла быть потеряна (например, пока не было
+// соединения с базой данных): каждый получает сигнал в Resync и заново загружает полное состояние.
+// Переподключившимся позже клиентам с ID событий до этого момента повтор тоже не предлагается.
+func (b *Broker) Resync() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	gap := b.seq.Add(1)
+	for _, buf := range b.replay {
+		buf.invalidate(gap)
+	}
+	for sub := range b.subscribers {
+		sub.mu.Lock()
+		if !sub.closed {
+			select {
+			case sub.resync <- struct{}{}:
+				sub.resyncs++
+				b.resyncs.Add(1)
+			default:
+			}
+		}
+		sub.mu.Unlock()
 	}
+	logger.Default().WithField("subscribers", len(b.subscribers)).WithField("id", gap).Warn("PubSub: All clients resynced.")
 }
@@ -0,0 +1,275 @@
+package pubsub
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int { return &v }
+
+// receive читает из подписки n событий или завершает тест по таймауту.
+func receive(t *testing.T, sub *Subscriber, n int) []Event {
+	t.Helper()
+	events := make([]Event, 0, n)
+	deadline := time.After(testTimeout)
+	for len(events) < n {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				t.Fatalf("events channel closed after %d of %d events", len(events), n)
+			}
+			events = append(events, event)
+		case <-deadline:
+			t.Fatalf("timed out after %d of %d events", len(events), n)
+		}
+	}
+	return events
+}
+
+func TestPublishConcurrentPublishersDeliverInOrder(t *testing.T) {
+	const publishers, perPublisher = 8, 200
+	broker := NewBroker(publishers*perPublisher, 0, 16)
+
+	all := broker.Subscribe(Filter{Topics: []Topic{TopicTicket, TopicSchedule}})
+	cabinet := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}, Cabinet: intPtr(100)})
+
+	var wg sync.WaitGroup
+	for p := 0; p < publishers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perPublisher; i++ {
+				topic := TopicTicket
+				if i%2 == 1 {
+					topic = TopicSchedule
+				}
+				broker.Publish(Event{Topic: topic, Name: "update", Cabinet: intPtr(100 + i%2)})
+			}
+		}()
+	}
+
+	// Подписчик читает, пока публикации еще идут, чтобы порядок проверялся при одновременной раздаче.
+	received := receive(t, all, publishers*perPublisher)
+	wg.Wait()
+
+	for i := 1; i < len(received); i++ {
+		if received[i].ID <= received[i-1].ID {
+			t.Fatalf("event %d has ID %d after ID %d: delivery out of order", i, received[i].ID, received[i-1].ID)
+		}
+	}
+	if last := received[len(received)-1].ID; last != broker.LastID() {
+		t.Errorf("last delivered ID = %d, want %d", last, broker.LastID())
+	}
+
+	// Подписчик кабинета получает только билеты кабинета 100 (i четное), тоже по порядку.
+	filtered := receive(t, cabinet, publishers*perPublisher/2)
+	for i, event := range filtered {
+		if event.Topic != TopicTicket || event.Cabinet == nil || *event.Cabinet != 100 {
+			t.Fatalf("unexpected event for cabinet subscriber: %+v", event)
+		}
+		if i > 0 && event.ID <= filtered[i-1].ID {
+			t.Fatalf("cabinet event %d out of order", i)
+		}
+	}
+	select {
+	case event := <-cabinet.Events:
+		t.Fatalf("cabinet subscriber got extra event %+v", event)
+	default:
+	}
+
+	stats := broker.Stats()
+	if stats.Published != publishers*perPublisher || stats.Dropped != 0 {
+		t.Errorf("stats published=%d dropped=%d, want %d and 0", stats.Published, stats.Dropped, publishers*perPublisher)
+	}
+}
+
+func TestPublishDoesNotBlockSubscribeDuringDelivery(t *testing.T) {
+	broker := NewBroker(1, 0, 16)
+	sub := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}})
+
+	// Задерживаем отправку подписчику: его очередь занята, пока тест держит sub.mu.
+	sub.mu.Lock()
+	published := make(chan struct{})
+	go func() {
+		broker.Publish(Event{Topic: TopicTicket, Name: "update"})
+		close(published)
+	}()
+	waitFor(t, "event queued for subscriber", func() bool {
+		broker.mu.RLock()
+		defer broker.mu.RUnlock()
+		return sub.queued == 1
+	})
+
+	subscribed := make(chan *Subscriber)
+	go func() { subscribed <- broker.Subscribe(Filter{Topics: []Topic{TopicTicket}}) }()
+	select {
+	case other := <-subscribed:
+		broker.Unsubscribe(other)
+	case <-time.After(testTimeout):
+		t.Fatal("Subscribe blocked while an event was being delivered")
+	}
+
+	sub.mu.Unlock()
+	select {
+	case <-published:
+	case <-time.After(testTimeout):
+		t.Fatal("Publish did not finish")
+	}
+	receive(t, sub, 1)
+}
+
+func eventIDs(events []Event) []uint64 {
+	ids := make([]uint64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func TestResumeReplaysMissedEvents(t *testing.T) {
+	broker := NewBroker(8, 0, 4)
+	broker.Publish(Event{Topic: TopicTicket, Name: "a", Cabinet: intPtr(100)})
+	lastSeen := broker.LastID()
+	broker.Publish(Event{Topic: TopicTicket, Name: "b", Cabinet: intPtr(101)})
+	broker.Publish(Event{Topic: TopicSchedule, Name: "c", Cabinet: intPtr(100)})
+	broker.Publish(Event{Topic: TopicTicket, Name: "d", Cabinet: intPtr(100)})
+
+	filter := Filter{Topics: []Topic{TopicTicket, TopicSchedule}, Cabinet: intPtr(100)}
+	sub, missed, ok := broker.Resume(filter, lastSeen)
+	defer broker.Unsubscribe(sub)
+	if !ok {
+		t.Fatal("Resume: expected complete replay")
+	}
+	if len(missed) != 2 || missed[0].Name != "c" || missed[1].Name != "d" || missed[0].ID >= missed[1].ID {
+		t.Fatalf("missed = %v, want events c and d in ID order", eventIDs(missed))
+	}
+	if sub.Cursor != broker.LastID() {
+		t.Errorf("Cursor = %d, want %d", sub.Cursor, broker.LastID())
+	}
+
+	// События после подписки приходят через канал, а не повторяются.
+	broker.Publish(Event{Topic: TopicTicket, Name: "e", Cabinet: intPtr(100)})
+	if got := receive(t, sub, 1); got[0].Name != "e" {
+		t.Errorf("live event = %q, want e", got[0].Name)
+	}
+}
+
+func TestResumeIncompleteReplay(t *testing.T) {
+	broker := NewBroker(8, 0, 2)
+	broker.Publish(Event{Topic: TopicTicket, Name: "a"})
+	lastSeen := broker.LastID()
+	for i := 0; i < 3; i++ {
+		broker.Publish(Event{Topic: TopicTicket, Name: "evicted"})
+	}
+
+	filter := Filter{Topics: []Topic{TopicTicket}}
+	tests := []struct {
+		name   string
+		lastID uint64
+	}{
+		{"evicted from buffer", lastSeen},
+		{"previous server run", 1},
+		{"from the future", broker.LastID() + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed, ok := broker.Resume(filter, tt.lastID)
+			defer broker.Unsubscribe(sub)
+			if ok || missed != nil {
+				t.Errorf("Resume(%d) = %v, %v; want full state", tt.lastID, eventIDs(missed), ok)
+			}
+		})
+	}
+}
+
+func TestResyncSignalsSubscribersAndInvalidatesReplay(t *testing.T) {
+	broker := NewBroker(8, 0, 16)
+	broker.Publish(Event{Topic: TopicTicket, Name: "a"})
+	lastSeen := broker.LastID()
+	sub := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}})
+	defer broker.Unsubscribe(sub)
+
+	broker.Resync()
+	select {
+	case <-sub.Resync:
+	case <-time.After(testTimeout):
+		t.Fatal("subscriber did not get a resync signal")
+	}
+
+	// События до ресинхронизации повторить нельзя, после нее — можно.
+	filter := Filter{Topics: []Topic{TopicTicket}}
+	stale, _, ok := broker.Resume(filter, lastSeen)
+	broker.Unsubscribe(stale)
+	if ok {
+		t.Error("Resume from before Resync must require full state")
+	}
+	afterGap := broker.LastID()
+	broker.Publish(Event{Topic: TopicTicket, Name: "b"})
+	fresh, missed, ok := broker.Resume(filter, afterGap)
+	broker.Unsubscribe(fresh)
+	if !ok || len(missed) != 1 || missed[0].Name != "b" {
+		t.Errorf("Resume after Resync = %v, %v; want event b", eventIDs(missed), ok)
+	}
+}
+
+func TestPublishFullBufferSignalsResync(t *testing.T) {
+	broker := NewBroker(1, 0, 16)
+	sub := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}})
+	defer broker.Unsubscribe(sub)
+
+	for i := 0; i < 3; i++ {
+		broker.Publish(Event{Topic: TopicTicket, Name: "update"})
+	}
+	select {
+	case <-sub.Resync:
+	default:
+		t.Fatal("dropped events must trigger a resync signal")
+	}
+
+	stats := broker.Stats()
+	if stats.Delivered != 1 || stats.Dropped != 2 || stats.Resyncs != 1 || stats.Disconnected != 0 {
+		t.Errorf("stats delivered=%d dropped=%d resyncs=%d disconnected=%d; want 1, 2, 1, 0",
+			stats.Delivered, stats.Dropped, stats.Resyncs, stats.Disconnected)
+	}
+
+	// После успешной доставки счетчик пропусков подряд сбрасывается.
+	sub.Drain()
+	broker.Publish(Event{Topic: TopicTicket, Name: "update"})
+	if got := broker.Stats().Subscribers[0].ConsecutiveDrops; got != 0 {
+		t.Errorf("ConsecutiveDrops = %d, want 0 after delivery", got)
+	}
+}
+
+func TestPublishDisconnectsSlowSubscriber(t *testing.T) {
+	const maxDrops = 3
+	broker := NewBroker(1, maxDrops, 16)
+	slow := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}})
+	fast := broker.Subscribe(Filter{Topics: []Topic{TopicTicket}})
+
+	for i := 0; i < maxDrops+1; i++ {
+		broker.Publish(Event{Topic: TopicTicket, Name: "update"})
+		receive(t, fast, 1)
+	}
+
+	// В канале осталось первое событие, после него канал закрыт.
+	receive(t, slow, 1)
+	select {
+	case _, ok := <-slow.Events:
+		if ok {
+			t.Fatal("slow subscriber got an event after being disconnected")
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("events channel of slow subscriber was not closed")
+	}
+
+	stats := broker.Stats()
+	if stats.Disconnected != 1 || len(stats.Subscribers) != 1 || stats.Subscribers[0].Delivered != maxDrops+1 {
+		t.Errorf("stats disconnected=%d subscribers=%d; want 1 disconnected and the fast subscriber left",
+			stats.Disconnected, len(stats.Subscribers))
+	}
+
+	// Повторное отключение уже отключенного клиента безопасно.
+	broker.Unsubscribe(slow)
+	broker.Unsubscribe(fast)
+}
@@ -0,0 +1,56 @@
+package pubsub
+
+import "sync"
+
+// replayBuffer хранит последние события одной темы для повтора при переподключении клиента.
+type replayBuffer struct {
+	mu     sync.Mutex
+	size   int
+	events []Event
+	// evicted — наибольший ID события, вытесненного из буфера. Клиенту, пропустившему его, повтор не поможет.
+	evicted uint64
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{size: size, events: make([]Event, 0, size)}
+}
+
+func (r *replayBuffer) add(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size <= 0 {
+		r.evicted = max(r.evicted, event.ID)
+		return
+	}
+	if len(r.events) == r.size {
+		r.evicted = max(r.evicted, r.events[0].ID)
+		copy(r.events, r.events[1:])
+		r.events = r.events[:len(r.events)-1]
+	}
+	r.events = append(r.events, event)
+}
+
+// invalidate отмечает, что события до id включительно повторить нельзя.
+func (r *replayBuffer) invalidate(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evicted = max(r.evicted, id)
+}
+
+// since возвращает события после lastID, подходящие под фильтр. complete=false означает, что часть
+// событий после lastID уже вытеснена.
+func (r *replayBuffer) since(lastID uint64, filter *Filter) (events []Event, complete bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.evicted > lastID {
+		return nil, false
+	}
+	for _, event := range r.events {
+		if event.ID > lastID && filter.matches(&event) {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
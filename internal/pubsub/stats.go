@@ -0,0 +1,80 @@
+package pubsub
+
+import (
+	"sort"
+	"time"
+)
+
+// SubscriberStats — счетчики одного подписчика.
+type SubscriberStats struct {
+	ID          uint64    `json:"id" example:"17"`
+	Topics      []Topic   `json:"topics"`
+	Cabinet     *int      `json:"cabinet,omitempty" example:"101"`
+	Window      *int      `json:"window,omitempty" example:"2"`
+	Display     *uint     `json:"display,omitempty" example:"4"`
+	ConnectedAt time.Time `json:"connected_at"`
+	// Pending — сколько событий ждет в буфере подписчика.
+	Pending   int    `json:"pending" example:"0"`
+	Delivered uint64 `json:"delivered" example:"240"`
+	Dropped   uint64 `json:"dropped" example:"3"`
+	// ConsecutiveDrops — сколько событий подряд пропущено с последней успешной доставки.
+	ConsecutiveDrops int    `json:"consecutive_drops" example:"0"`
+	Resyncs          uint64 `json:"resyncs" example:"1"`
+}
+
+// BrokerStats — счетчики брокера событий для мониторинга.
+type BrokerStats struct {
+	// LastEventID — ID последнего опубликованного события.
+	LastEventID uint64 `json:"last_event_id" example:"1718000000000123"`
+	BufferSize  int    `json:"buffer_size" example:"32"`
+	// MaxDrops — после скольких пропущенных подряд событий клиент отключается (0 — не отключается).
+	MaxDrops  int    `json:"max_drops" example:"100"`
+	Published uint64 `json:"published" example:"1520"`
+	Delivered uint64 `json:"delivered" example:"9800"`
+	Dropped   uint64 `json:"dropped" example:"12"`
+	Resyncs   uint64 `json:"resyncs" example:"4"`
+	// Disconnected — сколько медленных клиентов отключено.
+	Disconnected uint64            `json:"disconnected" example:"1"`
+	Subscribers  []SubscriberStats `json:"subscribers"`
+}
+
+// Stats возвращает текущие счетчики брокера и его подписчиков.
+func (b *Broker) Stats() BrokerStats {
+	b.mu.RLock()
+	subs := make([]*Subscriber, 0, len(b.subscribers))
+	for sub := range b.subscribers {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+
+	stats := BrokerStats{
+		LastEventID:  b.seq.Load(),
+		BufferSize:   b.bufferSize,
+		MaxDrops:     b.maxDrops,
+		Published:    b.published.Load(),
+		Delivered:    b.delivered.Load(),
+		Dropped:      b.dropped.Load(),
+		Resyncs:      b.resyncs.Load(),
+		Disconnected: b.disconnected.Load(),
+		Subscribers:  make([]SubscriberStats, 0, len(subs)),
+	}
+	for _, sub := range subs {
+		sub.mu.Lock()
+		stats.Subscribers = append(stats.Subscribers, SubscriberStats{
+			ID:               sub.id,
+			Topics:           sub.filter.Topics,
+			Cabinet:          sub.filter.Cabinet,
+			Window:           sub.filter.Window,
+			Display:          sub.filter.Display,
+			ConnectedAt:      sub.connectedAt,
+			Pending:          len(sub.ch),
+			Delivered:        sub.delivered,
+			Dropped:          sub.dropped,
+			ConsecutiveDrops: sub.consecutiveDrops,
+			Resyncs:          sub.resyncs,
+		})
+		sub.mu.Unlock()
+	}
+	sort.Slice(stats.Subscribers, func(i, j int) bool { return stats.Subscribers[i].ID < stats.Subscribers[j].ID })
+	return stats
+}
@@ -18,10 +18,28 @@ func NewAppointmentRepository(db *gorm.DB) AppointmentRepository {
 	return &appointmentRepo{db: db}
 }
 
-// CreateAppointmentInTransaction создает запись и блокирует слот в рамках одной транзакции.
-func (r *appointmentRepo) CreateAppointmentInTransaction(req *models.CreateAppointmentRequest) (*models.Appointment, error) {
+// CreateAppointmentInTransaction создает запись и занимает место в слоте в рамках одной транзакции.
+// Лимит активных записей (если maxActive > 0) и check проверяются под блокировкой строки пациента,
+// поэтому параллельные запросы одного пациента не могут их обойти. check может быть nil.
+func (r *appointmentRepo) CreateAppointmentInTransaction(req *models.CreateAppointmentRequest, maxActive int64, check BookingCheck) (*models.Appointment, error) {
 	var appointment models.Appointment
 	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if req.PatientID != nil {
+			if err := lockPatient(tx, *req.PatientID); err != nil {
+				return err
+			}
+			if maxActive > 0 {
+				if err := ensureActiveLimit(tx, *req.PatientID, maxActive); err != nil {
+					return err
+				}
+			}
+			if check != nil {
+				if err := check(tx, *req.PatientID, req.ScheduleID); err != nil {
+					return err
+				}
+			}
+		}
+
 		var schedule models.Schedule
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, req.ScheduleID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -30,25 +48,33 @@ func (r *appointmentRepo) CreateAppointmentInTransaction(req *models.CreateAppoi
 			return err
 		}
 
-		if !schedule.IsAvailable {
-			return errors.New("выбранное время уже занято")
+		if err := ensurePatientNotInSlot(tx, schedule.ID, req.PatientID); err != nil {
+			return err
+		}
+		if err := reserveSlotPlace(tx, &schedule); err != nil {
+			return err
 		}
 
 		appointment = models.Appointment{
-			ScheduleID: req.ScheduleID,
-			PatientID:  req.PatientID,
-			TicketID:   req.TicketID,
+			ScheduleID:      req.ScheduleID,
+			PatientID:       req.PatientID,
+			TicketID:        req.TicketID,
+			RulesOverrideBy: req.RulesOverrideBy,
+		}
+		if req.RulesOverrideBy != nil {
+			reason := req.OverrideReason
+			appointment.RulesOverrideReason = &reason
 		}
 		if err := tx.Create(&appointment).Error; err != nil {
 			return err
 		}
-
-		schedule.IsAvailable = false
-		if err := tx.Save(&schedule).Error; err != nil {
-			return err
+		if req.ReferralID != nil {
+			if err := fulfillReferral(tx, *req.ReferralID, appointment.ID); err != nil {
+				return err
+			}
 		}
 
-		return nil
+		return enqueueAppointmentNotification(tx, models.NotificationEventAppointmentCreated, &appointment)
 	})
 
 	if err != nil {
@@ -76,23 +102,42 @@ func (r *appointmentRepo) FindScheduleAndAppointmentsByDoctorAndDate(doctorID ui
 		return []models.ScheduleWithAppointmentInfo{}, nil
 	}
 
-	var result []models.ScheduleWithAppointmentInfo
+	ids := make([]uint, 0, len(schedules))
 	for _, s := range schedules {
-		info := models.ScheduleWithAppointmentInfo{Schedule: s}
-		if !s.IsAvailable {
-			var app models.Appointment
-			err := r.db.Preload("Patient").Preload("Ticket").Where("schedule_id = ?", s.ID).First(&app).Error
-			if err == nil {
-				info.Appointment = &app
-				if app.TicketID != nil {
-					info.TicketNumber = &app.Ticket.TicketNumber
-				}
+		ids = append(ids, s.ID)
+	}
+
+	var appointments []models.Appointment
+	if err := r.db.Preload("Patient").Preload("Ticket").
+		Where("schedule_id IN ?", ids).
+		Order("created_at asc, appointment_id asc").
+		Find(&appointments).Error; err != nil {
+		return nil, err
+	}
+
+	return groupAppointmentsBySchedule(schedules, appointments), nil
+}
+
+// groupAppointmentsBySchedule раскладывает записи по слотам с учетом вместимости.
+func groupAppointmentsBySchedule(schedules []models.Schedule, appointments []models.Appointment) []models.ScheduleWithAppointmentInfo {
+	bySchedule := make(map[uint][]models.Appointment, len(schedules))
+	for _, app := range appointments {
+		bySchedule[app.ScheduleID] = append(bySchedule[app.ScheduleID], app)
+	}
+
+	result := make([]models.ScheduleWithAppointmentInfo, 0, len(schedules))
+	for _, s := range schedules {
+		info := models.ScheduleWithAppointmentInfo{Schedule: s, FreePlaces: s.FreePlaces()}
+		if apps := bySchedule[s.ID]; len(apps) > 0 {
+			info.Appointments = apps
+			info.Appointment = &apps[0]
+			if apps[0].TicketID != nil {
+				info.TicketNumber = &apps[0].Ticket.TicketNumber
 			}
 		}
 		result = append(result, info)
 	}
-
-	return result, nil
+	return result
 }
 
 // FindByID находит запись по ID со всеми связанными данными.
@@ -119,8 +164,10 @@ func (r *appointmentRepo) Update(appointment *models.Appointment) error {
 }
 
 // DeleteAppointmentAndFreeSlot удаляет запись и освобождает слот в рамках одной транзакции.
-func (r *appointmentRepo) DeleteAppointmentAndFreeSlot(appointmentID uint) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// Возвращает ID освобожденного слота.
+func (r *appointmentRepo) DeleteAppointmentAndFreeSlot(appointmentID uint) (uint, error) {
+	var scheduleID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var app models.Appointment
 		if err := tx.First(&app, appointmentID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -128,29 +175,112 @@ func (r *appointmentRepo) DeleteAppointmentAndFreeSlot(appointmentID uint) error
 			}
 			return err
 		}
+		// Уведомление об отмене формируется до удаления, пока запись и слот еще доступны.
+		if err := enqueueAppointmentNotification(tx, models.NotificationEventAppointmentCancelled, &app); err != nil {
+			return err
+		}
+		if err := cancelPendingReminders(tx, app.ID, "запись на прием отменена"); err != nil {
+			return err
+		}
+		if err := reopenReferral(tx, app.ID); err != nil {
+			return err
+		}
 		if err := tx.Delete(&app).Error; err != nil {
 			return err
 		}
-		if err := tx.Model(&models.Schedule{}).Where("schedule_id = ?", app.ScheduleID).Update("is_available", true).Error; err != nil {
+		if err := releaseSlotPlace(tx, app.ScheduleID); err != nil {
 			return err
 		}
+		scheduleID = app.ScheduleID
 		return nil
 	})
+	return scheduleID, err
 }
 
-func (r *appointmentRepo) FindUpcomingByPatientID(patientID uint, now time.Time) (*models.Appointment, error) {
+// RescheduleAppointmentInTransaction переносит запись в другой свободный слот в рамках одной транзакции:
+// занимает место в новом слоте, освобождает место в старом и ставит в очередь уведомление об изменении.
+// check (может быть nil) вызывается под блокировкой строки пациента.
+// Возвращает обновленную запись и ID освобожденного слота.
+func (r *appointmentRepo) RescheduleAppointmentInTransaction(appointmentID, newScheduleID uint, check BookingCheck) (*models.Appointment, uint, error) {
 	var appointment models.Appointment
+	var oldScheduleID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&appointment, appointmentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("запись с ID %d не найдена", appointmentID)
+			}
+			return err
+		}
+		if appointment.TicketID != nil {
+			return errors.New("запись уже подтверждена и не может быть перенесена")
+		}
+		if appointment.ScheduleID == newScheduleID {
+			return errors.New("запись уже находится в выбранном слоте")
+		}
+		if appointment.PatientID != nil && check != nil {
+			if err := lockPatient(tx, *appointment.PatientID); err != nil {
+				return err
+			}
+			if err := check(tx, *appointment.PatientID, newScheduleID); err != nil {
+				return err
+			}
+		}
+
+		var schedule models.Schedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, newScheduleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("указанный слот в расписании не найден")
+			}
+			return err
+		}
+		if err := ensurePatientNotInSlot(tx, schedule.ID, appointment.PatientID); err != nil {
+			return err
+		}
+		if err := reserveSlotPlace(tx, &schedule); err != nil {
+			return err
+		}
+
+		oldScheduleID = appointment.ScheduleID
+		if err := tx.Model(&appointment).Update("schedule_id", newScheduleID).Error; err != nil {
+			return err
+		}
+		appointment.ScheduleID = newScheduleID
+		if err := releaseSlotPlace(tx, oldScheduleID); err != nil {
+			return err
+		}
+
+		if err := supersedeReminders(tx, appointment.ID, "запись на прием перенесена"); err != nil {
+			return err
+		}
+		return enqueueAppointmentNotification(tx, models.NotificationEventAppointmentChanged, &appointment)
+	})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.Preload("Patient").Preload("Schedule.Doctor").First(&appointment, appointment.ID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return &appointment, oldScheduleID, nil
+}
+
+// FindTodayByPatientID находит сегодняшние записи пациента, по которым еще не выдан талон.
+func (r *appointmentRepo) FindTodayByPatientID(patientID uint, now time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
 	today := now.Format("2006-01-02")
 
 	err := r.db.Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
 		Preload("Schedule.Doctor").
 		Where("appointments.patient_id = ? AND appointments.ticket_id IS NULL AND schedules.date = ?", patientID, today).
 		Order("schedules.start_time asc").
-		First(&appointment).Error
+		Find(&appointments).Error
 
-	return &appointment, err
+	return appointments, err
 }
 
+// AssignTicketToAppointment создает талон и привязывает его к записи в одной транзакции.
 func (r *appointmentRepo) AssignTicketToAppointment(appointment *models.Appointment, ticket *models.Ticket) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(ticket).Error; err != nil {
@@ -159,6 +289,67 @@ func (r *appointmentRepo) AssignTicketToAppointment(appointment *models.Appointm
 		if err := tx.Model(appointment).Update("ticket_id", ticket.ID).Error; err != nil {
 			return err
 		}
-		return nil
+		// Уведомление о вставке талона уходит без кабинета: запись к нему еще не привязана.
+		// Повторное сохранение статуса после привязки вызывает триггер еще раз, и табло кабинета
+		// получает талон вместе с номером кабинета.
+		return tx.Model(ticket).UpdateColumn("status", ticket.Status).Error
 	})
 }
+
+// countActiveByPatientID считает будущие записи пациента, по которым он еще не пришел.
+func countActiveByPatientID(db *gorm.DB, patientID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.Appointment{}).
+		Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
+		Where("appointments.patient_id = ? AND appointments.ticket_id IS NULL AND (schedules.date + schedules.start_time) > NOW()", patientID).
+		Count(&count).Error
+	return count, err
+}
+
+// lockPatient блокирует строку пациента до конца транзакции. Проверки, выполняемые после нее,
+// видят записи, созданные параллельными транзакциями того же пациента.
+func lockPatient(tx *gorm.DB, patientID uint) error {
+	var patient models.Patient
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("patient_id").First(&patient, patientID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("пациент с ID %d не найден", patientID)
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureActiveLimit проверяет, что у пациента меньше maxActive активных записей.
+// Строка пациента должна быть заблокирована lockPatient.
+func ensureActiveLimit(tx *gorm.DB, patientID uint, maxActive int64) error {
+	active, err := countActiveByPatientID(tx, patientID)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки активных записей: %w", err)
+	}
+	if active >= maxActive {
+		return fmt.Errorf("превышен лимит активных записей: не более %d", maxActive)
+	}
+	return nil
+}
+
+// FindActiveByPatientID находит будущие записи пациента, по которым он еще не пришел.
+func (r *appointmentRepo) FindActiveByPatientID(patientID uint) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.db.Preload("Schedule.Doctor").
+		Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
+		Where("appointments.patient_id = ? AND appointments.ticket_id IS NULL AND (schedules.date + schedules.start_time) > NOW()", patientID).
+		Order("schedules.date asc, schedules.start_time asc").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// FindByPatientInDateRange находит записи пациента на даты в диапазоне (включительно) с данными врача.
+func (r *appointmentRepo) FindByPatientInDateRange(patientID uint, from, to time.Time) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := r.db.Preload("Schedule.Doctor").
+		Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
+		Where("appointments.patient_id = ? AND schedules.date >= ? AND schedules.date <= ?", patientID, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("schedules.date asc, schedules.start_time asc").
+		Find(&appointments).Error
+	return appointments, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type bookingRuleRepo struct {
+	db *gorm.DB
+}
+
+func NewBookingRuleRepository(db *gorm.DB) BookingRuleRepository {
+	return &bookingRuleRepo{db: db}
+}
+
+func (r *bookingRuleRepo) GetAll() ([]models.BookingRule, error) {
+	var rules []models.BookingRule
+	err := r.db.Order("specialization asc").Find(&rules).Error
+	return rules, err
+}
+
+// FindBySpecialization возвращает правила специальности или gorm.ErrRecordNotFound, если они не заданы.
+func (r *bookingRuleRepo) FindBySpecialization(specialization string) (*models.BookingRule, error) {
+	var rule models.BookingRule
+	if err := r.db.Where("specialization = ?", specialization).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Upsert создает правила специальности или полностью заменяет существующие.
+func (r *bookingRuleRepo) Upsert(rule *models.BookingRule) error {
+	rule.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "specialization"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_active_bookings", "min_interval_days", "min_age", "max_age", "updated_at"}),
+	}).Create(rule).Error
+}
+
+// Delete удаляет правила и возвращает gorm.ErrRecordNotFound, если их не было.
+func (r *bookingRuleRepo) Delete(id uint) error {
+	result := r.db.Delete(&models.BookingRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
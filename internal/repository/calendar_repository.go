@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type calendarRepo struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) CalendarRepository {
+	return &calendarRepo{db: db}
+}
+
+func (r *calendarRepo) FindDoctorByToken(token string) (*models.Doctor, error) {
+	var doctor models.Doctor
+	if err := r.db.Where("calendar_token = ?", token).First(&doctor).Error; err != nil {
+		return nil, err
+	}
+	return &doctor, nil
+}
+
+func (r *calendarRepo) SetDoctorToken(doctorID uint, token string) error {
+	result := r.db.Model(&models.Doctor{}).Where("doctor_id = ?", doctorID).Update("calendar_token", token)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindDoctorSlots возвращает слоты врача в диапазоне дат вместе с записями на них.
+func (r *calendarRepo) FindDoctorSlots(doctorID uint, from, to time.Time) ([]models.ScheduleWithAppointmentInfo, error) {
+	var schedules []models.Schedule
+	if err := r.db.Where("doctor_id = ? AND date >= ? AND date <= ?", doctorID, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("date asc, start_time asc").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return []models.ScheduleWithAppointmentInfo{}, nil
+	}
+
+	ids := make([]uint, 0, len(schedules))
+	for _, s := range schedules {
+		ids = append(ids, s.ID)
+	}
+
+	var appointments []models.Appointment
+	if err := r.db.Preload("Patient").Where("schedule_id IN ?", ids).Order("created_at asc, appointment_id asc").Find(&appointments).Error; err != nil {
+		return nil, err
+	}
+	return groupAppointmentsBySchedule(schedules, appointments), nil
+}
+
+// FindDoctorTombstones возвращает отмененные слоты врача, начинающиеся в указанном диапазоне.
+func (r *calendarRepo) FindDoctorTombstones(doctorID uint, from, to time.Time) ([]models.CalendarTombstone, error) {
+	var tombstones []models.CalendarTombstone
+	err := r.db.Where("doctor_id = ? AND starts_at >= ? AND starts_at < ?", doctorID, from.Format("2006-01-02"), to.AddDate(0, 0, 1).Format("2006-01-02")).
+		Order("starts_at asc").
+		Find(&tombstones).Error
+	return tombstones, err
+}
+
+func (r *calendarRepo) FindAppointmentTombstone(appointmentID uint) (*models.CalendarTombstone, error) {
+	var tombstone models.CalendarTombstone
+	if err := r.db.Where("appointment_id = ?", appointmentID).First(&tombstone).Error; err != nil {
+		return nil, err
+	}
+	return &tombstone, nil
+}
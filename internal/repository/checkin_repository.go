@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type checkInLogRepo struct {
+	db *gorm.DB
+}
+
+func NewCheckInLogRepository(db *gorm.DB) CheckInLogRepository {
+	return &checkInLogRepo{db: db}
+}
+
+func (r *checkInLogRepo) Create(entry *models.CheckInLog) error {
+	return r.db.Create(entry).Error
+}
+
+// GetReport группирует регистрации за период [from, to) по способу идентификации и результату.
+func (r *checkInLogRepo) GetReport(from, to time.Time) ([]models.CheckInReportRow, error) {
+	var rows []models.CheckInReportRow
+	err := r.db.Model(&models.CheckInLog{}).
+		Select("method, outcome, COUNT(*) as count, ROUND(AVG(offset_minutes), 1) as avg_offset_minutes").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("method, outcome").
+		Order("method, outcome").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -14,28 +14,36 @@ func NewCleanupRepository(db *gorm.DB) CleanupRepository {
 	return &cleanupRepo{db: db}
 }
 
-// TruncateTickets удаляет завершенные tickets и связанные appointments
-func (r *cleanupRepo) TruncateTickets() error {
+// TruncateTickets удаляет завершенные tickets и связанные appointments.
+// Перед удалением визиты пациентов переносятся в архив; возвращает число перенесенных визитов.
+func (r *cleanupRepo) TruncateTickets() (int64, error) {
 	// Начинаем транзакцию
 	tx := r.db.Begin()
 	if tx.Error != nil {
-		return tx.Error
+		return 0, tx.Error
+	}
+
+	// Сохраняем в архив визиты, которые будут удалены
+	archived, err := archiveVisits(tx)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
 	}
 
 	// Удаляем appointments с ticket_id = NULL (осиротевшие записи)
 	if err := tx.Exec("DELETE FROM appointments WHERE ticket_id IS NULL").Error; err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
 
 	// Удаляем только tickets с completed_at != null
 	if err := tx.Exec("DELETE FROM tickets WHERE completed_at IS NOT NULL").Error; err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
 	}
 
 	// Подтверждаем транзакцию
-	return tx.Commit().Error
+	return archived, tx.Commit().Error
 }
 
 // GetTicketsCount возвращает количество завершенных записей в таблице tickets
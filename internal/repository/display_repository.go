@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type displayRepo struct {
+	db *gorm.DB
+}
+
+func NewDisplayRepository(db *gorm.DB) DisplayRepository {
+	return &displayRepo{db: db}
+}
+
+func (r *displayRepo) Create(display *models.Display) error {
+	return r.db.Create(display).Error
+}
+
+func (r *displayRepo) Update(display *models.Display) error {
+	return r.db.Save(display).Error
+}
+
+func (r *displayRepo) Delete(id uint) error {
+	result := r.db.Delete(&models.Display{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *displayRepo) GetAll() ([]models.Display, error) {
+	var displays []models.Display
+	if err := r.db.Order("display_id ASC").Find(&displays).Error; err != nil {
+		return nil, err
+	}
+	return displays, nil
+}
+
+func (r *displayRepo) GetByID(id uint) (*models.Display, error) {
+	var display models.Display
+	if err := r.db.First(&display, id).Error; err != nil {
+		return nil, err
+	}
+	return &display, nil
+}
+
+func (r *displayRepo) FindByTokenHash(tokenHash string) (*models.Display, error) {
+	var display models.Display
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&display).Error; err != nil {
+		return nil, err
+	}
+	return &display, nil
+}
+
+func (r *displayRepo) SetTokenHash(id uint, tokenHash string) error {
+	result := r.db.Model(&models.Display{}).Where("display_id = ?", id).Update("token_hash", tokenHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Touch отмечает время последнего обращения устройства, не меняя updated_at.
+func (r *displayRepo) Touch(id uint, at time.Time) error {
+	return r.db.Model(&models.Display{}).Where("display_id = ?", id).UpdateColumn("last_seen_at", at).Error
+}
@@ -67,3 +67,18 @@ func (r *doctorRepo) FindByLogin(login string) (*models.Doctor, error) {
 func (r *doctorRepo) UpdateStatus(doctorID uint, status models.DoctorStatus) error {
 	return r.db.Model(&models.Doctor{}).Where("doctor_id = ?", doctorID).Update("status", status).Error
 }
+
+// GetSpecializations возвращает список специальностей, по которым в базе есть врачи.
+func (r *doctorRepo) GetSpecializations() ([]string, error) {
+	var specializations []string
+	err := r.db.Model(&models.Doctor{}).Distinct("specialization").Order("specialization asc").Pluck("specialization", &specializations).Error
+	return specializations, err
+}
+
+func (r *doctorRepo) FindBySpecialization(specialization string) ([]models.Doctor, error) {
+	var doctors []models.Doctor
+	if err := r.db.Where("specialization = ?", specialization).Order("full_name asc").Find(&doctors).Error; err != nil {
+		return nil, err
+	}
+	return doctors, nil
+}
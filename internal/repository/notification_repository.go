@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notificationRepo struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepo{db: db}
+}
+
+// ClaimDue забирает на отправку ожидающие уведомления, время отправки которых наступило.
+// Строки блокируются с SKIP LOCKED и в той же транзакции получают claimed_at, поэтому другой экземпляр
+// их не заберет. Захват, не завершенный за lease (экземпляр упал во время отправки), считается снятым.
+func (r *notificationRepo) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.NotificationOutbox, error) {
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var due []models.NotificationOutbox
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("notification_id").
+			Where("status = ? AND next_attempt_at <= ?", models.NotificationStatusPending, now).
+			Where("(claimed_at IS NULL OR claimed_at <= ?)", now.Add(-lease)).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		for _, n := range due {
+			ids = append(ids, n.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&models.NotificationOutbox{}).Where("notification_id IN ?", ids).Update("claimed_at", now).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var notifications []models.NotificationOutbox
+	err = r.db.Preload("Patient").
+		Where("notification_id IN ?", ids).
+		Order("next_attempt_at ASC").
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepo) MarkSent(id uint, sentAt time.Time) error {
+	return r.db.Model(&models.NotificationOutbox{}).Where("notification_id = ?", id).Updates(map[string]interface{}{
+		"status":     models.NotificationStatusSent,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"sent_at":    sentAt,
+		"last_error": nil,
+		"claimed_at": nil,
+	}).Error
+}
+
+// MarkRetry фиксирует неудачную попытку и переносит следующую отправку на nextAttemptAt.
+func (r *notificationRepo) MarkRetry(id uint, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return r.db.Model(&models.NotificationOutbox{}).Where("notification_id = ?", id).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+		"claimed_at":      nil,
+	}).Error
+}
+
+// MarkFailed окончательно переводит уведомление в статус ошибки.
+func (r *notificationRepo) MarkFailed(id uint, attempts int, lastError string) error {
+	return r.db.Model(&models.NotificationOutbox{}).Where("notification_id = ?", id).Updates(map[string]interface{}{
+		"status":     models.NotificationStatusFailed,
+		"attempts":   attempts,
+		"last_error": lastError,
+		"claimed_at": nil,
+	}).Error
+}
+
+// MarkCancelled отменяет отправку уведомления с указанием причины.
+func (r *notificationRepo) MarkCancelled(id uint, reason string) error {
+	return r.db.Model(&models.NotificationOutbox{}).Where("notification_id = ?", id).Updates(map[string]interface{}{
+		"status":     models.NotificationStatusCancelled,
+		"last_error": reason,
+		"claimed_at": nil,
+	}).Error
+}
+
+// FindReminderCandidates возвращает будущие записи, до начала которых осталось не больше lead
+// и для которых напоминание о текущем слоте еще не поставлено в очередь. Записи, созданные уже внутри этого
+// окна, пропускаются: пациент получил уведомление о записи совсем недавно.
+func (r *notificationRepo) FindReminderCandidates(lead time.Duration) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	leadSeconds := int64(lead.Seconds())
+
+	err := r.db.Preload("Schedule.Doctor").
+		Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
+		Joins("JOIN patients ON patients.patient_id = appointments.patient_id").
+		Where("appointments.ticket_id IS NULL AND patients.notifications_opt_out = ?", false).
+		Where("(schedules.date + schedules.start_time) > NOW()").
+		Where("(schedules.date + schedules.start_time) <= NOW() + ? * INTERVAL '1 second'", leadSeconds).
+		Where("appointments.created_at <= (schedules.date + schedules.start_time) - ? * INTERVAL '1 second'", leadSeconds).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM notification_outbox n
+			WHERE n.appointment_id = appointments.appointment_id AND n.schedule_id = appointments.schedule_id
+				AND n.event = ? AND n.status NOT IN ?
+		)`, models.NotificationEventAppointmentReminder,
+			[]models.NotificationStatus{models.NotificationStatusCancelled, models.NotificationStatusSuperseded}).
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// EnqueueReminder ставит напоминание в очередь. Повторное напоминание для той же записи
+// в том же слоте игнорируется уникальным индексом.
+func (r *notificationRepo) EnqueueReminder(appointment *models.Appointment) error {
+	if appointment.PatientID == nil {
+		return nil
+	}
+	notification := models.NewAppointmentNotification(models.NotificationEventAppointmentReminder, *appointment.PatientID, appointment.ID, &appointment.Schedule)
+	return r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "appointment_id"}, {Name: "schedule_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "event = 'appointment_reminder' AND status NOT IN ('отменено', 'заменено')"}}},
+		DoNothing:   true,
+	}).Create(notification).Error
+}
+
+// enqueueAppointmentNotification ставит уведомление о записи в очередь в рамках переданной транзакции.
+// Записи без пациента (например, служебные) пропускаются.
+func enqueueAppointmentNotification(tx *gorm.DB, event models.NotificationEvent, appointment *models.Appointment) error {
+	if appointment.PatientID == nil {
+		return nil
+	}
+
+	var schedule models.Schedule
+	if err := tx.Preload("Doctor").First(&schedule, appointment.ScheduleID).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(models.NewAppointmentNotification(event, *appointment.PatientID, appointment.ID, &schedule)).Error
+}
+
+// cancelPendingReminders отменяет еще не отправленные напоминания по записи.
+func cancelPendingReminders(tx *gorm.DB, appointmentID uint, reason string) error {
+	return tx.Model(&models.NotificationOutbox{}).
+		Where("appointment_id = ? AND event = ? AND status = ?", appointmentID, models.NotificationEventAppointmentReminder, models.NotificationStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.NotificationStatusCancelled,
+			"last_error": reason,
+		}).Error
+}
+
+// supersedeReminders помечает напоминания о прежнем времени перенесенной записи как замененные:
+// неотправленные больше не уйдут, а уже отправленные не помешают напоминанию о новом времени.
+func supersedeReminders(tx *gorm.DB, appointmentID uint, reason string) error {
+	return tx.Model(&models.NotificationOutbox{}).
+		Where("appointment_id = ? AND event = ? AND status IN ?", appointmentID, models.NotificationEventAppointmentReminder,
+			[]models.NotificationStatus{models.NotificationStatusPending, models.NotificationStatusSent}).
+		Updates(map[string]interface{}{
+			"status":     models.NotificationStatusSuperseded,
+			"last_error": reason,
+		}).Error
+}
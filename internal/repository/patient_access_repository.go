@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type patientAccessRepo struct {
+	db *gorm.DB
+}
+
+// NewPatientAccessRepository создает новый экземпляр PatientAccessRepository.
+func NewPatientAccessRepository(db *gorm.DB) PatientAccessRepository {
+	return &patientAccessRepo{db: db}
+}
+
+// CreateBatch добавляет записи в журнал доступа одним запросом.
+func (r *patientAccessRepo) CreateBatch(entries []models.PatientAccessLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.Create(&entries).Error
+}
+
+// Find возвращает страницу журнала доступа по фильтру и общее число подходящих записей. Новые записи первыми.
+func (r *patientAccessRepo) Find(filter *models.PatientAccessFilter, offset, limit int) ([]models.PatientAccessLog, int64, error) {
+	query := r.db.Model(&models.PatientAccessLog{}).
+		Where("accessed_at >= ? AND accessed_at < ?", filter.From, filter.To)
+	if filter.PatientID != nil {
+		query = query.Where("patient_id = ?", *filter.PatientID)
+	}
+	if filter.ActorRole != "" {
+		query = query.Where("actor_role = ?", filter.ActorRole)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []models.PatientAccessLog
+	err := query.Order("accessed_at desc, access_id desc").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, total, err
+}
+
+// FindAnomalies возвращает часы периода, за которые пользователь обратился к данным больше threshold разных пациентов.
+// Часы считаются календарными. Имя пользователя — ФИО врача или логин регистратора.
+func (r *patientAccessRepo) FindAnomalies(from, to time.Time, threshold int) ([]models.PatientAccessAnomaly, error) {
+	var anomalies []models.PatientAccessAnomaly
+	err := r.db.Raw(`
+		SELECT l.actor_role, l.actor_id,
+			CASE l.actor_role
+				WHEN 'doctor' THEN (SELECT d.full_name FROM doctors d WHERE d.doctor_id = l.actor_id)
+				WHEN 'registrar' THEN (SELECT rg.login FROM registrars rg WHERE rg.registrar_id = l.actor_id)
+			END AS actor_name,
+			l.hour_start, l.distinct_patients, l.reads
+		FROM (
+			SELECT actor_role, actor_id, date_trunc('hour', accessed_at) AS hour_start,
+				COUNT(DISTINCT patient_id) AS distinct_patients, COUNT(*) AS reads
+			FROM patient_access_log
+			WHERE accessed_at >= ? AND accessed_at < ?
+			GROUP BY actor_role, actor_id, date_trunc('hour', accessed_at)
+			HAVING COUNT(DISTINCT patient_id) > ?
+		) l
+		ORDER BY l.distinct_patients DESC, l.hour_start DESC`, from, to, threshold).Scan(&anomalies).Error
+	return anomalies, err
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type patientAuditRepo struct {
+	db *gorm.DB
+}
+
+// NewPatientAuditRepository создает новый экземпляр PatientAuditRepository.
+func NewPatientAuditRepository(db *gorm.DB) PatientAuditRepository {
+	return &patientAuditRepo{db: db}
+}
+
+// FindByPatientID возвращает журнал изменений карточки, включая объединения, в которых она была дубликатом. Новые записи первыми.
+func (r *patientAuditRepo) FindByPatientID(patientID uint) ([]models.PatientAuditLog, error) {
+	var entries []models.PatientAuditLog
+	err := r.db.Where("patient_id = ? OR merged_patient_id = ?", patientID, patientID).
+		Order("created_at desc, audit_id desc").
+		Find(&entries).Error
+	return entries, err
+}
+
+// FindByAction возвращает записи журнала с указанным действием за период, новые первыми.
+func (r *patientAuditRepo) FindByAction(action models.PatientAuditAction, from, to time.Time) ([]models.PatientAuditLog, error) {
+	var entries []models.PatientAuditLog
+	err := r.db.Where("action = ? AND created_at >= ? AND created_at < ?", action, from, to).
+		Order("created_at desc, audit_id desc").
+		Find(&entries).Error
+	return entries, err
+}
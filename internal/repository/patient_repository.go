@@ -2,10 +2,28 @@ package repository
 
 import (
 	"ElectronicQueue/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// patientLinkedTables — таблицы, записи которых переносятся на оставшуюся карточку при объединении.
+// Записи на прием переносятся первыми: триггер календаря создает при этом «надгробия» со старым patient_id.
+var patientLinkedTables = []string{
+	"appointments",
+	"waitlist_entries",
+	"referrals",
+	"notification_outbox",
+	"calendar_tombstones",
+	"checkin_logs",
+	"visit_archive",
+}
+
 type patientRepo struct {
 	db *gorm.DB
 }
@@ -22,33 +40,597 @@ func (r *patientRepo) Create(patient *models.Patient) (*models.Patient, error) {
 	return patient, nil
 }
 
-// Search ищет по ФИО, номеру ОМС и полному номеру паспорта.
-func (r *patientRepo) Search(query string) ([]models.Patient, error) {
-	var patients []models.Patient
-	searchQuery := "%" + query + "%"
+func (r *patientRepo) GetByID(id uint) (*models.Patient, error) {
+	var patient models.Patient
+	if err := r.db.First(&patient, id).Error; err != nil {
+		return nil, err
+	}
+	return &patient, nil
+}
 
-	err := r.db.Where(
-		"full_name ILIKE ? OR oms_number LIKE ? OR (passport_series || passport_number) LIKE ?",
-		searchQuery,
-		searchQuery,
-		searchQuery,
-	).Limit(10).Find(&patients).Error
+// patientNameSQL — ФИО для нечеткого сравнения; совпадает с выражением триграммного индекса.
+const patientNameSQL = "replace(lower(full_name), 'ё', 'е')"
 
-	return patients, err
+// Search выполняет нечеткий поиск пациентов и возвращает страницу результатов, упорядоченных по релевантности,
+// и общее число найденных. Фамилия и другие слова ФИО ищутся по сходству триграмм, поэтому запрос
+// находит пациентов с опечатками; цифры сравниваются с полным номером полиса ОМС или паспорта и окончанием телефона.
+func (r *patientRepo) Search(query *models.PatientSearchQuery, offset, limit int) ([]models.PatientSearchHit, int64, error) {
+	var total int64
+	if err := applyPatientSearch(r.db.Model(&models.Patient{}), query).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []models.PatientSearchHit{}, 0, nil
+	}
+
+	rankSQL, rankArgs := patientSearchRank(query)
+	var hits []models.PatientSearchHit
+	err := applyPatientSearch(r.db.Model(&models.Patient{}), query).
+		Select("patients.*, ("+rankSQL+") AS relevance", rankArgs...).
+		Order("relevance DESC, full_name ASC, patient_id ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	// Scan не вызывает хуки модели, поэтому документы расшифровываются явно.
+	for i := range hits {
+		if err := hits[i].DecryptPII(); err != nil {
+			return nil, 0, err
+		}
+	}
+	return hits, total, nil
+}
+
+// applyPatientSearch добавляет к запросу условия поиска пациента. Анонимизированные карточки не ищутся.
+func applyPatientSearch(db *gorm.DB, query *models.PatientSearchQuery) *gorm.DB {
+	db = db.Where("anonymized_at IS NULL")
+	for _, word := range query.NameWords {
+		db = db.Where("(? <% "+patientNameSQL+" OR "+patientNameSQL+" LIKE ?)", word, "%"+word+"%")
+	}
+	for i, initial := range query.Initials {
+		// Первое слово — фамилия, инициалы относятся к следующим словам.
+		db = db.Where("(regexp_split_to_array(trim("+patientNameSQL+"), '\\s+'))[?] LIKE ?", i+2, initial+"%")
+	}
+	if query.BirthDate != nil {
+		db = db.Where("birth_date = ?", query.BirthDate.Format("2006-01-02"))
+	}
+	if query.BirthYear != nil {
+		db = db.Where("birth_date >= ? AND birth_date < ?",
+			fmt.Sprintf("%04d-01-01", *query.BirthYear), fmt.Sprintf("%04d-01-01", *query.BirthYear+1))
+	}
+	if query.Digits != "" {
+		// Паспорт и полис зашифрованы, поэтому ищутся только целиком — по слепым индексам.
+		db = db.Where("(oms_bidx = ? OR passport_bidx = ? OR regexp_replace(phone, '[^0-9]+', '', 'g') LIKE ?)",
+			models.OMSIndex(query.Digits), models.PassportIndex(query.Digits, ""), "%"+query.Digits)
+	}
+	return db
+}
+
+// patientSearchRank возвращает SQL-выражение релевантности: сходство слов ФИО с бонусом за совпадение
+// начала фамилии и точные совпадения документов выше частичных.
+func patientSearchRank(query *models.PatientSearchQuery) (string, []interface{}) {
+	parts := []string{"0"}
+	var args []interface{}
+	for i, word := range query.NameWords {
+		parts = append(parts, "word_similarity(?, "+patientNameSQL+")")
+		args = append(args, word)
+		if i == 0 {
+			parts = append(parts, "CASE WHEN "+patientNameSQL+" LIKE ? THEN 1 ELSE 0 END")
+			args = append(args, word+"%")
+		}
+	}
+	if query.Digits != "" {
+		parts = append(parts, "CASE WHEN oms_bidx = ? OR passport_bidx = ? THEN 2 "+
+			"WHEN right(regexp_replace(phone, '[^0-9]+', '', 'g'), 10) = right(?, 10) THEN 2 "+
+			"WHEN regexp_replace(phone, '[^0-9]+', '', 'g') LIKE ? THEN 1 ELSE 0.5 END")
+		args = append(args, models.OMSIndex(query.Digits), models.PassportIndex(query.Digits, ""), query.Digits, "%"+query.Digits)
+	}
+	return strings.Join(parts, " + "), args
 }
 
 func (r *patientRepo) FindByPassport(series, number string) (*models.Patient, error) {
 	var patient models.Patient
-	if err := r.db.Where("passport_series = ? AND passport_number = ?", series, number).First(&patient).Error; err != nil {
+	if err := r.db.Where("passport_bidx = ?", models.PassportIndex(series, number)).First(&patient).Error; err != nil {
 		return nil, err
 	}
 	return &patient, nil
 }
 
+// FindByPhone находит пациента по телефону в каноническом виде (+79001234567).
 func (r *patientRepo) FindByPhone(phone string) (*models.Patient, error) {
 	var patient models.Patient
-	if err := r.db.Where("regexp_replace(phone, '[^0-9]+', '', 'g') = ?", phone).First(&patient).Error; err != nil {
+	if err := r.db.Where("phone = ?", phone).First(&patient).Error; err != nil {
 		return nil, err
 	}
 	return &patient, nil
 }
+
+// FindAllByPhone находит всех пациентов с указанным номером телефона в каноническом виде (например, общий номер семьи).
+func (r *patientRepo) FindAllByPhone(phone string) ([]models.Patient, error) {
+	var patients []models.Patient
+	err := r.db.Where("phone = ?", phone).Order("patient_id asc").Find(&patients).Error
+	return patients, err
+}
+
+// FindAllByOMS находит всех пациентов с указанным номером полиса ОМС.
+func (r *patientRepo) FindAllByOMS(omsNumber string) ([]models.Patient, error) {
+	var patients []models.Patient
+	err := r.db.Where("oms_bidx = ?", models.OMSIndex(omsNumber)).Order("patient_id asc").Find(&patients).Error
+	return patients, err
+}
+
+// SetNotificationsOptOut включает или отключает уведомления пациенту.
+func (r *patientRepo) SetNotificationsOptOut(patientID uint, optOut bool) (*models.Patient, error) {
+	var patient models.Patient
+	if err := r.db.First(&patient, patientID).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&patient).Update("notifications_opt_out", optOut).Error; err != nil {
+		return nil, err
+	}
+	return &patient, nil
+}
+
+// FindByOMSAndBirthDate идентифицирует пациента по номеру полиса ОМС и дате рождения.
+func (r *patientRepo) FindByOMSAndBirthDate(omsNumber string, birthDate time.Time) (*models.Patient, error) {
+	var patient models.Patient
+	if err := r.db.Where("oms_bidx = ? AND birth_date = ?", models.OMSIndex(omsNumber), birthDate.Format("2006-01-02")).First(&patient).Error; err != nil {
+		return nil, err
+	}
+	return &patient, nil
+}
+
+// Update сохраняет измененную карточку пациента вместе с записью в журнале изменений.
+func (r *patientRepo) Update(patient *models.Patient, audit *models.PatientAuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(patient).Error; err != nil {
+			return err
+		}
+		audit.PatientID = patient.ID
+		return tx.Create(audit).Error
+	})
+}
+
+// FindDuplicateCandidates возвращает неанонимизированные карточки, совпадающие с пациентом по полису ОМС, телефону или дате рождения.
+// Сходство ФИО для совпадений по дате рождения проверяет сервис.
+func (r *patientRepo) FindDuplicateCandidates(patient *models.Patient) ([]models.Patient, error) {
+	var patients []models.Patient
+	query := r.db.Where("patient_id <> ? AND anonymized_at IS NULL", patient.ID)
+	cond := r.db.Where("oms_bidx = ?", models.OMSIndex(patient.OmsNumber)).Or("birth_date = ?", patient.BirthDate.Format("2006-01-02"))
+	if patient.Phone != "" {
+		cond = cond.Or("phone = ?", patient.Phone)
+	}
+	err := query.Where(cond).Order("patient_id asc").Find(&patients).Error
+	return patients, err
+}
+
+// normalizedFullNameSQL приводит ФИО в SQL к виду для сравнения: нижний регистр, "ё" как "е",
+// точки инициалов и повторные пробелы заменены одиночными пробелами.
+const normalizedFullNameSQL = `btrim(regexp_replace(replace(lower(%[1]s.full_name), 'ё', 'е'), '[[:space:].]+', ' ', 'g'))`
+
+// FindDuplicatePairs возвращает до limit пар карточек, совпадающих по полису ОМС, телефону
+// или дате рождения при сходстве ФИО (pg_trgm similarity не ниже minNameSimilarity либо совпадении
+// фамилии и инициалов). Пары отбираются и упорядочиваются в SQL: больше совпавших признаков,
+// затем выше сходство ФИО.
+func (r *patientRepo) FindDuplicatePairs(minNameSimilarity float64, limit int) ([]models.DuplicatePair, error) {
+	nameA, nameB := fmt.Sprintf(normalizedFullNameSQL, "a"), fmt.Sprintf(normalizedFullNameSQL, "b")
+	sameNameBirth := fmt.Sprintf(`a.birth_date = b.birth_date AND (
+				similarity(%[1]s, %[2]s) >= @threshold
+				OR (split_part(%[1]s, ' ', 1) = split_part(%[2]s, ' ', 1)
+					AND left(split_part(%[1]s, ' ', 2), 1) = left(split_part(%[2]s, ' ', 2), 1)
+					AND left(split_part(%[1]s, ' ', 3), 1) = left(split_part(%[2]s, ' ', 3), 1))
+			)`, nameA, nameB)
+
+	var rows []struct {
+		FirstID        uint
+		SecondID       uint
+		SameOMS        bool
+		SamePhone      bool
+		SameNameBirth  bool
+		NameSimilarity float64
+	}
+	err := r.db.Raw(fmt.Sprintf(`
+		WITH candidates AS (
+			SELECT a.patient_id AS first_id, b.patient_id AS second_id
+			FROM patients a JOIN patients b ON b.oms_bidx = a.oms_bidx AND a.patient_id < b.patient_id
+			WHERE a.anonymized_at IS NULL AND b.anonymized_at IS NULL
+			UNION
+			SELECT a.patient_id, b.patient_id
+			FROM patients a JOIN patients b ON b.phone = a.phone AND a.patient_id < b.patient_id
+			WHERE a.phone <> '' AND a.anonymized_at IS NULL AND b.anonymized_at IS NULL
+			UNION
+			SELECT a.patient_id, b.patient_id
+			FROM patients a JOIN patients b ON b.birth_date = a.birth_date AND a.patient_id < b.patient_id
+			WHERE a.anonymized_at IS NULL AND b.anonymized_at IS NULL AND %[3]s
+		), scored AS (
+			SELECT c.first_id, c.second_id,
+				COALESCE(a.oms_bidx = b.oms_bidx, false) AS same_oms,
+				(a.phone <> '' AND a.phone = b.phone) AS same_phone,
+				COALESCE(%[3]s, false) AS same_name_birth,
+				similarity(%[1]s, %[2]s) AS name_similarity
+			FROM candidates c
+			JOIN patients a ON a.patient_id = c.first_id
+			JOIN patients b ON b.patient_id = c.second_id
+		)
+		SELECT * FROM scored
+		ORDER BY same_oms::int + same_phone::int + same_name_birth::int DESC, name_similarity DESC, first_id, second_id
+		LIMIT @limit`, nameA, nameB, sameNameBirth),
+		map[string]interface{}{"threshold": minNameSimilarity, "limit": limit}).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(rows)*2)
+	for _, row := range rows {
+		ids = append(ids, row.FirstID, row.SecondID)
+	}
+	var patients []models.Patient
+	if err := r.db.Where("patient_id IN ?", ids).Find(&patients).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Patient, len(patients))
+	for _, p := range patients {
+		byID[p.ID] = p
+	}
+
+	pairs := make([]models.DuplicatePair, 0, len(rows))
+	for _, row := range rows {
+		pair := models.DuplicatePair{
+			First:          byID[row.FirstID],
+			Second:         byID[row.SecondID],
+			Reasons:        []models.DuplicateReason{},
+			NameSimilarity: row.NameSimilarity,
+		}
+		if row.SameOMS {
+			pair.Reasons = append(pair.Reasons, models.DuplicateSameOMS)
+		}
+		if row.SamePhone {
+			pair.Reasons = append(pair.Reasons, models.DuplicateSamePhone)
+		}
+		if row.SameNameBirth {
+			pair.Reasons = append(pair.Reasons, models.DuplicateNameBirthDate)
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// Merge переносит все данные карточки-дубликата на оставшуюся карточку и удаляет дубликат в одной транзакции.
+// Записи дубликата в слоты, где уже записана оставшаяся карточка, удаляются с освобождением места.
+// Незаполненные телефон и email оставшейся карточки берутся из дубликата.
+// В журнал изменений записывается снимок удаленной карточки и число перенесенных записей.
+func (r *patientRepo) Merge(survivorID, duplicateID uint, audit *models.PatientAuditLog) (*models.PatientMergeResult, error) {
+	result := &models.PatientMergeResult{Moved: make(map[string]int64), DroppedAppointments: []uint{}}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var locked []models.Patient
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("patient_id IN ?", []uint{survivorID, duplicateID}).
+			Order("patient_id asc").
+			Find(&locked).Error; err != nil {
+			return err
+		}
+		var survivor, duplicate *models.Patient
+		for i := range locked {
+			switch locked[i].ID {
+			case survivorID:
+				survivor = &locked[i]
+			case duplicateID:
+				duplicate = &locked[i]
+			}
+		}
+		if survivor == nil {
+			return fmt.Errorf("пациент с ID %d не найден", survivorID)
+		}
+		if duplicate == nil {
+			return fmt.Errorf("пациент с ID %d не найден", duplicateID)
+		}
+		for _, p := range []*models.Patient{survivor, duplicate} {
+			if p.AnonymizedAt != nil {
+				return fmt.Errorf("карточка пациента %d анонимизирована и не может быть объединена", p.ID)
+			}
+		}
+
+		var conflicting []models.Appointment
+		if err := tx.Where("patient_id = ? AND schedule_id IN (?)", duplicateID,
+			tx.Model(&models.Appointment{}).Select("schedule_id").Where("patient_id = ?", survivorID)).
+			Find(&conflicting).Error; err != nil {
+			return err
+		}
+		for i := range conflicting {
+			app := &conflicting[i]
+			if err := cancelPendingReminders(tx, app.ID, "карточка пациента объединена с другой"); err != nil {
+				return err
+			}
+			if err := reopenReferral(tx, app.ID); err != nil {
+				return err
+			}
+			if err := tx.Delete(app).Error; err != nil {
+				return err
+			}
+			if err := releaseSlotPlace(tx, app.ScheduleID); err != nil {
+				return err
+			}
+			result.DroppedAppointments = append(result.DroppedAppointments, app.ID)
+		}
+
+		for _, table := range patientLinkedTables {
+			res := tx.Table(table).Where("patient_id = ?", duplicateID).Update("patient_id", survivorID)
+			if res.Error != nil {
+				return res.Error
+			}
+			result.Moved[table] = res.RowsAffected
+		}
+
+		filled := make(map[string]interface{})
+		if survivor.Phone == "" && duplicate.Phone != "" {
+			filled["phone"] = duplicate.Phone
+			filled["phone_invalid"] = duplicate.PhoneInvalid
+		}
+		if survivor.Email == "" && duplicate.Email != "" {
+			filled["email"] = duplicate.Email
+		}
+		if len(filled) > 0 {
+			if err := tx.Model(survivor).Updates(filled).Error; err != nil {
+				return err
+			}
+		}
+
+		// В журнал попадает снимок с замаскированными документами: журнал не должен хранить их в открытом виде.
+		snapshot := *duplicate
+		snapshot.MaskPII()
+		details, err := json.Marshal(map[string]interface{}{
+			"merged_patient":       snapshot,
+			"moved":                result.Moved,
+			"dropped_appointments": result.DroppedAppointments,
+			"filled_fields":        filled,
+		})
+		if err != nil {
+			return err
+		}
+		audit.PatientID = survivorID
+		audit.Action = models.PatientAuditMerge
+		audit.MergedPatientID = &duplicateID
+		audit.Details = details
+		if err := tx.Create(audit).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(duplicate).Error; err != nil {
+			return err
+		}
+		result.Patient = survivor
+		result.AuditEntry = *audit
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReencryptPII перешифровывает активным ключом паспорт и полис ОМС карточек, зашифрованных прежним ключом
+// или еще не зашифрованных, порциями по batchSize. Возвращает число обработанных карточек.
+func (r *patientRepo) ReencryptPII(batchSize int) (int, error) {
+	prefix, err := models.PIIActivePrefix()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for {
+		processed := 0
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			var patients []models.Patient
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("passport_series IS NOT NULL OR passport_number IS NOT NULL OR oms_number IS NOT NULL "+
+					"OR (passport_encrypted <> '' AND passport_encrypted NOT LIKE ?) OR (oms_encrypted <> '' AND oms_encrypted NOT LIKE ?)",
+					prefix+"%", prefix+"%").
+				Order("patient_id asc").
+				Limit(batchSize).
+				Find(&patients).Error; err != nil {
+				return err
+			}
+			for i := range patients {
+				if err := tx.Save(&patients[i]).Error; err != nil {
+					return fmt.Errorf("пациент %d: %w", patients[i].ID, err)
+				}
+			}
+			processed = len(patients)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += processed
+		if processed < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// anonymizedNotificationText заменяет тему и текст уведомлений анонимизированного пациента.
+const anonymizedNotificationText = "[удалено при анонимизации]"
+
+// patientPreservedTables — связанные с карточкой таблицы, которые при анонимизации сохраняются для статистики.
+var patientPreservedTables = []string{
+	"appointments",
+	"referrals",
+	"checkin_logs",
+	"notification_outbox",
+	"visit_archive",
+}
+
+// Anonymize заменяет ФИО карточки токеном, сокращает дату рождения до года и удаляет документы и контакты
+// в одной транзакции. Записи на прием, талоны, направления и журналы регистраций остаются привязанными
+// к карточке, поэтому статистика не меняется. Заявки в листе ожидания и открытые направления отменяются,
+// неотправленные уведомления отменяются, а из текста всех уведомлений и прежних записей журнала изменений
+// удаляются данные пациента. Карточку с предстоящими записями на прием анонимизировать нельзя.
+func (r *patientRepo) Anonymize(patientID uint, token string, today time.Time, audit *models.PatientAuditLog) (*models.PatientAnonymizationResult, error) {
+	result := &models.PatientAnonymizationResult{
+		PatientID: patientID,
+		Token:     token,
+		Preserved: make(map[string]int64),
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var patient models.Patient
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&patient, patientID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("пациент с ID %d не найден", patientID)
+			}
+			return err
+		}
+		if patient.AnonymizedAt != nil {
+			return fmt.Errorf("карточка пациента %d уже анонимизирована", patientID)
+		}
+
+		var upcoming int64
+		if err := tx.Model(&models.Appointment{}).
+			Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
+			Where("appointments.patient_id = ? AND schedules.date >= ?", patientID, today.Format("2006-01-02")).
+			Count(&upcoming).Error; err != nil {
+			return err
+		}
+		if upcoming > 0 {
+			return fmt.Errorf("у пациента %d есть предстоящие записи на прием (%d): отмените их перед анонимизацией", patientID, upcoming)
+		}
+
+		var held []models.WaitlistEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("patient_id = ? AND status = ? AND held_schedule_id IS NOT NULL", patientID, models.WaitlistStatusOffered).
+			Find(&held).Error; err != nil {
+			return err
+		}
+		for _, entry := range held {
+			if err := releaseSlotPlace(tx, *entry.HeldScheduleID); err != nil {
+				return err
+			}
+		}
+		res := tx.Model(&models.WaitlistEntry{}).
+			Where("patient_id = ? AND status IN ?", patientID, []models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}).
+			Updates(map[string]interface{}{
+				"status":           models.WaitlistStatusCancelled,
+				"held_schedule_id": nil,
+				"hold_expires_at":  nil,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		result.CancelledWaitlistEntries = res.RowsAffected
+		if err := tx.Model(&models.WaitlistEntry{}).Where("patient_id = ?", patientID).Update("note", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Table("referrals").Where("patient_id = ? AND status = ?", patientID, "открыто").
+			Update("status", "отменено").Error; err != nil {
+			return err
+		}
+
+		res = tx.Table("notification_outbox").Where("patient_id = ? AND status = ?", patientID, "ожидает").
+			Updates(map[string]interface{}{"status": "отменено", "last_error": "карточка пациента анонимизирована"})
+		if res.Error != nil {
+			return res.Error
+		}
+		result.CancelledNotifications = res.RowsAffected
+		res = tx.Table("notification_outbox").Where("patient_id = ?", patientID).
+			Updates(map[string]interface{}{"subject": anonymizedNotificationText, "body": anonymizedNotificationText})
+		if res.Error != nil {
+			return res.Error
+		}
+		result.RedactedNotifications = res.RowsAffected
+
+		res = tx.Model(&models.PatientAuditLog{}).Where("patient_id = ?", patientID).
+			Update("details", gorm.Expr(`jsonb_build_object('redacted', true)`))
+		if res.Error != nil {
+			return res.Error
+		}
+		result.RedactedAuditEntries = res.RowsAffected
+
+		for _, table := range patientPreservedTables {
+			var count int64
+			if err := tx.Table(table).Where("patient_id = ?", patientID).Count(&count).Error; err != nil {
+				return err
+			}
+			result.Preserved[table] = count
+		}
+		var tickets int64
+		if err := tx.Model(&models.Appointment{}).Where("patient_id = ? AND ticket_id IS NOT NULL", patientID).
+			Count(&tickets).Error; err != nil {
+			return err
+		}
+		result.Preserved["tickets"] = tickets
+
+		// Хуки шифрования пропускаются: пустые документы и слепые индексы должны стать NULL, а не пустыми строками.
+		now := time.Now()
+		fields := map[string]interface{}{
+			"full_name":             token,
+			"phone":                 nil,
+			"phone_invalid":         false,
+			"email":                 nil,
+			"passport_encrypted":    nil,
+			"passport_bidx":         nil,
+			"oms_encrypted":         nil,
+			"oms_bidx":              nil,
+			"passport_series":       nil,
+			"passport_number":       nil,
+			"oms_number":            nil,
+			"notifications_opt_out": true,
+			"anonymized_at":         now,
+		}
+		result.ClearedFields = []string{"full_name", "phone", "email", "passport", "oms_number"}
+		if !patient.BirthDate.IsZero() {
+			fields["birth_date"] = time.Date(patient.BirthDate.Year(), time.January, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+			result.ClearedFields = append(result.ClearedFields, "birth_date")
+		}
+		if err := tx.Model(&models.Patient{}).Where("patient_id = ?", patientID).UpdateColumns(fields).Error; err != nil {
+			return err
+		}
+
+		details, err := json.Marshal(map[string]interface{}{
+			"token":                      token,
+			"cleared_fields":             result.ClearedFields,
+			"cancelled_waitlist_entries": result.CancelledWaitlistEntries,
+			"cancelled_notifications":    result.CancelledNotifications,
+			"redacted_notifications":     result.RedactedNotifications,
+			"redacted_audit_entries":     result.RedactedAuditEntries,
+			"preserved":                  result.Preserved,
+		})
+		if err != nil {
+			return err
+		}
+		audit.PatientID = patientID
+		audit.Action = models.PatientAuditAnonymize
+		audit.Details = details
+		if err := tx.Create(audit).Error; err != nil {
+			return err
+		}
+		result.AuditEntry = *audit
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindRetentionCandidates возвращает до limit неанонимизированных карточек, у которых не было приемов (в том числе
+// архивных) и которые не регистрировались с даты cutoff, а также нет направлений, выданных после нее. Самые давние первыми.
+func (r *patientRepo) FindRetentionCandidates(cutoff time.Time, limit int) ([]models.RetentionCandidate, error) {
+	var candidates []models.RetentionCandidate
+	err := r.db.Raw(`
+		SELECT patient_id, last_activity
+		FROM (
+			SELECT p.patient_id, GREATEST(
+				p.created_at::date,
+				(SELECT MAX(s.date) FROM appointments a JOIN schedules s ON s.schedule_id = a.schedule_id WHERE a.patient_id = p.patient_id),
+				(SELECT MAX(va.visit_date) FROM visit_archive va WHERE va.patient_id = p.patient_id)
+			) AS last_activity
+			FROM patients p
+			WHERE p.anonymized_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM referrals rf WHERE rf.patient_id = p.patient_id AND rf.created_at >= ?)
+		) activity
+		WHERE last_activity < ?
+		ORDER BY last_activity ASC, patient_id ASC
+		LIMIT ?`, cutoff, cutoff.Format("2006-01-02"), limit).Scan(&candidates).Error
+	return candidates, err
+}
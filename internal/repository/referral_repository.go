@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type referralRepo struct {
+	db *gorm.DB
+}
+
+func NewReferralRepository(db *gorm.DB) ReferralRepository {
+	return &referralRepo{db: db}
+}
+
+func (r *referralRepo) Create(referral *models.Referral) error {
+	return r.db.Omit("Doctor").Create(referral).Error
+}
+
+func (r *referralRepo) GetByID(id uint) (*models.Referral, error) {
+	var referral models.Referral
+	if err := r.db.Preload("Doctor").First(&referral, id).Error; err != nil {
+		return nil, err
+	}
+	return &referral, nil
+}
+
+// FindByPatientID возвращает направления пациента, при необходимости только с указанным статусом.
+func (r *referralRepo) FindByPatientID(patientID uint, status *models.ReferralStatus) ([]models.Referral, error) {
+	var referrals []models.Referral
+	query := r.db.Preload("Doctor").Where("patient_id = ?", patientID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	err := query.Order("created_at desc").Find(&referrals).Error
+	return referrals, err
+}
+
+// FindOpenByPatientIDs возвращает открытые действующие направления нескольких пациентов.
+func (r *referralRepo) FindOpenByPatientIDs(patientIDs []uint, today time.Time) ([]models.Referral, error) {
+	var referrals []models.Referral
+	if len(patientIDs) == 0 {
+		return referrals, nil
+	}
+	err := r.db.Preload("Doctor").
+		Where("patient_id IN ? AND status = ? AND expires_at >= ?", patientIDs, models.ReferralOpen, today.Format("2006-01-02")).
+		Order("expires_at asc").
+		Find(&referrals).Error
+	return referrals, err
+}
+
+// FindExpiredOpen возвращает неисполненные направления, срок которых истек до указанной даты.
+func (r *referralRepo) FindExpiredOpen(today time.Time) ([]models.ExpiredReferralRow, error) {
+	var rows []models.ExpiredReferralRow
+	err := r.db.Table("referrals r").
+		Select(`r.referral_id, r.patient_id, p.full_name AS patient_name, p.phone AS patient_phone,
+			d.full_name AS doctor_name, r.target_specialization, r.urgency, r.created_at, r.expires_at,
+			(?::date - r.expires_at) AS days_overdue`, today.Format("2006-01-02")).
+		Joins("JOIN patients p ON p.patient_id = r.patient_id").
+		Joins("LEFT JOIN doctors d ON d.doctor_id = r.doctor_id").
+		Where("r.status = ? AND r.expires_at < ?", models.ReferralOpen, today.Format("2006-01-02")).
+		Order("r.expires_at asc, r.referral_id asc").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Cancel отменяет открытое направление.
+func (r *referralRepo) Cancel(id uint) error {
+	result := r.db.Model(&models.Referral{}).
+		Where("referral_id = ? AND status = ?", id, models.ReferralOpen).
+		Update("status", models.ReferralCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// fulfillReferral связывает открытое направление с созданной по нему записью.
+func fulfillReferral(tx *gorm.DB, referralID, appointmentID uint) error {
+	result := tx.Model(&models.Referral{}).
+		Where("referral_id = ? AND status = ?", referralID, models.ReferralOpen).
+		Updates(map[string]interface{}{
+			"status":         models.ReferralFulfilled,
+			"appointment_id": appointmentID,
+			"fulfilled_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("направление уже использовано или отменено")
+	}
+	return nil
+}
+
+// reopenReferral снова открывает направление, если созданная по нему запись отменена.
+func reopenReferral(tx *gorm.DB, appointmentID uint) error {
+	return tx.Model(&models.Referral{}).
+		Where("appointment_id = ? AND status = ?", appointmentID, models.ReferralFulfilled).
+		Updates(map[string]interface{}{
+			"status":         models.ReferralOpen,
+			"appointment_id": nil,
+			"fulfilled_at":   nil,
+		}).Error
+}
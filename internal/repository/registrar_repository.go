@@ -25,3 +25,11 @@ func (r *registrarRepo) FindByLogin(login string) (*models.Registrar, error) {
 func (r *registrarRepo) Create(registrar *models.Registrar) error {
 	return r.db.Create(registrar).Error
 }
+
+func (r *registrarRepo) FindByID(id uint) (*models.Registrar, error) {
+	var registrar models.Registrar
+	if err := r.db.First(&registrar, id).Error; err != nil {
+		return nil, err
+	}
+	return &registrar, nil
+}
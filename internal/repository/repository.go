@@ -24,14 +24,41 @@ type DoctorRepository interface {
 	GetAnyDoctor() (*models.Doctor, error)
 	FindByLogin(login string) (*models.Doctor, error)
 	UpdateStatus(doctorID uint, status models.DoctorStatus) error
+	GetSpecializations() ([]string, error)
+	FindBySpecialization(specialization string) ([]models.Doctor, error)
 }
 
 // PatientRepository определяет методы для взаимодействия с данными пациентов.
 type PatientRepository interface {
 	Create(patient *models.Patient) (*models.Patient, error)
-	Search(query string) ([]models.Patient, error)
+	GetByID(id uint) (*models.Patient, error)
+	Search(query *models.PatientSearchQuery, offset, limit int) ([]models.PatientSearchHit, int64, error)
 	FindByPassport(series, number string) (*models.Patient, error)
 	FindByPhone(phone string) (*models.Patient, error)
+	FindAllByPhone(phone string) ([]models.Patient, error)
+	FindAllByOMS(omsNumber string) ([]models.Patient, error)
+	SetNotificationsOptOut(patientID uint, optOut bool) (*models.Patient, error)
+	FindByOMSAndBirthDate(omsNumber string, birthDate time.Time) (*models.Patient, error)
+	Update(patient *models.Patient, audit *models.PatientAuditLog) error
+	FindDuplicateCandidates(patient *models.Patient) ([]models.Patient, error)
+	FindDuplicatePairs(minNameSimilarity float64, limit int) ([]models.DuplicatePair, error)
+	Merge(survivorID, duplicateID uint, audit *models.PatientAuditLog) (*models.PatientMergeResult, error)
+	ReencryptPII(batchSize int) (int, error)
+	Anonymize(patientID uint, token string, today time.Time, audit *models.PatientAuditLog) (*models.PatientAnonymizationResult, error)
+	FindRetentionCandidates(cutoff time.Time, limit int) ([]models.RetentionCandidate, error)
+}
+
+// PatientAuditRepository определяет методы для чтения журнала изменений карточек пациентов.
+type PatientAuditRepository interface {
+	FindByPatientID(patientID uint) ([]models.PatientAuditLog, error)
+	FindByAction(action models.PatientAuditAction, from, to time.Time) ([]models.PatientAuditLog, error)
+}
+
+// PatientAccessRepository определяет методы для журнала чтения персональных данных пациентов.
+type PatientAccessRepository interface {
+	CreateBatch(entries []models.PatientAccessLog) error
+	Find(filter *models.PatientAccessFilter, offset, limit int) ([]models.PatientAccessLog, int64, error)
+	FindAnomalies(from, to time.Time, threshold int) ([]models.PatientAccessAnomaly, error)
 }
 
 // TicketRepository определяет методы для взаимодействия с талонами.
@@ -57,6 +84,7 @@ type TicketRepository interface {
 type ScheduleRepository interface {
 	Create(schedule *models.Schedule) error
 	Update(schedule *models.Schedule) error
+	SetBlocked(id uint, blocked bool) error
 	Delete(id uint) error
 	GetByID(id uint) (*models.Schedule, error)
 	FindByDoctorAndDate(doctorID uint, date time.Time) ([]models.Schedule, error)
@@ -65,23 +93,67 @@ type ScheduleRepository interface {
 	FindFirstScheduleForCabinetByDay(cabinetNumber int) (*models.Schedule, error)
 	FindAllSchedulesForDate(date time.Time) ([]models.Schedule, error)
 	FindMinMaxTimesForDate(date time.Time) (time.Time, time.Time, error)
+	FindFreeSlots(filter models.FreeSlotFilter) ([]models.Schedule, error)
+	FindInDateRange(from, to time.Time, doctorID *uint) ([]models.Schedule, error)
+	CreateBatch(schedules []models.Schedule) error
+}
+
+// CheckInLogRepository определяет методы для журнала регистраций по записи через киоск.
+type CheckInLogRepository interface {
+	Create(entry *models.CheckInLog) error
+	GetReport(from, to time.Time) ([]models.CheckInReportRow, error)
+}
+
+// RoutingRuleRepository определяет методы для правил прямой маршрутизации в очередь врача.
+type RoutingRuleRepository interface {
+	GetAll() ([]models.DirectRoutingRule, error)
+	Create(rule *models.DirectRoutingRule) error
+	Delete(id uint) error
+	Matches(serviceID, specialization string) (bool, error)
+}
+
+// BookingRuleRepository определяет методы для правил записи по специальностям.
+type BookingRuleRepository interface {
+	GetAll() ([]models.BookingRule, error)
+	FindBySpecialization(specialization string) (*models.BookingRule, error)
+	Upsert(rule *models.BookingRule) error
+	Delete(id uint) error
+}
+
+// ReferralRepository определяет методы для работы с направлениями к специалистам.
+type ReferralRepository interface {
+	Create(referral *models.Referral) error
+	GetByID(id uint) (*models.Referral, error)
+	FindByPatientID(patientID uint, status *models.ReferralStatus) ([]models.Referral, error)
+	FindOpenByPatientIDs(patientIDs []uint, today time.Time) ([]models.Referral, error)
+	FindExpiredOpen(today time.Time) ([]models.ExpiredReferralRow, error)
+	Cancel(id uint) error
 }
 
+// BookingCheck проверяет запись пациента в слот внутри транзакции создания или переноса записи.
+// Вызывается после блокировки строки пациента, поэтому параллельные записи того же пациента
+// дожидаются друг друга и не проходят проверку по устаревшим данным. Данные читаются через tx.
+type BookingCheck func(tx *gorm.DB, patientID, scheduleID uint) error
+
 // AppointmentRepository определяет методы для взаимодействия с записями на прием.
 type AppointmentRepository interface {
-	CreateAppointmentInTransaction(req *models.CreateAppointmentRequest) (*models.Appointment, error)
+	CreateAppointmentInTransaction(req *models.CreateAppointmentRequest, maxActive int64, check BookingCheck) (*models.Appointment, error)
 	FindScheduleAndAppointmentsByDoctorAndDate(doctorID uint, date time.Time) ([]models.ScheduleWithAppointmentInfo, error)
 	FindByID(id uint) (*models.Appointment, error)
 	FindByPatientID(patientID uint) ([]models.Appointment, error)
 	Update(appointment *models.Appointment) error
-	DeleteAppointmentAndFreeSlot(appointmentID uint) error
-	FindUpcomingByPatientID(patientID uint, now time.Time) (*models.Appointment, error)
+	DeleteAppointmentAndFreeSlot(appointmentID uint) (uint, error)
+	RescheduleAppointmentInTransaction(appointmentID, newScheduleID uint, check BookingCheck) (*models.Appointment, uint, error)
+	FindTodayByPatientID(patientID uint, now time.Time) ([]models.Appointment, error)
 	AssignTicketToAppointment(appointment *models.Appointment, ticket *models.Ticket) error
+	FindActiveByPatientID(patientID uint) ([]models.Appointment, error)
+	FindByPatientInDateRange(patientID uint, from, to time.Time) ([]models.Appointment, error)
 }
 
 // RegistrarRepository определяет методы для аутентификации регистраторов.
 type RegistrarRepository interface {
 	FindByLogin(login string) (*models.Registrar, error)
+	FindByID(id uint) (*models.Registrar, error)
 	Create(registrar *models.Registrar) error
 }
 
@@ -103,11 +175,16 @@ type ServiceRepository interface {
 
 // CleanupRepository определяет методы для очистки данных.
 type CleanupRepository interface {
-	TruncateTickets() error
+	TruncateTickets() (int64, error)
 	GetTicketsCount() (int64, error)
 	GetOrphanedAppointmentsCount() (int64, error)
 }
 
+// VisitRepository определяет методы для получения визитов пациента, включая архивные.
+type VisitRepository interface {
+	FindByPatient(patientID uint, specialization string, includeArchived bool) ([]models.PatientVisit, error)
+}
+
 // BusinessProcessRepository определяет методы для управления бизнес-процессами.
 type BusinessProcessRepository interface {
 	GetAll() ([]models.BusinessProcess, error)
@@ -131,6 +208,50 @@ type RegistrarPriorityRepository interface {
 	SetPriorities(registrarID uint, serviceIDs []uint) error
 }
 
+// WaitlistRepository определяет методы для работы с листом ожидания.
+type WaitlistRepository interface {
+	Create(entry *models.WaitlistEntry) error
+	GetByID(id uint) (*models.WaitlistEntry, error)
+	FindByStatuses(statuses []models.WaitlistStatus) ([]models.WaitlistEntry, error)
+	UpdateStatus(id uint, status models.WaitlistStatus) error
+	HoldSlotForNextEntry(scheduleID uint, holdUntil time.Time, excludeEntryID uint) (*models.WaitlistEntry, error)
+	ConfirmHold(entryID uint, check BookingCheck) (*models.Appointment, error)
+	ReleaseHold(entryID uint, status models.WaitlistStatus) (uint, error)
+	ReleaseExpiredHold(now time.Time) (entryID uint, scheduleID uint, err error)
+}
+
+// NotificationRepository определяет методы для работы с очередью уведомлений пациентам.
+type NotificationRepository interface {
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.NotificationOutbox, error)
+	MarkSent(id uint, sentAt time.Time) error
+	MarkRetry(id uint, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(id uint, attempts int, lastError string) error
+	MarkCancelled(id uint, reason string) error
+	FindReminderCandidates(lead time.Duration) ([]models.Appointment, error)
+	EnqueueReminder(appointment *models.Appointment) error
+}
+
+// CalendarRepository определяет методы для формирования календарей (.ics).
+type CalendarRepository interface {
+	FindDoctorByToken(token string) (*models.Doctor, error)
+	SetDoctorToken(doctorID uint, token string) error
+	FindDoctorSlots(doctorID uint, from, to time.Time) ([]models.ScheduleWithAppointmentInfo, error)
+	FindDoctorTombstones(doctorID uint, from, to time.Time) ([]models.CalendarTombstone, error)
+	FindAppointmentTombstone(appointmentID uint) (*models.CalendarTombstone, error)
+}
+
+// DisplayRepository определяет методы для работы с реестром устройств отображения.
+type DisplayRepository interface {
+	Create(display *models.Display) error
+	Update(display *models.Display) error
+	Delete(id uint) error
+	GetAll() ([]models.Display, error)
+	GetByID(id uint) (*models.Display, error)
+	FindByTokenHash(tokenHash string) (*models.Display, error)
+	SetTokenHash(id uint, tokenHash string) error
+	Touch(id uint, at time.Time) error
+}
+
 // Repository содержит все репозитории приложения.
 type Repository struct {
 	Doctor            DoctorRepository
@@ -142,10 +263,21 @@ type Repository struct {
 	Registrar         RegistrarRepository
 	Administrator     AdministratorRepository
 	Cleanup           CleanupRepository
+	Visit             VisitRepository
 	BusinessProcess   BusinessProcessRepository
 	ReceptionLog      ReceptionLogRepository
 	Ad                AdRepository
 	RegistrarPriority RegistrarPriorityRepository
+	Waitlist          WaitlistRepository
+	Notification      NotificationRepository
+	Calendar          CalendarRepository
+	CheckInLog        CheckInLogRepository
+	RoutingRule       RoutingRuleRepository
+	BookingRule       BookingRuleRepository
+	Referral          ReferralRepository
+	PatientAudit      PatientAuditRepository
+	PatientAccess     PatientAccessRepository
+	Display           DisplayRepository
 }
 
 // NewRepository создает новый экземпляр главного репозитория.
@@ -160,9 +292,20 @@ func NewRepository(db *gorm.DB) *Repository {
 		Registrar:         NewRegistrarRepository(db),
 		Administrator:     NewAdministratorRepository(db),
 		Cleanup:           NewCleanupRepository(db),
+		Visit:             NewVisitRepository(db),
 		BusinessProcess:   NewBusinessProcessRepository(db),
 		ReceptionLog:      NewReceptionLogRepository(db),
 		Ad:                NewAdRepository(db),
 		RegistrarPriority: NewRegistrarPriorityRepository(db),
+		Waitlist:          NewWaitlistRepository(db),
+		Notification:      NewNotificationRepository(db),
+		Calendar:          NewCalendarRepository(db),
+		CheckInLog:        NewCheckInLogRepository(db),
+		RoutingRule:       NewRoutingRuleRepository(db),
+		BookingRule:       NewBookingRuleRepository(db),
+		Referral:          NewReferralRepository(db),
+		PatientAudit:      NewPatientAuditRepository(db),
+		PatientAccess:     NewPatientAccessRepository(db),
+		Display:           NewDisplayRepository(db),
 	}
 }
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type routingRuleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoutingRuleRepository(db *gorm.DB) RoutingRuleRepository {
+	return &routingRuleRepo{db: db}
+}
+
+func (r *routingRuleRepo) GetAll() ([]models.DirectRoutingRule, error) {
+	var rules []models.DirectRoutingRule
+	err := r.db.Order("rule_id asc").Find(&rules).Error
+	return rules, err
+}
+
+func (r *routingRuleRepo) Create(rule *models.DirectRoutingRule) error {
+	return r.db.Create(rule).Error
+}
+
+// Delete удаляет правило и возвращает gorm.ErrRecordNotFound, если правила не было.
+func (r *routingRuleRepo) Delete(id uint) error {
+	result := r.db.Delete(&models.DirectRoutingRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Matches проверяет, есть ли правило прямой маршрутизации для услуги или специальности врача.
+func (r *routingRuleRepo) Matches(serviceID, specialization string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.DirectRoutingRule{}).
+		Where("service_id = ? OR specialization = ?", serviceID, specialization).
+		Count(&count).Error
+	return count > 0, err
+}
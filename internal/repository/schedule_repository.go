@@ -2,6 +2,8 @@ package repository
 
 import (
 	"ElectronicQueue/internal/models"
+	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -20,7 +22,17 @@ func (r *scheduleRepo) Create(schedule *models.Schedule) error {
 }
 
 func (r *scheduleRepo) Update(schedule *models.Schedule) error {
-	return r.db.Save(schedule).Error
+	// Число занятых мест ведется только транзакциями записи, чтобы не затереть параллельные изменения.
+	return r.db.Omit("booked_count").Save(schedule).Error
+}
+
+// SetBlocked блокирует или разблокирует слот и пересчитывает его доступность одним запросом,
+// не затрагивая число занятых мест.
+func (r *scheduleRepo) SetBlocked(id uint, blocked bool) error {
+	return r.db.Model(&models.Schedule{}).Where("schedule_id = ?", id).Updates(map[string]interface{}{
+		"is_blocked":   blocked,
+		"is_available": gorm.Expr("booked_count < capacity AND NOT ?", blocked),
+	}).Error
 }
 
 func (r *scheduleRepo) GetByID(id uint) (*models.Schedule, error) {
@@ -93,6 +105,47 @@ func (r *scheduleRepo) GetAllUniqueCabinets() ([]int, error) {
 	return cabinets, nil
 }
 
+// FindFreeSlots возвращает свободные будущие слоты в диапазоне дат с фильтром по врачу или специальности.
+func (r *scheduleRepo) FindFreeSlots(filter models.FreeSlotFilter) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	query := r.db.Joins("Doctor").
+		Where("schedules.is_available = ? AND schedules.booked_count < schedules.capacity AND (schedules.date + schedules.start_time) > NOW()", true).
+		Where("schedules.date >= ? AND schedules.date <= ?", filter.DateFrom.Format("2006-01-02"), filter.DateTo.Format("2006-01-02"))
+	if filter.DoctorID != nil {
+		query = query.Where("schedules.doctor_id = ?", *filter.DoctorID)
+	}
+	if filter.Specialization != "" {
+		query = query.Where(`"Doctor".specialization = ?`, filter.Specialization)
+	}
+	err := query.Order("schedules.date asc, schedules.start_time asc").Find(&schedules).Error
+	return schedules, err
+}
+
+// FindInDateRange возвращает все слоты в диапазоне дат (включительно) с данными врача,
+// при необходимости только для указанного врача.
+func (r *scheduleRepo) FindInDateRange(from, to time.Time, doctorID *uint) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	query := r.db.Joins("Doctor").
+		Where("schedules.date >= ? AND schedules.date <= ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if doctorID != nil {
+		query = query.Where("schedules.doctor_id = ?", *doctorID)
+	}
+	err := query.Order("schedules.date asc, schedules.start_time asc, schedules.doctor_id asc").Find(&schedules).Error
+	return schedules, err
+}
+
+// CreateBatch создает несколько слотов в одной транзакции: при ошибке не создается ни один.
+func (r *scheduleRepo) CreateBatch(schedules []models.Schedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range schedules {
+			if err := tx.Omit("Doctor").Create(&schedules[i]).Error; err != nil {
+				return fmt.Errorf("слот %s %s врача %d: %w", schedules[i].Date.Format("2006-01-02"), schedules[i].StartTime, schedules[i].DoctorID, err)
+			}
+		}
+		return nil
+	})
+}
+
 func (r *scheduleRepo) Delete(id uint) error {
 	return r.db.Delete(&models.Schedule{}, id).Error
 }
@@ -148,3 +201,46 @@ func (r *scheduleRepo) FindMinMaxTimesForDate(date time.Time) (time.Time, time.T
 
 	return minTime, maxTime, nil
 }
+
+// reserveSlotPlace занимает одно место в слоте, заблокированном текущей транзакцией (SELECT ... FOR UPDATE).
+// Когда свободных мест не остается, слот становится недоступным для записи.
+func reserveSlotPlace(tx *gorm.DB, schedule *models.Schedule) error {
+	if !schedule.IsAvailable || schedule.BookedCount >= schedule.Capacity {
+		return errors.New("выбранное время уже занято")
+	}
+	if err := tx.Model(&models.Schedule{}).Where("schedule_id = ?", schedule.ID).Updates(map[string]interface{}{
+		"booked_count": gorm.Expr("booked_count + 1"),
+		"is_available": gorm.Expr("booked_count + 1 < capacity AND NOT is_blocked"),
+	}).Error; err != nil {
+		return err
+	}
+	schedule.BookedCount++
+	schedule.IsAvailable = schedule.BookedCount < schedule.Capacity && !schedule.IsBlocked
+	return nil
+}
+
+// releaseSlotPlace освобождает одно место в слоте. Слот снова открывается для записи,
+// только если он не заблокирован администратором.
+func releaseSlotPlace(tx *gorm.DB, scheduleID uint) error {
+	return tx.Model(&models.Schedule{}).Where("schedule_id = ?", scheduleID).Updates(map[string]interface{}{
+		"booked_count": gorm.Expr("GREATEST(booked_count - 1, 0)"),
+		"is_available": gorm.Expr("GREATEST(booked_count - 1, 0) < capacity AND NOT is_blocked"),
+	}).Error
+}
+
+// ensurePatientNotInSlot проверяет, что пациент еще не записан в групповой слот.
+func ensurePatientNotInSlot(tx *gorm.DB, scheduleID uint, patientID *uint) error {
+	if patientID == nil {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.Appointment{}).
+		Where("schedule_id = ? AND patient_id = ?", scheduleID, *patientID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("пациент уже записан на это время")
+	}
+	return nil
+}
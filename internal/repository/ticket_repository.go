@@ -81,6 +81,7 @@ func (r *ticketRepo) GetNextWaitingTicket(categoryPrefixes []string) (*models.Ti
 
 	orderedQuery := baseQuery.Order(`
         CASE
+            WHEN t.is_late THEN 2
             WHEN s.start_time IS NOT NULL AND s.start_time < NOW()::time THEN 0
             WHEN s.start_time IS NOT NULL AND s.start_time BETWEEN NOW()::time AND (NOW() + INTERVAL '5 minutes')::time THEN 1
             ELSE 2
@@ -140,7 +141,7 @@ func (r *ticketRepo) FindTicketsForCabinetQueue(cabinetNumber int) ([]models.Doc
 	today := time.Now().Format("2006-01-02")
 
 	err := r.db.Table("tickets").
-		Select("to_char(schedules.start_time, 'HH24:MI') as start_time, tickets.ticket_number, COALESCE(patients.full_name, 'Пациент по талону') as full_name, tickets.status").
+		Select("to_char(schedules.start_time, 'HH24:MI') as start_time, tickets.ticket_number, COALESCE(patients.full_name, '') as full_name, tickets.status").
 		Joins("JOIN appointments ON appointments.ticket_id = tickets.ticket_id").
 		Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
 		Joins("LEFT JOIN patients ON patients.patient_id = appointments.patient_id").
@@ -162,7 +163,7 @@ func (r *ticketRepo) FindAllTicketsForDoctorQueues() ([]models.DoctorQueueTicket
 	today := time.Now().Format("2006-01-02")
 
 	err := r.db.Table("tickets").
-		Select("schedules.cabinet as cabinet_number, tickets.ticket_number, COALESCE(patients.full_name, 'Пациент по талону') as full_name, tickets.status").
+		Select("schedules.cabinet as cabinet_number, tickets.ticket_number, COALESCE(patients.full_name, '') as full_name, tickets.status").
 		Joins("JOIN appointments ON appointments.ticket_id = tickets.ticket_id").
 		Joins("JOIN schedules ON schedules.schedule_id = appointments.schedule_id").
 		Joins("LEFT JOIN patients ON patients.patient_id = appointments.patient_id").
@@ -204,7 +205,8 @@ func (r *ticketRepo) GetDailyReport(date time.Time) ([]models.DailyReportRow, er
             t.status,
             t.called_at,
             t.completed_at,
-            to_char(t.completed_at - COALESCE(t.started_at, t.called_at), 'HH24:MI:SS') as duration
+            to_char(t.completed_at - COALESCE(t.started_at, t.called_at), 'HH24:MI:SS') as duration,
+            t.is_late
         `).
 		Joins("LEFT JOIN appointments as a ON t.ticket_id = a.ticket_id").
 		Joins("LEFT JOIN patients as p ON a.patient_id = p.patient_id").
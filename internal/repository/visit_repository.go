@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// visitColumns — колонки архива визитов в порядке выражения visitFieldsSQL.
+const visitColumns = `patient_id, appointment_id, ticket_id, ticket_number, service_type, ticket_status, is_late,
+	doctor_id, doctor_name, specialization, cabinet, visit_date, start_time, end_time, outcome,
+	arrival, arrival_offset_minutes, ticket_created_at, called_at, started_at, completed_at, window_number,
+	wait_seconds, service_seconds, visit_seconds`
+
+// visitFieldsSQL и visitFromSQL собирают действующие записи на прием с талоном, временем ожидания и обслуживания
+// в регистратуре (по reception_logs), последней регистрацией через киоск и итогом визита. Выражения используются
+// и для истории пациента, и для переноса записей в архив перед ночной очисткой, поэтому итог считается на текущую дату.
+const visitFieldsSQL = `
+	a.patient_id, a.appointment_id, t.ticket_id, t.ticket_number, t.service_type, t.status AS ticket_status,
+		COALESCE(t.is_late, FALSE) AS is_late,
+		d.doctor_id, d.full_name AS doctor_name, d.specialization, s.cabinet, s.date AS visit_date, s.start_time, s.end_time,
+		CASE
+			WHEN t.completed_at IS NOT NULL OR t.status = 'завершен' THEN 'завершен'
+			WHEN t.status = 'на_приеме' THEN 'на приеме'
+			WHEN t.ticket_id IS NOT NULL AND s.date < CURRENT_DATE THEN 'не обслужен'
+			WHEN t.ticket_id IS NOT NULL THEN 'в очереди'
+			WHEN s.date < CURRENT_DATE THEN 'неявка'
+			ELSE 'запланирован'
+		END AS outcome,
+		c.outcome AS arrival, c.offset_minutes AS arrival_offset_minutes,
+		t.created_at AS ticket_created_at, t.called_at, t.started_at, t.completed_at, t.window_number,
+		EXTRACT(EPOCH FROM rl.first_called_at - t.created_at)::int AS wait_seconds,
+		rl.service_seconds,
+		EXTRACT(EPOCH FROM t.completed_at - t.started_at)::int AS visit_seconds`
+
+const visitFromSQL = `
+	FROM appointments a
+	JOIN schedules s ON s.schedule_id = a.schedule_id
+	JOIN doctors d ON d.doctor_id = s.doctor_id
+	LEFT JOIN tickets t ON t.ticket_id = a.ticket_id
+	LEFT JOIN LATERAL (
+		SELECT MIN(called_at) AS first_called_at,
+			EXTRACT(EPOCH FROM SUM(COALESCE(duration, completed_at - called_at)))::int AS service_seconds
+		FROM reception_logs
+		WHERE ticket_id = t.ticket_id
+	) rl ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT outcome, offset_minutes
+		FROM checkin_logs
+		WHERE appointment_id = a.appointment_id
+		ORDER BY created_at DESC
+		LIMIT 1
+	) c ON TRUE`
+
+type visitRepo struct {
+	db *gorm.DB
+}
+
+// NewVisitRepository создает новый экземпляр VisitRepository.
+func NewVisitRepository(db *gorm.DB) VisitRepository {
+	return &visitRepo{db: db}
+}
+
+// FindByPatient возвращает визиты пациента, новые первыми. Записи, уже перенесенные в архив, берутся только из него
+// и только при includeArchived. Пустая specialization не ограничивает выборку, иначе сравнение без учета регистра.
+func (r *visitRepo) FindByPatient(patientID uint, specialization string, includeArchived bool) ([]models.PatientVisit, error) {
+	specialization = strings.TrimSpace(specialization)
+
+	live := `SELECT ` + visitFieldsSQL + `, NULL::timestamptz AS archived_at ` + visitFromSQL + `
+		WHERE a.patient_id = ?
+			AND NOT EXISTS (SELECT 1 FROM visit_archive va WHERE va.appointment_id = a.appointment_id)`
+	args := []interface{}{patientID}
+	if specialization != "" {
+		live += ` AND lower(d.specialization) = lower(?)`
+		args = append(args, specialization)
+	}
+	query := live
+	if includeArchived {
+		archived := `SELECT ` + visitColumns + `, archived_at FROM visit_archive WHERE patient_id = ?`
+		args = append(args, patientID)
+		if specialization != "" {
+			archived += ` AND lower(specialization) = lower(?)`
+			args = append(args, specialization)
+		}
+		query = `(` + live + `) UNION ALL (` + archived + `)`
+	}
+
+	var visits []models.PatientVisit
+	err := r.db.Raw(`SELECT * FROM (`+query+`) v ORDER BY visit_date DESC, start_time DESC, appointment_id DESC`, args...).
+		Scan(&visits).Error
+	return visits, err
+}
+
+// archiveVisits переносит в архив записи на прием пациентов, которые удалит ночная очистка: с завершенными
+// талонами и без талонов. Уже заархивированные записи пропускаются, в том числе если их одновременно
+// архивирует другой экземпляр. Возвращает число перенесенных записей.
+func archiveVisits(tx *gorm.DB) (int64, error) {
+	res := tx.Exec(`INSERT INTO visit_archive (` + visitColumns + `) SELECT ` + visitFieldsSQL + visitFromSQL + `
+		WHERE a.patient_id IS NOT NULL
+			AND (t.completed_at IS NOT NULL OR a.ticket_id IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM visit_archive va WHERE va.appointment_id = a.appointment_id)
+		ON CONFLICT (appointment_id) DO NOTHING`)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"ElectronicQueue/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type waitlistRepo struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) WaitlistRepository {
+	return &waitlistRepo{db: db}
+}
+
+func (r *waitlistRepo) Create(entry *models.WaitlistEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *waitlistRepo) GetByID(id uint) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.db.Preload("Patient").Preload("Doctor").Preload("HeldSchedule.Doctor").First(&entry, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindByStatuses возвращает заявки с указанными статусами в порядке постановки в лист ожидания.
+// Если статусы не переданы, возвращаются все заявки.
+func (r *waitlistRepo) FindByStatuses(statuses []models.WaitlistStatus) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	query := r.db.Preload("Patient").Preload("Doctor").Preload("HeldSchedule.Doctor")
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Order("created_at ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepo) UpdateStatus(id uint, status models.WaitlistStatus) error {
+	return r.db.Model(&models.WaitlistEntry{}).Where("waitlist_id = ?", id).Update("status", status).Error
+}
+
+// HoldSlotForNextEntry в рамках одной транзакции подбирает первую подходящую заявку для
+// свободного слота и удерживает слот за ней до holdUntil.
+// Возвращает gorm.ErrRecordNotFound, если слот недоступен или подходящих заявок нет.
+func (r *waitlistRepo) HoldSlotForNextEntry(scheduleID uint, holdUntil time.Time, excludeEntryID uint) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var schedule models.Schedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("schedule_id = ? AND is_available = ? AND booked_count < capacity AND (date + start_time) > NOW()", scheduleID, true).
+			First(&schedule).Error; err != nil {
+			return err
+		}
+
+		var doctor models.Doctor
+		if err := tx.First(&doctor, schedule.DoctorID).Error; err != nil {
+			return err
+		}
+
+		slotDate := schedule.Date.Format("2006-01-02")
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND date_from <= ? AND date_to >= ?", models.WaitlistStatusWaiting, slotDate, slotDate).
+			Where("doctor_id = ? OR (doctor_id IS NULL AND specialization = ?)", schedule.DoctorID, doctor.Specialization).
+			Where("NOT EXISTS (SELECT 1 FROM appointments a WHERE a.schedule_id = ? AND a.patient_id = waitlist_entries.patient_id)", schedule.ID)
+		if excludeEntryID != 0 {
+			query = query.Where("waitlist_id <> ?", excludeEntryID)
+		}
+		if err := query.Order("created_at ASC").First(&entry).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&entry).Updates(map[string]interface{}{
+			"status":           models.WaitlistStatusOffered,
+			"held_schedule_id": schedule.ID,
+			"hold_expires_at":  holdUntil,
+			"offered_at":       now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return reserveSlotPlace(tx, &schedule)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(entry.ID)
+}
+
+// ConfirmHold превращает удерживаемый слот в полноценную запись на прием.
+// check (может быть nil) вызывается под блокировкой строки пациента; при ошибке удержание не меняется.
+// Если удерживаемый слот тем временем удален из расписания, заявка возвращается в лист ожидания.
+func (r *waitlistRepo) ConfirmHold(entryID uint, check BookingCheck) (*models.Appointment, error) {
+	var appointment models.Appointment
+	holdLost := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		entry, err := r.lockOfferedEntry(tx, entryID)
+		if err != nil {
+			return err
+		}
+		if entry.HeldScheduleID == nil {
+			// Слот удален из расписания: заявка возвращается в лист ожидания, подтверждать нечего.
+			holdLost = true
+			return returnEntryToWaitlist(tx, entry, models.WaitlistStatusWaiting)
+		}
+
+		if check != nil {
+			if err := lockPatient(tx, entry.PatientID); err != nil {
+				return err
+			}
+			if err := check(tx, entry.PatientID, *entry.HeldScheduleID); err != nil {
+				return err
+			}
+		}
+
+		var schedule models.Schedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, *entry.HeldScheduleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("удерживаемый слот в расписании не найден")
+			}
+			return err
+		}
+
+		patientID := entry.PatientID
+		appointment = models.Appointment{
+			ScheduleID: schedule.ID,
+			PatientID:  &patientID,
+		}
+		if err := tx.Create(&appointment).Error; err != nil {
+			return err
+		}
+		if err := enqueueAppointmentNotification(tx, models.NotificationEventAppointmentCreated, &appointment); err != nil {
+			return err
+		}
+
+		return tx.Model(entry).Updates(map[string]interface{}{
+			"status":          models.WaitlistStatusConfirmed,
+			"appointment_id":  appointment.ID,
+			"hold_expires_at": nil,
+		}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if holdLost {
+		return nil, fmt.Errorf("удерживаемый слот удален из расписания, заявка с ID %d возвращена в лист ожидания", entryID)
+	}
+
+	if err := r.db.Preload("Patient").Preload("Schedule.Doctor").First(&appointment, appointment.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &appointment, nil
+}
+
+// ReleaseHold снимает удержание слота, переводит заявку в указанный статус
+// и возвращает ID освобожденного слота (0, если слот уже удален из расписания).
+func (r *waitlistRepo) ReleaseHold(entryID uint, status models.WaitlistStatus) (uint, error) {
+	var scheduleID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		entry, err := r.lockOfferedEntry(tx, entryID)
+		if err != nil {
+			return err
+		}
+		if entry.HeldScheduleID == nil {
+			// Слот удален из расписания вместе с удержанием: освобождать нечего.
+			return returnEntryToWaitlist(tx, entry, status)
+		}
+		scheduleID = *entry.HeldScheduleID
+
+		if err := releaseSlotPlace(tx, scheduleID); err != nil {
+			return err
+		}
+		return returnEntryToWaitlist(tx, entry, status)
+	})
+	return scheduleID, err
+}
+
+// ReleaseExpiredHold снимает самое давнее просроченное удержание, возвращает заявку в лист ожидания
+// и возвращает ID заявки и освобожденного слота (0, если слот уже удален из расписания). Заявка выбирается
+// с SKIP LOCKED, а срок удержания проверяется под блокировкой строки, поэтому два экземпляра, одновременно
+// снимающие удержания, не обработают одну заявку дважды и не снимут удержание, которое тем временем
+// подтверждено или выдано заново. Если просроченных удержаний нет, возвращает gorm.ErrRecordNotFound.
+func (r *waitlistRepo) ReleaseExpiredHold(now time.Time) (uint, uint, error) {
+	var entryID, scheduleID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var entry models.WaitlistEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND hold_expires_at < ?", models.WaitlistStatusOffered, now).
+			Order("hold_expires_at ASC").
+			First(&entry).Error; err != nil {
+			return err
+		}
+		entryID = entry.ID
+		if entry.HeldScheduleID != nil {
+			scheduleID = *entry.HeldScheduleID
+			if err := releaseSlotPlace(tx, scheduleID); err != nil {
+				return err
+			}
+		}
+		return returnEntryToWaitlist(tx, &entry, models.WaitlistStatusWaiting)
+	})
+	return entryID, scheduleID, err
+}
+
+func (r *waitlistRepo) lockOfferedEntry(tx *gorm.DB, entryID uint) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, entryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("заявка в листе ожидания с ID %d не найдена", entryID)
+		}
+		return nil, err
+	}
+	if entry.Status != models.WaitlistStatusOffered {
+		return nil, fmt.Errorf("для заявки с ID %d нет удерживаемого слота", entryID)
+	}
+	return &entry, nil
+}
+
+// returnEntryToWaitlist снимает с заявки удержание и переводит ее в статус status.
+func returnEntryToWaitlist(tx *gorm.DB, entry *models.WaitlistEntry, status models.WaitlistStatus) error {
+	return tx.Model(entry).Updates(map[string]interface{}{
+		"status":           status,
+		"held_schedule_id": nil,
+		"hold_expires_at":  nil,
+	}).Error
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/pubsub"
 	"ElectronicQueue/internal/repository"
 	"encoding/base64"
 	"errors"
@@ -11,11 +12,12 @@ import (
 )
 
 type AdService struct {
-	repo repository.AdRepository
+	repo   repository.AdRepository
+	broker pubsub.Publisher
 }
 
-func NewAdService(repo repository.AdRepository) *AdService {
-	return &AdService{repo: repo}
+func NewAdService(repo repository.AdRepository, broker pubsub.Publisher) *AdService {
+	return &AdService{repo: repo, broker: broker}
 }
 
 func (s *AdService) Create(req *models.CreateAdRequest) (*models.Ad, error) {
@@ -64,6 +66,7 @@ func (s *AdService) Create(req *models.CreateAdRequest) (*models.Ad, error) {
 	if err := s.repo.Create(ad); err != nil {
 		return nil, fmt.Errorf("could not create ad: %w", err)
 	}
+	s.broker.Publish(pubsub.AdsChanged(ad.ID, "create"))
 	return ad, nil
 }
 
@@ -145,6 +148,7 @@ func (s *AdService) Update(id uint, req *models.UpdateAdRequest) (*models.Ad, er
 	if err := s.repo.Update(ad); err != nil {
 		return nil, fmt.Errorf("could not update ad: %w", err)
 	}
+	s.broker.Publish(pubsub.AdsChanged(ad.ID, "update"))
 	return ad, nil
 }
 
@@ -152,5 +156,6 @@ func (s *AdService) Delete(id uint) error {
 	if err := s.repo.Delete(id); err != nil {
 		return fmt.Errorf("could not delete ad: %w", err)
 	}
+	s.broker.Publish(pubsub.AdsChanged(id, "delete"))
 	return nil
 }
@@ -1,10 +1,16 @@
 package services
 
 import (
+	"ElectronicQueue/internal/logger"
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/repository"
+	"ElectronicQueue/internal/utils"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // AppointmentDetailsResponse определяет детальную информацию о записи для истории.
@@ -22,13 +28,45 @@ type AppointmentDetailsResponse struct {
 
 // AppointmentService предоставляет методы для управления записями на прием.
 type AppointmentService struct {
-	repo       repository.AppointmentRepository
-	ticketRepo repository.TicketRepository
+	repo          repository.AppointmentRepository
+	ticketRepo    repository.TicketRepository
+	registrarRepo repository.RegistrarRepository
+	waitlist      *WaitlistService
+	rules         *BookingRulesService
+	referrals     *ReferralService
+	checkInCodes  *utils.CheckInCodeSigner
 }
 
 // NewAppointmentService создает новый экземпляр AppointmentService.
-func NewAppointmentService(repo repository.AppointmentRepository, ticketRepo repository.TicketRepository) *AppointmentService {
-	return &AppointmentService{repo: repo, ticketRepo: ticketRepo}
+func NewAppointmentService(
+	repo repository.AppointmentRepository,
+	ticketRepo repository.TicketRepository,
+	registrarRepo repository.RegistrarRepository,
+	waitlist *WaitlistService,
+	rules *BookingRulesService,
+	referrals *ReferralService,
+	checkInCodes *utils.CheckInCodeSigner,
+) *AppointmentService {
+	return &AppointmentService{
+		repo:          repo,
+		ticketRepo:    ticketRepo,
+		registrarRepo: registrarRepo,
+		waitlist:      waitlist,
+		rules:         rules,
+		referrals:     referrals,
+		checkInCodes:  checkInCodes,
+	}
+}
+
+// GetCheckInCode возвращает код записи для QR-кода, по которому пациент регистрируется через киоск.
+func (s *AppointmentService) GetCheckInCode(appointmentID uint) (*models.CheckInCodeResponse, error) {
+	if _, err := s.repo.FindByID(appointmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("запись с ID %d не найдена", appointmentID)
+		}
+		return nil, fmt.Errorf("ошибка поиска записи: %w", err)
+	}
+	return &models.CheckInCodeResponse{AppointmentID: appointmentID, Code: s.checkInCodes.Code(appointmentID)}, nil
 }
 
 // GetDoctorScheduleWithAppointments получает расписание врача вместе с информацией о существующих записях.
@@ -41,16 +79,73 @@ func (s *AppointmentService) GetDoctorScheduleWithAppointments(doctorID uint, da
 }
 
 // CreateAppointment обрабатывает логику создания новой записи.
+// Правила записи проверяются в транзакции создания под блокировкой пациента; при нарушении возвращается *BookingRulesError.
+// Старший регистратор может записать пациента в обход правил, указав причину.
+// Запись по направлению (ReferralID) проверяется на соответствие пациента и специальности и исполняет его.
 // Основная работа (транзакция) выполняется в репозитории.
-func (s *AppointmentService) CreateAppointment(req *models.CreateAppointmentRequest) (*models.Appointment, error) {
+func (s *AppointmentService) CreateAppointment(req *models.CreateAppointmentRequest, registrarID uint) (*models.Appointment, error) {
 	if req.ScheduleID == 0 {
 		return nil, fmt.Errorf("ScheduleID является обязательным полем")
 	}
 
-	appointment, err := s.repo.CreateAppointmentInTransaction(req)
+	if req.ReferralID != nil {
+		if err := s.referrals.ValidateForBooking(*req.ReferralID, req.PatientID, req.ScheduleID); err != nil {
+			return nil, err
+		}
+	}
+
+	req.RulesOverrideBy = nil
+	var overridden []models.BookingRuleViolation
+	check := func(tx *gorm.DB, patientID, scheduleID uint) error {
+		violations, err := s.rules.Evaluate(tx, patientID, scheduleID, 0)
+		if err != nil {
+			return err
+		}
+		if len(violations) == 0 {
+			return nil
+		}
+		if !req.OverrideRules {
+			return &BookingRulesError{Violations: violations}
+		}
+		if strings.TrimSpace(req.OverrideReason) == "" {
+			return errors.New("укажите причину переопределения правил записи")
+		}
+		registrar, err := s.registrarRepo.FindByID(registrarID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("ошибка проверки прав регистратора: %w", err)
+		}
+		if registrar == nil || !registrar.IsSenior {
+			return &BookingRulesError{Violations: violations, OverrideDenied: true}
+		}
+		req.OverrideReason = strings.TrimSpace(req.OverrideReason)
+		req.RulesOverrideBy = &registrarID
+		overridden = violations
+		return nil
+	}
+
+	appointment, err := s.repo.CreateAppointmentInTransaction(req, 0, check)
 	if err != nil {
+		var rulesErr *BookingRulesError
+		if errors.As(err, &rulesErr) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("не удалось создать запись на прием: %w", err)
 	}
+
+	if len(overridden) > 0 {
+		rules := make([]string, 0, len(overridden))
+		for _, v := range overridden {
+			rules = append(rules, string(v.Rule))
+		}
+		logger.Default().WithFields(map[string]interface{}{
+			"registrar_id":   registrarID,
+			"patient_id":     *req.PatientID,
+			"schedule_id":    req.ScheduleID,
+			"appointment_id": appointment.ID,
+			"rules":          strings.Join(rules, ","),
+			"reason":         req.OverrideReason,
+		}).Warn("Правила записи переопределены старшим регистратором")
+	}
 	return appointment, nil
 }
 
@@ -93,9 +188,35 @@ func (s *AppointmentService) GetAppointmentsByPatient(patientID uint) ([]Appoint
 	return response, nil
 }
 
-// DeleteAppointment удаляет запись.
+// DeleteAppointment удаляет запись и предлагает освободившийся слот листу ожидания.
 func (s *AppointmentService) DeleteAppointment(appointmentID uint) error {
-	return s.repo.DeleteAppointmentAndFreeSlot(appointmentID)
+	scheduleID, err := s.repo.DeleteAppointmentAndFreeSlot(appointmentID)
+	if err != nil {
+		return err
+	}
+	s.waitlist.OfferSlot(scheduleID)
+	return nil
+}
+
+// RescheduleAppointment переносит запись в другой свободный слот.
+// Новый слот проверяется по правилам записи в транзакции переноса без учета переносимой записи;
+// при нарушении возвращается *BookingRulesError.
+// Освободившийся слот предлагается листу ожидания, пациенту ставится в очередь уведомление об изменении.
+func (s *AppointmentService) RescheduleAppointment(appointmentID, scheduleID uint) (*models.Appointment, error) {
+	check := func(tx *gorm.DB, patientID, newScheduleID uint) error {
+		return s.rules.Check(tx, patientID, newScheduleID, appointmentID)
+	}
+
+	appointment, oldScheduleID, err := s.repo.RescheduleAppointmentInTransaction(appointmentID, scheduleID, check)
+	if err != nil {
+		var rulesErr *BookingRulesError
+		if errors.As(err, &rulesErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("не удалось перенести запись: %w", err)
+	}
+	s.waitlist.OfferSlot(oldScheduleID)
+	return appointment, nil
 }
 
 // ConfirmAppointment подтверждает явку по записи.
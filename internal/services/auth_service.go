@@ -103,7 +103,7 @@ func (s *AuthService) AuthenticateAdministrator(login, password string) (string,
 	return s.jwtManager.GenerateJWT(claims)
 }
 
-func (s *AuthService) CreateRegistrar(windowNumber int, login, password string) (*models.Registrar, error) {
+func (s *AuthService) CreateRegistrar(windowNumber int, login, password string, isSenior bool) (*models.Registrar, error) {
 	_, err := s.registrarRepo.FindByLogin(login)
 	if err == nil {
 		return nil, fmt.Errorf("логин '%s' уже занят", login)
@@ -121,6 +121,7 @@ func (s *AuthService) CreateRegistrar(windowNumber int, login, password string)
 		WindowNumber: windowNumber,
 		Login:        login,
 		PasswordHash: string(hashedPassword),
+		IsSenior:     isSenior,
 	}
 
 	if err := s.registrarRepo.Create(newRegistrar); err != nil {
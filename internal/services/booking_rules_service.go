@@ -0,0 +1,301 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookingRulesError возвращается, если запись нарушает правила записи.
+// Регистратор видит список нарушений; старший регистратор может записать пациента с override_rules.
+type BookingRulesError struct {
+	Violations []models.BookingRuleViolation
+	// OverrideDenied — переопределение запрошено, но регистратор не является старшим.
+	OverrideDenied bool
+}
+
+func (e *BookingRulesError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	prefix := "запись нарушает правила"
+	if e.OverrideDenied {
+		prefix = "переопределять правила записи могут только старшие регистраторы, запись нарушает правила"
+	}
+	return prefix + ": " + strings.Join(messages, "; ")
+}
+
+// BookingRulesService проверяет правила записи пациентов и управляет их настройками.
+type BookingRulesService struct {
+	repo            repository.BookingRuleRepository
+	appointmentRepo repository.AppointmentRepository
+	scheduleRepo    repository.ScheduleRepository
+	doctorRepo      repository.DoctorRepository
+	patientRepo     repository.PatientRepository
+}
+
+// NewBookingRulesService создает новый экземпляр BookingRulesService.
+func NewBookingRulesService(
+	repo repository.BookingRuleRepository,
+	appointmentRepo repository.AppointmentRepository,
+	scheduleRepo repository.ScheduleRepository,
+	doctorRepo repository.DoctorRepository,
+	patientRepo repository.PatientRepository,
+) *BookingRulesService {
+	return &BookingRulesService{
+		repo:            repo,
+		appointmentRepo: appointmentRepo,
+		scheduleRepo:    scheduleRepo,
+		doctorRepo:      doctorRepo,
+		patientRepo:     patientRepo,
+	}
+}
+
+// GetAll возвращает правила записи по всем специальностям.
+func (s *BookingRulesService) GetAll() ([]models.BookingRule, error) {
+	return s.repo.GetAll()
+}
+
+// Upsert создает или заменяет правила записи для специальности.
+func (s *BookingRulesService) Upsert(req *models.UpsertBookingRuleRequest) (*models.BookingRule, error) {
+	specialization := strings.TrimSpace(req.Specialization)
+	if specialization == "" {
+		return nil, errors.New("укажите специальность")
+	}
+	if req.MinAge != nil && req.MaxAge != nil && *req.MinAge > *req.MaxAge {
+		return nil, errors.New("минимальный возраст не может быть больше максимального")
+	}
+
+	rule := &models.BookingRule{
+		Specialization:    specialization,
+		MaxActiveBookings: req.MaxActiveBookings,
+		MinIntervalDays:   req.MinIntervalDays,
+		MinAge:            req.MinAge,
+		MaxAge:            req.MaxAge,
+	}
+	if err := s.repo.Upsert(rule); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить правила записи: %w", err)
+	}
+	return rule, nil
+}
+
+// Delete удаляет правила записи для специальности.
+func (s *BookingRulesService) Delete(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("правила записи с ID %d не найдены", id)
+		}
+		return fmt.Errorf("не удалось удалить правила записи: %w", err)
+	}
+	return nil
+}
+
+// Check проверяет правила записи пациента в слот и возвращает *BookingRulesError при нарушениях.
+// Вызывается на всех путях создания и переноса записи внутри их транзакции (см. repository.BookingCheck);
+// excludeAppointmentID — переносимая запись пациента, которая не учитывается при проверке (0, если запись создается).
+func (s *BookingRulesService) Check(tx *gorm.DB, patientID, scheduleID, excludeAppointmentID uint) error {
+	violations, err := s.Evaluate(tx, patientID, scheduleID, excludeAppointmentID)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &BookingRulesError{Violations: violations}
+	}
+	return nil
+}
+
+// Evaluate проверяет запись пациента в слот и возвращает все нарушенные правила.
+// Данные читаются в транзакции tx, в которой строка пациента уже заблокирована.
+// Пересечение с другими записями пациента проверяется всегда, остальные правила — по настройкам специальности.
+// Запись excludeAppointmentID (переносимая) не учитывается.
+func (s *BookingRulesService) Evaluate(tx *gorm.DB, patientID, scheduleID, excludeAppointmentID uint) ([]models.BookingRuleViolation, error) {
+	return s.withTx(tx).evaluate(patientID, scheduleID, excludeAppointmentID)
+}
+
+// withTx возвращает копию сервиса, репозитории которой работают в транзакции tx.
+func (s *BookingRulesService) withTx(tx *gorm.DB) *BookingRulesService {
+	return NewBookingRulesService(
+		repository.NewBookingRuleRepository(tx),
+		repository.NewAppointmentRepository(tx),
+		repository.NewScheduleRepository(tx),
+		repository.NewDoctorRepository(tx),
+		repository.NewPatientRepository(tx),
+	)
+}
+
+func (s *BookingRulesService) evaluate(patientID, scheduleID, excludeAppointmentID uint) ([]models.BookingRuleViolation, error) {
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("указанный слот в расписании не найден")
+		}
+		return nil, fmt.Errorf("ошибка поиска слота: %w", err)
+	}
+	doctor, err := s.doctorRepo.GetByID(schedule.DoctorID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска врача: %w", err)
+	}
+	patient, err := s.patientRepo.GetByID(patientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("пациент с ID %d не найден", patientID)
+		}
+		return nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+	}
+
+	slotStart, err := schedule.StartsAt()
+	if err != nil {
+		return nil, fmt.Errorf("некорректное время начала слота: %w", err)
+	}
+	slotEnd, err := schedule.EndsAt()
+	if err != nil {
+		return nil, fmt.Errorf("некорректное время окончания слота: %w", err)
+	}
+
+	rule, err := s.repo.FindBySpecialization(doctor.Specialization)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("ошибка получения правил записи: %w", err)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		rule = &models.BookingRule{Specialization: doctor.Specialization}
+	}
+
+	// Записи, которые могут пересекаться с новой или быть ближе минимального интервала.
+	from, to := schedule.Date, schedule.Date
+	if rule.MinIntervalDays != nil {
+		from = schedule.Date.AddDate(0, 0, -(*rule.MinIntervalDays - 1))
+		to = schedule.Date.AddDate(0, 0, *rule.MinIntervalDays-1)
+	}
+	nearby, err := s.appointmentRepo.FindByPatientInDateRange(patientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей пациента: %w", err)
+	}
+	nearby = withoutAppointment(nearby, excludeAppointmentID)
+
+	var violations []models.BookingRuleViolation
+	violations = append(violations, overlapViolations(nearby, slotStart, slotEnd)...)
+
+	if rule.MaxActiveBookings != nil {
+		active, err := s.appointmentRepo.FindActiveByPatientID(patientID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка получения активных записей пациента: %w", err)
+		}
+		active = withoutAppointment(active, excludeAppointmentID)
+		count := 0
+		for _, a := range active {
+			if a.Schedule.Doctor.Specialization == doctor.Specialization {
+				count++
+			}
+		}
+		if count >= *rule.MaxActiveBookings {
+			violations = append(violations, models.BookingRuleViolation{
+				Rule: models.BookingRuleMaxActive,
+				Message: fmt.Sprintf("у пациента уже %d активных записей к специалисту «%s», допускается не более %d",
+					count, doctor.Specialization, *rule.MaxActiveBookings),
+			})
+		}
+	}
+
+	if rule.MinIntervalDays != nil {
+		for i := range nearby {
+			a := &nearby[i]
+			if a.Schedule.Doctor.Specialization != doctor.Specialization || a.ScheduleID == schedule.ID {
+				continue
+			}
+			id := a.ID
+			violations = append(violations, models.BookingRuleViolation{
+				Rule: models.BookingRuleMinInterval,
+				Message: fmt.Sprintf("между визитами к специалисту «%s» должно пройти не менее %d дн., у пациента есть запись на %s",
+					doctor.Specialization, *rule.MinIntervalDays, a.Schedule.Date.Format("02.01.2006")),
+				ConflictingAppointmentID: &id,
+			})
+			break
+		}
+	}
+
+	if rule.MinAge != nil || rule.MaxAge != nil {
+		if patient.BirthDate.IsZero() {
+			violations = append(violations, models.BookingRuleViolation{
+				Rule:    models.BookingRuleAge,
+				Message: fmt.Sprintf("для записи к специалисту «%s» нужна дата рождения пациента", doctor.Specialization),
+			})
+		} else {
+			age := ageOn(patient.BirthDate, schedule.Date)
+			if (rule.MinAge != nil && age < *rule.MinAge) || (rule.MaxAge != nil && age > *rule.MaxAge) {
+				violations = append(violations, models.BookingRuleViolation{
+					Rule: models.BookingRuleAge,
+					Message: fmt.Sprintf("специалист «%s» принимает пациентов %s, возраст пациента на дату приема — %d",
+						doctor.Specialization, ageRange(rule.MinAge, rule.MaxAge), age),
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// withoutAppointment убирает из списка запись с указанным ID.
+func withoutAppointment(appointments []models.Appointment, appointmentID uint) []models.Appointment {
+	if appointmentID == 0 {
+		return appointments
+	}
+	result := appointments[:0]
+	for _, a := range appointments {
+		if a.ID != appointmentID {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// overlapViolations находит записи пациента, пересекающиеся по времени со слотом.
+func overlapViolations(appointments []models.Appointment, slotStart, slotEnd time.Time) []models.BookingRuleViolation {
+	var violations []models.BookingRuleViolation
+	for i := range appointments {
+		a := &appointments[i]
+		start, err := a.Schedule.StartsAt()
+		if err != nil {
+			continue
+		}
+		end, err := a.Schedule.EndsAt()
+		if err != nil {
+			continue
+		}
+		if start.Before(slotEnd) && slotStart.Before(end) {
+			id := a.ID
+			violations = append(violations, models.BookingRuleViolation{
+				Rule: models.BookingRuleOverlap,
+				Message: fmt.Sprintf("пациент уже записан на это время: %s, %s",
+					a.Schedule.Doctor.Specialization, start.Format("02.01.2006 15:04")),
+				ConflictingAppointmentID: &id,
+			})
+		}
+	}
+	return violations
+}
+
+// ageOn возвращает полное число лет на указанную дату.
+func ageOn(birthDate, date time.Time) int {
+	age := date.Year() - birthDate.Year()
+	if date.Month() < birthDate.Month() || (date.Month() == birthDate.Month() && date.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+func ageRange(minAge, maxAge *int) string {
+	switch {
+	case minAge != nil && maxAge != nil:
+		return fmt.Sprintf("в возрасте от %d до %d лет", *minAge, *maxAge)
+	case minAge != nil:
+		return fmt.Sprintf("с %d лет", *minAge)
+	default:
+		return fmt.Sprintf("до %d лет включительно", *maxAge)
+	}
+}
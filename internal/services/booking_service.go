@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/repository"
+	"ElectronicQueue/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// BookingService реализует самостоятельную запись пациентов на прием через внешний API
+// (например, с портала пациента) с учетом ограничений на число записей, горизонт и отмену.
+type BookingService struct {
+	doctorRepo      repository.DoctorRepository
+	scheduleRepo    repository.ScheduleRepository
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+	waitlist        *WaitlistService
+	rules           *BookingRulesService
+	calendar        *CalendarService
+	checkInCodes    *utils.CheckInCodeSigner
+	maxActive       int64
+	horizonDays     int
+	cancelCutoff    time.Duration
+	log             *logger.AsyncLogger
+}
+
+// NewBookingService создает новый экземпляр BookingService.
+func NewBookingService(
+	doctorRepo repository.DoctorRepository,
+	scheduleRepo repository.ScheduleRepository,
+	patientRepo repository.PatientRepository,
+	appointmentRepo repository.AppointmentRepository,
+	waitlist *WaitlistService,
+	rules *BookingRulesService,
+	calendar *CalendarService,
+	checkInCodes *utils.CheckInCodeSigner,
+	maxActive string,
+	horizonDays string,
+	cancelCutoff string,
+) (*BookingService, error) {
+	active, err := strconv.ParseInt(maxActive, 10, 64)
+	if err != nil || active <= 0 {
+		return nil, fmt.Errorf("invalid external booking max active value: %q", maxActive)
+	}
+	horizon, err := strconv.Atoi(horizonDays)
+	if err != nil || horizon <= 0 {
+		return nil, fmt.Errorf("invalid external booking horizon days value: %q", horizonDays)
+	}
+	cutoff, err := time.ParseDuration(cancelCutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse external booking cancel cutoff: %w", err)
+	}
+	if cutoff < 0 {
+		return nil, fmt.Errorf("external booking cancel cutoff must not be negative")
+	}
+
+	return &BookingService{
+		doctorRepo:      doctorRepo,
+		scheduleRepo:    scheduleRepo,
+		patientRepo:     patientRepo,
+		appointmentRepo: appointmentRepo,
+		waitlist:        waitlist,
+		rules:           rules,
+		calendar:        calendar,
+		checkInCodes:    checkInCodes,
+		maxActive:       active,
+		horizonDays:     horizon,
+		cancelCutoff:    cutoff,
+		log:             logger.Default().WithField("module", "booking"),
+	}, nil
+}
+
+// GetSpecializations возвращает список специальностей, на которые можно записаться.
+func (s *BookingService) GetSpecializations() ([]string, error) {
+	specializations, err := s.doctorRepo.GetSpecializations()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения специальностей: %w", err)
+	}
+	return specializations, nil
+}
+
+// GetDoctors возвращает врачей, при необходимости только указанной специальности.
+func (s *BookingService) GetDoctors(specialization string) ([]models.ExternalDoctorResponse, error) {
+	var doctors []models.Doctor
+	var err error
+	if specialization = strings.TrimSpace(specialization); specialization != "" {
+		doctors, err = s.doctorRepo.FindBySpecialization(specialization)
+	} else {
+		doctors, err = s.doctorRepo.GetAll(false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка врачей: %w", err)
+	}
+
+	response := make([]models.ExternalDoctorResponse, 0, len(doctors))
+	for _, d := range doctors {
+		response = append(response, models.ExternalDoctorResponse{
+			ID:             d.ID,
+			FullName:       d.FullName,
+			Specialization: d.Specialization,
+		})
+	}
+	return response, nil
+}
+
+// GetFreeSlots возвращает свободные слоты в пределах горизонта записи.
+// Если даты не указаны, ищутся слоты с сегодняшнего дня до конца горизонта.
+func (s *BookingService) GetFreeSlots(doctorID *uint, specialization string, dateFrom, dateTo *time.Time) ([]models.ExternalSlotResponse, error) {
+	today := startOfDay(time.Now())
+	lastDay := s.lastBookableDay()
+
+	from := today
+	if dateFrom != nil && dateFrom.After(from) {
+		from = *dateFrom
+	}
+	to := lastDay
+	if dateTo != nil && dateTo.Before(to) {
+		to = *dateTo
+	}
+	if to.Before(from) {
+		return []models.ExternalSlotResponse{}, nil
+	}
+
+	schedules, err := s.scheduleRepo.FindFreeSlots(models.FreeSlotFilter{
+		DoctorID:       doctorID,
+		Specialization: strings.TrimSpace(specialization),
+		DateFrom:       from,
+		DateTo:         to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска свободных слотов: %w", err)
+	}
+
+	response := make([]models.ExternalSlotResponse, 0, len(schedules))
+	for _, sch := range schedules {
+		response = append(response, models.ExternalSlotResponse{
+			ScheduleID:     sch.ID,
+			DoctorID:       sch.DoctorID,
+			DoctorName:     sch.Doctor.FullName,
+			Specialization: sch.Doctor.Specialization,
+			Date:           sch.Date.Format("2006-01-02"),
+			StartTime:      sch.StartTime,
+			EndTime:        sch.EndTime,
+			Cabinet:        sch.Cabinet,
+			FreePlaces:     sch.FreePlaces(),
+		})
+	}
+	return response, nil
+}
+
+// GetPatientAppointments возвращает активные записи идентифицированного пациента и его ID для журнала доступа.
+func (s *BookingService) GetPatientAppointments(identity *models.ExternalPatientIdentity) ([]models.ExternalAppointmentResponse, uint, error) {
+	patient, err := s.identify(identity)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	appointments, err := s.appointmentRepo.FindActiveByPatientID(patient.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения записей пациента: %w", err)
+	}
+
+	response := make([]models.ExternalAppointmentResponse, 0, len(appointments))
+	for i := range appointments {
+		response = append(response, s.toExternalAppointment(&appointments[i]))
+	}
+	return response, patient.ID, nil
+}
+
+// Book записывает идентифицированного пациента в свободный слот и возвращает его ID для журнала доступа.
+func (s *BookingService) Book(req *models.ExternalBookingRequest) (*models.ExternalAppointmentResponse, uint, error) {
+	patient, err := s.identify(&req.ExternalPatientIdentity)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(req.ScheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, fmt.Errorf("слот с ID %d не найден", req.ScheduleID)
+		}
+		return nil, 0, fmt.Errorf("ошибка поиска слота: %w", err)
+	}
+	startsAt, err := schedule.StartsAt()
+	if err != nil {
+		return nil, 0, fmt.Errorf("некорректное время начала слота: %w", err)
+	}
+	if !startsAt.After(time.Now()) {
+		return nil, 0, fmt.Errorf("нельзя записаться на прошедшее время")
+	}
+	if startOfDay(startsAt).After(s.lastBookableDay()) {
+		return nil, 0, fmt.Errorf("запись доступна не более чем на %d дн. вперед", s.horizonDays)
+	}
+
+	// Лимит активных записей и правила записи проверяются в транзакции создания записи под блокировкой пациента.
+	patientID := patient.ID
+	check := func(tx *gorm.DB, lockedPatientID, scheduleID uint) error {
+		return s.rules.Check(tx, lockedPatientID, scheduleID, 0)
+	}
+	appointment, err := s.appointmentRepo.CreateAppointmentInTransaction(&models.CreateAppointmentRequest{
+		ScheduleID: req.ScheduleID,
+		PatientID:  &patientID,
+	}, s.maxActive, check)
+	if err != nil {
+		var rulesErr *BookingRulesError
+		if errors.As(err, &rulesErr) {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("не удалось создать запись на прием: %w", err)
+	}
+
+	s.log.WithField("appointment_id", appointment.ID).WithField("patient_id", patient.ID).Info("Пациент записался на прием через внешний API")
+	response := s.toExternalAppointment(appointment)
+	return &response, patient.ID, nil
+}
+
+// Cancel отменяет запись пациента, если до приема осталось не меньше времени отсечки.
+// Освободившийся слот предлагается листу ожидания. Возвращает ID пациента для журнала доступа.
+func (s *BookingService) Cancel(appointmentID uint, identity *models.ExternalPatientIdentity) (uint, error) {
+	patient, err := s.identify(identity)
+	if err != nil {
+		return 0, err
+	}
+
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil || appointment.PatientID == nil || *appointment.PatientID != patient.ID {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("запись с ID %d не найдена", appointmentID)
+		}
+		return 0, fmt.Errorf("ошибка поиска записи: %w", err)
+	}
+	if appointment.TicketID != nil {
+		return 0, fmt.Errorf("пациент уже пришел на прием, запись не может быть отменена")
+	}
+
+	startsAt, err := appointment.Schedule.StartsAt()
+	if err != nil {
+		return 0, fmt.Errorf("некорректное время начала слота: %w", err)
+	}
+	if time.Until(startsAt) < s.cancelCutoff {
+		return 0, fmt.Errorf("отмена записи возможна не позднее чем за %s до приема", s.cancelCutoff)
+	}
+
+	scheduleID, err := s.appointmentRepo.DeleteAppointmentAndFreeSlot(appointmentID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось отменить запись: %w", err)
+	}
+
+	s.log.WithField("appointment_id", appointmentID).WithField("patient_id", patient.ID).Info("Пациент отменил запись через внешний API")
+	s.waitlist.OfferSlot(scheduleID)
+	return patient.ID, nil
+}
+
+// AppointmentCalendar возвращает файл .ics для записи идентифицированного пациента и его ID для журнала доступа.
+func (s *BookingService) AppointmentCalendar(appointmentID uint, identity *models.ExternalPatientIdentity) ([]byte, uint, error) {
+	patient, err := s.identify(identity)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := s.calendar.AppointmentICS(appointmentID, &patient.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, patient.ID, nil
+}
+
+// identify находит пациента по номеру ОМС и дате рождения.
+func (s *BookingService) identify(identity *models.ExternalPatientIdentity) (*models.Patient, error) {
+	birthDate, err := time.Parse("2006-01-02", identity.BirthDate)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат даты рождения, используйте YYYY-MM-DD")
+	}
+
+	patient, err := s.patientRepo.FindByOMSAndBirthDate(identity.OmsNumber, birthDate)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("пациент с указанными данными не найден")
+		}
+		return nil, fmt.Errorf("ошибка идентификации пациента: %w", err)
+	}
+	return patient, nil
+}
+
+// lastBookableDay возвращает последний день, на который разрешена запись.
+func (s *BookingService) lastBookableDay() time.Time {
+	return startOfDay(time.Now()).AddDate(0, 0, s.horizonDays)
+}
+
+func (s *BookingService) toExternalAppointment(a *models.Appointment) models.ExternalAppointmentResponse {
+	return models.ExternalAppointmentResponse{
+		AppointmentID:  a.ID,
+		ScheduleID:     a.ScheduleID,
+		DoctorName:     a.Schedule.Doctor.FullName,
+		Specialization: a.Schedule.Doctor.Specialization,
+		Date:           a.Schedule.Date.Format("2006-01-02"),
+		StartTime:      a.Schedule.StartTime,
+		EndTime:        a.Schedule.EndTime,
+		Cabinet:        a.Schedule.Cabinet,
+		CheckInCode:    s.checkInCodes.Code(a.ID),
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
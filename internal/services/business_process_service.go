@@ -1,31 +1,40 @@
 package services
 
 import (
+	"ElectronicQueue/internal/cluster"
 	"ElectronicQueue/internal/logger"
 	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/pubsub"
 	"ElectronicQueue/internal/repository"
 	"fmt"
 	"sync"
 )
 
 // BusinessProcessService управляет состоянием бизнес-процессов.
-// Кэширует их в памяти для быстрой проверки в middleware.
+// Кэширует их в памяти для быстрой проверки в middleware; при изменении на одном экземпляре сервера
+// кэш остальных сбрасывается через cluster.Relay.
 type BusinessProcessService struct {
 	repo       repository.BusinessProcessRepository
+	relay      *cluster.Relay
 	log        *logger.AsyncLogger
 	states     map[string]bool
 	statesLock sync.RWMutex
 }
 
-func NewBusinessProcessService(repo repository.BusinessProcessRepository) (*BusinessProcessService, error) {
+// CacheBusinessProcesses — имя кэша состояний бизнес-процессов в cluster.Relay.
+const CacheBusinessProcesses = "business_processes"
+
+func NewBusinessProcessService(repo repository.BusinessProcessRepository, relay *cluster.Relay) (*BusinessProcessService, error) {
 	service := &BusinessProcessService{
 		repo:   repo,
+		relay:  relay,
 		log:    logger.Default().WithField("module", "BusinessProcess"),
 		states: make(map[string]bool),
 	}
 	if err := service.LoadProcesses(); err != nil {
 		return nil, fmt.Errorf("failed to load initial business processes state: %w", err)
 	}
+	relay.OnInvalidate(CacheBusinessProcesses, func() { _ = service.LoadProcesses() })
 	return service, nil
 }
 
@@ -78,5 +87,7 @@ func (s *BusinessProcessService) UpdateStatus(processName string, isEnabled bool
 	s.statesLock.Unlock()
 
 	s.log.WithField(processName, isEnabled).Info("Business process status updated")
+	s.relay.Invalidate(CacheBusinessProcesses)
+	s.relay.Publish(pubsub.ProcessChanged(processName, isEnabled))
 	return process, nil
 }
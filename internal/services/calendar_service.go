@@ -0,0 +1,233 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ElectronicQueue/internal/calendar"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/repository"
+	"ElectronicQueue/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// calendarFeedPastDays и calendarFeedFutureDays задают окно дат, попадающих в календарь врача.
+	calendarFeedPastDays   = 30
+	calendarFeedFutureDays = 90
+)
+
+// CalendarService формирует календари в формате iCalendar: персональный календарь врача
+// и файл .ics для отдельной записи на прием.
+type CalendarService struct {
+	repo            repository.CalendarRepository
+	appointmentRepo repository.AppointmentRepository
+	// publicNameFormat — вид ФИО пациента в календаре врача (utils.PublicName*): календари синхронизируются
+	// со сторонними облаками, а токен передается в URL, поэтому полное ФИО туда не попадает.
+	publicNameFormat string
+}
+
+// NewCalendarService создает новый экземпляр CalendarService.
+func NewCalendarService(repo repository.CalendarRepository, appointmentRepo repository.AppointmentRepository, publicNameFormat string) *CalendarService {
+	return &CalendarService{repo: repo, appointmentRepo: appointmentRepo, publicNameFormat: publicNameFormat}
+}
+
+// IssueDoctorToken выпускает врачу новый токен для подписки на календарь.
+// Старая ссылка при этом перестает работать.
+func (s *CalendarService) IssueDoctorToken(doctorID uint) (*models.CalendarFeedResponse, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("не удалось сгенерировать токен: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := s.repo.SetDoctorToken(doctorID, token); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("врач с ID %d не найден", doctorID)
+		}
+		return nil, fmt.Errorf("не удалось сохранить токен календаря: %w", err)
+	}
+
+	return &models.CalendarFeedResponse{
+		Token:   token,
+		FeedURL: "/api/calendar/doctor/" + token + ".ics",
+	}, nil
+}
+
+// DoctorFeedExport — календарь врача и сведения для журнала доступа к данным пациентов.
+type DoctorFeedExport struct {
+	Data       []byte
+	DoctorID   uint
+	PatientIDs []uint // пациенты, чьи ФИО попали в календарь
+}
+
+// DoctorFeed формирует календарь врача по его персональному токену.
+// Каждый слот — отдельное событие со стабильным UID; удаленные слоты передаются как отмененные.
+func (s *CalendarService) DoctorFeed(token string) (*DoctorFeedExport, error) {
+	token = strings.TrimSuffix(token, ".ics")
+	if token == "" {
+		return nil, fmt.Errorf("календарь не найден")
+	}
+
+	doctor, err := s.repo.FindDoctorByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("календарь не найден")
+		}
+		return nil, fmt.Errorf("ошибка поиска врача: %w", err)
+	}
+
+	today := startOfDay(time.Now())
+	from := today.AddDate(0, 0, -calendarFeedPastDays)
+	to := today.AddDate(0, 0, calendarFeedFutureDays)
+
+	slots, err := s.repo.FindDoctorSlots(doctor.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+	tombstones, err := s.repo.FindDoctorTombstones(doctor.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения отмененных слотов: %w", err)
+	}
+
+	export := &DoctorFeedExport{DoctorID: doctor.ID}
+	events := make([]calendar.Event, 0, len(slots)+len(tombstones))
+	for _, slot := range slots {
+		event, err := doctorSlotEvent(&slot, s.publicNameFormat)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+		if slot.Capacity <= 1 && slot.Appointment != nil && slot.Appointment.PatientID != nil {
+			export.PatientIDs = append(export.PatientIDs, *slot.Appointment.PatientID)
+		}
+	}
+	for _, t := range tombstones {
+		events = append(events, tombstoneEvent(&t, "Слот удален"))
+	}
+
+	export.Data = calendar.Render("Прием: "+doctor.FullName, calendar.MethodPublish, events)
+	return export, nil
+}
+
+// AppointmentICS формирует файл .ics для записи на прием. Если запись уже отменена,
+// возвращается отмена события с тем же UID. При переданном patientID проверяется,
+// что запись принадлежит этому пациенту.
+func (s *CalendarService) AppointmentICS(appointmentID uint, patientID *uint) ([]byte, error) {
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err == nil {
+		if patientID != nil && (appointment.PatientID == nil || *appointment.PatientID != *patientID) {
+			return nil, fmt.Errorf("запись с ID %d не найдена", appointmentID)
+		}
+		event, err := appointmentEvent(appointment)
+		if err != nil {
+			return nil, err
+		}
+		return calendar.Render("", calendar.MethodPublish, []calendar.Event{event}), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("ошибка поиска записи: %w", err)
+	}
+
+	tombstone, err := s.repo.FindAppointmentTombstone(appointmentID)
+	if err != nil || (patientID != nil && (tombstone.PatientID == nil || *tombstone.PatientID != *patientID)) {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("запись с ID %d не найдена", appointmentID)
+		}
+		return nil, fmt.Errorf("ошибка поиска отмененной записи: %w", err)
+	}
+	return calendar.Render("", calendar.MethodCancel, []calendar.Event{tombstoneEvent(tombstone, "Прием отменен")}), nil
+}
+
+func doctorSlotEvent(slot *models.ScheduleWithAppointmentInfo, nameFormat string) (calendar.Event, error) {
+	start, end, err := slotBounds(&slot.Schedule)
+	if err != nil {
+		return calendar.Event{}, err
+	}
+
+	event := calendar.Event{
+		UID:      calendar.ScheduleUID(slot.ID),
+		Sequence: slot.CalendarSequence,
+		Start:    start,
+		End:      end,
+		Location: cabinetLocation(slot.Cabinet),
+	}
+	switch {
+	case slot.Capacity > 1 && len(slot.Appointments) > 0:
+		event.Summary = fmt.Sprintf("Групповой прием: записано %d из %d", len(slot.Appointments), slot.Capacity)
+		event.Status = calendar.StatusConfirmed
+	case slot.Appointment != nil:
+		event.Summary = "Прием"
+		if name := utils.FormatPublicName(slot.Appointment.Patient.FullName, nameFormat); name != "" {
+			event.Summary += ": " + name
+		}
+		event.Status = calendar.StatusConfirmed
+	case slot.IsAvailable:
+		event.Summary = "Свободно"
+		event.Status = calendar.StatusTentative
+	default:
+		event.Summary = "Недоступно"
+		event.Status = calendar.StatusTentative
+	}
+	return event, nil
+}
+
+func appointmentEvent(a *models.Appointment) (calendar.Event, error) {
+	start, end, err := slotBounds(&a.Schedule)
+	if err != nil {
+		return calendar.Event{}, err
+	}
+
+	doctor := a.Schedule.Doctor
+	return calendar.Event{
+		UID:         calendar.AppointmentUID(a.ID),
+		Sequence:    a.CalendarSequence,
+		Start:       start,
+		End:         end,
+		Summary:     fmt.Sprintf("Прием: %s", doctor.Specialization),
+		Description: fmt.Sprintf("Врач: %s (%s). %s.", doctor.FullName, doctor.Specialization, cabinetLocation(a.Schedule.Cabinet)),
+		Location:    cabinetLocation(a.Schedule.Cabinet),
+		Status:      calendar.StatusConfirmed,
+	}, nil
+}
+
+func tombstoneEvent(t *models.CalendarTombstone, summary string) calendar.Event {
+	return calendar.Event{
+		UID:          calendar.TombstoneUID(t.UID),
+		Sequence:     t.Sequence,
+		Start:        asLocalWallClock(t.StartsAt),
+		End:          asLocalWallClock(t.EndsAt),
+		Summary:      summary,
+		Status:       calendar.StatusCancelled,
+		LastModified: t.CancelledAt,
+	}
+}
+
+func slotBounds(s *models.Schedule) (time.Time, time.Time, error) {
+	start, err := s.StartsAt()
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("некорректное время начала слота %d: %w", s.ID, err)
+	}
+	end, err := s.EndsAt()
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("некорректное время окончания слота %d: %w", s.ID, err)
+	}
+	return start, end, nil
+}
+
+func cabinetLocation(cabinet *int) string {
+	if cabinet == nil {
+		return "Кабинет не указан"
+	}
+	return fmt.Sprintf("Кабинет %d", *cabinet)
+}
+
+// asLocalWallClock трактует значение TIMESTAMP без часового пояса как локальное время сервера.
+func asLocalWallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+}
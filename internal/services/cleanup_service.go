@@ -43,11 +43,12 @@ func (s *CleanupService) CleanTickets() error {
 	}).Info("Найдено записей для очистки")
 
 	// Выполняем очистку
-	if err := s.repo.TruncateTickets(); err != nil {
+	archived, err := s.repo.TruncateTickets()
+	if err != nil {
 		s.log.WithError(err).Error("Ошибка очистки завершенных tickets и appointments")
 		return err
 	}
 
-	s.log.Info("Очистка завершенных tickets и осиротевших appointments завершена успешно")
+	s.log.WithField("archived_visits", archived).Info("Очистка завершенных tickets и осиротевших appointments завершена успешно")
 	return nil
 }
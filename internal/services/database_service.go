@@ -3,11 +3,34 @@ package services
 import (
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/repository"
+	"ElectronicQueue/internal/utils"
 	"fmt"
 	"reflect"
 	"strings"
 )
 
+// piiColumns — колонки с персональными данными, которые API базы данных не выдает в открытом виде,
+// не изменяет и по которым не фильтрует. Колонки без функции маскирования не выдаются вовсе.
+var piiColumns = map[string]map[string]func(string) string{
+	"patients": {
+		"passport_series":    nil,
+		"passport_number":    nil,
+		"oms_number":         nil,
+		"passport_encrypted": nil,
+		"passport_bidx":      nil,
+		"oms_encrypted":      nil,
+		"oms_bidx":           nil,
+		"phone":              utils.MaskPhone,
+		"email":              utils.MaskEmail,
+	},
+}
+
+// appendOnlyTables — журналы, которые API базы данных позволяет только читать.
+var appendOnlyTables = map[string]bool{
+	"patient_audit_log":  true,
+	"patient_access_log": true,
+}
+
 // DatabaseService предоставляет методы для работы с данными таблиц.
 type DatabaseService struct {
 	repo repository.DatabaseRepository
@@ -27,6 +50,9 @@ func (s *DatabaseService) GetData(tableName string, request models.GetDataReques
 	if err := s.validateFilters(request.Filters, allowedColumns); err != nil {
 		return nil, 0, err
 	}
+	if err := checkPIIFilters(tableName, request.Filters); err != nil {
+		return nil, 0, err
+	}
 
 	page := request.Page
 	if page <= 0 {
@@ -37,19 +63,42 @@ func (s *DatabaseService) GetData(tableName string, request models.GetDataReques
 		limit = 1000
 	}
 
-	return s.repo.GetData(tableName, page, limit, request.Filters)
+	rows, total, err := s.repo.GetData(tableName, page, limit, request.Filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	maskPIIRows(tableName, rows)
+	return rows, total, nil
 }
 
 func (s *DatabaseService) InsertData(tableName string, request models.InsertRequest) (int64, error) {
+	if err := checkAppendOnly(tableName); err != nil {
+		return 0, err
+	}
 	_, err := s.repo.GetTableColumns(tableName)
 	if err != nil {
 		return 0, fmt.Errorf("не удалось проверить таблицу '%s': %w", tableName, err)
 	}
+	// Вставка принимает один объект или массив объектов.
+	records, _ := request.Data.([]interface{})
+	if record, ok := request.Data.(map[string]interface{}); ok {
+		records = append(records, record)
+	}
+	for _, record := range records {
+		if fields, ok := record.(map[string]interface{}); ok {
+			if err := checkPIIData(tableName, fields); err != nil {
+				return 0, err
+			}
+		}
+	}
 
 	return s.repo.InsertData(tableName, request.Data)
 }
 
 func (s *DatabaseService) UpdateData(tableName string, request models.UpdateRequest) (int64, error) {
+	if err := checkAppendOnly(tableName); err != nil {
+		return 0, err
+	}
 	allowedColumns, err := s.repo.GetTableColumns(tableName)
 	if err != nil {
 		return 0, fmt.Errorf("не удалось проверить таблицу '%s': %w", tableName, err)
@@ -62,6 +111,12 @@ func (s *DatabaseService) UpdateData(tableName string, request models.UpdateRequ
 	if err := s.validateFilters(request.Filters, allowedColumns); err != nil {
 		return 0, err
 	}
+	if err := checkPIIFilters(tableName, request.Filters); err != nil {
+		return 0, err
+	}
+	if err := checkPIIData(tableName, request.Data); err != nil {
+		return 0, err
+	}
 
 	colsMap := make(map[string]bool)
 	for _, col := range allowedColumns {
@@ -77,6 +132,9 @@ func (s *DatabaseService) UpdateData(tableName string, request models.UpdateRequ
 }
 
 func (s *DatabaseService) DeleteData(tableName string, request models.DeleteRequest) (int64, error) {
+	if err := checkAppendOnly(tableName); err != nil {
+		return 0, err
+	}
 	allowedColumns, err := s.repo.GetTableColumns(tableName)
 	if err != nil {
 		return 0, fmt.Errorf("не удалось проверить таблицу '%s': %w", tableName, err)
@@ -89,6 +147,9 @@ func (s *DatabaseService) DeleteData(tableName string, request models.DeleteRequ
 	if err := s.validateFilters(request.Filters, allowedColumns); err != nil {
 		return 0, err
 	}
+	if err := checkPIIFilters(tableName, request.Filters); err != nil {
+		return 0, err
+	}
 
 	return s.repo.DeleteData(tableName, request.Filters)
 }
@@ -130,3 +191,70 @@ func (s *DatabaseService) validateFilters(filters models.Filters, allowedColumns
 	}
 	return nil
 }
+
+// maskPIIRows убирает из строк зашифрованные документы и слепые индексы и маскирует контакты.
+func maskPIIRows(tableName string, rows []map[string]interface{}) {
+	columns, ok := piiColumns[tableName]
+	if !ok {
+		return
+	}
+	for _, row := range rows {
+		for col, mask := range columns {
+			value, present := row[col]
+			if !present {
+				continue
+			}
+			if mask == nil {
+				delete(row, col)
+				continue
+			}
+			if str, isString := value.(string); isString {
+				row[col] = mask(str)
+			}
+		}
+	}
+}
+
+// PatientIDsFromRows возвращает ID пациентов из строк таблицы patients для журнала доступа.
+func PatientIDsFromRows(tableName string, rows []map[string]interface{}) []uint {
+	if tableName != "patients" {
+		return nil
+	}
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		switch id := row["patient_id"].(type) {
+		case int64:
+			ids = append(ids, uint(id))
+		case int32:
+			ids = append(ids, uint(id))
+		case int:
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+func checkAppendOnly(tableName string) error {
+	if appendOnlyTables[tableName] {
+		return fmt.Errorf("таблица '%s' доступна только для чтения", tableName)
+	}
+	return nil
+}
+
+func checkPIIFilters(tableName string, filters models.Filters) error {
+	for _, cond := range filters.Conditions {
+		if _, ok := piiColumns[tableName][cond.Field]; ok {
+			return fmt.Errorf("фильтрация по полю '%s' запрещена: поле содержит персональные данные", cond.Field)
+		}
+	}
+	return nil
+}
+
+func checkPIIData(tableName string, data map[string]interface{}) error {
+	for key := range data {
+		if _, ok := piiColumns[tableName][key]; ok {
+			return fmt.Errorf("поле '%s' содержит персональные данные и изменяется только через API регистратуры", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/pubsub"
+	"ElectronicQueue/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// displaySeenInterval — как часто обновляется время последнего обращения устройства.
+const displaySeenInterval = time.Minute
+
+// DisplayService ведет реестр устройств отображения: выпускает им токены, хранит их конфигурацию
+// и передает изменения подключенным устройствам через шину событий.
+type DisplayService struct {
+	repo   repository.DisplayRepository
+	adRepo repository.AdRepository
+	broker pubsub.Publisher
+}
+
+// NewDisplayService создает новый экземпляр DisplayService.
+func NewDisplayService(repo repository.DisplayRepository, adRepo repository.AdRepository, broker pubsub.Publisher) *DisplayService {
+	return &DisplayService{repo: repo, adRepo: adRepo, broker: broker}
+}
+
+// GetAll возвращает все зарегистрированные устройства.
+func (s *DisplayService) GetAll() ([]models.DisplayResponse, error) {
+	displays, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить список устройств: %w", err)
+	}
+	response := make([]models.DisplayResponse, 0, len(displays))
+	for i := range displays {
+		item, err := displays[i].ToResponse()
+		if err != nil {
+			return nil, fmt.Errorf("поврежденная конфигурация устройства %d: %w", displays[i].ID, err)
+		}
+		response = append(response, item)
+	}
+	return response, nil
+}
+
+// GetByID возвращает устройство с разобранной конфигурацией.
+func (s *DisplayService) GetByID(id uint) (*models.DisplayResponse, error) {
+	display, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	response, err := display.ToResponse()
+	if err != nil {
+		return nil, fmt.Errorf("поврежденная конфигурация устройства %d: %w", id, err)
+	}
+	return &response, nil
+}
+
+// Create регистрирует устройство и выпускает ему токен.
+func (s *DisplayService) Create(req *models.CreateDisplayRequest) (*models.DisplayTokenResponse, error) {
+	settings := models.DisplaySettings{
+		Windows:  req.Windows,
+		Cabinets: req.Cabinets,
+		Playlist: req.Playlist,
+		Audio:    defaultDisplayAudio(req.Role),
+		Theme:    models.DisplayTheme{Mode: "light"},
+	}
+	if req.Audio != nil {
+		settings.Audio = *req.Audio
+	}
+	if req.Theme != nil {
+		settings.Theme = *req.Theme
+	}
+
+	display := &models.Display{Name: strings.TrimSpace(req.Name), Role: req.Role}
+	if err := s.apply(display, settings); err != nil {
+		return nil, err
+	}
+	token, hash, err := newDisplayToken()
+	if err != nil {
+		return nil, err
+	}
+	display.TokenHash = hash
+
+	if err := s.repo.Create(display); err != nil {
+		return nil, fmt.Errorf("не удалось зарегистрировать устройство: %w", err)
+	}
+	response, err := display.ToResponse()
+	if err != nil {
+		return nil, err
+	}
+	logger.Default().WithField("display_id", display.ID).WithField("role", display.Role).Info("Display registered")
+	return &models.DisplayTokenResponse{Display: response, Token: token}, nil
+}
+
+// Update меняет назначение или конфигурацию устройства и сразу отправляет новую конфигурацию
+// подключенному устройству.
+func (s *DisplayService) Update(id uint, req *models.UpdateDisplayRequest) (*models.DisplayResponse, error) {
+	display, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := display.ToResponse()
+	if err != nil {
+		return nil, fmt.Errorf("поврежденная конфигурация устройства %d: %w", id, err)
+	}
+	settings := current.Settings
+
+	if req.Name != nil {
+		display.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Role != nil {
+		display.Role = *req.Role
+	}
+	if req.Windows != nil {
+		settings.Windows = *req.Windows
+	}
+	if req.Cabinets != nil {
+		settings.Cabinets = *req.Cabinets
+	}
+	if req.Playlist != nil {
+		settings.Playlist = *req.Playlist
+	}
+	if req.Audio != nil {
+		settings.Audio = *req.Audio
+	}
+	if req.Theme != nil {
+		settings.Theme = *req.Theme
+	}
+
+	if err := s.apply(display, settings); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(display); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить устройство: %w", err)
+	}
+	response, err := display.ToResponse()
+	if err != nil {
+		return nil, err
+	}
+	s.broker.Publish(pubsub.DisplayConfigChanged(display.ID, response))
+	logger.Default().WithField("display_id", display.ID).WithField("role", display.Role).Info("Display reconfigured")
+	return &response, nil
+}
+
+// Delete удаляет устройство из реестра. Подключенное устройство получает событие отзыва и отключается.
+func (s *DisplayService) Delete(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("устройство с ID %d не найдено", id)
+		}
+		return fmt.Errorf("не удалось удалить устройство: %w", err)
+	}
+	s.broker.Publish(pubsub.DisplayRevoked(id))
+	return nil
+}
+
+// RotateToken выпускает устройству новый токен. Прежний токен перестает действовать,
+// а подключенное с ним устройство отключается.
+func (s *DisplayService) RotateToken(id uint) (*models.DisplayTokenResponse, error) {
+	display, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	token, hash, err := newDisplayToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetTokenHash(id, hash); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить токен устройства: %w", err)
+	}
+	s.broker.Publish(pubsub.DisplayRevoked(id))
+
+	response, err := display.ToResponse()
+	if err != nil {
+		return nil, err
+	}
+	return &models.DisplayTokenResponse{Display: response, Token: token}, nil
+}
+
+// Authenticate находит устройство по токену и отмечает время обращения.
+func (s *DisplayService) Authenticate(token string) (*models.Display, error) {
+	if token == "" {
+		return nil, fmt.Errorf("токен устройства не указан")
+	}
+	display, err := s.repo.FindByTokenHash(hashDisplayToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("устройство с таким токеном не найдено")
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if display.LastSeenAt == nil || now.Sub(*display.LastSeenAt) >= displaySeenInterval {
+		if err := s.repo.Touch(display.ID, now); err != nil {
+			logger.Default().WithError(err).WithField("display_id", display.ID).Warn("Failed to update display last seen time")
+		}
+		display.LastSeenAt = &now
+	}
+	return display, nil
+}
+
+// Playlist возвращает рекламные материалы устройства в порядке показа. Если плейлист не задан,
+// табло регистратуры и расписания показывают все материалы, включенные для своего экрана.
+func (s *DisplayService) Playlist(display *models.Display) ([]models.Ad, error) {
+	config, err := display.ToResponse()
+	if err != nil {
+		return nil, fmt.Errorf("поврежденная конфигурация устройства %d: %w", display.ID, err)
+	}
+	if len(config.Settings.Playlist) == 0 {
+		switch display.Role {
+		case models.DisplayRoleReception:
+			return s.adRepo.GetEnabledFor("reception")
+		case models.DisplayRoleSchedule:
+			return s.adRepo.GetEnabledFor("schedule")
+		}
+		return []models.Ad{}, nil
+	}
+
+	ads := make([]models.Ad, 0, len(config.Settings.Playlist))
+	for _, adID := range config.Settings.Playlist {
+		ad, err := s.adRepo.GetByID(adID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue // материал удален после назначения плейлиста
+			}
+			return nil, err
+		}
+		if ad.IsEnabled {
+			ads = append(ads, *ad)
+		}
+	}
+	return ads, nil
+}
+
+func (s *DisplayService) find(id uint) (*models.Display, error) {
+	display, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("устройство с ID %d не найдено", id)
+		}
+		return nil, err
+	}
+	return display, nil
+}
+
+// apply проверяет назначение и конфигурацию устройства и сохраняет конфигурацию в модель.
+func (s *DisplayService) apply(display *models.Display, settings models.DisplaySettings) error {
+	if display.Name == "" {
+		return fmt.Errorf("некорректное название устройства: название не может быть пустым")
+	}
+	if !display.Role.IsValid() {
+		return fmt.Errorf("некорректное назначение устройства %q: допустимы reception, cabinet, schedule, kiosk", display.Role)
+	}
+	if display.Role == models.DisplayRoleCabinet && len(settings.Cabinets) == 0 {
+		return fmt.Errorf("некорректная конфигурация: экрану у кабинета нужно назначить хотя бы один кабинет")
+	}
+	for _, window := range settings.Windows {
+		if window < 1 {
+			return fmt.Errorf("некорректный номер окна %d", window)
+		}
+	}
+	for _, cabinet := range settings.Cabinets {
+		if cabinet < 1 {
+			return fmt.Errorf("некорректный номер кабинета %d", cabinet)
+		}
+	}
+	if settings.Audio.Volume < 0 || settings.Audio.Volume > 100 {
+		return fmt.Errorf("некорректная громкость %d: допустимо от 0 до 100", settings.Audio.Volume)
+	}
+	switch settings.Theme.Mode {
+	case "", "light", "dark":
+	default:
+		return fmt.Errorf("некорректная тема %q: допустимы light и dark", settings.Theme.Mode)
+	}
+	if settings.Theme.FontScale != 0 && (settings.Theme.FontScale < 0.5 || settings.Theme.FontScale > 3) {
+		return fmt.Errorf("некорректный масштаб шрифта %g: допустимо от 0.5 до 3", settings.Theme.FontScale)
+	}
+	for _, adID := range settings.Playlist {
+		if _, err := s.adRepo.GetByID(adID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("рекламный материал с ID %d не найден", adID)
+			}
+			return err
+		}
+	}
+
+	if settings.Windows == nil {
+		settings.Windows = []int{}
+	}
+	if settings.Cabinets == nil {
+		settings.Cabinets = []int{}
+	}
+	if settings.Playlist == nil {
+		settings.Playlist = []uint{}
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить конфигурацию устройства: %w", err)
+	}
+	display.Settings = data
+	return nil
+}
+
+// defaultDisplayAudio — настройки звука нового устройства: оповещения включены там, где пациента вызывают.
+func defaultDisplayAudio(role models.DisplayRole) models.DisplayAudio {
+	enabled := role == models.DisplayRoleReception || role == models.DisplayRoleCabinet
+	return models.DisplayAudio{Enabled: enabled, Volume: 80}
+}
+
+// newDisplayToken выпускает токен устройства и возвращает его вместе с хешем для хранения.
+func newDisplayToken() (token, hash string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("не удалось сгенерировать токен: %w", err)
+	}
+	token = hex.EncodeToString(buf)
+	return token, hashDisplayToken(token), nil
+}
+
+func hashDisplayToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
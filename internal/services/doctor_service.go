@@ -5,6 +5,7 @@ import (
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/pubsub"
 	"ElectronicQueue/internal/repository"
+	"ElectronicQueue/internal/utils"
 	"errors"
 	"fmt"
 	"time"
@@ -17,16 +18,36 @@ type DoctorService struct {
 	ticketRepo   repository.TicketRepository
 	doctorRepo   repository.DoctorRepository
 	scheduleRepo repository.ScheduleRepository
-	broker       *pubsub.Broker
+	broker       pubsub.Publisher
+	// publicNameFormat — вид ФИО пациента на общедоступных табло (utils.PublicName*).
+	publicNameFormat string
 }
 
 // NewDoctorService создает новый экземпляр DoctorService.
-func NewDoctorService(ticketRepo repository.TicketRepository, doctorRepo repository.DoctorRepository, scheduleRepo repository.ScheduleRepository, broker *pubsub.Broker) *DoctorService {
+func NewDoctorService(ticketRepo repository.TicketRepository, doctorRepo repository.DoctorRepository, scheduleRepo repository.ScheduleRepository, broker pubsub.Publisher, publicNameFormat string) *DoctorService {
 	return &DoctorService{
-		ticketRepo:   ticketRepo,
-		doctorRepo:   doctorRepo,
-		scheduleRepo: scheduleRepo,
-		broker:       broker,
+		ticketRepo:       ticketRepo,
+		doctorRepo:       doctorRepo,
+		scheduleRepo:     scheduleRepo,
+		broker:           broker,
+		publicNameFormat: publicNameFormat,
+	}
+}
+
+// publishStatus сообщает табло кабинетов, где врач принимает сегодня, о смене его статуса.
+func (s *DoctorService) publishStatus(doctorID uint, status models.DoctorStatus) {
+	schedules, err := s.scheduleRepo.FindByDoctorAndDate(doctorID, time.Now())
+	if err != nil {
+		logger.Default().WithError(err).WithField("doctor_id", doctorID).Warn("Не удалось определить кабинеты врача для уведомления табло")
+		return
+	}
+	seen := make(map[int]bool)
+	for _, schedule := range schedules {
+		if schedule.Cabinet == nil || seen[*schedule.Cabinet] {
+			continue
+		}
+		seen[*schedule.Cabinet] = true
+		s.broker.Publish(pubsub.DoctorStatusChanged(doctorID, status, schedule.Cabinet))
 	}
 }
 
@@ -151,7 +172,7 @@ func (s *DoctorService) GetAllDoctorQueuesState() ([]models.DoctorQueueTicketRes
 		logger.Default().WithError(err).Error("Ошибка получения очередей ко всем кабинетам")
 		return nil, err
 	}
-	return queue, nil
+	return s.maskQueueNames(queue), nil
 }
 
 // GetDoctorScreenState находит расписание врача и полную очередь к его кабинету.
@@ -172,13 +193,25 @@ func (s *DoctorService) GetDoctorScreenState(cabinetNumber int) (*models.Schedul
 			// В случае ошибки получения очереди, возвращаем пустую очередь, но с данными о враче.
 			return schedule, []models.DoctorQueueTicketResponse{}, nil
 		}
-		return schedule, queue, nil
+		return schedule, s.maskQueueNames(queue), nil
 	}
 
 	// Если расписание не найдено (gorm.ErrRecordNotFound), возвращаем nil и пустую очередь.
 	return nil, []models.DoctorQueueTicketResponse{}, nil
 }
 
+// maskQueueNames оставляет в очереди для табло только допустимую для показа часть ФИО пациента.
+func (s *DoctorService) maskQueueNames(queue []models.DoctorQueueTicketResponse) []models.DoctorQueueTicketResponse {
+	for i := range queue {
+		if queue[i].PatientFullName == "" {
+			queue[i].PatientFullName = "Пациент по талону"
+			continue
+		}
+		queue[i].PatientFullName = utils.FormatPublicName(queue[i].PatientFullName, s.publicNameFormat)
+	}
+	return queue
+}
+
 // GetAllUniqueCabinets возвращает список всех уникальных кабинетов.
 func (s *DoctorService) GetAllUniqueCabinets() ([]int, error) {
 	cabinets, err := s.scheduleRepo.GetAllUniqueCabinets()
@@ -208,7 +241,7 @@ func (s *DoctorService) StartBreak(doctorID uint) error {
 		return fmt.Errorf("не удалось обновить статус врача: %w", err)
 	}
 
-	s.broker.Publish("doctor_status_update")
+	s.publishStatus(doctorID, models.DoctorStatusOnBreak)
 	log.Info("Перерыв начат успешно")
 	return nil
 }
@@ -233,7 +266,7 @@ func (s *DoctorService) EndBreak(doctorID uint) error {
 		return fmt.Errorf("не удалось обновить статус врача: %w", err)
 	}
 
-	s.broker.Publish("doctor_status_update")
+	s.publishStatus(doctorID, models.DoctorStatusActive)
 	log.Info("Перерыв завершен успешно")
 	return nil
 }
@@ -247,7 +280,7 @@ func (s *DoctorService) SetDoctorActive(doctorID uint) error {
 		return fmt.Errorf("не удалось установить статус активен: %w", err)
 	}
 
-	s.broker.Publish("doctor_status_update")
+	s.publishStatus(doctorID, models.DoctorStatusActive)
 	log.Info("Статус врача установлен как активен")
 	return nil
 }
@@ -261,7 +294,7 @@ func (s *DoctorService) SetDoctorInactive(doctorID uint) error {
 		return fmt.Errorf("не удалось установить статус неактивен: %w", err)
 	}
 
-	s.broker.Publish("doctor_status_update")
+	s.publishStatus(doctorID, models.DoctorStatusInactive)
 	log.Info("Статус врача установлен как неактивен")
 	return nil
 }
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/notification"
+	"ElectronicQueue/internal/repository"
+)
+
+const (
+	// notificationBatchSize ограничивает число уведомлений, отправляемых за один проход диспетчера.
+	notificationBatchSize = 50
+	// notificationRetryBase и notificationRetryMax задают экспоненциальную задержку между попытками.
+	notificationRetryBase = 30 * time.Second
+	notificationRetryMax  = time.Hour
+	// notificationSendTimeout ограничивает время одной попытки отправки.
+	notificationSendTimeout = 15 * time.Second
+	// notificationClaimLease — сколько забранное уведомление недоступно другим экземплярам.
+	// Хватает на отправку всего пакета; по истечении срока неотправленное уведомление снова попадает в очередь.
+	notificationClaimLease = notificationBatchSize * notificationSendTimeout
+)
+
+// NotificationService отправляет уведомления пациентам из очереди (outbox) через выбранный канал
+// и ставит в очередь напоминания о предстоящих приемах.
+type NotificationService struct {
+	repo         repository.NotificationRepository
+	channel      notification.Channel
+	reminderLead time.Duration
+	pollInterval time.Duration
+	maxAttempts  int
+	log          *logger.AsyncLogger
+}
+
+// NewNotificationService создает новый экземпляр NotificationService.
+// reminderLead определяет, за сколько до начала приема отправляется напоминание.
+func NewNotificationService(
+	repo repository.NotificationRepository,
+	channel notification.Channel,
+	reminderLead string,
+	pollInterval string,
+	maxAttempts string,
+) (*NotificationService, error) {
+	lead, err := time.ParseDuration(reminderLead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification reminder lead: %w", err)
+	}
+	if lead <= 0 {
+		return nil, fmt.Errorf("notification reminder lead must be positive")
+	}
+
+	interval, err := time.ParseDuration(pollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification poll interval: %w", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("notification poll interval must be positive")
+	}
+
+	attempts, err := strconv.Atoi(maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification max attempts: %w", err)
+	}
+	if attempts <= 0 {
+		return nil, fmt.Errorf("notification max attempts must be positive")
+	}
+
+	return &NotificationService{
+		repo:         repo,
+		channel:      channel,
+		reminderLead: lead,
+		pollInterval: interval,
+		maxAttempts:  attempts,
+		log:          logger.Default().WithField("module", "notification"),
+	}, nil
+}
+
+// Start запускает диспетчер: периодически ставит в очередь напоминания и отправляет готовые уведомления.
+func (s *NotificationService) Start(ctx context.Context) {
+	s.log.WithField("channel", s.channel.Name()).Info("Диспетчер уведомлений запущен")
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.scheduleReminders()
+			s.dispatchDue(ctx)
+		case <-ctx.Done():
+			s.log.Info("Диспетчер уведомлений остановлен")
+			return
+		}
+	}
+}
+
+func (s *NotificationService) scheduleReminders() {
+	appointments, err := s.repo.FindReminderCandidates(s.reminderLead)
+	if err != nil {
+		s.log.WithError(err).Error("Ошибка поиска записей для напоминаний")
+		return
+	}
+
+	for i := range appointments {
+		if err := s.repo.EnqueueReminder(&appointments[i]); err != nil {
+			s.log.WithError(err).WithField("appointment_id", appointments[i].ID).Error("Не удалось поставить напоминание в очередь")
+		}
+	}
+}
+
+func (s *NotificationService) dispatchDue(ctx context.Context) {
+	notifications, err := s.repo.ClaimDue(time.Now(), notificationClaimLease, notificationBatchSize)
+	if err != nil {
+		s.log.WithError(err).Error("Ошибка получения уведомлений для отправки")
+		return
+	}
+
+	for i := range notifications {
+		if ctx.Err() != nil {
+			return
+		}
+		s.dispatch(ctx, &notifications[i])
+	}
+}
+
+func (s *NotificationService) dispatch(ctx context.Context, n *models.NotificationOutbox) {
+	log := s.log.WithField("notification_id", n.ID).WithField("event", n.Event)
+
+	if n.Patient.NotificationsOptOut {
+		if err := s.repo.MarkCancelled(n.ID, "пациент отказался от уведомлений"); err != nil {
+			log.WithError(err).Error("Не удалось отменить уведомление")
+		}
+		return
+	}
+
+	recipient := notification.Recipient(s.channel, n.Patient.Phone, n.Patient.Email)
+	if recipient == "" {
+		if err := s.repo.MarkFailed(n.ID, n.Attempts, "у пациента не указан контакт для канала "+s.channel.Name()); err != nil {
+			log.WithError(err).Error("Не удалось обновить статус уведомления")
+		}
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	err := s.channel.Send(sendCtx, notification.Message{Recipient: recipient, Subject: n.Subject, Body: n.Body})
+	cancel()
+
+	if err == nil {
+		if err := s.repo.MarkSent(n.ID, time.Now()); err != nil {
+			log.WithError(err).Error("Не удалось отметить уведомление как отправленное")
+		}
+		return
+	}
+
+	attempts := n.Attempts + 1
+	if attempts >= s.maxAttempts {
+		log.WithError(err).WithField("attempts", attempts).Error("Уведомление не отправлено, попытки исчерпаны")
+		if err := s.repo.MarkFailed(n.ID, attempts, err.Error()); err != nil {
+			log.WithError(err).Error("Не удалось обновить статус уведомления")
+		}
+		return
+	}
+
+	next := time.Now().Add(notificationBackoff(attempts))
+	log.WithError(err).WithField("attempts", attempts).WithField("next_attempt_at", next).Warn("Ошибка отправки уведомления, попытка будет повторена")
+	if err := s.repo.MarkRetry(n.ID, attempts, next, err.Error()); err != nil {
+		log.WithError(err).Error("Не удалось запланировать повторную отправку уведомления")
+	}
+}
+
+// notificationBackoff возвращает задержку перед следующей попыткой: 30s, 1m, 2m, ... но не более часа.
+func notificationBackoff(attempts int) time.Duration {
+	delay := notificationRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= notificationRetryMax {
+			return notificationRetryMax
+		}
+	}
+	return delay
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/repository"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultPatientAccessPageSize = 50
+	maxPatientAccessPageSize     = 500
+)
+
+// PatientAccessService ведет журнал чтения персональных данных пациентов и строит по нему отчеты.
+type PatientAccessService struct {
+	repo repository.PatientAccessRepository
+	// anomalyThreshold — сколько разных пациентов за час пользователь может просмотреть без попадания в отчет.
+	anomalyThreshold int
+}
+
+func NewPatientAccessService(repo repository.PatientAccessRepository, anomalyThreshold int) *PatientAccessService {
+	return &PatientAccessService{repo: repo, anomalyThreshold: anomalyThreshold}
+}
+
+// Record записывает в журнал, что пользователь получил данные пациентов через эндпоинт.
+// Повторяющиеся ID пациентов записываются один раз.
+func (s *PatientAccessService) Record(actorRole string, actorID *uint, endpoint, clientIP string, patientIDs []uint) error {
+	seen := make(map[uint]bool, len(patientIDs))
+	entries := make([]models.PatientAccessLog, 0, len(patientIDs))
+	now := time.Now()
+	for _, id := range patientIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		entry := models.PatientAccessLog{
+			PatientID:  id,
+			ActorRole:  actorRole,
+			ActorID:    actorID,
+			Endpoint:   endpoint,
+			AccessedAt: now,
+		}
+		if clientIP != "" {
+			entry.ClientIP = &clientIP
+		}
+		entries = append(entries, entry)
+	}
+	if err := s.repo.CreateBatch(entries); err != nil {
+		return fmt.Errorf("ошибка записи в журнал доступа к данным пациентов: %w", err)
+	}
+	return nil
+}
+
+// Find возвращает страницу журнала доступа. page начинается с 1; pageSize ограничен maxPatientAccessPageSize.
+func (s *PatientAccessService) Find(filter *models.PatientAccessFilter, page, pageSize int) (*models.PatientAccessPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxPatientAccessPageSize {
+		pageSize = defaultPatientAccessPageSize
+	}
+	entries, total, err := s.repo.Find(filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала доступа к данным пациентов: %w", err)
+	}
+	if entries == nil {
+		entries = []models.PatientAccessLog{}
+	}
+	return &models.PatientAccessPage{Items: entries, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// GetAnomalies возвращает часы периода [from, to), за которые пользователи просмотрели данные больше threshold
+// разных пациентов. При threshold <= 0 используется порог из настроек.
+func (s *PatientAccessService) GetAnomalies(from, to time.Time, threshold int) (*models.PatientAccessAnomalyReport, error) {
+	if threshold <= 0 {
+		threshold = s.anomalyThreshold
+	}
+	anomalies, err := s.repo.FindAnomalies(from, to, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка построения отчета о подозрительном доступе: %w", err)
+	}
+	if anomalies == nil {
+		anomalies = []models.PatientAccessAnomaly{}
+	}
+	return &models.PatientAccessAnomalyReport{From: from, To: to, Threshold: threshold, Anomalies: anomalies}, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// retentionBatchSize — наибольшее число карточек, анонимизируемых за один запуск задания хранения.
+	retentionBatchSize = 500
+	// retentionActorName — имя, под которым задание хранения записывает анонимизацию в журнал.
+	retentionActorName = "задание хранения данных"
+)
+
+// AnonymizePatient анонимизирует карточку пациента по решению администратора, например по заявлению
+// об удалении персональных данных. Кто и почему это сделал, сохраняется в журнале изменений карточки.
+func (s *PatientService) AnonymizePatient(patientID uint, req *models.AnonymizePatientRequest) (*models.PatientAnonymizationResult, error) {
+	performedBy := strings.TrimSpace(req.PerformedBy)
+	reason := strings.TrimSpace(req.Reason)
+	if performedBy == "" || reason == "" {
+		return nil, errors.New("не указано, кто и почему анонимизирует карточку")
+	}
+	return s.anonymize(patientID, &models.PatientAuditLog{
+		ActorRole: "admin",
+		ActorName: &performedBy,
+		Reason:    &reason,
+	})
+}
+
+// RetentionYears возвращает срок хранения данных пациентов без визитов в годах; 0 — задание хранения отключено.
+func (s *PatientService) RetentionYears() int {
+	return s.retentionYears
+}
+
+// RunRetention анонимизирует карточки пациентов, у которых не было визитов дольше срока хранения.
+// За один запуск обрабатывается не больше retentionBatchSize карточек, самые давние первыми; ошибка
+// по одной карточке не останавливает остальные и попадает в отчет. При dryRun карточки только находятся.
+func (s *PatientService) RunRetention(actorRole, performedBy string, dryRun bool) (*models.RetentionReport, error) {
+	if s.retentionYears <= 0 {
+		return nil, errors.New("срок хранения данных пациентов не задан (PATIENT_RETENTION_YEARS)")
+	}
+	now := time.Now()
+	report := &models.RetentionReport{
+		StartedAt:      now,
+		RetentionYears: s.retentionYears,
+		Cutoff:         startOfDay(now).AddDate(-s.retentionYears, 0, 0),
+		DryRun:         dryRun,
+		Anonymized:     []uint{},
+		Failed:         []models.RetentionFailure{},
+	}
+
+	candidates, err := s.repo.FindRetentionCandidates(report.Cutoff, retentionBatchSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска карточек с истекшим сроком хранения: %w", err)
+	}
+	if len(candidates) > retentionBatchSize {
+		candidates = candidates[:retentionBatchSize]
+		report.HasMore = true
+	}
+	report.Candidates = candidates
+
+	if !dryRun {
+		for _, candidate := range candidates {
+			reason := fmt.Sprintf("Истек срок хранения данных: нет визитов с %s (срок %d лет)",
+				candidate.LastActivity.Format("02.01.2006"), s.retentionYears)
+			actor := performedBy
+			_, err := s.anonymize(candidate.PatientID, &models.PatientAuditLog{
+				ActorRole: actorRole,
+				ActorName: &actor,
+				Reason:    &reason,
+			})
+			if err != nil {
+				report.Failed = append(report.Failed, models.RetentionFailure{PatientID: candidate.PatientID, Error: err.Error()})
+				continue
+			}
+			report.Anonymized = append(report.Anonymized, candidate.PatientID)
+		}
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// GetAnonymizations возвращает журнал анонимизаций карточек за период.
+func (s *PatientService) GetAnonymizations(from, to time.Time) ([]models.PatientAuditLog, error) {
+	entries, err := s.auditRepo.FindByAction(models.PatientAuditAnonymize, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала анонимизаций: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *PatientService) anonymize(patientID uint, audit *models.PatientAuditLog) (*models.PatientAnonymizationResult, error) {
+	token, err := anonymizationToken()
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.repo.Anonymize(patientID, token, startOfDay(time.Now()), audit)
+	if err != nil {
+		if strings.Contains(err.Error(), "не найден") || strings.Contains(err.Error(), "анонимизирована") ||
+			strings.Contains(err.Error(), "предстоящие записи") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("не удалось анонимизировать карточку пациента: %w", err)
+	}
+	return result, nil
+}
+
+// anonymizationToken возвращает случайное обезличенное имя, которое заменяет ФИО пациента.
+func anonymizationToken() (string, error) {
+	raw := make([]byte, 6)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("ошибка генерации токена анонимизации: %w", err)
+	}
+	return "Аноним " + strings.ToUpper(hex.EncodeToString(raw)), nil
+}
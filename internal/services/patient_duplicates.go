@@ -0,0 +1,95 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"sort"
+	"strings"
+)
+
+// duplicateNameThreshold — минимальное сходство ФИО, при котором карточки с одной датой рождения считаются дубликатами.
+const duplicateNameThreshold = 0.8
+
+// duplicateTrigramThreshold — то же для поиска дубликатов по всей базе, где сходство считает pg_trgm.
+// Триграммная оценка строже расстояния Левенштейна: одна опечатка в ФИО дает около 0.6–0.7.
+const duplicateTrigramThreshold = 0.6
+
+// duplicateReasons возвращает признаки, по которым карточки похожи, и сходство их ФИО.
+// Пустой список означает, что карточки не считаются дубликатами.
+func duplicateReasons(a, b *models.Patient) ([]models.DuplicateReason, float64) {
+	similarity := nameSimilarity(a.FullName, b.FullName)
+
+	var reasons []models.DuplicateReason
+	if a.OmsNumber != "" && a.OmsNumber == b.OmsNumber {
+		reasons = append(reasons, models.DuplicateSameOMS)
+	}
+	if a.Phone != "" && a.Phone == b.Phone {
+		reasons = append(reasons, models.DuplicateSamePhone)
+	}
+	if !a.BirthDate.IsZero() && sameDate(a.BirthDate, b.BirthDate) && similarity >= duplicateNameThreshold {
+		reasons = append(reasons, models.DuplicateNameBirthDate)
+	}
+	return reasons, similarity
+}
+
+// nameSimilarity оценивает сходство ФИО от 0 до 1 по расстоянию Левенштейна без учета регистра и е/ё.
+// Сокращенная запись («Иванов И. И.») считается совпадающей с полной, если совпадают фамилия и инициалы.
+func nameSimilarity(a, b string) float64 {
+	partsA := strings.Fields(normalizeName(strings.ReplaceAll(a, ".", " ")))
+	partsB := strings.Fields(normalizeName(strings.ReplaceAll(b, ".", " ")))
+	if len(partsA) == 0 || len(partsB) == 0 {
+		return 0
+	}
+
+	runesA, runesB := []rune(strings.Join(partsA, " ")), []rune(strings.Join(partsB, " "))
+	maxLen := len(runesA)
+	if len(runesB) > maxLen {
+		maxLen = len(runesB)
+	}
+	similarity := 1 - float64(levenshtein(runesA, runesB))/float64(maxLen)
+
+	if len(partsA) == len(partsB) && partsA[0] == partsB[0] && sameInitials(partsA[1:], partsB[1:]) {
+		if similarity < 0.9 {
+			similarity = 0.9
+		}
+	}
+	return similarity
+}
+
+func sameInitials(a, b []string) bool {
+	for i := range a {
+		if []rune(a[i])[0] != []rune(b[i])[0] {
+			return false
+		}
+	}
+	return true
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// sortDuplicates упорядочивает кандидатов: больше совпавших признаков, затем выше сходство ФИО.
+func sortDuplicates(candidates []models.DuplicateCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i].Reasons) != len(candidates[j].Reasons) {
+			return len(candidates[i].Reasons) > len(candidates[j].Reasons)
+		}
+		return candidates[i].NameSimilarity > candidates[j].NameSimilarity
+	})
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// parsePatientSearchQuery разбирает строку поиска регистратора.
+// Слова из букв — ФИО: первое считается фамилией, однобуквенные и слова с точкой после первого — инициалами
+// («Иванов И. П.», «Иванов ИП»). Четырехзначное число рядом с ФИО — год рождения, дата вида 02.01.2006 — дата рождения.
+// Остальные цифры склеиваются: так находятся полис ОМС, паспорт («4510 123456») и телефон («+7 912 345-67-89»).
+// Возвращает nil, если в запросе нечего искать.
+func parsePatientSearchQuery(raw string, now time.Time) *models.PatientSearchQuery {
+	query := &models.PatientSearchQuery{}
+	var numbers []string
+
+	for _, token := range strings.FieldsFunc(raw, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+		if date, err := time.ParseInLocation("02.01.2006", token, time.Local); err == nil {
+			query.BirthDate = &date
+			continue
+		}
+		if letters := nameToken(token); letters != "" {
+			addNameToken(query, token, letters)
+			continue
+		}
+		if digits := digitsOnly(token); digits != "" {
+			numbers = append(numbers, digits)
+		}
+	}
+
+	for _, n := range numbers {
+		if year, err := strconv.Atoi(n); err == nil && len(n) == 4 && len(query.NameWords) > 0 &&
+			query.BirthYear == nil && year >= 1900 && year <= now.Year() {
+			query.BirthYear = &year
+			continue
+		}
+		query.Digits += n
+	}
+	// Номер с кодом страны ищется по последним 10 цифрам, чтобы +7 и 8 находили один и тот же телефон.
+	if len(query.Digits) == 11 && (query.Digits[0] == '7' || query.Digits[0] == '8') {
+		query.Digits = query.Digits[1:]
+	}
+
+	if len(query.NameWords) == 0 && query.BirthDate == nil && len([]rune(query.Digits)) < 2 {
+		return nil
+	}
+	return query
+}
+
+// addNameToken относит слово к фамилии, слову ФИО или инициалам.
+func addNameToken(query *models.PatientSearchQuery, token, letters string) {
+	runes := []rune(letters)
+	if len(query.NameWords) == 0 {
+		query.NameWords = append(query.NameWords, letters)
+		return
+	}
+	switch {
+	case len(runes) == 1:
+		query.Initials = append(query.Initials, letters)
+	case strings.Contains(token, ".") || (len(runes) == 2 && strings.ToUpper(token) == token):
+		// «И.П.», «И.» или «ИП» — инициалы имени и отчества.
+		for _, r := range runes {
+			query.Initials = append(query.Initials, string(r))
+		}
+	default:
+		query.NameWords = append(query.NameWords, letters)
+	}
+}
+
+// nameToken возвращает буквенную часть слова в нижнем регистре с «е» вместо «ё» или пустую строку,
+// если в слове нет букв. Дефис (двойная фамилия) сохраняется.
+func nameToken(token string) string {
+	var b strings.Builder
+	hasLetters := false
+	for _, r := range strings.ToLower(token) {
+		switch {
+		case r == 'ё':
+			b.WriteRune('е')
+			hasLetters = true
+		case unicode.IsLetter(r):
+			b.WriteRune(r)
+			hasLetters = true
+		case r == '-' && b.Len() > 0:
+			b.WriteRune(r)
+		}
+	}
+	if !hasLetters {
+		return ""
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+func digitsOnly(token string) string {
+	var b strings.Builder
+	for _, r := range token {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
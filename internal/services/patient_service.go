@@ -3,25 +3,52 @@ package services
 import (
 	"ElectronicQueue/internal/models"
 	"ElectronicQueue/internal/repository"
+	"ElectronicQueue/internal/utils"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPatientSearchPageSize = 20
+	maxPatientSearchPageSize     = 50
+	// piiReencryptBatchSize — число карточек, перешифровываемых в одной транзакции.
+	piiReencryptBatchSize = 200
 )
 
 type PatientService struct {
-	repo repository.PatientRepository
+	repo      repository.PatientRepository
+	auditRepo repository.PatientAuditRepository
+	visits    repository.VisitRepository
+	referrals *ReferralService
+	// retentionYears — через сколько лет без визитов карточка анонимизируется; 0 — не анонимизируется.
+	retentionYears int
 }
 
-func NewPatientService(repo repository.PatientRepository) *PatientService {
-	return &PatientService{repo: repo}
+func NewPatientService(repo repository.PatientRepository, auditRepo repository.PatientAuditRepository, visits repository.VisitRepository, referrals *ReferralService, retentionYears int) *PatientService {
+	return &PatientService{repo: repo, auditRepo: auditRepo, visits: visits, referrals: referrals, retentionYears: retentionYears}
 }
 
 func (s *PatientService) CreatePatient(req *models.CreatePatientRequest) (*models.Patient, error) {
 	patient := &models.Patient{
 		PassportSeries: req.PassportSeries,
 		PassportNumber: req.PassportNumber,
-		FullName:       req.FullName,
+		FullName:       strings.TrimSpace(req.FullName),
 		BirthDate:      req.BirthDate,
-		Phone:          req.Phone,
+		Phone:          strings.TrimSpace(req.Phone),
 		OmsNumber:      req.OmsNumber,
+		Email:          strings.TrimSpace(req.Email),
+	}
+	if err := validatePatient(patient); err != nil {
+		return nil, err
+	}
+	if err := s.ensurePassportFree(patient); err != nil {
+		return nil, err
 	}
 	createdPatient, err := s.repo.Create(patient)
 	if err != nil {
@@ -30,14 +57,326 @@ func (s *PatientService) CreatePatient(req *models.CreatePatientRequest) (*model
 	return createdPatient, nil
 }
 
-// SearchPatients вызывает универсальный поиск.
-func (s *PatientService) SearchPatients(query string) ([]models.Patient, error) {
-	if len(query) < 2 {
-		return []models.Patient{}, nil
+// SearchPatients выполняет нечеткий поиск пациентов и добавляет к найденным открытые направления.
+// page начинается с 1; pageSize ограничен maxPatientSearchPageSize.
+func (s *PatientService) SearchPatients(query string, page, pageSize int) (*models.PatientSearchPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxPatientSearchPageSize {
+		pageSize = defaultPatientSearchPageSize
 	}
-	patients, err := s.repo.Search(query)
+	result := &models.PatientSearchPage{Items: []models.PatientSearchResult{}, Page: page, PageSize: pageSize}
+
+	parsed := parsePatientSearchQuery(query, time.Now())
+	if parsed == nil {
+		return result, nil
+	}
+	hits, total, err := s.repo.Search(parsed, (page-1)*pageSize, pageSize)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка поиска пациентов в репозитории: %w", err)
 	}
-	return patients, nil
+	result.Total = total
+
+	patients := make([]models.Patient, 0, len(hits))
+	for _, h := range hits {
+		patients = append(patients, h.Patient)
+	}
+	items, err := s.referrals.AttachOpenReferrals(patients)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Relevance = hits[i].Relevance
+	}
+	result.Items = items
+	return result, nil
+}
+
+// SetNotificationsOptOut включает или отключает уведомления пациенту.
+func (s *PatientService) SetNotificationsOptOut(patientID uint, optOut bool) (*models.Patient, error) {
+	patient, err := s.repo.SetNotificationsOptOut(patientID, optOut)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("пациент с ID %d не найден", patientID)
+		}
+		return nil, fmt.Errorf("не удалось изменить настройки уведомлений: %w", err)
+	}
+	return patient, nil
+}
+
+// UpdatePatient изменяет указанные поля карточки пациента после проверки и записывает изменения в журнал.
+func (s *PatientService) UpdatePatient(patientID, registrarID uint, req *models.UpdatePatientRequest) (*models.Patient, error) {
+	patient, err := s.repo.GetByID(patientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("пациент с ID %d не найден", patientID)
+		}
+		return nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+	}
+	if patient.AnonymizedAt != nil {
+		return nil, fmt.Errorf("карточка пациента %d анонимизирована и не может быть изменена", patientID)
+	}
+
+	// Телефон нормализуется до сравнения, чтобы другой формат записи того же номера не считался изменением.
+	phone, err := utils.NormalizePhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	req.Phone = phone
+
+	changes := make(map[string]models.PatientFieldChange)
+	setString := func(field string, target *string, value string) {
+		value = strings.TrimSpace(value)
+		if value != "" && value != *target {
+			changes[field] = models.PatientFieldChange{Old: *target, New: value}
+			*target = value
+		}
+	}
+	setString("passport_series", &patient.PassportSeries, req.PassportSeries)
+	setString("passport_number", &patient.PassportNumber, req.PassportNumber)
+	setString("full_name", &patient.FullName, req.FullName)
+	setString("phone", &patient.Phone, req.Phone)
+	if _, ok := changes["phone"]; ok {
+		patient.PhoneInvalid = false
+	}
+	setString("oms_number", &patient.OmsNumber, req.OmsNumber)
+	setString("email", &patient.Email, req.Email)
+	if req.BirthDate != nil && !sameDate(*req.BirthDate, patient.BirthDate) {
+		changes["birth_date"] = models.PatientFieldChange{
+			Old: patient.BirthDate.Format("2006-01-02"),
+			New: req.BirthDate.Format("2006-01-02"),
+		}
+		patient.BirthDate = *req.BirthDate
+	}
+	if len(changes) == 0 {
+		return patient, nil
+	}
+
+	// Проверяются только измененные поля: карточки, созданные до ужесточения проверок,
+	// можно править, не исправляя сразу все остальные данные.
+	if err := validatePatientChanges(patient, changes); err != nil {
+		return nil, err
+	}
+	_, seriesChanged := changes["passport_series"]
+	_, numberChanged := changes["passport_number"]
+	if seriesChanged || numberChanged {
+		if err := s.ensurePassportFree(patient); err != nil {
+			return nil, err
+		}
+	}
+
+	details, err := json.Marshal(maskDocumentChanges(changes))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования журнала изменений: %w", err)
+	}
+	audit := &models.PatientAuditLog{
+		Action:    models.PatientAuditUpdate,
+		ActorRole: "registrar",
+		ActorID:   &registrarID,
+		Details:   details,
+	}
+	if err := s.repo.Update(patient, audit); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить изменения пациента: %w", err)
+	}
+	return patient, nil
+}
+
+// GetHistory возвращает журнал изменений карточки пациента.
+func (s *PatientService) GetHistory(patientID uint) ([]models.PatientAuditLog, error) {
+	entries, err := s.auditRepo.FindByPatientID(patientID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала изменений пациента: %w", err)
+	}
+	return entries, nil
+}
+
+// FindDuplicates возвращает карточки, которые, вероятно, относятся к тому же пациенту:
+// с тем же полисом ОМС, тем же телефоном или похожим ФИО и той же датой рождения.
+func (s *PatientService) FindDuplicates(patientID uint) ([]models.DuplicateCandidate, error) {
+	patient, err := s.repo.GetByID(patientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("пациент с ID %d не найден", patientID)
+		}
+		return nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+	}
+	candidates, err := s.repo.FindDuplicateCandidates(patient)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска дубликатов: %w", err)
+	}
+
+	result := make([]models.DuplicateCandidate, 0)
+	for _, c := range candidates {
+		reasons, similarity := duplicateReasons(patient, &c)
+		if len(reasons) == 0 {
+			continue
+		}
+		result = append(result, models.DuplicateCandidate{Patient: c, Reasons: reasons, NameSimilarity: similarity})
+	}
+	sortDuplicates(result)
+	return result, nil
+}
+
+// FindAllDuplicates возвращает до limit пар возможных дубликатов по всей базе, самые вероятные первыми.
+// Пары отбираются, упорядочиваются и ограничиваются в SQL; сходство ФИО оценивается через pg_trgm.
+func (s *PatientService) FindAllDuplicates(limit int) ([]models.DuplicatePair, error) {
+	pairs, err := s.repo.FindDuplicatePairs(duplicateTrigramThreshold, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска дубликатов: %w", err)
+	}
+	if pairs == nil {
+		pairs = []models.DuplicatePair{}
+	}
+	return pairs, nil
+}
+
+// MergePatients объединяет карточку-дубликат с оставшейся карточкой: переносит записи на прием,
+// лист ожидания, направления и уведомления, удаляет дубликат и сохраняет снимок его данных в журнале.
+func (s *PatientService) MergePatients(req *models.MergePatientsRequest) (*models.PatientMergeResult, error) {
+	if req.SurvivingPatientID == req.DuplicatePatientID {
+		return nil, errors.New("нельзя объединить карточку пациента саму с собой")
+	}
+	performedBy := strings.TrimSpace(req.PerformedBy)
+	reason := strings.TrimSpace(req.Reason)
+	if performedBy == "" || reason == "" {
+		return nil, errors.New("не указано, кто и почему объединяет карточки")
+	}
+
+	audit := &models.PatientAuditLog{
+		ActorRole: "admin",
+		ActorName: &performedBy,
+		Reason:    &reason,
+	}
+	result, err := s.repo.Merge(req.SurvivingPatientID, req.DuplicatePatientID, audit)
+	if err != nil {
+		if strings.Contains(err.Error(), "не найден") || strings.Contains(err.Error(), "анонимизирована") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("не удалось объединить карточки пациентов: %w", err)
+	}
+	return result, nil
+}
+
+// RotatePIIKeys перешифровывает активным ключом паспорта и полисы ОМС, зашифрованные прежними ключами
+// или оставшиеся незашифрованными. Возвращает число обработанных карточек.
+func (s *PatientService) RotatePIIKeys() (int, error) {
+	count, err := s.repo.ReencryptPII(piiReencryptBatchSize)
+	if err != nil {
+		return count, fmt.Errorf("ошибка перешифрования персональных данных: %w", err)
+	}
+	return count, nil
+}
+
+// GetMerges возвращает журнал объединений карточек за период.
+func (s *PatientService) GetMerges(from, to time.Time) ([]models.PatientAuditLog, error) {
+	entries, err := s.auditRepo.FindByAction(models.PatientAuditMerge, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала объединений: %w", err)
+	}
+	return entries, nil
+}
+
+// ensurePassportFree проверяет, что паспорт не принадлежит другой карточке.
+func (s *PatientService) ensurePassportFree(patient *models.Patient) error {
+	existing, err := s.repo.FindByPassport(patient.PassportSeries, patient.PassportNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("ошибка проверки паспорта: %w", err)
+	}
+	if existing.ID != patient.ID {
+		return fmt.Errorf("пациент с таким паспортом уже существует (ID %d)", existing.ID)
+	}
+	return nil
+}
+
+// validatePatient проверяет поля карточки пациента и приводит телефон к каноническому виду.
+func validatePatient(p *models.Patient) error {
+	if err := validateFullName(p.FullName); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := utils.ValidatePassport(p.PassportSeries, p.PassportNumber, now); err != nil {
+		return err
+	}
+	if err := utils.ValidateOMS(p.OmsNumber); err != nil {
+		return err
+	}
+	if err := utils.ValidateBirthDate(p.BirthDate, now); err != nil {
+		return err
+	}
+	phone, err := utils.NormalizePhone(p.Phone)
+	if err != nil {
+		return err
+	}
+	p.Phone = phone
+	return nil
+}
+
+// validatePatientChanges проверяет только поля карточки, перечисленные в changes.
+// Серия и номер паспорта проверяются вместе, если изменено любое из них.
+// Телефон к этому моменту уже нормализован.
+func validatePatientChanges(p *models.Patient, changes map[string]models.PatientFieldChange) error {
+	now := time.Now()
+	if _, ok := changes["full_name"]; ok {
+		if err := validateFullName(p.FullName); err != nil {
+			return err
+		}
+	}
+	_, seriesChanged := changes["passport_series"]
+	_, numberChanged := changes["passport_number"]
+	if seriesChanged || numberChanged {
+		if err := utils.ValidatePassport(p.PassportSeries, p.PassportNumber, now); err != nil {
+			return err
+		}
+	}
+	if _, ok := changes["oms_number"]; ok {
+		if err := utils.ValidateOMS(p.OmsNumber); err != nil {
+			return err
+		}
+	}
+	if _, ok := changes["birth_date"]; ok {
+		if err := utils.ValidateBirthDate(p.BirthDate, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateFullName(name string) error {
+	if name == "" {
+		return errors.New("некорректное ФИО: поле не может быть пустым")
+	}
+	if len([]rune(name)) > 100 {
+		return errors.New("некорректное ФИО: не более 100 символов")
+	}
+	for _, ch := range name {
+		if !unicode.IsLetter(ch) && ch != ' ' && ch != '-' && ch != '.' && ch != '\'' {
+			return fmt.Errorf("некорректное ФИО: недопустимый символ %q", ch)
+		}
+	}
+	return nil
+}
+
+// maskDocumentChanges скрывает номера документов в журнале изменений: журнал не должен хранить их в открытом виде.
+func maskDocumentChanges(changes map[string]models.PatientFieldChange) map[string]models.PatientFieldChange {
+	mask := map[string]func(string) string{
+		"passport_series": func(v string) string { series, _ := utils.MaskPassport(v, ""); return series },
+		"passport_number": func(v string) string { return utils.MaskTail(v, 3) },
+		"oms_number":      func(v string) string { return utils.MaskTail(v, 4) },
+	}
+	for field, change := range changes {
+		if m, ok := mask[field]; ok {
+			oldValue, _ := change.Old.(string)
+			newValue, _ := change.New.(string)
+			changes[field] = models.PatientFieldChange{Old: m(oldValue), New: m(newValue)}
+		}
+	}
+	return changes
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
 }
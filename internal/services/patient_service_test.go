@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"ElectronicQueue/internal/models"
+)
+
+func TestValidatePatientChangesChecksOnlyChangedFields(t *testing.T) {
+	// Карточка, созданная до ужесточения проверок: полис и паспорт не проходят проверку.
+	patient := &models.Patient{
+		FullName:       "Иванов Иван Иванович",
+		BirthDate:      time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		PassportSeries: "0010",
+		PassportNumber: "1",
+		OmsNumber:      "1234",
+	}
+
+	tests := []struct {
+		name    string
+		changes []string
+		wantErr bool
+	}{
+		{"email only", []string{"email"}, false},
+		{"full name", []string{"full_name"}, false},
+		{"birth date", []string{"birth_date"}, false},
+		{"oms", []string{"oms_number"}, true},
+		{"passport number", []string{"passport_number"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := make(map[string]models.PatientFieldChange, len(tt.changes))
+			for _, field := range tt.changes {
+				changes[field] = models.PatientFieldChange{}
+			}
+			if err := validatePatientChanges(patient, changes); (err != nil) != tt.wantErr {
+				t.Errorf("validatePatientChanges(%v) error = %v, wantErr %v", tt.changes, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validatePatient(patient); err == nil {
+		t.Error("validatePatient: expected error for the whole legacy record")
+	}
+}
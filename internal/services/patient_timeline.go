@@ -0,0 +1,80 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTimelinePageSize = 20
+	maxTimelinePageSize     = 100
+)
+
+// GetTimeline возвращает историю пациента: визиты с талонами, временем ожидания и обслуживания и итогом,
+// а также выданные ему направления, новые события первыми. specialization оставляет визиты к врачам этой
+// специализации и направления к ним; includeArchived добавляет визиты, перенесенные в архив ночной очисткой.
+func (s *PatientService) GetTimeline(patientID uint, specialization string, includeArchived bool, page, pageSize int) (*models.PatientTimelinePage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxTimelinePageSize {
+		pageSize = defaultTimelinePageSize
+	}
+	if _, err := s.repo.GetByID(patientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("пациент с ID %d не найден", patientID)
+		}
+		return nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+	}
+	specialization = strings.TrimSpace(specialization)
+
+	visits, err := s.visits.FindByPatient(patientID, specialization, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения визитов пациента: %w", err)
+	}
+	referrals, err := s.referrals.GetByPatient(patientID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.PatientTimelineEntry, 0, len(visits)+len(referrals))
+	for i := range visits {
+		visit := &visits[i]
+		occurredAt, err := visit.StartsAt()
+		if err != nil {
+			return nil, fmt.Errorf("некорректное время приема записи %d: %w", visit.AppointmentID, err)
+		}
+		entries = append(entries, models.PatientTimelineEntry{
+			Kind:       models.TimelineVisit,
+			OccurredAt: occurredAt,
+			Archived:   visit.ArchivedAt != nil,
+			Visit:      visit,
+		})
+	}
+	for i := range referrals {
+		referral := &referrals[i]
+		if specialization != "" && !strings.EqualFold(referral.TargetSpecialization, specialization) {
+			continue
+		}
+		entries = append(entries, models.PatientTimelineEntry{
+			Kind:       models.TimelineReferral,
+			OccurredAt: referral.CreatedAt,
+			Referral:   referral,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].OccurredAt.After(entries[j].OccurredAt)
+	})
+
+	result := &models.PatientTimelinePage{Items: []models.PatientTimelineEntry{}, Total: len(entries), Page: page, PageSize: pageSize}
+	from := (page - 1) * pageSize
+	if from < len(entries) {
+		result.Items = entries[from:min(from+pageSize, len(entries))]
+	}
+	return result, nil
+}
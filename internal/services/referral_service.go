@@ -0,0 +1,216 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultReferralValidity — срок действия направления по умолчанию в зависимости от срочности.
+var defaultReferralValidity = map[models.ReferralUrgency]int{
+	models.ReferralRoutine:   30,
+	models.ReferralUrgent:    7,
+	models.ReferralEmergency: 1,
+}
+
+// ReferralService управляет направлениями пациентов к специалистам.
+type ReferralService struct {
+	repo            repository.ReferralRepository
+	appointmentRepo repository.AppointmentRepository
+	scheduleRepo    repository.ScheduleRepository
+	doctorRepo      repository.DoctorRepository
+}
+
+// NewReferralService создает новый экземпляр ReferralService.
+func NewReferralService(
+	repo repository.ReferralRepository,
+	appointmentRepo repository.AppointmentRepository,
+	scheduleRepo repository.ScheduleRepository,
+	doctorRepo repository.DoctorRepository,
+) *ReferralService {
+	return &ReferralService{
+		repo:            repo,
+		appointmentRepo: appointmentRepo,
+		scheduleRepo:    scheduleRepo,
+		doctorRepo:      doctorRepo,
+	}
+}
+
+// Create выдает направление по записи, прием по которой врач ведет сейчас или провел сегодня.
+func (s *ReferralService) Create(doctorID uint, req *models.CreateReferralRequest) (*models.Referral, error) {
+	appointment, err := s.appointmentRepo.FindByID(req.AppointmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("запись с ID %d не найдена", req.AppointmentID)
+		}
+		return nil, fmt.Errorf("ошибка поиска записи: %w", err)
+	}
+	if appointment.Schedule.DoctorID != doctorID {
+		return nil, errors.New("направление можно выдать только по записи на свой прием")
+	}
+	if appointment.PatientID == nil {
+		return nil, errors.New("в записи не указан пациент")
+	}
+	if !isActiveAppointment(appointment, time.Now()) {
+		return nil, errors.New("направление можно выдать только во время приема или в день приема после его завершения")
+	}
+
+	specialization := strings.TrimSpace(req.TargetSpecialization)
+	doctors, err := s.doctorRepo.FindBySpecialization(specialization)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки специальности: %w", err)
+	}
+	if len(doctors) == 0 {
+		return nil, fmt.Errorf("специальность «%s» не найдена", specialization)
+	}
+
+	urgency := req.Urgency
+	if urgency == "" {
+		urgency = models.ReferralRoutine
+	}
+	today := startOfDay(time.Now())
+	expiresAt := today.AddDate(0, 0, defaultReferralValidity[urgency])
+	if req.ExpiresAt != "" {
+		expiresAt, err = time.ParseInLocation("2006-01-02", req.ExpiresAt, time.Local)
+		if err != nil {
+			return nil, errors.New("неверный формат даты окончания действия, используйте YYYY-MM-DD")
+		}
+		if expiresAt.Before(today) {
+			return nil, errors.New("дата окончания действия направления не может быть в прошлом")
+		}
+	}
+
+	referral := &models.Referral{
+		PatientID:            *appointment.PatientID,
+		DoctorID:             &doctorID,
+		SourceAppointmentID:  &appointment.ID,
+		TargetSpecialization: specialization,
+		Urgency:              urgency,
+		ExpiresAt:            expiresAt,
+		Status:               models.ReferralOpen,
+	}
+	if note := strings.TrimSpace(req.Note); note != "" {
+		referral.Note = &note
+	}
+	if err := s.repo.Create(referral); err != nil {
+		return nil, fmt.Errorf("не удалось создать направление: %w", err)
+	}
+	return referral, nil
+}
+
+// isActiveAppointment проверяет, что пациент сейчас на приеме или был принят сегодня.
+func isActiveAppointment(appointment *models.Appointment, now time.Time) bool {
+	if appointment.TicketID == nil {
+		return false
+	}
+	switch appointment.Ticket.Status {
+	case models.StatusInProgress:
+		return true
+	case models.StatusCompleted:
+		return appointment.Schedule.Date.Format("2006-01-02") == now.Format("2006-01-02")
+	}
+	return false
+}
+
+// GetByPatient возвращает направления пациента; onlyOpen оставляет только открытые.
+func (s *ReferralService) GetByPatient(patientID uint, onlyOpen bool) ([]models.Referral, error) {
+	var status *models.ReferralStatus
+	if onlyOpen {
+		open := models.ReferralOpen
+		status = &open
+	}
+	referrals, err := s.repo.FindByPatientID(patientID, status)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения направлений пациента: %w", err)
+	}
+	return referrals, nil
+}
+
+// Cancel отменяет открытое направление.
+func (s *ReferralService) Cancel(id uint) error {
+	if err := s.repo.Cancel(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("открытое направление с ID %d не найдено", id)
+		}
+		return fmt.Errorf("не удалось отменить направление: %w", err)
+	}
+	return nil
+}
+
+// ValidateForBooking проверяет, что по направлению можно записать пациента в слот:
+// направление открыто и действует, выдано этому пациенту и ведет к специальности врача слота.
+func (s *ReferralService) ValidateForBooking(referralID uint, patientID *uint, scheduleID uint) error {
+	referral, err := s.repo.GetByID(referralID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("направление с ID %d не найдено", referralID)
+		}
+		return fmt.Errorf("ошибка поиска направления: %w", err)
+	}
+	if referral.Status != models.ReferralOpen {
+		return fmt.Errorf("направление уже использовано или отменено (статус: %s)", referral.Status)
+	}
+	if referral.ExpiresAt.Before(startOfDay(time.Now())) {
+		return fmt.Errorf("срок действия направления истек %s", referral.ExpiresAt.Format("02.01.2006"))
+	}
+	if patientID == nil || *patientID != referral.PatientID {
+		return errors.New("направление выдано другому пациенту")
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("указанный слот в расписании не найден")
+		}
+		return fmt.Errorf("ошибка поиска слота: %w", err)
+	}
+	doctor, err := s.doctorRepo.GetByID(schedule.DoctorID)
+	if err != nil {
+		return fmt.Errorf("ошибка поиска врача: %w", err)
+	}
+	if doctor.Specialization != referral.TargetSpecialization {
+		return fmt.Errorf("направление выдано к специалисту «%s», а слот — к специалисту «%s»",
+			referral.TargetSpecialization, doctor.Specialization)
+	}
+	return nil
+}
+
+// GetExpiredReport возвращает неисполненные направления с истекшим сроком действия.
+func (s *ReferralService) GetExpiredReport() ([]models.ExpiredReferralRow, error) {
+	rows, err := s.repo.FindExpiredOpen(startOfDay(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования отчета по направлениям: %w", err)
+	}
+	return rows, nil
+}
+
+// AttachOpenReferrals дополняет найденных пациентов их открытыми действующими направлениями.
+func (s *ReferralService) AttachOpenReferrals(patients []models.Patient) ([]models.PatientSearchResult, error) {
+	ids := make([]uint, 0, len(patients))
+	for _, p := range patients {
+		ids = append(ids, p.ID)
+	}
+	referrals, err := s.repo.FindOpenByPatientIDs(ids, startOfDay(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения направлений пациентов: %w", err)
+	}
+	byPatient := make(map[uint][]models.Referral, len(patients))
+	for _, r := range referrals {
+		byPatient[r.PatientID] = append(byPatient[r.PatientID], r)
+	}
+
+	results := make([]models.PatientSearchResult, 0, len(patients))
+	for _, p := range patients {
+		open := byPatient[p.ID]
+		if open == nil {
+			open = []models.Referral{}
+		}
+		results = append(results, models.PatientSearchResult{Patient: p, OpenReferrals: open})
+	}
+	return results, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// RoutingService управляет правилами прямой маршрутизации записей в очередь кабинета врача.
+type RoutingService struct {
+	repo        repository.RoutingRuleRepository
+	serviceRepo repository.ServiceRepository
+}
+
+// NewRoutingService создает новый экземпляр RoutingService.
+func NewRoutingService(repo repository.RoutingRuleRepository, serviceRepo repository.ServiceRepository) *RoutingService {
+	return &RoutingService{repo: repo, serviceRepo: serviceRepo}
+}
+
+// GetAll возвращает все правила прямой маршрутизации.
+func (s *RoutingService) GetAll() ([]models.DirectRoutingRule, error) {
+	rules, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения правил маршрутизации: %w", err)
+	}
+	return rules, nil
+}
+
+// Create добавляет правило для услуги терминала или специальности врача.
+func (s *RoutingService) Create(req *models.CreateDirectRoutingRuleRequest) (*models.DirectRoutingRule, error) {
+	serviceID := strings.TrimSpace(req.ServiceID)
+	specialization := strings.TrimSpace(req.Specialization)
+	if (serviceID == "") == (specialization == "") {
+		return nil, fmt.Errorf("укажите либо service_id, либо specialization")
+	}
+
+	rule := &models.DirectRoutingRule{}
+	if serviceID != "" {
+		if _, err := s.serviceRepo.GetByServiceID(serviceID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("услуга %q не найдена", serviceID)
+			}
+			return nil, fmt.Errorf("ошибка проверки услуги: %w", err)
+		}
+		rule.ServiceID = &serviceID
+	} else {
+		rule.Specialization = &specialization
+	}
+
+	if err := s.repo.Create(rule); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "duplicate key") {
+			return nil, fmt.Errorf("такое правило маршрутизации уже существует")
+		}
+		return nil, fmt.Errorf("не удалось создать правило маршрутизации: %w", err)
+	}
+	return rule, nil
+}
+
+// Delete удаляет правило маршрутизации.
+func (s *RoutingService) Delete(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("правило маршрутизации с ID %d не найдено", id)
+		}
+		return fmt.Errorf("не удалось удалить правило маршрутизации: %w", err)
+	}
+	return nil
+}
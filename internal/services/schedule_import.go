@@ -0,0 +1,404 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/spreadsheet"
+)
+
+// scheduleSheetHeader — заголовок таблицы расписания. Порядок столбцов одинаков для импорта и экспорта.
+var scheduleSheetHeader = []string{"Врач", "Дата", "Начало", "Окончание", "Кабинет"}
+
+const (
+	colDoctor = iota
+	colDate
+	colStart
+	colEnd
+	colCabinet
+)
+
+// excelEpoch — начало отсчета серийных дат Excel (с учетом ошибки 1900 года).
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.Local)
+
+// importedSlot — проверенная строка файла, готовая к созданию.
+type importedSlot struct {
+	result   *models.ScheduleImportRowResult
+	schedule models.Schedule
+	start    time.Time
+	end      time.Time
+}
+
+// ImportSchedules проверяет строки файла расписания (CSV или XLSX) и, если это не пробный запуск,
+// создает все корректные слоты в одной транзакции. Для каждой строки возвращается результат проверки.
+// Столбцы: врач (логин или ФИО), дата, начало, окончание, кабинет; строка заголовка необязательна.
+func (s *ScheduleService) ImportSchedules(format spreadsheet.Format, data []byte, dryRun bool) (*models.ScheduleImportReport, error) {
+	rows, err := spreadsheet.Read(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	doctors, err := s.doctorRepo.GetAll(false)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка врачей: %w", err)
+	}
+	resolver := newDoctorResolver(doctors)
+
+	// Емкость выделяется заранее, чтобы указатели на результаты строк оставались действительными.
+	report := &models.ScheduleImportReport{DryRun: dryRun, Rows: make([]models.ScheduleImportRowResult, 0, len(rows))}
+	var parsed []*importedSlot
+	headerChecked := false
+
+	for i, row := range rows {
+		if isBlankRow(row) {
+			continue
+		}
+		if !headerChecked {
+			headerChecked = true
+			if isScheduleHeader(row) {
+				continue
+			}
+		}
+
+		report.Rows = append(report.Rows, models.ScheduleImportRowResult{Row: i + 1})
+		result := &report.Rows[len(report.Rows)-1]
+		if slot := parseScheduleRow(row, result, resolver); slot != nil {
+			parsed = append(parsed, slot)
+		}
+	}
+	report.Total = len(report.Rows)
+
+	if err := s.checkImportOverlaps(parsed); err != nil {
+		return nil, err
+	}
+
+	var valid []*importedSlot
+	for _, slot := range parsed {
+		if len(slot.result.Errors) == 0 {
+			slot.result.Status = models.ScheduleImportRowValid
+			valid = append(valid, slot)
+		}
+	}
+	for i := range report.Rows {
+		if len(report.Rows[i].Errors) > 0 {
+			report.Rows[i].Status = models.ScheduleImportRowError
+		}
+	}
+	report.Valid = len(valid)
+	report.Invalid = report.Total - report.Valid
+
+	if dryRun || len(valid) == 0 {
+		return report, nil
+	}
+
+	schedules := make([]models.Schedule, len(valid))
+	for i, slot := range valid {
+		schedules[i] = slot.schedule
+	}
+	if err := s.scheduleRepo.CreateBatch(schedules); err != nil {
+		return nil, fmt.Errorf("не удалось создать слоты расписания: %w", err)
+	}
+
+	for i, slot := range valid {
+		id := schedules[i].ID
+		slot.result.ScheduleID = &id
+		slot.result.Status = models.ScheduleImportRowCreated
+		s.waitlist.OfferSlot(id)
+	}
+	report.Created = len(valid)
+	return report, nil
+}
+
+// ExportSchedules выгружает расписание за период в формате, который принимает ImportSchedules.
+func (s *ScheduleService) ExportSchedules(format spreadsheet.Format, from, to time.Time, doctorID *uint) ([]byte, error) {
+	schedules, err := s.scheduleRepo.FindInDateRange(from, to, doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписания: %w", err)
+	}
+
+	rows := make([][]string, 0, len(schedules)+1)
+	rows = append(rows, scheduleSheetHeader)
+	for _, sch := range schedules {
+		doctor := sch.Doctor.Login
+		if doctor == "" {
+			doctor = sch.Doctor.FullName
+		}
+		cabinet := ""
+		if sch.Cabinet != nil {
+			cabinet = strconv.Itoa(*sch.Cabinet)
+		}
+		rows = append(rows, []string{
+			doctor,
+			sch.Date.Format("2006-01-02"),
+			shortClock(sch.StartTime),
+			shortClock(sch.EndTime),
+			cabinet,
+		})
+	}
+
+	return spreadsheet.Write(format, "Расписание", rows)
+}
+
+// parseScheduleRow разбирает строку файла. Ошибки записываются в result; при ошибке возвращается nil.
+func parseScheduleRow(row []string, result *models.ScheduleImportRowResult, resolver *doctorResolver) *importedSlot {
+	cell := func(i int) string {
+		if i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	result.Doctor = cell(colDoctor)
+	var doctor *models.Doctor
+	if result.Doctor == "" {
+		result.Errors = append(result.Errors, "не указан врач")
+	} else if d, err := resolver.resolve(result.Doctor); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		doctor = d
+	}
+
+	date, err := parseImportDate(cell(colDate))
+	if err != nil {
+		result.Date = cell(colDate)
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		result.Date = date.Format("2006-01-02")
+	}
+
+	startClock, startErr := parseImportClock(cell(colStart))
+	endClock, endErr := parseImportClock(cell(colEnd))
+	result.StartTime, result.EndTime = cell(colStart), cell(colEnd)
+	if startErr != nil {
+		result.Errors = append(result.Errors, "начало: "+startErr.Error())
+	} else {
+		result.StartTime = shortClock(startClock)
+	}
+	if endErr != nil {
+		result.Errors = append(result.Errors, "окончание: "+endErr.Error())
+	} else {
+		result.EndTime = shortClock(endClock)
+	}
+
+	if raw := cell(colCabinet); raw != "" {
+		cabinet, err := parseImportInt(raw)
+		if err != nil || cabinet <= 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("некорректный номер кабинета: %q", raw))
+		} else {
+			result.Cabinet = &cabinet
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return nil
+	}
+
+	schedule := models.Schedule{
+		DoctorID:    doctor.ID,
+		Date:        date,
+		StartTime:   startClock,
+		EndTime:     endClock,
+		IsAvailable: true,
+		Cabinet:     result.Cabinet,
+	}
+	start, _ := schedule.StartsAt()
+	end, _ := schedule.EndsAt()
+	if !start.Before(end) {
+		result.Errors = append(result.Errors, "время начала должно быть раньше времени окончания")
+		return nil
+	}
+	if !start.After(time.Now()) {
+		result.Errors = append(result.Errors, "слот находится в прошлом")
+		return nil
+	}
+
+	return &importedSlot{result: result, schedule: schedule, start: start, end: end}
+}
+
+// checkImportOverlaps отмечает строки, пересекающиеся по времени с существующими слотами
+// или с предыдущими корректными строками файла: у того же врача или в том же кабинете.
+func (s *ScheduleService) checkImportOverlaps(slots []*importedSlot) error {
+	if len(slots) == 0 {
+		return nil
+	}
+
+	from, to := slots[0].schedule.Date, slots[0].schedule.Date
+	for _, slot := range slots[1:] {
+		if slot.schedule.Date.Before(from) {
+			from = slot.schedule.Date
+		}
+		if slot.schedule.Date.After(to) {
+			to = slot.schedule.Date
+		}
+	}
+
+	existing, err := s.scheduleRepo.FindInDateRange(from, to, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка получения существующего расписания: %w", err)
+	}
+
+	var accepted []*importedSlot
+	for _, sch := range existing {
+		start, errStart := sch.StartsAt()
+		end, errEnd := sch.EndsAt()
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		accepted = append(accepted, &importedSlot{schedule: sch, start: start, end: end})
+	}
+
+	for _, slot := range slots {
+		for _, other := range accepted {
+			if !slot.start.Before(other.end) || !other.start.Before(slot.end) {
+				continue
+			}
+			if msg := overlapMessage(slot, other); msg != "" {
+				slot.result.Errors = append(slot.result.Errors, msg)
+			}
+		}
+		if len(slot.result.Errors) == 0 {
+			accepted = append(accepted, slot)
+		}
+	}
+	return nil
+}
+
+// overlapMessage описывает конфликт двух пересекающихся по времени слотов или возвращает пустую строку.
+func overlapMessage(slot, other *importedSlot) string {
+	where := "с существующим слотом"
+	if other.result != nil {
+		where = fmt.Sprintf("со строкой %d", other.result.Row)
+	} else if other.schedule.ID != 0 {
+		where = fmt.Sprintf("с существующим слотом ID %d", other.schedule.ID)
+	}
+	interval := fmt.Sprintf("%s–%s", other.start.Format("15:04"), other.end.Format("15:04"))
+
+	if slot.schedule.DoctorID == other.schedule.DoctorID {
+		return fmt.Sprintf("пересечение по времени у врача %s (%s)", where, interval)
+	}
+	if slot.schedule.Cabinet != nil && other.schedule.Cabinet != nil && *slot.schedule.Cabinet == *other.schedule.Cabinet {
+		return fmt.Sprintf("кабинет %d занят другим врачом: пересечение %s (%s)", *slot.schedule.Cabinet, where, interval)
+	}
+	return ""
+}
+
+// doctorResolver находит врача по логину или ФИО.
+type doctorResolver struct {
+	byLogin map[string]*models.Doctor
+	byName  map[string][]*models.Doctor
+}
+
+func newDoctorResolver(doctors []models.Doctor) *doctorResolver {
+	r := &doctorResolver{
+		byLogin: make(map[string]*models.Doctor, len(doctors)),
+		byName:  make(map[string][]*models.Doctor, len(doctors)),
+	}
+	for i := range doctors {
+		d := &doctors[i]
+		if d.Login != "" {
+			r.byLogin[strings.ToLower(d.Login)] = d
+		}
+		name := normalizeName(d.FullName)
+		r.byName[name] = append(r.byName[name], d)
+	}
+	return r
+}
+
+func (r *doctorResolver) resolve(value string) (*models.Doctor, error) {
+	if d, ok := r.byLogin[strings.ToLower(value)]; ok {
+		return d, nil
+	}
+	switch matches := r.byName[normalizeName(value)]; len(matches) {
+	case 0:
+		return nil, fmt.Errorf("врач %q не найден", value)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("найдено несколько врачей с ФИО %q, укажите логин", value)
+	}
+}
+
+// normalizeName приводит ФИО к виду для сравнения: нижний регистр, "ё" как "е", одиночные пробелы.
+func normalizeName(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// parseImportDate принимает даты вида 2025-07-20, 20.07.2025 и серийные даты Excel.
+func parseImportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("не указана дата")
+	}
+	for _, layout := range []string{"2006-01-02", "02.01.2006", "2.1.2006"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial >= 1 {
+		return excelEpoch.AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, fmt.Errorf("некорректная дата %q, используйте ГГГГ-ММ-ДД или ДД.ММ.ГГГГ", value)
+}
+
+// parseImportClock принимает время вида 9:00, 09:00:00 и доли суток Excel; возвращает "15:04:05".
+func parseImportClock(value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("не указано время")
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("15:04:05"), nil
+		}
+	}
+	if fraction, err := strconv.ParseFloat(value, 64); err == nil && fraction >= 0 && fraction < 1 {
+		seconds := int(math.Round(fraction * 24 * 60 * 60))
+		return time.Date(0, 1, 1, 0, 0, seconds, 0, time.UTC).Format("15:04:05"), nil
+	}
+	return "", fmt.Errorf("некорректное время %q, используйте ЧЧ:ММ", value)
+}
+
+// parseImportInt разбирает целое число, в том числе записанное Excel как "101.0".
+func parseImportInt(value string) (int, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f != math.Trunc(f) {
+		return 0, fmt.Errorf("не целое число: %q", value)
+	}
+	return int(f), nil
+}
+
+// isScheduleHeader определяет строку заголовка: в ней дата и время начала не разбираются.
+func isScheduleHeader(row []string) bool {
+	get := func(i int) string {
+		if i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	_, dateErr := parseImportDate(get(colDate))
+	_, startErr := parseImportClock(get(colStart))
+	return dateErr != nil && startErr != nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// shortClock сокращает время "15:04:05" до "15:04".
+func shortClock(clock string) string {
+	if len(clock) >= 5 {
+		return clock[:5]
+	}
+	return clock
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ElectronicQueue/internal/models"
+)
+
+func testDoctorResolver() *doctorResolver {
+	return newDoctorResolver([]models.Doctor{
+		{ID: 1, FullName: "Иванов Иван Иванович", Login: "ivanov"},
+		{ID: 2, FullName: "Петров Петр Петрович", Login: "petrov"},
+		{ID: 3, FullName: "Петров Петр Петрович", Login: "petrov2"},
+		{ID: 4, FullName: "Сёмин Семен Семенович", Login: "semin"},
+	})
+}
+
+func TestIsScheduleHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []string
+		want bool
+	}{
+		{"standard header", scheduleSheetHeader, true},
+		{"custom header", []string{"Doctor", "Date", "Start", "End", "Room"}, true},
+		{"data row", []string{"ivanov", "2030-07-20", "09:00", "09:30", "101"}, false},
+		{"bad time only", []string{"ivanov", "2030-07-20", "9 утра", "09:30"}, false},
+		{"bad date only", []string{"ivanov", "завтра", "09:00", "09:30"}, false},
+		{"short row", []string{"ivanov"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isScheduleHeader(tt.row); got != tt.want {
+				t.Errorf("isScheduleHeader(%q) = %v, want %v", tt.row, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleRow(t *testing.T) {
+	future := time.Now().AddDate(0, 0, 7).Format("02.01.2006")
+	past := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+
+	tests := []struct {
+		name       string
+		row        []string
+		wantDoctor uint
+		wantErrors []string
+	}{
+		{
+			name:       "valid by login",
+			row:        []string{"ivanov", future, "9:00", "09:30:00", "101"},
+			wantDoctor: 1,
+		},
+		{
+			name:       "valid by name with yo",
+			row:        []string{"  семин  семен семенович ", future, "0.375", "0.395833333", "101.0"},
+			wantDoctor: 4,
+		},
+		{
+			name:       "malformed first row",
+			row:        []string{"", "20/07/2030", "09:00", "", "каб. 5"},
+			wantErrors: []string{"не указан врач", "некорректная дата", "окончание: не указано время", "некорректный номер кабинета"},
+		},
+		{
+			name:       "bad times",
+			row:        []string{"ivanov", future, "25:00", "9.30", ""},
+			wantErrors: []string{"начало: некорректное время", "окончание: некорректное время"},
+		},
+		{
+			name:       "end before start",
+			row:        []string{"ivanov", future, "10:00", "09:00"},
+			wantErrors: []string{"время начала должно быть раньше времени окончания"},
+		},
+		{
+			name:       "slot in the past",
+			row:        []string{"ivanov", past, "09:00", "09:30"},
+			wantErrors: []string{"слот находится в прошлом"},
+		},
+		{
+			name:       "ambiguous name",
+			row:        []string{"Петров Петр Петрович", future, "09:00", "09:30"},
+			wantErrors: []string{"найдено несколько врачей"},
+		},
+		{
+			name:       "unknown doctor",
+			row:        []string{"sidorov", future, "09:00", "09:30"},
+			wantErrors: []string{`врач "sidorov" не найден`},
+		},
+	}
+
+	resolver := testDoctorResolver()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &models.ScheduleImportRowResult{Row: 1}
+			slot := parseScheduleRow(tt.row, result, resolver)
+
+			if len(tt.wantErrors) == 0 {
+				if slot == nil {
+					t.Fatalf("unexpected errors: %q", result.Errors)
+				}
+				if slot.schedule.DoctorID != tt.wantDoctor {
+					t.Errorf("DoctorID = %d, want %d", slot.schedule.DoctorID, tt.wantDoctor)
+				}
+				return
+			}
+
+			if slot != nil {
+				t.Fatalf("expected row to be rejected, got slot %+v", slot.schedule)
+			}
+			if len(result.Errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %q, want %d errors", result.Errors, len(tt.wantErrors))
+			}
+			for i, want := range tt.wantErrors {
+				if !strings.Contains(result.Errors[i], want) {
+					t.Errorf("error %d = %q, want substring %q", i, result.Errors[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseImportClock(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"9:00", "09:00:00", false},
+		{"09:30:15", "09:30:15", false},
+		{"0.5", "12:00:00", false},
+		{"0", "00:00:00", false},
+		{"", "", true},
+		{"1", "", true},
+		{"24:00", "", true},
+		{"9-00", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseImportClock(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseImportClock(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseImportClock(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseImportDate(t *testing.T) {
+	want := time.Date(2025, 7, 20, 0, 0, 0, 0, time.Local)
+	for _, value := range []string{"2025-07-20", "20.07.2025", "20.7.2025", "45858"} {
+		got, err := parseImportDate(value)
+		if err != nil {
+			t.Errorf("parseImportDate(%q): %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseImportDate(%q) = %v, want %v", value, got, want)
+		}
+	}
+	for _, value := range []string{"", "2025/07/20", "0", "завтра"} {
+		if _, err := parseImportDate(value); err == nil {
+			t.Errorf("parseImportDate(%q): expected error", value)
+		}
+	}
+}
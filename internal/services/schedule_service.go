@@ -14,13 +14,15 @@ import (
 type ScheduleService struct {
 	scheduleRepo repository.ScheduleRepository
 	doctorRepo   repository.DoctorRepository
+	waitlist     *WaitlistService
 }
 
 // NewScheduleService создает новый экземпляр ScheduleService.
-func NewScheduleService(scheduleRepo repository.ScheduleRepository, doctorRepo repository.DoctorRepository) *ScheduleService {
+func NewScheduleService(scheduleRepo repository.ScheduleRepository, doctorRepo repository.DoctorRepository, waitlist *WaitlistService) *ScheduleService {
 	return &ScheduleService{
 		scheduleRepo: scheduleRepo,
 		doctorRepo:   doctorRepo,
+		waitlist:     waitlist,
 	}
 }
 
@@ -39,19 +41,62 @@ func (s *ScheduleService) CreateSchedule(req *models.CreateScheduleRequest) (*mo
 		isAvailable = *req.IsAvailable
 	}
 
+	capacity := 1
+	if req.Capacity != nil {
+		capacity = *req.Capacity
+	}
+
 	schedule := &models.Schedule{
 		DoctorID:    req.DoctorID,
 		Date:        req.Date,
 		StartTime:   req.StartTime.Format("15:04:05"),
 		EndTime:     req.EndTime.Format("15:04:05"),
 		IsAvailable: isAvailable,
+		IsBlocked:   !isAvailable,
 		Cabinet:     req.Cabinet,
+		Capacity:    capacity,
 	}
 
 	if err := s.scheduleRepo.Create(schedule); err != nil {
 		return nil, fmt.Errorf("не удалось создать слот в расписании: %w", err)
 	}
 
+	if schedule.IsAvailable {
+		s.waitlist.OfferSlot(schedule.ID)
+	}
+
+	return schedule, nil
+}
+
+// UpdateScheduleAvailability блокирует или разблокирует слот.
+// Блокировка хранится отдельно от занятости: разблокированный слот без свободных мест
+// останется недоступным до освобождения места. Слот со свободными местами сразу
+// предлагается листу ожидания.
+func (s *ScheduleService) UpdateScheduleAvailability(id uint, isAvailable bool) (*models.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("слот расписания с ID %d не найден", id)
+		}
+		return nil, fmt.Errorf("ошибка при поиске слота расписания: %w", err)
+	}
+
+	if schedule.IsBlocked == !isAvailable {
+		return schedule, nil
+	}
+
+	if err := s.scheduleRepo.SetBlocked(id, !isAvailable); err != nil {
+		return nil, fmt.Errorf("не удалось обновить слот в расписании: %w", err)
+	}
+	schedule, err = s.scheduleRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске слота расписания: %w", err)
+	}
+
+	if schedule.IsAvailable {
+		s.waitlist.OfferSlot(schedule.ID)
+	}
+
 	return schedule, nil
 }
 
@@ -93,6 +138,8 @@ type TimeSlotModel struct {
 	EndTime     string `json:"end_time"`
 	IsAvailable bool   `json:"is_available"`
 	Cabinet     *int   `json:"cabinet,omitempty"`
+	Capacity    int    `json:"capacity"`
+	BookedCount int    `json:"booked_count"`
 }
 
 // GetTodayScheduleState подготавливает данные для отображения дневного расписания.
@@ -138,6 +185,8 @@ func (s *ScheduleService) GetTodayScheduleState() (*TodayScheduleResponse, error
 			EndTime:     schedule.EndTime,
 			IsAvailable: schedule.IsAvailable,
 			Cabinet:     schedule.Cabinet,
+			Capacity:    schedule.Capacity,
+			BookedCount: schedule.BookedCount,
 		})
 		schedulesByDoctor[schedule.DoctorID] = docSchedule
 	}
@@ -8,17 +8,21 @@ import (
 
 	"ElectronicQueue/internal/config"
 	"ElectronicQueue/internal/logger"
+
+	"github.com/sirupsen/logrus"
 )
 
 type TasksTimerService struct {
 	cleanupService *CleanupService
+	patientService *PatientService
 	config         *config.Config
 	log            *logger.AsyncLogger
 }
 
-func NewTasksTimerService(cleanupService *CleanupService, config *config.Config) *TasksTimerService {
+func NewTasksTimerService(cleanupService *CleanupService, patientService *PatientService, config *config.Config) *TasksTimerService {
 	return &TasksTimerService{
 		cleanupService: cleanupService,
+		patientService: patientService,
 		config:         config,
 		log:            logger.Default().WithField("module", "tasks_timer"),
 	}
@@ -40,6 +44,7 @@ func (s *TasksTimerService) Start(ctx context.Context) {
 			if err := s.cleanupService.CleanTickets(); err != nil {
 				s.log.WithError(err).Error("Ошибка выполнения очистки tickets")
 			}
+			s.runPatientRetention()
 		case <-ctx.Done():
 			s.log.Info("Планировщик задач остановлен")
 			return
@@ -47,6 +52,37 @@ func (s *TasksTimerService) Start(ctx context.Context) {
 	}
 }
 
+// runPatientRetention анонимизирует карточки пациентов без визитов дольше срока хранения и пишет отчет в лог.
+// Если срок хранения не задан, задание пропускается.
+func (s *TasksTimerService) runPatientRetention() {
+	if s.patientService.RetentionYears() <= 0 {
+		return
+	}
+	report, err := s.patientService.RunRetention("system", retentionActorName, false)
+	if err != nil {
+		s.log.WithError(err).Error("Ошибка выполнения задания хранения данных пациентов")
+		return
+	}
+
+	fields := logrus.Fields{
+		"retention_years": report.RetentionYears,
+		"cutoff":          report.Cutoff.Format("2006-01-02"),
+		"candidates":      len(report.Candidates),
+		"anonymized":      report.Anonymized,
+		"failed":          len(report.Failed),
+		"has_more":        report.HasMore,
+		"duration":        report.FinishedAt.Sub(report.StartedAt).String(),
+	}
+	if len(report.Failed) > 0 {
+		for _, failure := range report.Failed {
+			s.log.WithField("patient_id", failure.PatientID).WithField("error", failure.Error).Warn("Не удалось анонимизировать карточку пациента")
+		}
+		s.log.WithFields(fields).Warn("Задание хранения данных пациентов завершено с ошибками")
+		return
+	}
+	s.log.WithFields(fields).Info("Задание хранения данных пациентов завершено")
+}
+
 // calculateNextRun вычисляет время следующего запуска
 func (s *TasksTimerService) calculateNextRun() time.Time {
 	now := time.Now()
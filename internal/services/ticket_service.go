@@ -7,7 +7,7 @@ import (
 	"ElectronicQueue/internal/utils"
 	"errors"
 	"fmt"
-	"regexp"
+	"math"
 	"strings"
 	"time"
 
@@ -23,6 +23,11 @@ type TicketService struct {
 	patientRepo      repository.PatientRepository
 	appointmentRepo  repository.AppointmentRepository
 	priorityRepo     repository.RegistrarPriorityRepository
+	checkInCodes     *utils.CheckInCodeSigner
+	checkInLogRepo   repository.CheckInLogRepository
+	routingRepo      repository.RoutingRuleRepository
+	earlyWindow      time.Duration
+	lateWindow       time.Duration
 }
 
 func NewTicketService(
@@ -32,7 +37,21 @@ func NewTicketService(
 	patientRepo repository.PatientRepository,
 	appointmentRepo repository.AppointmentRepository,
 	priorityRepo repository.RegistrarPriorityRepository,
-) *TicketService {
+	checkInCodes *utils.CheckInCodeSigner,
+	checkInLogRepo repository.CheckInLogRepository,
+	routingRepo repository.RoutingRuleRepository,
+	earlyWindow string,
+	lateWindow string,
+) (*TicketService, error) {
+	early, err := time.ParseDuration(earlyWindow)
+	if err != nil || early < 0 {
+		return nil, fmt.Errorf("invalid check-in early window: %q", earlyWindow)
+	}
+	late, err := time.ParseDuration(lateWindow)
+	if err != nil || late < 0 {
+		return nil, fmt.Errorf("invalid check-in late window: %q", lateWindow)
+	}
+
 	return &TicketService{
 		repo:             repo,
 		serviceRepo:      serviceRepo,
@@ -40,7 +59,12 @@ func NewTicketService(
 		patientRepo:      patientRepo,
 		appointmentRepo:  appointmentRepo,
 		priorityRepo:     priorityRepo,
-	}
+		checkInCodes:     checkInCodes,
+		checkInLogRepo:   checkInLogRepo,
+		routingRepo:      routingRepo,
+		earlyWindow:      early,
+		lateWindow:       late,
+	}, nil
 }
 
 func (s *TicketService) GetTicketsForRegistrar(categoryPrefix string, registrarID uint) ([]models.RegistrarTicketResponse, error) {
@@ -253,45 +277,290 @@ func (s *TicketService) GetInvitedTicketForWindow(windowNumber int) (*models.Tic
 	return ticket, nil
 }
 
-func (s *TicketService) CheckInByPhone(phone string) (*models.Ticket, error) {
-	nonAlphanumericRegex := regexp.MustCompile(`[^0-9]+`)
-	sanitizedPhone := nonAlphanumericRegex.ReplaceAllString(phone, "")
+// CheckInByPhone регистрирует пациента на прием по номеру телефона.
+func (s *TicketService) CheckInByPhone(phone string, birthYear *int) (*models.CheckInResult, error) {
+	return s.CheckIn(&models.KioskCheckInRequest{Method: models.CheckInByPhone, Value: phone, BirthYear: birthYear})
+}
+
+// checkInCandidate — пациент, найденный по данным с киоска, вместе с его записью на сегодня.
+type checkInCandidate struct {
+	patient     models.Patient
+	appointment *models.Appointment
+}
 
-	patient, err := s.patientRepo.FindByPhone(sanitizedPhone)
+// CheckInTooEarlyError возвращается, если пациент пришел раньше, чем открывается регистрация на его прием.
+type CheckInTooEarlyError struct {
+	SlotStart time.Time
+	OpensAt   time.Time
+}
+
+func (e *CheckInTooEarlyError) Error() string {
+	return fmt.Sprintf("ваша запись на %s, регистрация откроется в %s — пожалуйста, подойдите к этому времени",
+		e.SlotStart.Format("15:04"), e.OpensAt.Format("15:04"))
+}
+
+// CheckIn регистрирует пациента на прием по записи через киоск и выдает талон.
+// Пациент идентифицируется по телефону, полису ОМС (номер или штрих-код), паспорту или QR-коду записи.
+// Если данным соответствует несколько пациентов с записями на сегодня, запрашивается год рождения.
+// Регистрация открывается за earlyWindow до начала слота; пациент, пришедший позже lateWindow
+// после начала, получает талон в регистратуру с отметкой об опоздании. Каждый исход пишется в журнал.
+// Если для услуги или специальности врача настроена прямая маршрутизация, своевременно пришедший
+// пациент сразу попадает в очередь кабинета со статусом «зарегистрирован».
+func (s *TicketService) CheckIn(req *models.KioskCheckInRequest) (*models.CheckInResult, error) {
+	now := time.Now()
+	var appointment *models.Appointment
+	var err error
+	if req.Method == models.CheckInByQR {
+		appointment, err = s.findAppointmentByCheckInCode(req.Value)
+	} else {
+		appointment, err = s.findAppointmentByPatientData(req, now)
+	}
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("пациент с указанным номером телефона не найден")
-		}
-		return nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+		return nil, err
 	}
 
-	now := time.Now()
-	appointment, err := s.appointmentRepo.FindUpcomingByPatientID(patient.ID, now)
+	slotStart, err := appointment.Schedule.StartsAt()
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("у вас нет предстоящих записей на сегодня")
-		}
-		return nil, fmt.Errorf("ошибка поиска записи: %w", err)
+		return nil, fmt.Errorf("некорректное время начала записи: %w", err)
+	}
+
+	outcome := s.checkInOutcome(slotStart, now)
+	if outcome == models.CheckInEarly {
+		s.logCheckIn(req.Method, appointment, nil, outcome, slotStart, now)
+		return nil, &CheckInTooEarlyError{SlotStart: slotStart, OpensAt: slotStart.Add(-s.earlyWindow)}
 	}
 
 	serviceID := "confirm_appointment"
+	directToDoctor := false
+	if outcome == models.CheckInOnTime {
+		directToDoctor, err = s.routingRepo.Matches(serviceID, appointment.Schedule.Doctor.Specialization)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось проверить правила маршрутизации: %w", err)
+		}
+	}
+
 	ticketNumber, err := s.generateTicketNumber(serviceID)
 	if err != nil {
 		return nil, err
 	}
 
+	status := models.StatusWaiting
+	if directToDoctor {
+		status = models.StatusRegistered
+	}
+
 	newTicket := &models.Ticket{
 		TicketNumber: ticketNumber,
-		Status:       models.StatusWaiting,
-		CreatedAt:    time.Now(),
+		Status:       status,
+		CreatedAt:    now,
 		ServiceType:  &serviceID,
+		IsLate:       outcome == models.CheckInLate,
 	}
 
 	if err := s.appointmentRepo.AssignTicketToAppointment(appointment, newTicket); err != nil {
 		return nil, fmt.Errorf("не удалось создать талон и привязать к записи: %w", err)
 	}
 
-	return newTicket, nil
+	s.logCheckIn(req.Method, appointment, &newTicket.ID, outcome, slotStart, now)
+	logger.Default().WithFields(map[string]interface{}{
+		"method":         req.Method,
+		"appointment_id": appointment.ID,
+		"ticket_number":  newTicket.TicketNumber,
+		"outcome":        outcome,
+		"direct":         directToDoctor,
+	}).Info("Пациент зарегистрировался на прием через киоск")
+
+	return &models.CheckInResult{
+		Ticket:          newTicket,
+		DirectToDoctor:  directToDoctor,
+		Cabinet:         appointment.Schedule.Cabinet,
+		AppointmentTime: slotStart,
+		DoctorName:      appointment.Schedule.Doctor.FullName,
+	}, nil
+}
+
+// GetCheckInReport возвращает статистику регистраций по записи за период дат (включительно).
+func (s *TicketService) GetCheckInReport(from, to time.Time) ([]models.CheckInReportRow, error) {
+	rows, err := s.checkInLogRepo.GetReport(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения отчета по регистрациям: %w", err)
+	}
+	return rows, nil
+}
+
+// checkInOutcome определяет, пришел ли пациент вовремя относительно окна регистрации.
+func (s *TicketService) checkInOutcome(slotStart, now time.Time) models.CheckInOutcome {
+	switch {
+	case now.Before(slotStart.Add(-s.earlyWindow)):
+		return models.CheckInEarly
+	case now.After(slotStart.Add(s.lateWindow)):
+		return models.CheckInLate
+	default:
+		return models.CheckInOnTime
+	}
+}
+
+// selectTodayAppointment выбирает из сегодняшних записей пациента (по возрастанию времени)
+// ближайшую, на которую он еще не опоздал, а если опоздал на все — последнюю из них.
+func (s *TicketService) selectTodayAppointment(appointments []models.Appointment, now time.Time) *models.Appointment {
+	if len(appointments) == 0 {
+		return nil
+	}
+	for i := range appointments {
+		slotStart, err := appointments[i].Schedule.StartsAt()
+		if err == nil && !now.After(slotStart.Add(s.lateWindow)) {
+			return &appointments[i]
+		}
+	}
+	return &appointments[len(appointments)-1]
+}
+
+// logCheckIn пишет исход регистрации в журнал. Ошибка записи журнала не прерывает регистрацию.
+func (s *TicketService) logCheckIn(method models.CheckInMethod, appointment *models.Appointment, ticketID *uint, outcome models.CheckInOutcome, slotStart, now time.Time) {
+	appointmentID := appointment.ID
+	entry := &models.CheckInLog{
+		AppointmentID: &appointmentID,
+		PatientID:     appointment.PatientID,
+		TicketID:      ticketID,
+		Method:        method,
+		Outcome:       outcome,
+		SlotStart:     slotStart,
+		OffsetMinutes: int(math.Round(now.Sub(slotStart).Minutes())),
+	}
+	if err := s.checkInLogRepo.Create(entry); err != nil {
+		logger.Default().WithError(err).WithField("appointment_id", appointmentID).Warn("Не удалось записать исход регистрации в журнал")
+	}
+}
+
+// findAppointmentByCheckInCode находит запись на сегодня по подписанному коду из QR-кода.
+func (s *TicketService) findAppointmentByCheckInCode(code string) (*models.Appointment, error) {
+	appointmentID, err := s.checkInCodes.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+
+	appointment, err := s.appointmentRepo.FindByID(appointmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("запись на прием не найдена")
+		}
+		return nil, fmt.Errorf("ошибка поиска записи: %w", err)
+	}
+	if appointment.TicketID != nil {
+		return nil, fmt.Errorf("вы уже зарегистрированы на этот прием, талон %s", appointment.Ticket.TicketNumber)
+	}
+	if appointment.Schedule.Date.Format("2006-01-02") != time.Now().Format("2006-01-02") {
+		return nil, fmt.Errorf("ваша запись на %s, регистрация возможна только в день приема", appointment.Schedule.Date.Format("02.01.2006"))
+	}
+	return appointment, nil
+}
+
+// findAppointmentByPatientData находит пациентов по данным с киоска и выбирает единственную запись на сегодня.
+func (s *TicketService) findAppointmentByPatientData(req *models.KioskCheckInRequest, now time.Time) (*models.Appointment, error) {
+	patients, birthDate, err := s.findCheckInPatients(req.Method, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if birthDate != nil {
+		patients = filterPatients(patients, func(p models.Patient) bool {
+			return p.BirthDate.Format("2006-01-02") == birthDate.Format("2006-01-02")
+		})
+	}
+	if req.BirthYear != nil {
+		patients = filterPatients(patients, func(p models.Patient) bool {
+			return p.BirthDate.Year() == *req.BirthYear
+		})
+	}
+	if len(patients) == 0 {
+		return nil, fmt.Errorf("пациент с указанными данными не найден")
+	}
+
+	var candidates []checkInCandidate
+	for _, patient := range patients {
+		appointments, err := s.appointmentRepo.FindTodayByPatientID(patient.ID, now)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка поиска записи: %w", err)
+		}
+		if appointment := s.selectTodayAppointment(appointments, now); appointment != nil {
+			candidates = append(candidates, checkInCandidate{patient: patient, appointment: appointment})
+		}
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return nil, fmt.Errorf("у вас нет предстоящих записей на сегодня")
+	case len(candidates) == 1:
+		return candidates[0].appointment, nil
+	case req.BirthYear == nil:
+		return nil, fmt.Errorf("найдено несколько пациентов с записями на сегодня, подтвердите год рождения")
+	default:
+		return nil, fmt.Errorf("не удалось однозначно определить пациента, обратитесь в регистратуру")
+	}
+}
+
+// findCheckInPatients ищет пациентов выбранным способом. Для штрих-кода полиса дополнительно
+// возвращается дата рождения из штрих-кода, если она там есть.
+func (s *TicketService) findCheckInPatients(method models.CheckInMethod, value string) ([]models.Patient, *time.Time, error) {
+	switch method {
+	case models.CheckInByPhone:
+		phone, err := utils.NormalizePhone(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if phone == "" {
+			return nil, nil, fmt.Errorf("не указан номер телефона")
+		}
+		patients, err := s.patientRepo.FindAllByPhone(phone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+		}
+		if len(patients) == 0 {
+			return nil, nil, fmt.Errorf("пациент с указанным номером телефона не найден")
+		}
+		return patients, nil, nil
+
+	case models.CheckInByOMS:
+		scan, err := utils.ParseOMSScan(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		patients, err := s.patientRepo.FindAllByOMS(scan.Number)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+		}
+		if len(patients) == 0 {
+			return nil, nil, fmt.Errorf("пациент с указанным полисом ОМС не найден")
+		}
+		return patients, scan.BirthDate, nil
+
+	case models.CheckInByPassport:
+		series, number, err := utils.NormalizePassport(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		patient, err := s.patientRepo.FindByPassport(series, number)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, fmt.Errorf("пациент с указанным паспортом не найден")
+			}
+			return nil, nil, fmt.Errorf("ошибка поиска пациента: %w", err)
+		}
+		return []models.Patient{*patient}, nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("неизвестный способ идентификации: %s", method)
+	}
+}
+
+func filterPatients(patients []models.Patient, keep func(models.Patient) bool) []models.Patient {
+	filtered := patients[:0]
+	for _, p := range patients {
+		if keep(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
 }
 
 func (s *TicketService) finalizeReceptionAndUpdateTicket(ticket *models.Ticket) error {
@@ -381,7 +650,8 @@ func (s *TicketService) MapServiceIDToName(serviceID string) string {
 	return service.Name
 }
 
-func (s *TicketService) GenerateTicketImage(baseSize int, ticket *models.Ticket, serviceName string, mode string, qrData []byte) ([]byte, error) {
+// GenerateTicketImage рисует изображение талона. Непустой footer печатается внизу вместо числа ожидающих.
+func (s *TicketService) GenerateTicketImage(baseSize int, ticket *models.Ticket, serviceName string, mode string, qrData []byte, footer string) ([]byte, error) {
 	waitingTickets, err := s.repo.FindByStatuses([]models.TicketStatus{models.StatusWaiting})
 	waitingNumber := 0
 	if err == nil {
@@ -414,6 +684,7 @@ func (s *TicketService) GenerateTicketImage(baseSize int, ticket *models.Ticket,
 		TicketNumber:   ticket.TicketNumber,
 		DateTime:       ticket.CreatedAt,
 		WaitingNumber:  waitingNumber,
+		Footer:         footer,
 	}
 
 	img, err := utils.GenerateTicketImage(config, isColor)
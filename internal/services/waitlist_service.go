@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ElectronicQueue/internal/logger"
+	"ElectronicQueue/internal/models"
+	"ElectronicQueue/internal/notification"
+	"ElectronicQueue/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// waitlistHoldCheckInterval определяет, как часто проверяются просроченные удержания слотов.
+const waitlistHoldCheckInterval = time.Minute
+
+// WaitlistService управляет листом ожидания: подбирает заявки для освободившихся слотов,
+// удерживает слот на время ожидания ответа пациента и отправляет предложение.
+type WaitlistService struct {
+	repo         repository.WaitlistRepository
+	doctorRepo   repository.DoctorRepository
+	patientRepo  repository.PatientRepository
+	rules        *BookingRulesService
+	channel      notification.Channel
+	holdDuration time.Duration
+	log          *logger.AsyncLogger
+}
+
+// NewWaitlistService создает новый экземпляр WaitlistService.
+func NewWaitlistService(
+	repo repository.WaitlistRepository,
+	doctorRepo repository.DoctorRepository,
+	patientRepo repository.PatientRepository,
+	rules *BookingRulesService,
+	channel notification.Channel,
+	holdDuration string,
+) (*WaitlistService, error) {
+	duration, err := time.ParseDuration(holdDuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse waitlist hold duration: %w", err)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("waitlist hold duration must be positive")
+	}
+
+	return &WaitlistService{
+		repo:         repo,
+		doctorRepo:   doctorRepo,
+		patientRepo:  patientRepo,
+		rules:        rules,
+		channel:      channel,
+		holdDuration: duration,
+		log:          logger.Default().WithField("module", "waitlist"),
+	}, nil
+}
+
+// CreateEntry ставит пациента в лист ожидания к врачу или на специальность.
+func (s *WaitlistService) CreateEntry(req *models.CreateWaitlistEntryRequest) (*models.WaitlistEntry, error) {
+	specialization := strings.TrimSpace(req.Specialization)
+	if req.DoctorID == nil && specialization == "" {
+		return nil, fmt.Errorf("необходимо указать врача или специальность")
+	}
+	if req.DateTo.Before(req.DateFrom) {
+		return nil, fmt.Errorf("дата окончания периода не может быть раньше даты начала")
+	}
+
+	if _, err := s.patientRepo.GetByID(req.PatientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("пациент с ID %d не найден", req.PatientID)
+		}
+		return nil, fmt.Errorf("ошибка проверки пациента: %w", err)
+	}
+
+	entry := &models.WaitlistEntry{
+		PatientID: req.PatientID,
+		DateFrom:  req.DateFrom,
+		DateTo:    req.DateTo,
+		Status:    models.WaitlistStatusWaiting,
+		Note:      req.Note,
+	}
+
+	if req.DoctorID != nil {
+		if _, err := s.doctorRepo.GetByID(*req.DoctorID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("врач с ID %d не найден", *req.DoctorID)
+			}
+			return nil, fmt.Errorf("ошибка проверки врача: %w", err)
+		}
+		entry.DoctorID = req.DoctorID
+	} else {
+		entry.Specialization = &specialization
+	}
+
+	if err := s.repo.Create(entry); err != nil {
+		return nil, fmt.Errorf("не удалось поставить пациента в лист ожидания: %w", err)
+	}
+
+	return s.repo.GetByID(entry.ID)
+}
+
+// GetEntries возвращает заявки из листа ожидания.
+// Без фильтра возвращаются только активные заявки (ожидающие и с предложенным слотом).
+func (s *WaitlistService) GetEntries(status string) ([]models.WaitlistEntry, error) {
+	var statuses []models.WaitlistStatus
+	switch status {
+	case "":
+		statuses = []models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}
+	case "all":
+	default:
+		statuses = []models.WaitlistStatus{models.WaitlistStatus(status)}
+	}
+
+	entries, err := s.repo.FindByStatuses(statuses)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения листа ожидания: %w", err)
+	}
+	return entries, nil
+}
+
+// OfferSlot предлагает освободившийся слот следующему подходящему пациенту из листа ожидания.
+// Ошибки только логируются, чтобы не мешать основной операции, освободившей слот.
+func (s *WaitlistService) OfferSlot(scheduleID uint) {
+	s.offerSlot(scheduleID, 0)
+}
+
+// ConfirmHold подтверждает удерживаемый слот и создает запись на прием.
+// Слот проверяется по правилам записи в транзакции подтверждения. При нарушении удержание снимается,
+// заявка возвращается в лист ожидания, слот предлагается следующему пациенту и возвращается *BookingRulesError.
+func (s *WaitlistService) ConfirmHold(entryID uint) (*models.Appointment, error) {
+	check := func(tx *gorm.DB, patientID, scheduleID uint) error {
+		return s.rules.Check(tx, patientID, scheduleID, 0)
+	}
+
+	appointment, err := s.repo.ConfirmHold(entryID, check)
+	if err != nil {
+		var rulesErr *BookingRulesError
+		if errors.As(err, &rulesErr) {
+			if releaseErr := s.ReleaseHold(entryID, true); releaseErr != nil {
+				s.log.WithError(releaseErr).WithField("waitlist_id", entryID).Error("Не удалось снять удержание слота, нарушающего правила записи")
+			}
+			return nil, err
+		}
+		return nil, fmt.Errorf("не удалось подтвердить предложенный слот: %w", err)
+	}
+	s.log.WithField("waitlist_id", entryID).WithField("appointment_id", appointment.ID).Info("Слот из листа ожидания подтвержден")
+	return appointment, nil
+}
+
+// ReleaseHold снимает удержание слота. Если keepWaiting, заявка возвращается в лист ожидания,
+// иначе отменяется. Освобожденный слот сразу предлагается следующему пациенту.
+func (s *WaitlistService) ReleaseHold(entryID uint, keepWaiting bool) error {
+	status := models.WaitlistStatusCancelled
+	if keepWaiting {
+		status = models.WaitlistStatusWaiting
+	}
+
+	scheduleID, err := s.repo.ReleaseHold(entryID, status)
+	if err != nil {
+		return fmt.Errorf("не удалось снять удержание слота: %w", err)
+	}
+	s.log.WithField("waitlist_id", entryID).WithField("schedule_id", scheduleID).Info("Удержание слота снято")
+
+	if scheduleID != 0 {
+		s.offerSlot(scheduleID, entryID)
+	}
+	return nil
+}
+
+// CancelEntry отменяет заявку. Если за заявкой удерживается слот, он освобождается.
+func (s *WaitlistService) CancelEntry(entryID uint) error {
+	entry, err := s.repo.GetByID(entryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("заявка в листе ожидания с ID %d не найдена", entryID)
+		}
+		return fmt.Errorf("ошибка поиска заявки: %w", err)
+	}
+
+	switch entry.Status {
+	case models.WaitlistStatusOffered:
+		return s.ReleaseHold(entryID, false)
+	case models.WaitlistStatusWaiting:
+		if err := s.repo.UpdateStatus(entryID, models.WaitlistStatusCancelled); err != nil {
+			return fmt.Errorf("не удалось отменить заявку: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("заявка с ID %d уже закрыта со статусом '%s'", entryID, entry.Status)
+	}
+}
+
+// StartHoldWatcher периодически снимает просроченные удержания и передает слоты дальше по очереди.
+func (s *WaitlistService) StartHoldWatcher(ctx context.Context) {
+	s.log.Info("Наблюдение за удержаниями слотов запущено")
+	ticker := time.NewTicker(waitlistHoldCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.expireHolds()
+		case <-ctx.Done():
+			s.log.Info("Наблюдение за удержаниями слотов остановлено")
+			return
+		}
+	}
+}
+
+// expireHolds снимает просроченные удержания по одному, пока они не закончатся. Каждое удержание забирается
+// в отдельной транзакции с SKIP LOCKED, поэтому задание безопасно выполнять на двух экземплярах одновременно.
+func (s *WaitlistService) expireHolds() {
+	now := time.Now()
+	for {
+		entryID, scheduleID, err := s.repo.ReleaseExpiredHold(now)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				s.log.WithError(err).Error("Не удалось снять просроченное удержание")
+			}
+			return
+		}
+		if scheduleID == 0 {
+			s.log.WithField("waitlist_id", entryID).Info("Удерживаемый слот удален из расписания, заявка возвращена в лист ожидания")
+			continue
+		}
+		s.log.WithField("waitlist_id", entryID).WithField("schedule_id", scheduleID).Info("Удержание слота истекло, заявка возвращена в лист ожидания")
+		s.offerSlot(scheduleID, entryID)
+	}
+}
+
+func (s *WaitlistService) offerSlot(scheduleID uint, excludeEntryID uint) {
+	log := s.log.WithField("schedule_id", scheduleID)
+
+	entry, err := s.repo.HoldSlotForNextEntry(scheduleID, time.Now().Add(s.holdDuration), excludeEntryID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.WithError(err).Error("Ошибка подбора заявки из листа ожидания")
+		}
+		return
+	}
+
+	log.WithField("waitlist_id", entry.ID).WithField("patient_id", entry.PatientID).Info("Слот удерживается для пациента из листа ожидания")
+	s.notifyOffer(entry)
+}
+
+func (s *WaitlistService) notifyOffer(entry *models.WaitlistEntry) {
+	log := s.log.WithField("waitlist_id", entry.ID).WithField("channel", s.channel.Name())
+
+	if entry.Patient.NotificationsOptOut {
+		log.Info("Пациент отказался от уведомлений, предложение не отправлено")
+		return
+	}
+	recipient := notification.Recipient(s.channel, entry.Patient.Phone, entry.Patient.Email)
+	if recipient == "" {
+		log.Warn("У пациента не указан контакт для канала уведомлений, предложение не отправлено")
+		return
+	}
+	if entry.HeldSchedule == nil || entry.HoldExpiresAt == nil {
+		log.Warn("Нет данных об удерживаемом слоте, предложение не отправлено")
+		return
+	}
+
+	schedule := entry.HeldSchedule
+	cabinet := "не указан"
+	if schedule.Cabinet != nil {
+		cabinet = fmt.Sprintf("%d", *schedule.Cabinet)
+	}
+
+	msg := notification.Message{
+		Recipient: recipient,
+		Subject:   "Освободилось время приема",
+		Body: fmt.Sprintf(
+			"Освободилось время приема: %s в %s, врач %s (%s), кабинет %s. Время закреплено за вами до %s. Для подтверждения обратитесь в регистратуру.",
+			schedule.Date.Format("02.01.2006"),
+			formatClock(schedule.StartTime),
+			schedule.Doctor.FullName,
+			schedule.Doctor.Specialization,
+			cabinet,
+			entry.HoldExpiresAt.Format("02.01.2006 15:04"),
+		),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.channel.Send(ctx, msg); err != nil {
+		log.WithError(err).Error("Не удалось отправить предложение из листа ожидания")
+	}
+}
+
+// formatClock сокращает время вида "15:04:05" до "15:04".
+func formatClock(t string) string {
+	if len(t) >= 5 {
+		return t[:5]
+	}
+	return t
+}
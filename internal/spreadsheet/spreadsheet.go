@@ -0,0 +1,102 @@
+// Package spreadsheet читает и записывает табличные файлы (CSV и XLSX) без внешних зависимостей.
+// Поддерживается только первый лист книги XLSX; все значения читаются как строки.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format определяет формат табличного файла.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// FormatFromFilename определяет формат по расширению имени файла.
+func FormatFromFilename(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("неподдерживаемый формат файла %q: ожидается .csv или .xlsx", name)
+	}
+}
+
+// Read читает строки таблицы из файла указанного формата.
+func Read(format Format, data []byte) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(data)
+	case FormatXLSX:
+		return readXLSX(data)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла: %s", format)
+	}
+}
+
+// Write записывает строки таблицы в файл указанного формата.
+func Write(format Format, sheetName string, rows [][]string) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return writeCSV(rows)
+	case FormatXLSX:
+		return writeXLSX(sheetName, rows)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла: %s", format)
+	}
+}
+
+// ContentType возвращает MIME-тип файла указанного формата.
+func ContentType(format Format) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// readCSV читает CSV, сохраненный как с запятой, так и с точкой с запятой (так Excel сохраняет CSV
+// в русской локали). Разделитель определяется по первой строке.
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte{';'}) > bytes.Count(firstLine, []byte{','}) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// writeCSV записывает CSV с точкой с запятой и BOM, чтобы файл корректно открывался в Excel.
+func writeCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(utf8BOM)
+
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("ошибка записи CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,289 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type xlsxSharedStrings struct {
+	Items []xlsxStringItem `xml:"si"`
+}
+
+type xlsxStringItem struct {
+	Text string        `xml:"t"`
+	Runs []xlsxTextRun `xml:"r"`
+}
+
+type xlsxTextRun struct {
+	Text string `xml:"t"`
+}
+
+func (si xlsxStringItem) value() string {
+	if len(si.Runs) == 0 {
+		return si.Text
+	}
+	var b strings.Builder
+	for _, r := range si.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []xlsxRow `xml:"sheetData>row"`
+}
+
+type xlsxRow struct {
+	Index int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref    string          `xml:"r,attr"`
+	Type   string          `xml:"t,attr"`
+	Value  string          `xml:"v"`
+	Inline *xlsxStringItem `xml:"is"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// readXLSX читает первый лист книги. Числа возвращаются в исходном виде (даты Excel — серийными номерами).
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("файл не является книгой XLSX: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, fmt.Errorf("ошибка чтения общих строк XLSX: %w", err)
+		}
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("в книге XLSX не найден лист %s", sheetPath)
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(sheetFile, &sheet); err != nil {
+		return nil, fmt.Errorf("ошибка чтения листа XLSX: %w", err)
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		// Пропущенные пустые строки сохраняем, чтобы номера строк в отчете совпадали с Excel.
+		rowIndex := row.Index
+		if rowIndex == 0 {
+			rowIndex = i + 1
+		}
+		for len(rows) < rowIndex-1 {
+			rows = append(rows, []string{})
+		}
+
+		var values []string
+		for j, cell := range row.Cells {
+			col := j
+			if cell.Ref != "" {
+				if c, err := columnIndex(cell.Ref); err == nil {
+					col = c
+				}
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("некорректная ссылка на строку в ячейке %s", cell.Ref)
+				}
+				value = shared.Items[idx].value()
+			case "inlineStr":
+				if cell.Inline != nil {
+					value = cell.Inline.value()
+				}
+			default:
+				value = cell.Value
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return fallback, nil
+	}
+	var wb xlsxWorkbook
+	if err := decodeZipXML(wbFile, &wb); err != nil {
+		return "", fmt.Errorf("ошибка чтения книги XLSX: %w", err)
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok || len(wb.Sheets) == 0 {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodeZipXML(relsFile, &rels); err != nil {
+		return "", fmt.Errorf("ошибка чтения связей книги XLSX: %w", err)
+	}
+	for _, rel := range rels.Items {
+		if rel.ID == wb.Sheets[0].RelID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+// maxXLSXEntrySize ограничивает распакованный размер одной части XLSX: лимит на размер загрузки
+// не защищает от сильно сжатых архивов.
+const maxXLSXEntrySize = 50 << 20
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxXLSXEntrySize {
+		return fmt.Errorf("часть %s слишком велика: %d байт, допустимо не более %d", f.Name, f.UncompressedSize64, maxXLSXEntrySize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// Размер в заголовке архива может не соответствовать данным, поэтому чтение тоже ограничено.
+	limited := &io.LimitedReader{R: rc, N: maxXLSXEntrySize + 1}
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		if limited.N <= 0 {
+			return fmt.Errorf("часть %s превышает допустимый размер %d байт", f.Name, maxXLSXEntrySize)
+		}
+		return err
+	}
+	return nil
+}
+
+// columnIndex переводит ссылку на ячейку ("C12") в номер столбца с нуля.
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			col = col*26 + int(r-'A'+1)
+			n++
+			continue
+		}
+		break
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("некорректная ссылка на ячейку: %s", ref)
+	}
+	return col - 1, nil
+}
+
+// columnName переводит номер столбца с нуля в буквенное обозначение ("A", "B", ..., "AA").
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// writeXLSX создает минимальную книгу XLSX с одним листом, все значения записываются как строки.
+func writeXLSX(sheetName string, rows [][]string) ([]byte, error) {
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(j), i+1)
+			if err := xml.EscapeText(&sheet, []byte(value)); err != nil {
+				return nil, err
+			}
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var escapedName bytes.Buffer
+	if err := xml.EscapeText(&escapedName, []byte(sheetName)); err != nil {
+		return nil, err
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escapedName.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(w, p.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,101 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"Врач", "Дата", "Начало", "Окончание", "Кабинет"},
+		{"ivanov", "2025-07-20", "09:00", "09:30", "101"},
+		{"Петров Петр Петрович", "20.07.2025", "10:00", "10:30", ""},
+	}
+
+	for _, format := range []Format{FormatCSV, FormatXLSX} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := Write(format, "Расписание", rows)
+			if err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			got, err := Read(format, data)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !reflect.DeepEqual(got, rows) {
+				t.Fatalf("got %q, want %q", got, rows)
+			}
+		})
+	}
+}
+
+func TestReadCSVSemicolon(t *testing.T) {
+	got, err := Read(FormatCSV, []byte("Врач;Дата\nivanov;2025-07-20\n"))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	want := [][]string{{"Врач", "Дата"}, {"ivanov", "2025-07-20"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSXNotZip(t *testing.T) {
+	if _, err := Read(FormatXLSX, []byte("not a zip")); err == nil {
+		t.Fatal("expected error for non-zip data")
+	}
+}
+
+func TestReadXLSXRejectsOversizedEntry(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	// Заголовок заявляет размер больше допустимого; сами данные малы, чтобы тест не расходовал память.
+	payload := []byte(`<worksheet><sheetData></sheetData></worksheet>`)
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "xl/worksheets/sheet1.xml",
+		Method:             zip.Store,
+		CompressedSize64:   uint64(len(payload)),
+		UncompressedSize64: maxXLSXEntrySize + 1,
+	})
+	if err != nil {
+		t.Fatalf("CreateRaw: %v", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	_, err = Read(FormatXLSX, buf.Bytes())
+	if err == nil || !strings.Contains(err.Error(), "слишком велика") {
+		t.Fatalf("expected size error, got %v", err)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want int
+	}{
+		{"A1", 0},
+		{"E12", 4},
+		{"Z3", 25},
+		{"AA7", 26},
+	}
+	for _, tt := range tests {
+		got, err := columnIndex(tt.ref)
+		if err != nil {
+			t.Fatalf("columnIndex(%q): %v", tt.ref, err)
+		}
+		if got != tt.want {
+			t.Errorf("columnIndex(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+		if name := columnName(tt.want); !strings.HasPrefix(tt.ref, name) {
+			t.Errorf("columnName(%d) = %q, want prefix of %q", tt.want, name, tt.ref)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// checkInCodePrefix отличает QR-код записи на прием от других QR-кодов, поднесенных к сканеру киоска.
+const checkInCodePrefix = "EQA1"
+
+// CheckInCodeSigner создает и проверяет коды записи на прием, которые печатаются в виде QR-кода
+// в подтверждении записи. Код подписан HMAC, поэтому номер записи нельзя подобрать перебором.
+type CheckInCodeSigner struct {
+	secret []byte
+}
+
+// NewCheckInCodeSigner создает новый экземпляр CheckInCodeSigner.
+func NewCheckInCodeSigner(secret string) (*CheckInCodeSigner, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("check-in code secret is required")
+	}
+	return &CheckInCodeSigner{secret: []byte(secret)}, nil
+}
+
+// Code возвращает код записи вида "EQA1.<id>.<подпись>".
+func (s *CheckInCodeSigner) Code(appointmentID uint) string {
+	id := strconv.FormatUint(uint64(appointmentID), 10)
+	return checkInCodePrefix + "." + id + "." + s.sign(id)
+}
+
+// Parse проверяет подпись кода и возвращает ID записи на прием.
+func (s *CheckInCodeSigner) Parse(code string) (uint, error) {
+	parts := strings.Split(strings.TrimSpace(code), ".")
+	if len(parts) != 3 || parts[0] != checkInCodePrefix {
+		return 0, fmt.Errorf("код записи не распознан")
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(parts[1]))) {
+		return 0, fmt.Errorf("код записи недействителен")
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("код записи не распознан")
+	}
+	return uint(id), nil
+}
+
+func (s *CheckInCodeSigner) sign(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(checkInCodePrefix + "." + id))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
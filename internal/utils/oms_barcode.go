@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// OMSScan содержит данные, извлеченные из сканированного полиса ОМС.
+type OMSScan struct {
+	Number string
+	// BirthDate заполняется, только если дата рождения есть в штрих-коде полиса единого образца.
+	BirthDate *time.Time
+}
+
+var (
+	omsDigitsRegex  = regexp.MustCompile(`\d{16}`)
+	manualOMSRegex  = regexp.MustCompile(`^[\d\s-]+$`)
+	nonDigitRegex   = regexp.MustCompile(`\D+`)
+	hexPayloadRegex = regexp.MustCompile(`^(?i)[0-9a-f]+$`)
+	omsBarcodeEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.Local)
+)
+
+// Смещения полей в двоичном штрих-коде полиса ОМС единого образца:
+// 1 байт — тип, 8 байт — номер полиса, 51 байт — ФИО, 1 байт — пол,
+// 2 байта — дата рождения в днях от 01.01.1900, далее срок действия и ЭЦП.
+const (
+	omsBarcodeNumberOffset    = 1
+	omsBarcodeBirthDateOffset = 61
+	omsBarcodeMinSize         = omsBarcodeNumberOffset + 8
+)
+
+// ParseOMSScan извлекает номер полиса ОМС из ввода на киоске: номер, набранный вручную
+// (с пробелами или дефисами), или содержимое штрих-кода полиса единого образца,
+// которое сканер передает в шестнадцатеричном виде.
+func ParseOMSScan(raw string) (*OMSScan, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return nil, fmt.Errorf("не указан номер полиса ОМС")
+	}
+
+	if manualOMSRegex.MatchString(value) {
+		if digits := nonDigitRegex.ReplaceAllString(value, ""); len(digits) == 16 {
+			return &OMSScan{Number: digits}, nil
+		}
+	}
+
+	if len(value)%2 == 0 && len(value) >= omsBarcodeMinSize*2 && hexPayloadRegex.MatchString(value) {
+		payload, err := hex.DecodeString(value)
+		if err == nil {
+			return parseOMSBarcode(payload)
+		}
+	}
+
+	if match := omsDigitsRegex.FindString(value); match != "" {
+		return &OMSScan{Number: match}, nil
+	}
+	return nil, fmt.Errorf("не удалось распознать номер полиса ОМС")
+}
+
+func parseOMSBarcode(payload []byte) (*OMSScan, error) {
+	number := binary.BigEndian.Uint64(payload[omsBarcodeNumberOffset : omsBarcodeNumberOffset+8])
+	if number == 0 || number > 9999999999999999 {
+		return nil, fmt.Errorf("штрих-код не содержит номера полиса ОМС")
+	}
+	scan := &OMSScan{Number: fmt.Sprintf("%016d", number)}
+
+	if len(payload) >= omsBarcodeBirthDateOffset+2 {
+		days := binary.BigEndian.Uint16(payload[omsBarcodeBirthDateOffset : omsBarcodeBirthDateOffset+2])
+		if days > 0 {
+			birthDate := omsBarcodeEpoch.AddDate(0, 0, int(days))
+			scan.BirthDate = &birthDate
+		}
+	}
+	return scan, nil
+}
+
+// NormalizePassport приводит серию и номер паспорта к виду "4510" + "123456",
+// принимая ввод с пробелами, дефисами или знаком "№".
+func NormalizePassport(raw string) (series, number string, err error) {
+	digits := nonDigitRegex.ReplaceAllString(raw, "")
+	if len(digits) != 10 {
+		return "", "", fmt.Errorf("серия и номер паспорта должны содержать 10 цифр")
+	}
+	return digits[:4], digits[4:], nil
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPatientAge — возраст, старше которого дата рождения считается ошибочной.
+const maxPatientAge = 130
+
+// NormalizePhone приводит номер телефона к каноническому виду E.164, в котором он хранится в базе.
+// Российские номера (8 900 123-45-67, +7 (900) 123-45-67, 9001234567) приводятся к +79001234567,
+// международные номера со знаком "+" — к "+" и цифрам. Пустая строка возвращается без изменений.
+func NormalizePhone(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", nil
+	}
+	digits := nonDigitRegex.ReplaceAllString(value, "")
+	switch {
+	case len(digits) == 11 && (digits[0] == '7' || digits[0] == '8'):
+		return "+7" + digits[1:], nil
+	case len(digits) == 10:
+		return "+7" + digits, nil
+	case strings.HasPrefix(value, "+") && len(digits) >= 11 && len(digits) <= 15:
+		return "+" + digits, nil
+	}
+	return "", fmt.Errorf("некорректный номер телефона %q: ожидается российский номер из 10–11 цифр или международный в формате +<код страны><номер>", raw)
+}
+
+// ValidateOMS проверяет номер полиса ОМС единого образца: 16 цифр, последняя — контрольная.
+// Контрольная цифра считается по алгоритму ФОМС: цифры первых 15 позиций, стоящие на нечетных местах
+// справа, образуют число, которое умножается на 2; слева к произведению приписываются цифры с четных мест.
+// Контрольная цифра дополняет сумму цифр полученного числа до ближайшего кратного 10.
+func ValidateOMS(number string) error {
+	if len(number) != 16 || nonDigitRegex.MatchString(number) {
+		return fmt.Errorf("некорректный номер полиса ОМС: нужно 16 цифр")
+	}
+	if omsCheckDigit(number[:15]) != number[15] {
+		return fmt.Errorf("некорректный номер полиса ОМС: не сходится контрольная цифра")
+	}
+	return nil
+}
+
+func omsCheckDigit(body string) byte {
+	var odd, even strings.Builder
+	for i := len(body) - 1; i >= 0; i-- {
+		// Позиции считаются справа, начиная с 1.
+		if (len(body)-i)%2 == 1 {
+			odd.WriteByte(body[i])
+		} else {
+			even.WriteByte(body[i])
+		}
+	}
+	oddNumber, _ := strconv.ParseUint(reverse(odd.String()), 10, 64)
+	combined := reverse(even.String()) + strconv.FormatUint(oddNumber*2, 10)
+
+	sum := 0
+	for i := 0; i < len(combined); i++ {
+		sum += int(combined[i] - '0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// ValidatePassport проверяет серию и номер паспорта гражданина РФ: серия — 4 цифры, из которых первые две —
+// код региона (не 00), последние две — год выпуска бланка (с 1997 года); номер — 6 цифр, не ниже 000101.
+func ValidatePassport(series, number string, now time.Time) error {
+	if len(series) != 4 || nonDigitRegex.MatchString(series) {
+		return fmt.Errorf("некорректная серия паспорта: нужно 4 цифры")
+	}
+	if len(number) != 6 || nonDigitRegex.MatchString(number) {
+		return fmt.Errorf("некорректный номер паспорта: нужно 6 цифр")
+	}
+	if series[:2] == "00" {
+		return fmt.Errorf("некорректная серия паспорта: код региона не может быть 00")
+	}
+	blankYear, _ := strconv.Atoi(series[2:])
+	if blankYear < 97 && blankYear > now.Year()%100+1 {
+		return fmt.Errorf("некорректная серия паспорта: год выпуска бланка %s невозможен", series[2:])
+	}
+	if number < "000101" {
+		return fmt.Errorf("некорректный номер паспорта: номера бланков начинаются с 000101")
+	}
+	return nil
+}
+
+// ValidateBirthDate отклоняет пустые, будущие и слишком давние даты рождения.
+func ValidateBirthDate(birthDate, now time.Time) error {
+	if birthDate.IsZero() {
+		return fmt.Errorf("некорректная дата рождения: поле не может быть пустым")
+	}
+	if birthDate.After(now) {
+		return fmt.Errorf("некорректная дата рождения: дата в будущем")
+	}
+	if birthDate.Before(now.AddDate(-maxPatientAge, 0, 0)) {
+		return fmt.Errorf("некорректная дата рождения: возраст больше %d лет", maxPatientAge)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"   ", "", false},
+		{"+7 (900) 123-45-67", "+79001234567", false},
+		{"8 900 123-45-67", "+79001234567", false},
+		{"89001234567", "+79001234567", false},
+		{"79001234567", "+79001234567", false},
+		{"9001234567", "+79001234567", false},
+		{"(900) 123 45 67", "+79001234567", false},
+		{"+79001234567", "+79001234567", false},
+		{"+44 20 7946 0958", "+442079460958", false},
+		{"+1 202 555 0143", "+12025550143", false},
+		{"12345", "", true},
+		{"8900123456789", "", true},
+		{"+1234567890123456", "", true},
+		{"телефон", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizePhone(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestOMSCheckDigit(t *testing.T) {
+	// Ожидаемые значения посчитаны вручную по алгоритму ФОМС.
+	// 123456789012345: нечетные позиции справа — 13579135, ×2 = 27158270;
+	// четные — 2468024; сумма цифр 2468024 и 27158270 равна 58, контрольная цифра 2.
+	tests := []struct {
+		body string
+		want byte
+	}{
+		{"123456789012345", '2'},
+		{"000000000000000", '0'},
+		{"770000000000001", '6'},
+	}
+	for _, tt := range tests {
+		if got := omsCheckDigit(tt.body); got != tt.want {
+			t.Errorf("omsCheckDigit(%q) = %c, want %c", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestValidateOMS(t *testing.T) {
+	tests := []struct {
+		number  string
+		wantErr bool
+	}{
+		{"1234567890123452", false},
+		{"7700000000000016", false},
+		{"1234567890123456", true},
+		{"123456789012345", true},
+		{"12345678901234x2", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateOMS(tt.number); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOMS(%q) error = %v, wantErr %v", tt.number, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidatePassport(t *testing.T) {
+	now := time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		series, number string
+		wantErr        bool
+	}{
+		{"4510", "123456", false},
+		{"4598", "000101", false},
+		{"4526", "123456", false},
+		{"4527", "123456", true},
+		{"0010", "123456", true},
+		{"4510", "000100", true},
+		{"451", "123456", true},
+		{"4510", "12345a", true},
+	}
+	for _, tt := range tests {
+		if err := ValidatePassport(tt.series, tt.number, now); (err != nil) != tt.wantErr {
+			t.Errorf("ValidatePassport(%q, %q) error = %v, wantErr %v", tt.series, tt.number, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBirthDate(t *testing.T) {
+	now := time.Date(2025, 7, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		birthDate time.Time
+		wantErr   bool
+	}{
+		{"valid", time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"today", time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC), false},
+		{"zero", time.Time{}, true},
+		{"future", time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC), true},
+		{"too old", time.Date(1890, 1, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if err := ValidateBirthDate(tt.birthDate, now); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateBirthDate error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// PIICipher шифрует персональные данные пациентов (AES-256-GCM) и вычисляет для них слепые индексы (HMAC-SHA256).
+// Зашифрованное значение имеет вид "k<ID ключа>:<base64(nonce|шифртекст)>", поэтому после смены активного ключа
+// старые значения по-прежнему расшифровываются, а перешифровать нужно только записи с префиксом прежнего ключа.
+type PIICipher struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+	indexKey []byte
+}
+
+// samplePIIKeys — ключи, опубликованные в примере конфигурации. Данные, зашифрованные ими, не защищены,
+// поэтому шифр с такими ключами не создается.
+var samplePIIKeys = map[string]bool{
+	"LNW+PeagJL5Q7sYX9/C5Psx7j1dfPvQmkK2yOQUZZH0=": true,
+	"xFK2bsvK3XBe3XnDL8krTqdMcpgV/cVNojdBwZZ+eTA=": true,
+}
+
+// NewPIICipher создает шифр из списка ключей вида "1:<base64>,2:<base64>" (ключи по 32 байта),
+// ID активного ключа, которым шифруются новые значения, и ключа слепого индекса (base64, не короче 32 байт).
+// Ключ слепого индекса не ротируется: от него зависят поиск и уникальность паспорта.
+func NewPIICipher(keysSpec, activeID, indexKey string) (*PIICipher, error) {
+	c := &PIICipher{keys: make(map[string]cipher.AEAD), activeID: strings.TrimSpace(activeID)}
+
+	for _, entry := range strings.Split(keysSpec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("ключ шифрования должен иметь вид <ID>:<base64>, получено %q", entry)
+		}
+		if samplePIIKeys[encoded] {
+			return nil, fmt.Errorf("ключ шифрования %s взят из примера конфигурации, сгенерируйте новый: openssl rand -base64 32", id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("ключ шифрования %s должен содержать 32 байта в base64", id)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[id] = aead
+	}
+	if len(c.keys) == 0 {
+		return nil, fmt.Errorf("не задано ни одного ключа шифрования персональных данных")
+	}
+	if _, ok := c.keys[c.activeID]; !ok {
+		return nil, fmt.Errorf("активный ключ шифрования %q отсутствует в списке ключей", c.activeID)
+	}
+
+	indexKey = strings.TrimSpace(indexKey)
+	if samplePIIKeys[indexKey] {
+		return nil, fmt.Errorf("ключ слепого индекса взят из примера конфигурации, сгенерируйте новый: openssl rand -base64 32")
+	}
+	index, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil || len(index) < 32 {
+		return nil, fmt.Errorf("ключ слепого индекса должен содержать не менее 32 байт в base64")
+	}
+	c.indexKey = index
+	return c, nil
+}
+
+// ActivePrefix возвращает префикс значений, зашифрованных активным ключом.
+func (c *PIICipher) ActivePrefix() string {
+	return "k" + c.activeID + ":"
+}
+
+// Encrypt шифрует значение активным ключом. Пустая строка не шифруется.
+func (c *PIICipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	aead := c.keys[c.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("ошибка генерации nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return c.ActivePrefix() + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает значение ключом, указанным в его префиксе.
+func (c *PIICipher) Decrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	prefix, encoded, ok := strings.Cut(value, ":")
+	if !ok || !strings.HasPrefix(prefix, "k") {
+		return "", fmt.Errorf("неизвестный формат зашифрованного значения")
+	}
+	aead, ok := c.keys[strings.TrimPrefix(prefix, "k")]
+	if !ok {
+		return "", fmt.Errorf("ключ шифрования %s не найден", strings.TrimPrefix(prefix, "k"))
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("поврежденное зашифрованное значение")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("не удалось расшифровать значение: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex возвращает детерминированный HMAC значения для точного поиска по зашифрованному полю.
+// domain разделяет индексы разных полей, чтобы одинаковые цифры паспорта и полиса не совпадали.
+func (c *PIICipher) BlindIndex(domain, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(domain))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
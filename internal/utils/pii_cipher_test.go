@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testPIIKey(t *testing.T) string {
+	t.Helper()
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatalf("rand: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestPIICipherRoundTrip(t *testing.T) {
+	c, err := NewPIICipher("1:"+testPIIKey(t), "1", testPIIKey(t))
+	if err != nil {
+		t.Fatalf("NewPIICipher: %v", err)
+	}
+
+	for _, plaintext := range []string{"4510123456", "1234567890123452", "Иванов"} {
+		encrypted, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q): %v", plaintext, err)
+		}
+		if !strings.HasPrefix(encrypted, "k1:") || strings.Contains(encrypted, plaintext) {
+			t.Fatalf("Encrypt(%q) = %q: expected k1: prefix and no plaintext", plaintext, encrypted)
+		}
+		again, _ := c.Encrypt(plaintext)
+		if again == encrypted {
+			t.Errorf("Encrypt(%q) is deterministic, nonce must be random", plaintext)
+		}
+		decrypted, err := c.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		if decrypted != plaintext {
+			t.Errorf("Decrypt = %q, want %q", decrypted, plaintext)
+		}
+	}
+
+	if encrypted, err := c.Encrypt(""); err != nil || encrypted != "" {
+		t.Errorf("Encrypt(\"\") = %q, %v; want empty", encrypted, err)
+	}
+	if decrypted, err := c.Decrypt(""); err != nil || decrypted != "" {
+		t.Errorf("Decrypt(\"\") = %q, %v; want empty", decrypted, err)
+	}
+}
+
+func TestPIICipherRotation(t *testing.T) {
+	oldKey, newKey, indexKey := testPIIKey(t), testPIIKey(t), testPIIKey(t)
+
+	before, err := NewPIICipher("1:"+oldKey, "1", indexKey)
+	if err != nil {
+		t.Fatalf("NewPIICipher: %v", err)
+	}
+	encrypted, err := before.Encrypt("4510123456")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	after, err := NewPIICipher("1:"+oldKey+", 2:"+newKey, "2", indexKey)
+	if err != nil {
+		t.Fatalf("NewPIICipher after rotation: %v", err)
+	}
+	if after.ActivePrefix() != "k2:" || strings.HasPrefix(encrypted, after.ActivePrefix()) {
+		t.Fatalf("old value %q must not carry the new active prefix %q", encrypted, after.ActivePrefix())
+	}
+	decrypted, err := after.Decrypt(encrypted)
+	if err != nil || decrypted != "4510123456" {
+		t.Fatalf("Decrypt old value after rotation = %q, %v", decrypted, err)
+	}
+	reencrypted, err := after.Encrypt(decrypted)
+	if err != nil || !strings.HasPrefix(reencrypted, "k2:") {
+		t.Fatalf("Encrypt after rotation = %q, %v; want k2: prefix", reencrypted, err)
+	}
+
+	// Слепой индекс зависит только от ключа индекса и не меняется при ротации.
+	if before.BlindIndex("passport", "4510123456") != after.BlindIndex("passport", "4510123456") {
+		t.Error("blind index changed after key rotation")
+	}
+
+	// Без прежнего ключа старые значения не расшифровываются.
+	withoutOld, err := NewPIICipher("2:"+newKey, "2", indexKey)
+	if err != nil {
+		t.Fatalf("NewPIICipher: %v", err)
+	}
+	if _, err := withoutOld.Decrypt(encrypted); err == nil {
+		t.Error("expected error decrypting with a removed key")
+	}
+}
+
+func TestPIICipherDecryptRejectsTampering(t *testing.T) {
+	c, err := NewPIICipher("1:"+testPIIKey(t), "1", testPIIKey(t))
+	if err != nil {
+		t.Fatalf("NewPIICipher: %v", err)
+	}
+	encrypted, _ := c.Encrypt("4510123456")
+	raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, "k1:"))
+	raw[len(raw)-1] ^= 0xff
+
+	for _, value := range []string{
+		"k1:" + base64.StdEncoding.EncodeToString(raw),
+		"k1:not-base64",
+		"k1:" + base64.StdEncoding.EncodeToString([]byte("short")),
+		"4510123456",
+	} {
+		if _, err := c.Decrypt(value); err == nil {
+			t.Errorf("Decrypt(%q): expected error", value)
+		}
+	}
+}
+
+func TestPIICipherBlindIndex(t *testing.T) {
+	c, err := NewPIICipher("1:"+testPIIKey(t), "1", testPIIKey(t))
+	if err != nil {
+		t.Fatalf("NewPIICipher: %v", err)
+	}
+	a := c.BlindIndex("oms", "1234567890123452")
+	if a != c.BlindIndex("oms", "1234567890123452") {
+		t.Error("blind index must be deterministic")
+	}
+	if a == c.BlindIndex("passport", "1234567890123452") {
+		t.Error("blind indexes of different domains must differ")
+	}
+	if c.BlindIndex("oms", "") != "" {
+		t.Error("blind index of an empty value must be empty")
+	}
+}
+
+func TestNewPIICipherRejectsInvalidConfig(t *testing.T) {
+	key, index := testPIIKey(t), testPIIKey(t)
+	short := base64.StdEncoding.EncodeToString([]byte("too short"))
+
+	tests := []struct {
+		name              string
+		keys, active, idx string
+		wantErr           string
+	}{
+		{"no keys", "", "1", index, "ни одного ключа"},
+		{"missing id", ":" + key, "1", index, "<ID>:<base64>"},
+		{"short key", "1:" + short, "1", index, "32 байта"},
+		{"unknown active", "1:" + key, "2", index, "отсутствует в списке"},
+		{"short index", "1:" + key, "1", short, "не менее 32 байт"},
+		{"sample key", "1:LNW+PeagJL5Q7sYX9/C5Psx7j1dfPvQmkK2yOQUZZH0=", "1", index, "openssl rand -base64 32"},
+		{"sample index", "1:" + key, "1", "xFK2bsvK3XBe3XnDL8krTqdMcpgV/cVNojdBwZZ+eTA=", "openssl rand -base64 32"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPIICipher(tt.keys, tt.active, tt.idx)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewPIICipher error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Форматы отображения ФИО пациента на общедоступных табло.
+const (
+	PublicNameSurnameInitial  = "surname_initial"  // Иванов И.
+	PublicNameSurnameInitials = "surname_initials" // Иванов И. П.
+	PublicNameInitials        = "initials"         // И. И. П.
+	PublicNameHidden          = "none"             // только номер талона
+)
+
+// FormatPublicName приводит ФИО к виду, допустимому для показа на табло. Неизвестный формат
+// обрабатывается как PublicNameSurnameInitial.
+func FormatPublicName(fullName, format string) string {
+	parts := strings.Fields(fullName)
+	if len(parts) == 0 || format == PublicNameHidden {
+		return ""
+	}
+	initial := func(word string) string {
+		r, _ := utf8.DecodeRuneInString(word)
+		return string(r) + "."
+	}
+
+	switch format {
+	case PublicNameInitials:
+		initials := make([]string, 0, len(parts))
+		for _, p := range parts {
+			initials = append(initials, initial(p))
+		}
+		return strings.Join(initials, " ")
+	case PublicNameSurnameInitials:
+		result := []string{parts[0]}
+		for _, p := range parts[1:] {
+			result = append(result, initial(p))
+		}
+		return strings.Join(result, " ")
+	default:
+		if len(parts) == 1 {
+			return parts[0]
+		}
+		return parts[0] + " " + initial(parts[1])
+	}
+}
+
+// MaskTail заменяет звездочками все символы, кроме последних visible.
+func MaskTail(value string, visible int) string {
+	runes := []rune(value)
+	if len(runes) <= visible {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+// MaskPassport оставляет код региона в серии и последние три цифры номера: "45**", "***456".
+func MaskPassport(series, number string) (string, string) {
+	maskedSeries := MaskTail(series, 0)
+	if len(series) == 4 {
+		maskedSeries = series[:2] + "**"
+	}
+	return maskedSeries, MaskTail(number, 3)
+}
+
+// MaskPhone оставляет код страны и последние четыре цифры: "+7******4567".
+func MaskPhone(phone string) string {
+	if strings.HasPrefix(phone, "+7") {
+		return "+7" + MaskTail(phone[2:], 4)
+	}
+	return MaskTail(phone, 4)
+}
+
+// MaskEmail оставляет первую букву имени ящика и домен: "i***@mail.ru".
+func MaskEmail(email string) string {
+	name, domain, ok := strings.Cut(email, "@")
+	if !ok || name == "" {
+		return MaskTail(email, 0)
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	return string(r) + "***@" + domain
+}
@@ -0,0 +1,80 @@
+package utils
+
+import "testing"
+
+func TestFormatPublicName(t *testing.T) {
+	tests := []struct {
+		name, format, want string
+	}{
+		{"Иванов Иван Петрович", PublicNameSurnameInitial, "Иванов И."},
+		{"Иванов Иван Петрович", PublicNameSurnameInitials, "Иванов И. П."},
+		{"Иванов Иван Петрович", PublicNameInitials, "И. И. П."},
+		{"Иванов Иван Петрович", PublicNameHidden, ""},
+		{"Иванов Иван Петрович", "unknown", "Иванов И."},
+		{"  Иванов   Иван ", PublicNameSurnameInitial, "Иванов И."},
+		{"Иванов", PublicNameSurnameInitial, "Иванов"},
+		{"", PublicNameSurnameInitial, ""},
+	}
+	for _, tt := range tests {
+		if got := FormatPublicName(tt.name, tt.format); got != tt.want {
+			t.Errorf("FormatPublicName(%q, %q) = %q, want %q", tt.name, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestMaskTail(t *testing.T) {
+	tests := []struct {
+		value   string
+		visible int
+		want    string
+	}{
+		{"1234567890123452", 4, "************3452"},
+		{"123456", 3, "***456"},
+		{"12", 4, "**"},
+		{"", 4, ""},
+		{"абвгд", 2, "***гд"},
+	}
+	for _, tt := range tests {
+		if got := MaskTail(tt.value, tt.visible); got != tt.want {
+			t.Errorf("MaskTail(%q, %d) = %q, want %q", tt.value, tt.visible, got, tt.want)
+		}
+	}
+}
+
+func TestMaskPassport(t *testing.T) {
+	series, number := MaskPassport("4510", "123456")
+	if series != "45**" || number != "***456" {
+		t.Errorf("MaskPassport = %q, %q; want 45**, ***456", series, number)
+	}
+	series, number = MaskPassport("451", "")
+	if series != "***" || number != "" {
+		t.Errorf("MaskPassport(short) = %q, %q; want ***, empty", series, number)
+	}
+}
+
+func TestMaskPhone(t *testing.T) {
+	tests := []struct{ phone, want string }{
+		{"+79001234567", "+7******4567"},
+		{"+442079460958", "*********0958"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MaskPhone(tt.phone); got != tt.want {
+			t.Errorf("MaskPhone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct{ email, want string }{
+		{"ivanov@mail.ru", "i***@mail.ru"},
+		{"иван@почта.рф", "и***@почта.рф"},
+		{"@mail.ru", "********"},
+		{"no-at-sign", "**********"},
+	}
+	for _, tt := range tests {
+		if got := MaskEmail(tt.email); got != tt.want {
+			t.Errorf("MaskEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
@@ -30,6 +30,8 @@ type TicketConfig struct {
 	TicketNumber   string
 	DateTime       time.Time
 	WaitingNumber  int
+	// Footer — надпись внизу талона вместо числа ожидающих (например, кабинет и время приема).
+	Footer string
 }
 
 // resizeImage масштабирует изображение с сохранением пропорций и заполнением фона
@@ -343,11 +345,16 @@ func GenerateTicketImage(config TicketConfig, isColor bool) ([]byte, error) {
 	// Накладываем QR-код на изображение
 	draw.Draw(img, qrRect, qrImg, image.Point{}, draw.Over)
 
-	// Добавляем надпись о количестве ожидающих (в самом конце, посередине)
-	if config.WaitingNumber > 0 {
+	// Добавляем надпись о количестве ожидающих или заданную подпись (в самом конце, посередине)
+	queueText := ""
+	if config.Footer != "" {
+		queueText = strings.ToUpper(config.Footer)
+	} else if config.WaitingNumber > 0 {
+		queueText = strings.ToUpper(fmt.Sprintf("Перед вами %d человек в очереди", config.WaitingNumber))
+	}
+	if queueText != "" {
 		c.SetFont(ttfFont)
 		c.SetFontSize(WaitingSize)
-		queueText := strings.ToUpper(fmt.Sprintf("Перед вами %d человек в очереди", config.WaitingNumber))
 
 		// Точный расчет центрирования
 		face := truetype.NewFace(ttfFont, &truetype.Options{
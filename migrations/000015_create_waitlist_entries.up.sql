@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    waitlist_id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(patient_id) ON DELETE CASCADE,
+    doctor_id INTEGER REFERENCES doctors(doctor_id) ON DELETE CASCADE,
+    specialization VARCHAR(100),
+    date_from DATE NOT NULL,
+    date_to DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'ожидает' CHECK (status IN (
+        'ожидает',
+        'предложено',
+        'подтверждено',
+        'отменено'
+    )),
+    note TEXT,
+    held_schedule_id INTEGER REFERENCES schedules(schedule_id) ON DELETE SET NULL,
+    hold_expires_at TIMESTAMPTZ,
+    offered_at TIMESTAMPTZ,
+    appointment_id INTEGER REFERENCES appointments(appointment_id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT check_waitlist_target CHECK (doctor_id IS NOT NULL OR specialization IS NOT NULL),
+    CONSTRAINT check_waitlist_window CHECK (date_from <= date_to)
+);
+
+-- Индекс для подбора очередной заявки при освобождении слота
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_waiting ON waitlist_entries (created_at) WHERE status = 'ожидает';
+
+-- Индекс для поиска просроченных удержаний слотов
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_holds ON waitlist_entries (hold_expires_at) WHERE status = 'предложено';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS notification_outbox;
+
+ALTER TABLE patients
+    DROP COLUMN IF EXISTS email,
+    DROP COLUMN IF EXISTS notifications_opt_out;
@@ -0,0 +1,38 @@
+ALTER TABLE patients
+    ADD COLUMN IF NOT EXISTS email VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS notifications_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    notification_id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(patient_id) ON DELETE CASCADE,
+    appointment_id INTEGER, -- Без внешнего ключа: запись об отмене переживает удаленную запись на прием
+    schedule_id INTEGER, -- Слот, к которому относится уведомление; без внешнего ключа по той же причине
+    event VARCHAR(32) NOT NULL CHECK (event IN (
+        'appointment_created',
+        'appointment_changed',
+        'appointment_cancelled',
+        'appointment_reminder'
+    )),
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'ожидает' CHECK (status IN (
+        'ожидает',
+        'отправлено',
+        'ошибка',
+        'отменено',
+        'заменено'
+    )),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+-- Индекс для выборки уведомлений, готовых к отправке
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due ON notification_outbox (next_attempt_at) WHERE status = 'ожидает';
+
+-- Не более одного действующего напоминания на запись в конкретном слоте.
+-- При переносе записи прежние напоминания помечаются как замененные и не мешают новому.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_outbox_reminder ON notification_outbox (appointment_id, schedule_id)
+    WHERE event = 'appointment_reminder' AND status NOT IN ('отменено', 'заменено');
@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS appointments_calendar_sync_trigger ON appointments;
+DROP FUNCTION IF EXISTS sync_appointment_calendar();
+DROP TRIGGER IF EXISTS appointments_calendar_sequence_trigger ON appointments;
+DROP FUNCTION IF EXISTS bump_appointment_calendar_sequence();
+DROP TRIGGER IF EXISTS schedules_appointments_calendar_trigger ON schedules;
+DROP FUNCTION IF EXISTS bump_slot_appointments_calendar_sequence();
+DROP TRIGGER IF EXISTS schedules_calendar_tombstone_trigger ON schedules;
+DROP FUNCTION IF EXISTS tombstone_schedule();
+DROP TRIGGER IF EXISTS schedules_calendar_sequence_trigger ON schedules;
+DROP FUNCTION IF EXISTS bump_schedule_calendar_sequence();
+DROP TABLE IF EXISTS calendar_tombstones;
+ALTER TABLE appointments DROP COLUMN IF EXISTS calendar_sequence;
+ALTER TABLE schedules DROP COLUMN IF EXISTS calendar_sequence;
+ALTER TABLE doctors DROP COLUMN IF EXISTS calendar_token;
@@ -0,0 +1,130 @@
+-- Подавляем вывод NOTICE-сообщений, например, при удалении несуществующего триггера
+SET client_min_messages TO warning;
+
+-- Персональный токен врача для подписки на календарь (.ics)
+ALTER TABLE doctors ADD COLUMN IF NOT EXISTS calendar_token VARCHAR(64) UNIQUE;
+
+-- Номера версий (SEQUENCE) событий календаря: растут при каждом изменении слота или записи
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS calendar_sequence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS calendar_sequence INTEGER NOT NULL DEFAULT 0;
+
+-- Отмененные события календаря. Нужны, чтобы после удаления слота или записи
+-- календари получили отмену с тем же UID и увеличенным SEQUENCE.
+CREATE TABLE IF NOT EXISTS calendar_tombstones (
+    uid VARCHAR(128) PRIMARY KEY,
+    doctor_id INTEGER REFERENCES doctors(doctor_id) ON DELETE CASCADE,
+    appointment_id INTEGER,
+    patient_id INTEGER REFERENCES patients(patient_id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    sequence INTEGER NOT NULL,
+    cancelled_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_tombstones_doctor ON calendar_tombstones (doctor_id, starts_at);
+
+-- Увеличивает версию слота при изменении видимых в календаре полей
+CREATE OR REPLACE FUNCTION bump_schedule_calendar_sequence() RETURNS TRIGGER AS $$
+BEGIN
+    IF (OLD.date, OLD.start_time, OLD.end_time, OLD.cabinet, OLD.is_available)
+        IS DISTINCT FROM (NEW.date, NEW.start_time, NEW.end_time, NEW.cabinet, NEW.is_available) THEN
+        NEW.calendar_sequence := OLD.calendar_sequence + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS schedules_calendar_sequence_trigger ON schedules;
+CREATE TRIGGER schedules_calendar_sequence_trigger
+BEFORE UPDATE ON schedules
+FOR EACH ROW EXECUTE FUNCTION bump_schedule_calendar_sequence();
+
+-- При переносе слота на другое время или в другой кабинет меняются и события записей в нем
+CREATE OR REPLACE FUNCTION bump_slot_appointments_calendar_sequence() RETURNS TRIGGER AS $$
+BEGIN
+    IF (OLD.date, OLD.start_time, OLD.end_time, OLD.cabinet)
+        IS DISTINCT FROM (NEW.date, NEW.start_time, NEW.end_time, NEW.cabinet) THEN
+        UPDATE appointments SET calendar_sequence = calendar_sequence + 1 WHERE schedule_id = NEW.schedule_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS schedules_appointments_calendar_trigger ON schedules;
+CREATE TRIGGER schedules_appointments_calendar_trigger
+AFTER UPDATE ON schedules
+FOR EACH ROW EXECUTE FUNCTION bump_slot_appointments_calendar_sequence();
+
+-- Сохраняет отмену удаленного слота и записей в нем. Время берется из OLD:
+-- после удаления слота найти его в schedules уже нельзя.
+CREATE OR REPLACE FUNCTION tombstone_schedule() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO calendar_tombstones (uid, doctor_id, starts_at, ends_at, sequence)
+    VALUES ('schedule-' || OLD.schedule_id, OLD.doctor_id, OLD.date + OLD.start_time, OLD.date + OLD.end_time, OLD.calendar_sequence + 1)
+    ON CONFLICT (uid) DO UPDATE SET sequence = EXCLUDED.sequence, cancelled_at = NOW();
+
+    INSERT INTO calendar_tombstones (uid, appointment_id, patient_id, starts_at, ends_at, sequence)
+    SELECT 'appointment-' || a.appointment_id, a.appointment_id, a.patient_id, OLD.date + OLD.start_time, OLD.date + OLD.end_time, a.calendar_sequence + 1
+    FROM appointments a
+    WHERE a.schedule_id = OLD.schedule_id
+    ON CONFLICT (uid) DO UPDATE SET sequence = EXCLUDED.sequence, cancelled_at = NOW();
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS schedules_calendar_tombstone_trigger ON schedules;
+CREATE TRIGGER schedules_calendar_tombstone_trigger
+BEFORE DELETE ON schedules
+FOR EACH ROW EXECUTE FUNCTION tombstone_schedule();
+
+-- Увеличивает версию записи при переносе в другой слот
+CREATE OR REPLACE FUNCTION bump_appointment_calendar_sequence() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.schedule_id IS DISTINCT FROM NEW.schedule_id THEN
+        NEW.calendar_sequence := OLD.calendar_sequence + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS appointments_calendar_sequence_trigger ON appointments;
+CREATE TRIGGER appointments_calendar_sequence_trigger
+BEFORE UPDATE ON appointments
+FOR EACH ROW EXECUTE FUNCTION bump_appointment_calendar_sequence();
+
+-- При появлении, переносе или удалении записи меняется содержимое события слота в календаре врача,
+-- а удаленная запись сохраняется как отмена для календаря пациента
+CREATE OR REPLACE FUNCTION sync_appointment_calendar() RETURNS TRIGGER AS $$
+DECLARE
+    slot RECORD;
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        UPDATE schedules SET calendar_sequence = calendar_sequence + 1 WHERE schedule_id = NEW.schedule_id;
+        RETURN NEW;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        IF OLD.schedule_id IS DISTINCT FROM NEW.schedule_id OR OLD.patient_id IS DISTINCT FROM NEW.patient_id THEN
+            UPDATE schedules SET calendar_sequence = calendar_sequence + 1 WHERE schedule_id IN (OLD.schedule_id, NEW.schedule_id);
+        END IF;
+        RETURN NEW;
+    END IF;
+
+    -- Время отмены берется из слота, версия которого увеличивается. Если слот удаляется вместе
+    -- с записью, отмену записи уже сохранил tombstone_schedule.
+    UPDATE schedules SET calendar_sequence = calendar_sequence + 1 WHERE schedule_id = OLD.schedule_id
+    RETURNING date, start_time, end_time INTO slot;
+    IF FOUND THEN
+        INSERT INTO calendar_tombstones (uid, appointment_id, patient_id, starts_at, ends_at, sequence)
+        VALUES ('appointment-' || OLD.appointment_id, OLD.appointment_id, OLD.patient_id, slot.date + slot.start_time, slot.date + slot.end_time, OLD.calendar_sequence + 1)
+        ON CONFLICT (uid) DO UPDATE SET sequence = EXCLUDED.sequence, cancelled_at = NOW();
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS appointments_calendar_sync_trigger ON appointments;
+CREATE TRIGGER appointments_calendar_sync_trigger
+AFTER INSERT OR UPDATE OR DELETE ON appointments
+FOR EACH ROW EXECUTE FUNCTION sync_appointment_calendar();
+
+-- Возвращаем уровень сообщений по умолчанию
+RESET client_min_messages;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS checkin_logs;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS is_late;
@@ -0,0 +1,18 @@
+-- Флаг опоздания: талон пациента, пришедшего позже окна регистрации, направляется в регистратуру
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS is_late BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Журнал регистраций по записи через киоск для отчетности по опозданиям и ранним приходам
+CREATE TABLE IF NOT EXISTS checkin_logs (
+    checkin_log_id SERIAL PRIMARY KEY,
+    appointment_id INTEGER REFERENCES appointments(appointment_id) ON DELETE SET NULL,
+    patient_id INTEGER REFERENCES patients(patient_id) ON DELETE SET NULL,
+    ticket_id INTEGER REFERENCES tickets(ticket_id) ON DELETE SET NULL,
+    method VARCHAR(20) NOT NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('вовремя', 'рано', 'опоздание')),
+    slot_start TIMESTAMP NOT NULL,
+    offset_minutes INTEGER NOT NULL, -- Отрицательное значение: пришел раньше начала слота
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkin_logs_created_at ON checkin_logs (created_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS direct_routing_rules;
@@ -0,0 +1,15 @@
+-- Правила прямой маршрутизации: при регистрации по записи через киоск талон сразу получает статус
+-- 'зарегистрирован' и попадает в очередь кабинета врача, минуя регистратуру.
+-- Правило задается либо для услуги терминала, либо для специальности врача.
+CREATE TABLE IF NOT EXISTS direct_routing_rules (
+    rule_id SERIAL PRIMARY KEY,
+    service_id VARCHAR(64) REFERENCES services(service_id) ON DELETE CASCADE,
+    specialization VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT direct_routing_rules_target_check CHECK ((service_id IS NULL) <> (specialization IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_direct_routing_rules_service
+    ON direct_routing_rules (service_id) WHERE service_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_direct_routing_rules_specialization
+    ON direct_routing_rules (specialization) WHERE specialization IS NOT NULL;
@@ -0,0 +1,76 @@
+CREATE OR REPLACE FUNCTION bump_schedule_calendar_sequence() RETURNS TRIGGER AS $$
+BEGIN
+    IF (OLD.date, OLD.start_time, OLD.end_time, OLD.cabinet, OLD.is_available)
+        IS DISTINCT FROM (NEW.date, NEW.start_time, NEW.end_time, NEW.cabinet, NEW.is_available) THEN
+        NEW.calendar_sequence := OLD.calendar_sequence + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_schedule_change() RETURNS TRIGGER AS $$
+DECLARE
+    payload JSONB;
+    data_row RECORD;
+    doctor_info RECORD;
+    operation_text TEXT;
+BEGIN
+    operation_text := TG_OP;
+
+    IF (operation_text = 'DELETE') THEN
+        data_row := OLD;
+    ELSE
+        data_row := NEW;
+    END IF;
+
+    SELECT doctor_id, full_name, specialization
+    INTO doctor_info
+    FROM doctors
+    WHERE doctor_id = data_row.doctor_id;
+
+    IF NOT FOUND THEN
+        IF (operation_text = 'DELETE') THEN
+            RETURN OLD;
+        ELSE
+            RETURN NEW;
+        END IF;
+    END IF;
+
+    payload := jsonb_build_object(
+        'operation', lower(operation_text),
+        'data', jsonb_build_object(
+            'date', to_char(data_row.date, 'YYYY-MM-DD'),
+            'doctors', jsonb_build_array(
+                jsonb_build_object(
+                    'id', doctor_info.doctor_id,
+                    'full_name', doctor_info.full_name,
+                    'specialization', doctor_info.specialization,
+                    'slots', jsonb_build_array(
+                        jsonb_build_object(
+                            'start_time', to_char(data_row.start_time, 'HH24:MI:SS'),
+                            'end_time', to_char(data_row.end_time, 'HH24:MI:SS'),
+                            'is_available', data_row.is_available,
+                            'cabinet', data_row.cabinet
+                        )
+                    )
+                )
+            )
+        )
+    );
+
+    PERFORM pg_notify('schedule_update', payload::text);
+
+    IF (operation_text = 'DELETE') THEN
+        RETURN OLD;
+    ELSE
+        RETURN NEW;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_appointments_schedule_patient;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_booked_count_check;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_capacity_check;
+ALTER TABLE schedules DROP COLUMN IF EXISTS is_blocked;
+ALTER TABLE schedules DROP COLUMN IF EXISTS booked_count;
+ALTER TABLE schedules DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,115 @@
+-- Подавляем вывод NOTICE-сообщений, например, при удалении несуществующего триггера
+SET client_min_messages TO warning;
+
+-- Вместимость слота (групповые процедуры: прививки, забор крови, групповая терапия)
+-- и число занятых мест. is_available по-прежнему означает, что в слоте можно записаться,
+-- и вычисляется как booked_count < capacity AND NOT is_blocked.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS capacity INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS booked_count INTEGER NOT NULL DEFAULT 0;
+
+-- Блокировка слота администратором хранится отдельно, чтобы освобождение места не снимало ее
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS is_blocked BOOLEAN NOT NULL DEFAULT FALSE;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'schedules_capacity_check') THEN
+        ALTER TABLE schedules ADD CONSTRAINT schedules_capacity_check CHECK (capacity >= 1);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'schedules_booked_count_check') THEN
+        ALTER TABLE schedules ADD CONSTRAINT schedules_booked_count_check CHECK (booked_count >= 0 AND booked_count <= capacity);
+    END IF;
+END $$;
+
+-- Заполняем число занятых мест по существующим записям и удержаниям листа ожидания
+UPDATE schedules s SET booked_count = LEAST(s.capacity,
+    (SELECT COUNT(*) FROM appointments a WHERE a.schedule_id = s.schedule_id)
+    + (SELECT COUNT(*) FROM waitlist_entries w WHERE w.held_schedule_id = s.schedule_id AND w.status = 'предложено'));
+
+-- Недоступный слот со свободными местами до этой миграции мог быть закрыт только администратором
+UPDATE schedules SET is_blocked = TRUE WHERE NOT is_available AND booked_count < capacity;
+UPDATE schedules SET is_available = (booked_count < capacity AND NOT is_blocked);
+
+-- Один пациент не может занять несколько мест в одном слоте
+CREATE UNIQUE INDEX IF NOT EXISTS idx_appointments_schedule_patient ON appointments (schedule_id, patient_id) WHERE patient_id IS NOT NULL;
+
+-- Уведомление об изменении слота дополняется вместимостью и числом занятых мест
+CREATE OR REPLACE FUNCTION notify_schedule_change() RETURNS TRIGGER AS $$
+DECLARE
+    payload JSONB;
+    data_row RECORD;
+    doctor_info RECORD;
+    operation_text TEXT;
+BEGIN
+    operation_text := TG_OP;
+
+    -- Определяем, какую строку использовать: старую (при удалении) или новую
+    IF (operation_text = 'DELETE') THEN
+        data_row := OLD;
+    ELSE
+        data_row := NEW;
+    END IF;
+
+    -- Получаем информацию о враче
+    SELECT doctor_id, full_name, specialization
+    INTO doctor_info
+    FROM doctors
+    WHERE doctor_id = data_row.doctor_id;
+
+    -- Если врач не найден, ничего не делаем
+    IF NOT FOUND THEN
+        IF (operation_text = 'DELETE') THEN
+            RETURN OLD;
+        ELSE
+            RETURN NEW;
+        END IF;
+    END IF;
+
+    -- Формируем сложный JSON объект, который ожидает фронтенд
+    payload := jsonb_build_object(
+        'operation', lower(operation_text),
+        'data', jsonb_build_object(
+            'date', to_char(data_row.date, 'YYYY-MM-DD'),
+            'doctors', jsonb_build_array(
+                jsonb_build_object(
+                    'id', doctor_info.doctor_id,
+                    'full_name', doctor_info.full_name,
+                    'specialization', doctor_info.specialization,
+                    'slots', jsonb_build_array(
+                        jsonb_build_object(
+                            'start_time', to_char(data_row.start_time, 'HH24:MI:SS'),
+                            'end_time', to_char(data_row.end_time, 'HH24:MI:SS'),
+                            'is_available', data_row.is_available,
+                            'cabinet', data_row.cabinet,
+                            'capacity', data_row.capacity,
+                            'booked_count', data_row.booked_count
+                        )
+                    )
+                )
+            )
+        )
+    );
+
+    -- Отправляем уведомление на канал 'schedule_update'
+    PERFORM pg_notify('schedule_update', payload::text);
+
+    IF (operation_text = 'DELETE') THEN
+        RETURN OLD;
+    ELSE
+        RETURN NEW;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Версия события календаря меняется и при изменении вместимости
+CREATE OR REPLACE FUNCTION bump_schedule_calendar_sequence() RETURNS TRIGGER AS $$
+BEGIN
+    IF (OLD.date, OLD.start_time, OLD.end_time, OLD.cabinet, OLD.is_available, OLD.capacity)
+        IS DISTINCT FROM (NEW.date, NEW.start_time, NEW.end_time, NEW.cabinet, NEW.is_available, NEW.capacity) THEN
+        NEW.calendar_sequence := OLD.calendar_sequence + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Возвращаем уровень сообщений по умолчанию
+RESET client_min_messages;
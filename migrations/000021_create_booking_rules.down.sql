@@ -0,0 +1,4 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS rules_override_reason;
+ALTER TABLE appointments DROP COLUMN IF EXISTS rules_override_by;
+ALTER TABLE registrars DROP COLUMN IF EXISTS is_senior;
+DROP TABLE IF EXISTS booking_rules;
@@ -0,0 +1,19 @@
+-- Правила записи по специальностям. Пересечение записей одного пациента по времени запрещено всегда,
+-- остальные ограничения задаются для специальности и не действуют, если поле не заполнено.
+CREATE TABLE IF NOT EXISTS booking_rules (
+    rule_id SERIAL PRIMARY KEY,
+    specialization VARCHAR(100) NOT NULL UNIQUE,
+    max_active_bookings INTEGER CHECK (max_active_bookings > 0),
+    min_interval_days INTEGER CHECK (min_interval_days > 0),
+    min_age INTEGER CHECK (min_age >= 0),
+    max_age INTEGER CHECK (max_age >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (min_age IS NULL OR max_age IS NULL OR min_age <= max_age)
+);
+
+-- Старшие регистраторы могут записывать пациента в обход правил
+ALTER TABLE registrars ADD COLUMN IF NOT EXISTS is_senior BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Кто и почему переопределил правила при создании записи
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS rules_override_by INTEGER REFERENCES registrars(registrar_id) ON DELETE SET NULL;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS rules_override_reason TEXT;
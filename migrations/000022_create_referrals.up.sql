@@ -0,0 +1,19 @@
+-- Направления к другому специалисту, выданные врачом на приеме
+CREATE TABLE IF NOT EXISTS referrals (
+    referral_id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(patient_id) ON DELETE CASCADE,
+    doctor_id INTEGER REFERENCES doctors(doctor_id) ON DELETE SET NULL,
+    source_appointment_id INTEGER REFERENCES appointments(appointment_id) ON DELETE SET NULL,
+    target_specialization VARCHAR(100) NOT NULL,
+    urgency VARCHAR(20) NOT NULL DEFAULT 'плановое' CHECK (urgency IN ('плановое', 'срочное', 'экстренное')),
+    expires_at DATE NOT NULL,
+    note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'открыто' CHECK (status IN ('открыто', 'исполнено', 'отменено')),
+    -- Запись, созданная по направлению
+    appointment_id INTEGER UNIQUE REFERENCES appointments(appointment_id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    fulfilled_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_patient_status ON referrals (patient_id, status);
+CREATE INDEX IF NOT EXISTS idx_referrals_open_expires ON referrals (expires_at) WHERE status = 'открыто';
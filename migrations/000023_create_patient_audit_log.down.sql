@@ -0,0 +1 @@
+DROP TABLE IF EXISTS patient_audit_log;
@@ -0,0 +1,18 @@
+-- Журнал изменений карточек пациентов: правки данных и объединение дубликатов.
+-- patient_id не ссылается на patients, чтобы записи о слитых карточках сохранялись после их удаления.
+CREATE TABLE IF NOT EXISTS patient_audit_log (
+    audit_id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('изменение', 'объединение')),
+    -- Для объединения — ID удаленной карточки-дубликата
+    merged_patient_id INTEGER,
+    actor_role VARCHAR(20) NOT NULL,
+    actor_id INTEGER,
+    actor_name VARCHAR(100),
+    reason TEXT,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_audit_log_patient ON patient_audit_log (patient_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_patient_audit_log_merged ON patient_audit_log (merged_patient_id) WHERE merged_patient_id IS NOT NULL;
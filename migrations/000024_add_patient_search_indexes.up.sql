@@ -0,0 +1,18 @@
+-- Нечеткий поиск пациентов по триграммам
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- ФИО сравнивается в нижнем регистре и без различия е/ё
+CREATE INDEX IF NOT EXISTS idx_patients_full_name_trgm
+    ON patients USING gin ((replace(lower(full_name), 'ё', 'е')) gin_trgm_ops);
+
+-- Поиск по окончанию номера телефона без учета форматирования
+CREATE INDEX IF NOT EXISTS idx_patients_phone_digits_trgm
+    ON patients USING gin ((regexp_replace(phone, '[^0-9]+', '', 'g')) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_patients_oms_trgm
+    ON patients USING gin (oms_number gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_patients_passport_trgm
+    ON patients USING gin ((passport_series || passport_number) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_patients_birth_date ON patients (birth_date);
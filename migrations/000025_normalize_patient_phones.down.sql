@@ -0,0 +1,3 @@
+-- Исходное форматирование телефонов не восстанавливается.
+DROP INDEX IF EXISTS idx_patients_phone;
+ALTER TABLE patients DROP COLUMN IF EXISTS phone_invalid;
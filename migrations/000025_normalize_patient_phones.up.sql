@@ -0,0 +1,36 @@
+-- Приведение телефонов пациентов к каноническому виду E.164 (+79001234567), как при записи из приложения.
+-- Номера, которые не удается распознать, остаются без изменений и отмечаются phone_invalid,
+-- чтобы регистратура уточнила их у пациентов.
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS phone_invalid BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE patients p
+SET phone = CASE
+    WHEN s.digits ~ '^[78][0-9]{10}$' THEN '+7' || right(s.digits, 10)
+    WHEN s.digits ~ '^[0-9]{10}$' THEN '+7' || s.digits
+    WHEN btrim(p.phone) LIKE '+%' AND length(s.digits) BETWEEN 11 AND 15 THEN '+' || s.digits
+    ELSE p.phone
+END,
+phone_invalid = NOT (
+    s.digits ~ '^[78][0-9]{10}$'
+    OR s.digits ~ '^[0-9]{10}$'
+    OR (btrim(p.phone) LIKE '+%' AND length(s.digits) BETWEEN 11 AND 15)
+)
+FROM (
+    SELECT patient_id, regexp_replace(phone, '[^0-9]+', '', 'g') AS digits
+    FROM patients
+    WHERE phone IS NOT NULL AND phone <> ''
+) s
+WHERE s.patient_id = p.patient_id;
+
+DO $$
+DECLARE
+    invalid_count INTEGER;
+BEGIN
+    SELECT count(*) INTO invalid_count FROM patients WHERE phone_invalid;
+    IF invalid_count > 0 THEN
+        RAISE WARNING 'Не удалось нормализовать телефоны у % пациентов, они отмечены phone_invalid', invalid_count;
+    END IF;
+END $$;
+
+-- Поиск пациента по телефону на киоске — точное совпадение с каноническим видом
+CREATE INDEX IF NOT EXISTS idx_patients_phone ON patients (phone);
@@ -0,0 +1,15 @@
+-- Откат возможен только до перешифрования записей: зашифрованные значения в открытые колонки не возвращаются.
+CREATE INDEX IF NOT EXISTS idx_patients_passport_trgm
+    ON patients USING gin ((passport_series || passport_number) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_patients_oms_trgm
+    ON patients USING gin (oms_number gin_trgm_ops);
+
+DROP INDEX IF EXISTS idx_patients_oms_bidx;
+DROP INDEX IF EXISTS idx_patients_passport_bidx;
+ALTER TABLE patients ADD CONSTRAINT patients_passport_series_passport_number_key UNIQUE (passport_series, passport_number);
+
+ALTER TABLE patients
+    DROP COLUMN IF EXISTS oms_bidx,
+    DROP COLUMN IF EXISTS oms_encrypted,
+    DROP COLUMN IF EXISTS passport_bidx,
+    DROP COLUMN IF EXISTS passport_encrypted;
@@ -0,0 +1,22 @@
+-- Шифрование паспорта и полиса ОМС пациентов.
+-- Значения шифруются приложением (AES-256-GCM); для точного поиска хранятся слепые индексы (HMAC-SHA256).
+-- Существующие записи перешифровываются приложением при запуске, после чего старые колонки очищаются.
+ALTER TABLE patients
+    ADD COLUMN IF NOT EXISTS passport_encrypted TEXT,
+    ADD COLUMN IF NOT EXISTS passport_bidx VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS oms_encrypted TEXT,
+    ADD COLUMN IF NOT EXISTS oms_bidx VARCHAR(64);
+
+ALTER TABLE patients
+    ALTER COLUMN passport_series DROP NOT NULL,
+    ALTER COLUMN passport_number DROP NOT NULL,
+    ALTER COLUMN oms_number DROP NOT NULL;
+
+-- Уникальность паспорта проверяется по слепому индексу
+ALTER TABLE patients DROP CONSTRAINT IF EXISTS patients_passport_series_passport_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_patients_passport_bidx ON patients (passport_bidx) WHERE passport_bidx IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_patients_oms_bidx ON patients (oms_bidx) WHERE oms_bidx IS NOT NULL;
+
+-- Триграммные индексы содержат открытые номера документов
+DROP INDEX IF EXISTS idx_patients_oms_trgm;
+DROP INDEX IF EXISTS idx_patients_passport_trgm;
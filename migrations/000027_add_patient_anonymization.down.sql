@@ -0,0 +1,9 @@
+-- Записи об анонимизации остаются в журнале, поэтому ограничение восстанавливается без проверки существующих строк.
+DROP INDEX IF EXISTS idx_patient_audit_log_action;
+ALTER TABLE patient_audit_log DROP CONSTRAINT IF EXISTS patient_audit_log_action_check;
+ALTER TABLE patient_audit_log ADD CONSTRAINT patient_audit_log_action_check
+    CHECK (action IN ('изменение', 'объединение')) NOT VALID;
+
+ALTER TABLE patients
+    DROP COLUMN IF EXISTS anonymized_at,
+    DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,12 @@
+-- Анонимизация карточек пациентов: по запросу на удаление данных и по истечении срока хранения.
+-- created_at нужен, чтобы задание хранения не анонимизировало только что зарегистрированных пациентов без визитов;
+-- существующим карточкам проставляется время миграции.
+ALTER TABLE patients
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
+
+ALTER TABLE patient_audit_log DROP CONSTRAINT IF EXISTS patient_audit_log_action_check;
+ALTER TABLE patient_audit_log ADD CONSTRAINT patient_audit_log_action_check
+    CHECK (action IN ('изменение', 'объединение', 'анонимизация'));
+
+CREATE INDEX IF NOT EXISTS idx_patient_audit_log_action ON patient_audit_log (action, created_at DESC);
@@ -0,0 +1,38 @@
+-- Архив визитов пациентов. Ночная очистка удаляет завершенные талоны и записи без талонов; перед удалением
+-- запись на прием вместе с талоном, временем ожидания и обслуживания в регистратуре и итогом визита
+-- сохраняется сюда, чтобы история пациента не терялась.
+-- patient_id не ссылается на patients: архив сохраняется и для объединенных карточек.
+CREATE TABLE IF NOT EXISTS visit_archive (
+    archive_id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL,
+    appointment_id INTEGER NOT NULL,
+    ticket_id INTEGER,
+    ticket_number VARCHAR(20),
+    service_type VARCHAR(50),
+    ticket_status VARCHAR(20),
+    is_late BOOLEAN NOT NULL DEFAULT FALSE,
+    doctor_id INTEGER,
+    doctor_name VARCHAR(100),
+    specialization VARCHAR(100),
+    cabinet INTEGER,
+    visit_date DATE NOT NULL,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    -- Результат регистрации через киоск: вовремя, рано или опоздание
+    arrival VARCHAR(20),
+    arrival_offset_minutes INTEGER,
+    ticket_created_at TIMESTAMP,
+    called_at TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    window_number INTEGER,
+    -- Ожидание вызова в регистратуру и время обслуживания в окне по reception_logs, время приема у врача
+    wait_seconds INTEGER,
+    service_seconds INTEGER,
+    visit_seconds INTEGER,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_visit_archive_appointment ON visit_archive (appointment_id);
+CREATE INDEX IF NOT EXISTS idx_visit_archive_patient ON visit_archive (patient_id, visit_date DESC);
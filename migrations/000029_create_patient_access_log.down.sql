@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS patient_access_log;
+DROP FUNCTION IF EXISTS forbid_patient_access_log_change();
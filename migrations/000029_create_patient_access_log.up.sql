@@ -0,0 +1,32 @@
+-- Журнал чтения персональных данных пациентов: кто, с какой ролью, через какой эндпоинт и когда получил данные пациента.
+-- Таблица только для добавления: изменение и удаление записей запрещено триггерами.
+-- patient_id не ссылается на patients, чтобы записи сохранялись после объединения и удаления карточек.
+CREATE TABLE IF NOT EXISTS patient_access_log (
+    access_id BIGSERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    actor_id INTEGER,
+    endpoint VARCHAR(255) NOT NULL,
+    client_ip VARCHAR(45),
+    accessed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_access_log_patient ON patient_access_log (patient_id, accessed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_patient_access_log_actor ON patient_access_log (actor_role, actor_id, accessed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_patient_access_log_accessed_at ON patient_access_log (accessed_at);
+
+CREATE OR REPLACE FUNCTION forbid_patient_access_log_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'журнал доступа к данным пациентов доступен только для добавления';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS patient_access_log_append_only ON patient_access_log;
+CREATE TRIGGER patient_access_log_append_only
+    BEFORE UPDATE OR DELETE ON patient_access_log
+    FOR EACH ROW EXECUTE FUNCTION forbid_patient_access_log_change();
+
+DROP TRIGGER IF EXISTS patient_access_log_no_truncate ON patient_access_log;
+CREATE TRIGGER patient_access_log_no_truncate
+    BEFORE TRUNCATE ON patient_access_log
+    FOR EACH STATEMENT EXECUTE FUNCTION forbid_patient_access_log_change();
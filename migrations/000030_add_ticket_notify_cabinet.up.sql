@@ -0,0 +1,50 @@
+-- Уведомление о талоне дополняется кабинетом, в который записан пациент по этому талону,
+-- чтобы табло кабинета получали только свои события.
+CREATE OR REPLACE FUNCTION notify_ticket_change() RETURNS TRIGGER AS $$
+DECLARE
+    payload JSON;
+    action TEXT;
+    channel_name TEXT := 'ticket_update';
+    data_row RECORD;
+    ticket_cabinet INT;
+BEGIN
+    action := TG_OP;
+
+    IF (TG_OP = 'DELETE') THEN
+        data_row := OLD;
+    ELSE
+        data_row := NEW;
+    END IF;
+
+    SELECT s.cabinet
+    INTO ticket_cabinet
+    FROM appointments a
+    JOIN schedules s ON s.schedule_id = a.schedule_id
+    WHERE a.ticket_id = data_row.ticket_id
+    ORDER BY s.date DESC, s.start_time DESC
+    LIMIT 1;
+
+    payload := json_build_object(
+        'action', lower(action),
+        'cabinet', ticket_cabinet,
+        'data', json_build_object(
+            'ticket_id', data_row.ticket_id,
+            'ticket_number', data_row.ticket_number,
+            'status', data_row.status,
+            'service_type', data_row.service_type,
+            'window_number', data_row.window_number,
+
+            'qr_code', encode(data_row.qr_code, 'base64'),
+
+            'created_at', to_char(data_row.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+            'called_at', to_char(data_row.called_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+            'started_at', to_char(data_row.started_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+            'completed_at', to_char(data_row.completed_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+        )
+    );
+
+    PERFORM pg_notify(channel_name, payload::text);
+
+    RETURN data_row;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,13 @@
+-- Реестр устройств отображения: табло регистратуры, экраны у кабинетов, табло расписания и киоски.
+-- Устройство авторизуется токеном; в базе хранится только его SHA-256.
+CREATE TABLE IF NOT EXISTS displays (
+    display_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('reception', 'cabinet', 'schedule', 'kiosk')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    -- Окна и кабинеты, плейлист рекламы, настройки звука и темы оформления
+    settings JSONB NOT NULL DEFAULT '{}'::jsonb,
+    last_seen_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Момент, когда диспетчер забрал уведомление на отправку. Строки забираются с FOR UPDATE SKIP LOCKED
+-- и помечаются в той же транзакции, поэтому два экземпляра (например, при смене лидера) не отправят
+-- одно уведомление дважды. Если экземпляр упал во время отправки, уведомление снова становится
+-- доступным по истечении срока захвата.
+ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;